@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func NewAdoptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "adopt [path]",
+		Short: "Register an existing workspace without running scripts",
+		Long:  "Register an already-working directory as an environment and cache its build artifacts.\nInit and setup scripts are skipped and existing containers are left untouched.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			absPath, err := resolvePath(args)
+			if err != nil {
+				return err
+			}
+
+			if _, err := os.Stat(absPath); err != nil {
+				return fmt.Errorf("path does not exist: %s", absPath)
+			}
+
+			root, err := cmd.Flags().GetString("root")
+			if err != nil {
+				return err
+			}
+
+			return mono.Adopt(absPath, root)
+		},
+	}
+
+	cmd.Flags().String("root", "", "Project root used for caching (defaults to CONDUCTOR_ROOT_PATH or the main git worktree)")
+
+	return cmd
+}
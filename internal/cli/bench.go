@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func NewBenchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bench [root]",
+		Short: "Measure cold builds against warm cache restores",
+		Long:  "Check out HEAD of a project root into a scratch worktree and, for each artifact with a warm_command,\ntime a cold build, then a restore from a scratch cache followed by the same build, and report the\ntime and disk each workspace saves. Uses the root's mono.yml; the real cache is left untouched.\nIf no path is provided, uses CONDUCTOR_ROOT_PATH or the current directory.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			artifacts, err := cmd.Flags().GetStringSlice("artifact")
+			if err != nil {
+				return err
+			}
+
+			absRoot, err := resolveRoot(args)
+			if err != nil {
+				return err
+			}
+
+			results, err := mono.Bench(absRoot, mono.BenchOptions{Artifacts: artifacts})
+			if err != nil {
+				return err
+			}
+
+			if len(results) == 0 {
+				fmt.Println("No artifacts configured or detected.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ARTIFACT\tCOLD\tRESTORE\tWARM BUILD\tTIME SAVED\tSIZE\tDISK SAVED\tRESULT")
+
+			failed := 0
+			for _, r := range results {
+				switch {
+				case r.Err != nil:
+					failed++
+					fmt.Fprintf(w, "%s\t\t\t\t\t\t\tfailed: %s\n", r.Name, r.Err)
+				case r.Status != "ok":
+					fmt.Fprintf(w, "%s\t\t\t\t\t\t\t%s\n", r.Name, r.Status)
+				default:
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+						r.Name,
+						r.ColdBuild.Round(time.Millisecond),
+						r.Restore.Round(time.Millisecond),
+						r.WarmBuild.Round(time.Millisecond),
+						r.TimeSaved().Round(time.Millisecond),
+						formatSize(r.Size),
+						formatSize(r.DiskSaved()),
+						r.Status,
+					)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			if failed > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d artifacts failed to benchmark", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSlice("artifact", nil, "Only benchmark these artifacts")
+
+	return cmd
+}
@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/gwuah/mono/internal/mono"
@@ -20,15 +24,48 @@ func NewCacheCmd() *cobra.Command {
 
 	cmd.AddCommand(newCacheStatsCmd())
 	cmd.AddCommand(newCacheCleanCmd())
+	cmd.AddCommand(newCacheGCCmd())
+	cmd.AddCommand(newCacheWarmCmd())
+	cmd.AddCommand(newCacheDiffCmd())
+	cmd.AddCommand(newCacheVerifyCmd())
+	cmd.AddCommand(newCacheKeyCmd())
+	cmd.AddCommand(newCacheTopCmd())
 
 	return cmd
 }
 
 func newCacheStatsCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "stats",
 		Short: "Show cache usage statistics",
+		Long:  "Show cache entries with their hits and sizes.\nSizes come from the index recorded when entries are stored; use --recalculate to walk\nthe cache and report disk usage shared with live environments.\nUse --format csv or --format json (or --json) to export sizes, hits, misses, last use and key\ncomponents of every entry, followed by per-project totals (CSV rows with an empty artifact).\nFilter entries with --project, --artifact (a glob such as 'npm*'), --older-than and --min-size.\nUse --sccache to show each project's sccache server and its compile-cache hit rate instead.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			recalculate, err := cmd.Flags().GetBool("recalculate")
+			if err != nil {
+				return err
+			}
+			sccache, err := cmd.Flags().GetBool("sccache")
+			if err != nil {
+				return err
+			}
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return err
+			}
+			asJSON, err := cmd.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				if cmd.Flags().Changed("format") && format != mono.CacheStatsFormatJSON {
+					return fmt.Errorf("--json cannot be combined with --format %s", format)
+				}
+				format = mono.CacheStatsFormatJSON
+			}
+			if !slices.Contains([]string{mono.CacheStatsFormatTable, mono.CacheStatsFormatCSV, mono.CacheStatsFormatJSON}, format) {
+				return fmt.Errorf("unknown format %q (use table, csv or json)", format)
+			}
+
 			cm, err := mono.NewCacheManager()
 			if err != nil {
 				return err
@@ -40,26 +77,50 @@ func newCacheStatsCmd() *cobra.Command {
 			}
 			defer db.Close()
 
-			sizes, err := cm.GetCacheSizes()
+			if sccache {
+				return printProjectSccacheStats(cm, db)
+			}
+
+			var sizes []mono.CacheSizeEntry
+			var usage mono.CacheUsage
+			if recalculate {
+				sizes, usage, err = cm.RecalculateCacheSizes(db)
+			} else {
+				sizes, err = cm.GetCacheSizes(db)
+			}
 			if err != nil {
 				return err
 			}
 
-			if len(sizes) == 0 {
-				fmt.Println("No cache entries found.")
-				return nil
+			stats, err := db.GetCacheStats()
+			if err != nil {
+				return err
 			}
 
-			stats, err := db.GetCacheStats()
+			filter, used, err := cacheFilterFromFlags(cmd, db, sizes)
 			if err != nil {
 				return err
 			}
+			sizes = filter.Apply(sizes, stats, time.Now())
+
+			if len(sizes) == 0 && format == mono.CacheStatsFormatTable {
+				fmt.Println("No cache entries found.")
+				return nil
+			}
 
 			rootPaths, err := db.GetAllRootPaths()
 			if err != nil {
 				return err
 			}
 
+			if format != mono.CacheStatsFormatTable {
+				records, err := mono.BuildCacheStatsRecords(sizes, stats, rootPaths)
+				if err != nil {
+					return err
+				}
+				return mono.WriteCacheStats(os.Stdout, records, format)
+			}
+
 			projectNames := buildProjectNameMap(rootPaths)
 
 			statsMap := make(map[string]mono.CacheEntry)
@@ -68,12 +129,18 @@ func newCacheStatsCmd() *cobra.Command {
 				statsMap[key] = s
 			}
 
-			fmt.Printf("%-20s %-10s %-12s %6s %8s   %s\n", "Project", "Artifact", "Key", "Hits", "Size", "Last Used")
-			fmt.Println(strings.Repeat("─", 80))
+			t := newTable(
+				column{header: "Project", truncate: truncateMiddle},
+				column{header: "Artifact", truncate: truncateEnd},
+				column{header: "Key", truncate: truncateEnd},
+				column{header: "Hits", right: true},
+				column{header: "Size", right: true},
+				column{header: "Disk", right: true},
+				column{header: "Last Used"},
+			)
+			t.rule = true
 
-			var totalSize int64
 			for _, entry := range sizes {
-				totalSize += entry.Size
 				key := entry.ProjectID + "/" + entry.Artifact + "/" + entry.CacheKey
 
 				hits := 0
@@ -88,26 +155,412 @@ func newCacheStatsCmd() *cobra.Command {
 					projectName = name
 				}
 
-				fmt.Printf("%-20s %-10s %-12s %6d %8s   %s\n",
-					projectName,
-					entry.Artifact,
-					entry.CacheKey,
-					hits,
-					formatSize(entry.Size),
-					lastUsed,
+				hitsColor := colorDim
+				if hits > 0 {
+					hitsColor = colorGreen
+				}
+
+				t.add(
+					cell{text: projectName},
+					cell{text: entry.Artifact},
+					cell{text: entry.CacheKey},
+					cell{text: strconv.Itoa(hits), color: hitsColor},
+					cell{text: formatSize(entry.Size)},
+					cell{text: formatSize(entry.DiskUsage)},
+					cell{text: lastUsed},
 				)
 			}
 
-			fmt.Println(strings.Repeat("─", 80))
-			fmt.Printf("Total: %d entries, %s\n", len(sizes), formatSize(totalSize))
+			if err := t.render(os.Stdout); err != nil {
+				return err
+			}
+
+			if !recalculate || len(used) > 0 {
+				var logical, disk int64
+				for _, entry := range sizes {
+					logical += entry.Size
+					disk += entry.DiskUsage
+				}
+				fmt.Printf("Total: %d entries, %s logical, %s on disk\n", len(sizes), formatSize(logical), formatSize(disk))
+			} else {
+				fmt.Printf("Total: %d entries, %s logical\n", len(sizes), formatSize(usage.Logical))
+			}
+			if recalculate {
+				label := "Actual disk usage"
+				if len(used) > 0 {
+					label = "Whole cache disk usage"
+				}
+				fmt.Printf("%s: %s (%s shared with environments, %s reclaimable)\n", label,
+					formatSize(usage.Disk),
+					formatSize(usage.SharedWithEnv),
+					formatSize(usage.Reclaimable()),
+				)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("recalculate", false, "Walk every cache entry and rebuild the size index")
+	cmd.Flags().Bool("sccache", false, "Show per-project sccache servers and their compile-cache hit rates")
+	cmd.Flags().String("format", mono.CacheStatsFormatTable, "Output format: table, csv or json")
+	cmd.Flags().Bool("json", false, "Shorthand for --format json")
+	addCacheFilterFlags(cmd, "Only show entries")
+
+	return cmd
+}
+
+func newCacheWarmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "warm [root]",
+		Short: "Populate the cache from a project root",
+		Long:  "Compute cache keys for the artifacts of a project root and, on a miss, run each artifact's\nwarm_command in the root and store the result so new workspaces hit the cache.\nIf no path is provided, uses CONDUCTOR_ROOT_PATH or the current directory.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			absRoot, err := resolveRoot(args)
+			if err != nil {
+				return err
+			}
+
+			results, err := mono.WarmCache(absRoot)
+			if err != nil {
+				return err
+			}
+
+			if len(results) == 0 {
+				fmt.Println("No artifacts configured or detected.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ARTIFACT\tKEY\tRESULT\tDURATION")
+
+			failed := 0
+			for _, r := range results {
+				status := r.Status
+				if r.Err != nil {
+					failed++
+					status = "failed: " + r.Err.Error()
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, r.Key, status, r.Duration.Round(time.Millisecond))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d artifacts failed to warm", failed, len(results))
+			}
+			return nil
+		},
+	}
+}
+
+func newCacheDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <artifact> <from-key> <to-key>",
+		Short: "Compare two cache entries of an artifact",
+		Long:  "Show files added, removed and changed between two cache entries of the same artifact.\nKeys may be given as unique prefixes.",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit, err := cmd.Flags().GetInt("limit")
+			if err != nil {
+				return err
+			}
+
+			cm, err := mono.NewCacheManager()
+			if err != nil {
+				return err
+			}
+
+			diff, err := cm.DiffCacheEntries(args[0], args[1], args[2])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%s: %s (%s) -> %s (%s), %s\n",
+				args[0],
+				diff.From.CacheKey, formatSize(diff.From.Size),
+				diff.To.CacheKey, formatSize(diff.To.Size),
+				formatSizeDelta(diff.SizeDelta()),
+			)
+			fmt.Printf("%d added, %d removed, %d changed\n",
+				diff.Count(mono.FileAdded),
+				diff.Count(mono.FileRemoved),
+				diff.Count(mono.FileChanged),
+			)
+
+			if len(diff.Changes) == 0 {
+				return nil
+			}
+
+			changes := diff.Changes
+			if limit > 0 && len(changes) > limit {
+				changes = changes[:limit]
+			}
+
+			fmt.Println()
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CHANGE\tDELTA\tPATH")
+			for _, c := range changes {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", c.Kind, formatSizeDelta(c.Delta()), c.Path)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			if len(changes) < len(diff.Changes) {
+				fmt.Printf("... %d more (use --limit 0 to show all)\n", len(diff.Changes)-len(changes))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Int("limit", 20, "Maximum number of changed files to list, largest first (0 for all)")
+
+	return cmd
+}
+
+func newCacheGCCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Evict cache entries by age and size budget",
+		Long:  "Evict cache entries not used within --older-than (such as 14d, 2w or 36h), then evict the least\nrecently used of the rest until the cache fits in --max-size (such as 50GB). Last use comes from\nrecorded cache hits and misses, falling back to when the entry was stored.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			olderThan, err := cmd.Flags().GetString("older-than")
+			if err != nil {
+				return err
+			}
+			maxSize, err := cmd.Flags().GetString("max-size")
+			if err != nil {
+				return err
+			}
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return err
+			}
+			if !dryRun {
+				cleanupStaleState()
+			}
+			if olderThan == "" && maxSize == "" {
+				return fmt.Errorf("set --older-than, --max-size or both")
+			}
+
+			opts := mono.CacheGCOptions{DryRun: dryRun}
+			if olderThan != "" {
+				if opts.OlderThan, err = mono.ParseAge(olderThan); err != nil {
+					return err
+				}
+			}
+			if maxSize != "" {
+				if opts.MaxSize, err = mono.ParseByteSize(maxSize); err != nil {
+					return err
+				}
+			}
+
+			cm, err := mono.NewCacheManager()
+			if err != nil {
+				return err
+			}
+			db, err := mono.OpenDB()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			result, err := cm.GC(db, opts)
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				return printDryRunEvictions(cm, result.Evictions)
+			}
+
+			packages, blobs, err := pruneStores(cm)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Removed %d entries (%s), %d unused packages, %d unused blobs; kept %d entries (%s)\n",
+				len(result.Evictions), formatSize(result.Freed), packages, blobs, result.Kept, formatSize(result.KeptSize))
+			return nil
+		},
+	}
+
+	cmd.Flags().String("older-than", "", "Evict entries not used for this long, such as 14d, 2w or 36h")
+	cmd.Flags().String("max-size", "", "Evict least recently used entries until the cache fits in this size, such as 50GB")
+	cmd.Flags().Bool("dry-run", false, "Print what would be removed without removing anything")
+
+	return cmd
+}
+
+func newCacheVerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check cached artifacts against their manifests",
+		Long:  "Hash every cache entry and compare it with the manifest recorded when it was stored.\nWith --repair, corrupt entries are moved to ~/.mono/cache_quarantine so the next init treats them as a miss.\nExits non-zero when corrupt entries remain.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repair, err := cmd.Flags().GetBool("repair")
+			if err != nil {
+				return err
+			}
+			if repair {
+				cleanupStaleState()
+			}
+
+			cm, err := mono.NewCacheManager()
+			if err != nil {
+				return err
+			}
+
+			results, err := cm.VerifyCache(repair)
+			if err != nil {
+				return err
+			}
+
+			var evictions []mono.CacheEviction
+			for _, r := range results {
+				if r.Status == mono.VerifyQuarantined {
+					evictions = append(evictions, mono.CacheEviction{Entry: r.Entry, Reason: fmt.Sprintf("corrupt, quarantined by cache verify (%d problems)", len(r.Problems))})
+				}
+			}
+			if len(evictions) > 0 {
+				db, err := mono.OpenDB()
+				if err != nil {
+					return err
+				}
+				defer db.Close()
+				index, err := db.GetCacheEntryIndex()
+				if err != nil {
+					return err
+				}
+				for i, e := range evictions {
+					evictions[i].Entry.Size = index[e.Entry.ProjectID+"/"+e.Entry.Artifact+"/"+e.Entry.CacheKey].Size
+				}
+				if err := mono.RecordCacheEvictions(db, evictions); err != nil {
+					return err
+				}
+			}
 
+			if len(results) == 0 {
+				fmt.Println("No cache entries found.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PROJECT\tARTIFACT\tKEY\tSTATUS")
+			corrupt := 0
+			for _, r := range results {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Entry.ProjectID, r.Entry.Artifact, r.Entry.CacheKey, r.Status)
+				if r.Status == mono.VerifyCorrupt {
+					corrupt++
+				}
+				for i, p := range r.Problems {
+					if i == 5 {
+						fmt.Fprintf(w, "\t\t\t  ... %d more\n", len(r.Problems)-i)
+						break
+					}
+					fmt.Fprintf(w, "\t\t\t  %s\n", p)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			if corrupt > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d cache entries are corrupt (use --repair to quarantine them)", corrupt, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("repair", false, "Quarantine corrupt entries so they are rebuilt on the next miss")
+
+	return cmd
+}
+
+func newCacheKeyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "key",
+		Short: "Print the public key used to sign cache manifests",
+		Long:  "Print this machine's cache signing public key, creating the key pair on first use.\nAdd it to cache.trusted_keys in ~/.mono/config.yml on machines that should accept entries signed here.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := mono.CachePublicKey()
+			if err != nil {
+				return err
+			}
+			fmt.Println(key)
 			return nil
 		},
 	}
 }
 
+func printProjectSccacheStats(cm *mono.CacheManager, db *mono.DB) error {
+	projects, err := cm.ProjectSccacheStats(db)
+	if err != nil {
+		return err
+	}
+	if len(projects) == 0 {
+		fmt.Println("No projects found.")
+		return nil
+	}
+
+	t := newTable(
+		column{header: "Project", truncate: truncateMiddle},
+		column{header: "Port", right: true},
+		column{header: "Compilations", right: true},
+		column{header: "Hits", right: true},
+		column{header: "Misses", right: true},
+		column{header: "Hit Rate", right: true},
+		column{header: "Size", right: true},
+	)
+	t.rule = true
+
+	for _, p := range projects {
+		project := formatProjectName(p.Server.RootPath)
+		if p.Server.Shared {
+			project = "(shared)"
+		}
+		if !p.Stats.Running {
+			t.add(
+				cell{text: project},
+				cell{text: strconv.Itoa(p.Server.Port)},
+				cell{text: "not running", color: colorDim},
+				cell{text: "-", color: colorDim},
+				cell{text: "-", color: colorDim},
+				cell{text: "-", color: colorDim},
+				cell{text: "-", color: colorDim},
+			)
+			continue
+		}
+		rateColor := colorDim
+		if p.Stats.Hits > 0 {
+			rateColor = colorGreen
+		}
+		t.add(
+			cell{text: project},
+			cell{text: strconv.Itoa(p.Server.Port)},
+			cell{text: strconv.FormatInt(p.Stats.CompileCount, 10)},
+			cell{text: strconv.FormatInt(p.Stats.Hits, 10)},
+			cell{text: strconv.FormatInt(p.Stats.Misses, 10)},
+			cell{text: fmt.Sprintf("%.0f%%", p.Stats.HitRate()), color: rateColor},
+			cell{text: formatSize(p.Stats.CacheSize)},
+		)
+	}
+	return t.render(os.Stdout)
+}
+
+func formatSizeDelta(delta int64) string {
+	if delta < 0 {
+		return "-" + formatSize(-delta)
+	}
+	return "+" + formatSize(delta)
+}
+
 func buildProjectNameMap(rootPaths []string) map[string]string {
-	nameMap := make(map[string]string)
+	nameMap := map[string]string{mono.SharedProjectID: "(shared)"}
 	for _, rootPath := range rootPaths {
 		projectID := mono.ComputeProjectID(rootPath)
 		nameMap[projectID] = formatProjectName(rootPath)
@@ -138,12 +591,8 @@ func newCacheCleanCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "clean",
 		Short: "Remove cached artifacts",
-		Long:  "Interactively select and remove cached build artifacts.",
+		Long:  "Interactively select and remove cached build artifacts.\nUses fzf when installed and falls back to a numbered prompt otherwise.\n--artifact alone removes every cached key of that artifact; with --older-than, --min-size, --project\nalone or an artifact glob such as 'npm*', every matching entry is removed without a selection prompt.\nWith --dry-run, print the entries that would be removed, their sizes and why, without removing anything.\nRemovals are recorded as evict events in the cache event log.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if _, err := exec.LookPath("fzf"); err != nil {
-				return fmt.Errorf("fzf not found (install with: brew install fzf)")
-			}
-
 			cm, err := mono.NewCacheManager()
 			if err != nil {
 				return err
@@ -159,8 +608,31 @@ func newCacheCleanCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			artifact, err := cmd.Flags().GetString("artifact")
+			if err != nil {
+				return err
+			}
+			project, err := cmd.Flags().GetString("project")
+			if err != nil {
+				return err
+			}
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return err
+			}
+			if !dryRun {
+				cleanupStaleState()
+			}
+			filtered := cmd.Flags().Changed("older-than") || cmd.Flags().Changed("min-size") || (project != "" && artifact == "") || strings.ContainsAny(artifact, "*?[")
+
+			if all && (artifact != "" || project != "" || filtered) {
+				return fmt.Errorf("--all cannot be combined with filters")
+			}
+			if artifact != "" && !filtered {
+				return cleanArtifact(cmd, cm, db, artifact, project, dryRun)
+			}
 
-			sizes, err := cm.GetCacheSizes()
+			sizes, err := cm.GetCacheSizes(db)
 			if err != nil {
 				return err
 			}
@@ -170,15 +642,43 @@ func newCacheCleanCmd() *cobra.Command {
 				return nil
 			}
 
+			if filtered {
+				return cleanFiltered(cmd, cm, db, sizes, dryRun)
+			}
+
 			if all {
-				count, totalSize, err := cm.RemoveAllCache()
+				var totalSize int64
+				var evictions []mono.CacheEviction
+				for _, entry := range sizes {
+					totalSize += entry.Size
+					evictions = append(evictions, mono.CacheEviction{Entry: entry, Reason: "cache clean --all"})
+				}
+				if dryRun {
+					return printDryRunEvictions(cm, evictions)
+				}
+				item := fmt.Sprintf("all %d cache entries (%s) and their hit statistics", len(sizes), formatSize(totalSize))
+				confirmed, err := confirmRemoval(cmd, "clean the cache", []string{item})
+				if err != nil || !confirmed {
+					return err
+				}
+				count, err := cm.RemoveAllCache()
 				if err != nil {
 					return err
 				}
 				if err := db.DeleteAllCacheEvents(); err != nil {
 					return fmt.Errorf("failed to clear cache events: %w", err)
 				}
-				fmt.Printf("Removed %d entries (%s)\n", count, formatSize(totalSize))
+				if err := db.DeleteAllCacheEntries(); err != nil {
+					return fmt.Errorf("failed to clear cache index: %w", err)
+				}
+				if err := mono.RecordCacheEvictions(db, evictions); err != nil {
+					return err
+				}
+				packages, blobs, err := pruneStores(cm)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Removed %d entries (%s), %d unused packages, %d unused blobs\n", count, formatSize(totalSize), packages, blobs)
 				return nil
 			}
 
@@ -235,7 +735,7 @@ func newCacheCleanCmd() *cobra.Command {
 				})
 			}
 
-			selected, err := selectCachesWithFzf(displayEntries)
+			selected, err := selectCaches(displayEntries)
 			if err != nil {
 				return err
 			}
@@ -246,26 +746,271 @@ func newCacheCleanCmd() *cobra.Command {
 			}
 
 			var totalRemoved int64
+			var evictions []mono.CacheEviction
 			for _, entry := range selected {
-				if err := cm.RemoveCacheEntry(entry.ProjectID, entry.Artifact, entry.CacheKey); err != nil {
-					return fmt.Errorf("failed to remove %s/%s: %w", entry.ProjectID, entry.Artifact, err)
-				}
-				if err := db.DeleteCacheEvents(entry.ProjectID, entry.Artifact, entry.CacheKey); err != nil {
-					return fmt.Errorf("failed to delete cache events: %w", err)
-				}
+				evictions = append(evictions, mono.CacheEviction{Entry: entry, Reason: "selected in cache clean"})
 				totalRemoved += entry.Size
 			}
+			if dryRun {
+				return printDryRunEvictions(cm, evictions)
+			}
+			evicted, err := cm.EvictCacheEntries(db, evictions)
+			if err != nil {
+				return err
+			}
 
-			fmt.Printf("Removed %d entries (%s)\n", len(selected), formatSize(totalRemoved))
+			packages, blobs, err := pruneStores(cm)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Removed %d entries (%s), %d unused packages, %d unused blobs\n", len(evicted), formatSize(evictedSize(evicted)), packages, blobs)
+			printBusyEntries(len(evictions) - len(evicted))
 			return nil
 		},
 	}
 
-	cmd.Flags().Bool("all", false, "Remove all cached entries without prompting")
+	cmd.Flags().Bool("all", false, "Remove all cached entries")
+	addCacheFilterFlags(cmd, "Remove entries")
+	cmd.Flags().Bool("dry-run", false, "Print what would be removed without removing anything")
 
 	return cmd
 }
 
+func addCacheFilterFlags(cmd *cobra.Command, verb string) {
+	cmd.Flags().String("project", "", verb+" of this project, by name, root path or project ID")
+	cmd.Flags().String("artifact", "", verb+" of this artifact, or artifacts matching a glob such as 'npm*'")
+	cmd.Flags().String("older-than", "", verb+" not used for this long, such as 30d, 2w or 36h")
+	cmd.Flags().String("min-size", "", verb+" of at least this size, such as 500MB")
+}
+
+func cacheFilterFromFlags(cmd *cobra.Command, db *mono.DB, sizes []mono.CacheSizeEntry) (mono.CacheFilter, []string, error) {
+	var filter mono.CacheFilter
+	var used []string
+	for _, name := range []string{"project", "artifact", "older-than", "min-size"} {
+		value, err := cmd.Flags().GetString(name)
+		if err != nil {
+			return filter, nil, err
+		}
+		if value == "" {
+			continue
+		}
+		used = append(used, "--"+name+" "+value)
+		switch name {
+		case "project":
+			var projects []string
+			for _, entry := range sizes {
+				if !slices.Contains(projects, entry.ProjectID) {
+					projects = append(projects, entry.ProjectID)
+				}
+			}
+			filter.ProjectID, err = mono.ResolveCacheProject(db, value, projects)
+		case "artifact":
+			filter.Artifact = value
+			err = filter.Validate()
+		case "older-than":
+			filter.OlderThan, err = mono.ParseAge(value)
+		case "min-size":
+			filter.MinSize, err = mono.ParseByteSize(value)
+		}
+		if err != nil {
+			return filter, nil, err
+		}
+	}
+	return filter, used, nil
+}
+
+func cleanFiltered(cmd *cobra.Command, cm *mono.CacheManager, db *mono.DB, sizes []mono.CacheSizeEntry, dryRun bool) error {
+	stats, err := db.GetCacheStats()
+	if err != nil {
+		return err
+	}
+	filter, used, err := cacheFilterFromFlags(cmd, db, sizes)
+	if err != nil {
+		return err
+	}
+
+	matched := filter.Apply(sizes, stats, time.Now())
+	if len(matched) == 0 {
+		fmt.Println("No cache entries match.")
+		return nil
+	}
+
+	var totalSize int64
+	var evictions []mono.CacheEviction
+	reason := "cache clean " + strings.Join(used, " ")
+	for _, entry := range matched {
+		totalSize += entry.Size
+		evictions = append(evictions, mono.CacheEviction{Entry: entry, Reason: reason})
+	}
+	if dryRun {
+		return printDryRunEvictions(cm, evictions)
+	}
+	item := fmt.Sprintf("%d cache entries (%s) matching %s", len(matched), formatSize(totalSize), strings.Join(used, " "))
+	confirmed, err := confirmRemoval(cmd, "clean these entries", []string{item})
+	if err != nil || !confirmed {
+		return err
+	}
+	evicted, err := cm.EvictCacheEntries(db, evictions)
+	if err != nil {
+		return err
+	}
+
+	packages, blobs, err := pruneStores(cm)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d entries (%s), %d unused packages, %d unused blobs\n", len(evicted), formatSize(evictedSize(evicted)), packages, blobs)
+	printBusyEntries(len(evictions) - len(evicted))
+	return nil
+}
+
+func cleanArtifact(cmd *cobra.Command, cm *mono.CacheManager, db *mono.DB, artifact, project string, dryRun bool) error {
+	listed, err := cm.GetCacheSizes(db)
+	if err != nil {
+		return err
+	}
+	var projects []string
+	for _, entry := range listed {
+		if entry.Artifact == artifact && !slices.Contains(projects, entry.ProjectID) {
+			projects = append(projects, entry.ProjectID)
+		}
+	}
+
+	var projectID string
+	if project != "" {
+		projectID, err = mono.ResolveCacheProject(db, project, projects)
+		if err != nil {
+			return err
+		}
+	} else {
+		switch len(projects) {
+		case 0:
+			return fmt.Errorf("no cache entries for artifact %s", artifact)
+		case 1:
+			projectID = projects[0]
+		default:
+			return fmt.Errorf("artifact %s is cached for %d projects, pick one with --project", artifact, len(projects))
+		}
+	}
+
+	keys := 0
+	for _, entry := range listed {
+		if entry.ProjectID == projectID && entry.Artifact == artifact {
+			keys++
+		}
+	}
+	if keys == 0 {
+		return fmt.Errorf("no cache entries for artifact %s in project %s", artifact, projectID)
+	}
+	if dryRun {
+		result, err := cm.CleanArtifact(db, projectID, artifact, true)
+		if err != nil {
+			return err
+		}
+		return printDryRunEvictions(cm, result.Evictions)
+	}
+	item := fmt.Sprintf("%d cached keys of %s in project %s", keys, artifact, projectID)
+	confirmed, err := confirmRemoval(cmd, "clean this artifact", []string{item})
+	if err != nil || !confirmed {
+		return err
+	}
+
+	result, err := cm.CleanArtifact(db, projectID, artifact, false)
+	if err != nil {
+		return err
+	}
+	packages, blobs, err := pruneStores(cm)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d entries of %s (%s), %d unused packages, %d unused blobs\n", result.Entries, artifact, formatSize(result.Size), packages, blobs)
+	return nil
+}
+
+func evictedSize(evictions []mono.CacheEviction) int64 {
+	var total int64
+	for _, e := range evictions {
+		total += e.Entry.Size
+	}
+	return total
+}
+
+func printBusyEntries(n int) {
+	if n > 0 {
+		fmt.Printf("Skipped %d entries in use by another mono process\n", n)
+	}
+}
+
+func pruneStores(cm *mono.CacheManager) (int, int, error) {
+	packages, err := cm.PrunePackageStore(false)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to prune package store: %w", err)
+	}
+	blobs, err := cm.PruneBlobStore(false)
+	if err != nil {
+		return len(packages), 0, fmt.Errorf("failed to prune blob store: %w", err)
+	}
+	return len(packages), len(blobs), nil
+}
+
+func printDryRunEvictions(cm *mono.CacheManager, evictions []mono.CacheEviction) error {
+	packages, err := cm.PrunePackageStore(true)
+	if err != nil {
+		return fmt.Errorf("failed to inspect package store: %w", err)
+	}
+	blobs, err := cm.PruneBlobStore(true)
+	if err != nil {
+		return fmt.Errorf("failed to inspect blob store: %w", err)
+	}
+
+	var total, packageTotal, blobTotal int64
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tARTIFACT\tKEY\tSIZE\tREASON")
+	for _, e := range evictions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Entry.ProjectID, e.Entry.Artifact, e.Entry.CacheKey, formatSize(e.Entry.Size), e.Reason)
+		total += e.Entry.Size
+	}
+	for _, pkg := range packages {
+		rel, err := filepath.Rel(cm.PackageStoreDir(), pkg.Path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "(package store)\t-\t%s\t%s\tnot linked from any cache entry or environment\n", rel, formatSize(pkg.Size))
+		packageTotal += pkg.Size
+	}
+	for _, blob := range blobs {
+		blobTotal += blob.Size
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("Dry run: would remove %d entries (%s), %d unused packages (%s) and %d unused blobs (%s); packages and blobs only linked from these entries would be pruned too\n",
+		len(evictions), formatSize(total), len(packages), formatSize(packageTotal), len(blobs), formatSize(blobTotal))
+	return nil
+}
+
+func selectCaches(entries []cacheDisplayEntry) ([]mono.CacheSizeEntry, error) {
+	if _, err := exec.LookPath("fzf"); err == nil {
+		return selectCachesWithFzf(entries)
+	}
+
+	var labels []string
+	for _, e := range entries {
+		labels = append(labels, e.label)
+	}
+
+	indexes, err := promptMultiSelect(os.Stdin, os.Stdout, "clean", labels)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []mono.CacheSizeEntry
+	for _, i := range indexes {
+		selected = append(selected, entries[i].entry)
+	}
+	return selected, nil
+}
+
 func selectCachesWithFzf(entries []cacheDisplayEntry) ([]mono.CacheSizeEntry, error) {
 	var lines []string
 	for _, e := range entries {
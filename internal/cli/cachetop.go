@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func newCacheTopCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "Show live cache activity",
+		Long:  "Show in-flight init, sync, reconcile and destroy operations across environments,\nrecent cache hits and misses, and disk usage per project, refreshing until interrupted.\nPrints a single snapshot when stdout is not a terminal or --once is set.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			interval, err := cmd.Flags().GetDuration("interval")
+			if err != nil {
+				return err
+			}
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			once, err := cmd.Flags().GetBool("once")
+			if err != nil {
+				return err
+			}
+			recent, err := cmd.Flags().GetInt("recent")
+			if err != nil {
+				return err
+			}
+
+			cm, err := mono.NewCacheManager()
+			if err != nil {
+				return err
+			}
+
+			db, err := mono.OpenDB()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			live := !once && isTerminal(os.Stdout)
+			if !live {
+				return renderCacheTop(os.Stdout, cm, db, recent, interval, false)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			for {
+				var frame bytes.Buffer
+				if err := renderCacheTop(&frame, cm, db, recent, interval, true); err != nil {
+					return err
+				}
+				if _, err := fmt.Fprint(os.Stdout, "\033[H\033[2J", frame.String()); err != nil {
+					return err
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(interval):
+				}
+			}
+		},
+	}
+
+	cmd.Flags().Duration("interval", 2*time.Second, "Refresh interval")
+	cmd.Flags().Bool("once", false, "Print a single snapshot and exit")
+	cmd.Flags().Int("recent", 10, "Number of recent cache events to show")
+
+	return cmd
+}
+
+func renderCacheTop(w io.Writer, cm *mono.CacheManager, db *mono.DB, recent int, interval time.Duration, live bool) error {
+	activity, err := cm.Activity(db, recent)
+	if err != nil {
+		return err
+	}
+	rootPaths, err := db.GetAllRootPaths()
+	if err != nil {
+		return err
+	}
+	projectNames := buildProjectNameMap(rootPaths)
+	projectName := func(projectID string) string {
+		if name, ok := projectNames[projectID]; ok {
+			return name
+		}
+		return projectID
+	}
+
+	header := "mono cache top, " + time.Now().Format("15:04:05")
+	if live {
+		header += fmt.Sprintf(" (every %s, Ctrl-C to quit)", interval)
+	}
+	fmt.Fprintf(w, "%s\n\n", header)
+
+	fmt.Fprintln(w, "In flight")
+	if len(activity.Operations) == 0 {
+		fmt.Fprintln(w, "  nothing running")
+	} else {
+		t := newTable(
+			column{header: "ENV", truncate: truncateEnd},
+			column{header: "OPERATION"},
+			column{header: "PROGRESS", truncate: truncateEnd},
+			column{header: "ELAPSED", right: true},
+		)
+		for _, op := range activity.Operations {
+			t.add(
+				cell{text: op.Env},
+				cell{text: op.Operation, color: colorYellow},
+				cell{text: op.Progress()},
+				cell{text: time.Since(op.StartedAt).Round(time.Second).String()},
+			)
+		}
+		if err := t.render(w); err != nil {
+			return err
+		}
+	}
+
+	hits, misses := 0, 0
+	for _, e := range activity.Recent {
+		switch e.Event {
+		case "hit":
+			hits++
+		case "miss":
+			misses++
+		}
+	}
+	fmt.Fprintf(w, "\nRecent events (%d hits, %d misses)\n", hits, misses)
+	if len(activity.Recent) == 0 {
+		fmt.Fprintln(w, "  no cache events recorded")
+	} else {
+		t := newTable(
+			column{header: "WHEN"},
+			column{header: "EVENT"},
+			column{header: "PROJECT", truncate: truncateMiddle},
+			column{header: "ARTIFACT", truncate: truncateEnd},
+			column{header: "KEY", truncate: truncateEnd},
+		)
+		for _, e := range activity.Recent {
+			color := colorYellow
+			switch e.Event {
+			case "hit":
+				color = colorGreen
+			case mono.CacheEventEvict:
+				color = colorDim
+			}
+			t.add(
+				cell{text: formatTimeAgo(e.Time)},
+				cell{text: e.Event, color: color},
+				cell{text: projectName(e.ProjectID)},
+				cell{text: e.Artifact},
+				cell{text: e.CacheKey},
+			)
+		}
+		if err := t.render(w); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(w, "\nDisk usage by project")
+	if len(activity.Projects) == 0 {
+		fmt.Fprintln(w, "  cache is empty")
+		return nil
+	}
+	t := newTable(
+		column{header: "PROJECT", truncate: truncateMiddle},
+		column{header: "ENTRIES", right: true},
+		column{header: "SIZE", right: true},
+		column{header: "DISK", right: true},
+	)
+	for _, p := range activity.Projects {
+		t.add(
+			cell{text: projectName(p.ProjectID)},
+			cell{text: strconv.Itoa(p.Entries)},
+			cell{text: formatSize(p.Size)},
+			cell{text: formatSize(p.DiskUsage)},
+		)
+	}
+	return t.render(w)
+}
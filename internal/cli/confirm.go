@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/unix"
+)
+
+func confirmRemoval(cmd *cobra.Command, action string, items []string) (bool, error) {
+	printItems(items)
+
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return false, err
+	}
+	if yes {
+		return true, nil
+	}
+	if !isTerminal(os.Stdin) {
+		return false, fmt.Errorf("refusing to %s without confirmation (pass --yes to skip the prompt)", action)
+	}
+
+	confirmed, err := confirm(fmt.Sprintf("%s%s? [y/N] ", strings.ToUpper(action[:1]), action[1:]))
+	if err != nil {
+		return false, err
+	}
+	if !confirmed {
+		fmt.Println("Aborted.")
+	}
+	return confirmed, nil
+}
+
+func printItems(items []string) {
+	for _, item := range items {
+		fmt.Printf("  %s\n", item)
+	}
+}
+
+func confirm(prompt string) (bool, error) {
+	fmt.Print(prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func isTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), ioctlReadTermios)
+	return err == nil
+}
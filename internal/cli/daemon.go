@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func NewDaemonCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run and manage the mono daemon",
+		Long:  "The mono daemon periodically cleans up stale locks, temp directories and compose overrides left by interrupted operations.\nThe interval comes from the daemon section of ~/.mono/config.yml.",
+	}
+
+	cmd.AddCommand(newDaemonRunCmd())
+	cmd.AddCommand(newDaemonInstallCmd())
+	cmd.AddCommand(newDaemonUninstallCmd())
+	cmd.AddCommand(newDaemonStatusCmd())
+
+	return cmd
+}
+
+func newDaemonRunCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "run",
+		Short: "Run the daemon in the foreground",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return mono.RunDaemon(ctx)
+		},
+	}
+}
+
+func newDaemonInstallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "install",
+		Short: "Start the daemon at login with launchd or systemd",
+		Long:  "Install a launchd agent (macOS) or systemd user unit (Linux) that runs 'mono daemon run' and restarts it if it exits.\nRe-run after moving the mono binary.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := mono.InstallDaemon()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Installed %s\n", path)
+			return nil
+		},
+	}
+}
+
+func newDaemonUninstallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall",
+		Short: "Stop the daemon and remove its launchd agent or systemd unit",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, removed, err := mono.UninstallDaemon()
+			if err != nil {
+				return err
+			}
+
+			if !removed {
+				fmt.Println("Daemon is not installed.")
+				return nil
+			}
+			fmt.Printf("Removed %s\n", path)
+			return nil
+		},
+	}
+}
+
+func newDaemonStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Report whether the daemon is installed, running and healthy",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			health, err := mono.CheckDaemon()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("  Service: %s", health.ServicePath)
+			if !health.Installed {
+				fmt.Print(" (not installed)")
+			} else if !health.Loaded {
+				fmt.Print(" (installed, not loaded)")
+			}
+			fmt.Println()
+
+			if hb := health.Heartbeat; hb != nil {
+				state := "exited"
+				if health.Running {
+					state = "running"
+				}
+				fmt.Printf("  PID: %d (%s)\n", hb.PID, state)
+				fmt.Printf("  Started: %s\n", hb.StartedAt.Local().Format(time.DateTime))
+				fmt.Printf("  Last tick: %s ago\n", time.Since(hb.LastTick).Round(time.Second))
+				if hb.LastError != "" {
+					fmt.Printf("  Last error: %s\n", hb.LastError)
+				}
+			} else {
+				fmt.Println("  PID: never started")
+			}
+
+			if !health.Healthy() {
+				return fmt.Errorf("daemon is not healthy")
+			}
+			fmt.Println("Daemon is healthy")
+			return nil
+		},
+	}
+}
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"fmt"
+
 	"github.com/gwuah/mono/internal/mono"
 	"github.com/spf13/cobra"
 )
@@ -9,7 +11,7 @@ func NewDestroyCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "destroy [path]",
 		Short: "Destroy an environment",
-		Long:  "Stop containers, kill tmux session, and clean up data.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH.",
+		Long:  "Stop containers, kill tmux session, and clean up data.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH.\nUse --force to clean up environments that are unregistered or have an invalid config.",
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			absPath, err := resolvePath(args)
@@ -17,9 +19,42 @@ func NewDestroyCmd() *cobra.Command {
 				return err
 			}
 
-			return mono.Destroy(absPath)
+			force, err := cmd.Flags().GetBool("force")
+			if err != nil {
+				return err
+			}
+
+			noSync, err := cmd.Flags().GetBool("no-sync")
+			if err != nil {
+				return err
+			}
+
+			syncTimeout, err := cmd.Flags().GetDuration("sync-timeout")
+			if err != nil {
+				return err
+			}
+			if syncTimeout < 0 {
+				return fmt.Errorf("--sync-timeout must not be negative")
+			}
+
+			opts := mono.DestroyOptions{Force: force, NoSync: noSync, SyncTimeout: syncTimeout}
+			items, err := mono.DescribeDestroy(absPath, opts)
+			if err != nil {
+				return err
+			}
+			confirmed, err := confirmRemoval(cmd, "destroy this environment", items)
+			if err != nil || !confirmed {
+				return err
+			}
+
+			cleanupStaleState()
+			return mono.Destroy(absPath, opts)
 		},
 	}
 
+	cmd.Flags().Bool("force", false, "Best-effort cleanup of broken or unregistered environments")
+	cmd.Flags().Bool("no-sync", false, "Skip syncing build artifacts to the cache before teardown")
+	cmd.Flags().Duration("sync-timeout", 0, "Stop syncing further artifacts once this much time has passed (default: destroy.sync_timeout in mono.yml, unlimited)")
+
 	return cmd
 }
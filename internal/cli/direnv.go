@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func NewDirenvCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "direnv [path]",
+		Short: "Export the environment's variables for direnv",
+		Long:  "Print an .envrc block exporting MONO_* variables, allocated ports and mono.yml env,\nso shells entering the workspace pick up the environment without tmux.\nUse --write to create or update the block in the workspace's .envrc; init and reconcile keep it current.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			write, err := cmd.Flags().GetBool("write")
+			if err != nil {
+				return err
+			}
+
+			absPath, err := resolvePath(args)
+			if err != nil {
+				return err
+			}
+
+			vars, err := mono.EnvironmentVars(absPath)
+			if err != nil {
+				return err
+			}
+
+			if !write {
+				fmt.Print(mono.RenderEnvrc(vars))
+				return nil
+			}
+
+			changed, err := mono.WriteEnvrc(absPath, vars)
+			if err != nil {
+				return err
+			}
+			if !changed {
+				fmt.Printf("%s is up to date\n", mono.EnvrcPath(absPath))
+				return nil
+			}
+			fmt.Printf("Updated %s\n", mono.EnvrcPath(absPath))
+			fmt.Println("Run 'direnv allow' to load it.")
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("write", false, "Create or update the mono block in the workspace's .envrc")
+
+	return cmd
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func NewHealthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "health [path]",
+		Short: "Check the health of an environment",
+		Long:  "Verify the tmux session, docker services, build artifacts, and data directory of an environment.\nExits non-zero when any check fails.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			absPath, err := resolvePath(args)
+			if err != nil {
+				return err
+			}
+
+			report, err := mono.Health(absPath)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Environment: %s\n", report.Name)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, c := range report.Checks {
+				result := "ok"
+				if !c.OK {
+					result = "FAIL"
+				}
+				fmt.Fprintf(w, "  %s\t%s\t%s\n", result, c.Name, c.Detail)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			if !report.Healthy() {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d checks failed", report.Failed(), len(report.Checks))
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func NewHooksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "Manage git hooks that keep the cache warm",
+		Long:  "Install post-checkout and post-merge hooks in the root repository. When a checkout or merge\nchanges an artifact's key files, the hook warms the cache in the background so new workspaces hit it.",
+	}
+
+	cmd.AddCommand(newHooksInstallCmd())
+	cmd.AddCommand(newHooksUninstallCmd())
+	cmd.AddCommand(newHooksRunCmd())
+
+	return cmd
+}
+
+func newHooksInstallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "install [root]",
+		Short: "Install the post-checkout and post-merge hooks",
+		Long:  "Add a mono block to the root repository's post-checkout and post-merge hooks, keeping any existing hook content.\nIf no path is provided, uses CONDUCTOR_ROOT_PATH or the current directory.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			absRoot, err := resolveRoot(args)
+			if err != nil {
+				return err
+			}
+
+			statuses, err := mono.InstallHooks(absRoot)
+			if err != nil {
+				return err
+			}
+
+			for _, status := range statuses {
+				if status.Changed {
+					fmt.Printf("Installed %s\n", status.Path)
+				} else {
+					fmt.Printf("%s is up to date\n", status.Path)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func newHooksUninstallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall [root]",
+		Short: "Remove the mono block from the git hooks",
+		Long:  "Remove the mono block from the root repository's hooks, deleting hooks that contain nothing else.\nIf no path is provided, uses CONDUCTOR_ROOT_PATH or the current directory.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			absRoot, err := resolveRoot(args)
+			if err != nil {
+				return err
+			}
+
+			statuses, err := mono.UninstallHooks(absRoot)
+			if err != nil {
+				return err
+			}
+
+			if len(statuses) == 0 {
+				fmt.Println("No mono hooks installed.")
+				return nil
+			}
+			for _, status := range statuses {
+				fmt.Printf("Removed mono hook from %s\n", status.Path)
+			}
+			return nil
+		},
+	}
+}
+
+func newHooksRunCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:    "run <hook> [args...]",
+		Short:  "Run a git hook (invoked by the installed hooks)",
+		Args:   cobra.MinimumNArgs(1),
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get working directory: %w", err)
+			}
+
+			results, err := mono.RunHook(root, args[0], args[1:])
+			if err != nil {
+				return err
+			}
+
+			for _, r := range results {
+				if r.Err != nil {
+					return fmt.Errorf("failed to warm %s: %w", r.Name, r.Err)
+				}
+			}
+			return nil
+		},
+	}
+}
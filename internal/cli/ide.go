@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func NewIDECmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ide [path]",
+		Short: "Generate editor tasks that run through mono",
+		Long:  "Generate VS Code tasks or JetBrains run configurations for the run script, sync and reconcile,\ncarrying the environment's MONO_* variables and allocated ports.\nPrints the files by default; use --write to save them into the workspace (existing non-mono tasks are kept).\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return err
+			}
+
+			write, err := cmd.Flags().GetBool("write")
+			if err != nil {
+				return err
+			}
+
+			absPath, err := resolvePath(args)
+			if err != nil {
+				return err
+			}
+
+			tasks, err := mono.IDETasks(absPath)
+			if err != nil {
+				return err
+			}
+
+			files, err := mono.RenderIDEFiles(absPath, format, tasks)
+			if err != nil {
+				return err
+			}
+
+			if !write {
+				for i, f := range files {
+					if i > 0 {
+						fmt.Println()
+					}
+					fmt.Printf("# %s\n%s", f.Path, f.Content)
+				}
+				return nil
+			}
+
+			if err := mono.WriteIDEFiles(files); err != nil {
+				return err
+			}
+			for _, f := range files {
+				fmt.Printf("Wrote %s\n", f.Path)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("format", mono.IDEFormatVSCode, "Editor format: vscode or jetbrains")
+	cmd.Flags().Bool("write", false, "Write the generated files into the workspace")
+
+	return cmd
+}
@@ -2,7 +2,11 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
 
 	"github.com/gwuah/mono/internal/mono"
 	"github.com/spf13/cobra"
@@ -10,11 +14,60 @@ import (
 
 func NewInitCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "init [path]",
+		Use:   "init [path...]",
 		Short: "Initialize a new environment",
-		Long:  "Register an environment, start containers, and create a tmux session.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH.",
-		Args:  cobra.MaximumNArgs(1),
+		Long:  "Register an environment, start containers, and create a tmux session.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH.\nMultiple paths or --batch initialize environments concurrently.\nUse --force to reconcile an environment that already exists.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			batchFile, err := cmd.Flags().GetString("batch")
+			if err != nil {
+				return err
+			}
+
+			jobs, err := cmd.Flags().GetInt("parallel")
+			if err != nil {
+				return err
+			}
+
+			force, err := cmd.Flags().GetBool("force")
+			if err != nil {
+				return err
+			}
+
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return err
+			}
+
+			root, err := cmd.Flags().GetString("root")
+			if err != nil {
+				return err
+			}
+
+			opts := mono.InitOptions{Force: force, Root: root}
+
+			if dryRun {
+				return runDryRunInit(args, batchFile, opts)
+			}
+			cleanupStaleState()
+
+			if batchFile != "" || len(args) > 1 {
+				profiling, _, err := profileEnabled(cmd)
+				if err != nil {
+					return err
+				}
+				if profiling {
+					return fmt.Errorf("--profile supports a single environment, not batch init")
+				}
+				format, err := progressFormat(cmd)
+				if err != nil {
+					return err
+				}
+				if format != "" {
+					return fmt.Errorf("--progress supports a single environment, not batch init")
+				}
+				return runBatchInit(args, batchFile, jobs, opts)
+			}
+
 			absPath, err := resolvePath(args)
 			if err != nil {
 				return err
@@ -24,9 +77,109 @@ func NewInitCmd() *cobra.Command {
 				return fmt.Errorf("path does not exist: %s", absPath)
 			}
 
-			return mono.Init(absPath)
+			envName := mono.EnvName(absPath)
+			return withProgress(cmd, envName, "init", func(out io.Writer) error {
+				return withProfile(cmd, envName, "init", func() error {
+					return mono.Init(absPath, opts, out)
+				})
+			})
 		},
 	}
 
+	cmd.Flags().String("batch", "", "File with one environment path per line to initialize")
+	cmd.Flags().Int("parallel", mono.DefaultBatchJobs, "Maximum number of environments to initialize concurrently")
+	cmd.Flags().Bool("force", false, "Reconcile an existing environment instead of failing")
+	cmd.Flags().Bool("dry-run", false, "Print what init would do without changing anything")
+	cmd.Flags().String("root", "", "Project root used for cache seeding (defaults to CONDUCTOR_ROOT_PATH or the main git worktree)")
+	addProfileFlags(cmd)
+	addProgressFlag(cmd)
+
 	return cmd
 }
+
+func runDryRunInit(args []string, batchFile string, opts mono.InitOptions) error {
+	var paths []string
+	if batchFile != "" || len(args) > 1 {
+		batchPaths, err := collectInitPaths(args, batchFile)
+		if err != nil {
+			return err
+		}
+		paths = batchPaths
+	} else {
+		absPath, err := resolvePath(args)
+		if err != nil {
+			return err
+		}
+		paths = []string{absPath}
+	}
+
+	for i, path := range paths {
+		if i > 0 {
+			fmt.Println()
+		}
+		if err := mono.DryRunInit(path, opts); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func collectInitPaths(args []string, batchFile string) ([]string, error) {
+	var paths []string
+	if batchFile != "" {
+		filePaths, err := mono.ReadBatchFile(batchFile)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, filePaths...)
+	}
+
+	for _, arg := range args {
+		absPath, err := filepath.Abs(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path: %w", err)
+		}
+		paths = append(paths, absPath)
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no paths to initialize")
+	}
+	return paths, nil
+}
+
+func runBatchInit(args []string, batchFile string, jobs int, opts mono.InitOptions) error {
+	paths, err := collectInitPaths(args, batchFile)
+	if err != nil {
+		return err
+	}
+
+	results := mono.InitBatch(paths, jobs, opts)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPATH\tSTATUS\tDURATION")
+
+	failed := 0
+	for _, r := range results {
+		name := mono.EnvName(r.Path)
+		status := "initialized"
+		if r.Result != nil && r.Result.Reconciled {
+			status = "reconciled"
+		}
+		if r.Err != nil {
+			failed++
+			status = "failed: " + r.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, r.Path, status, r.Duration.Round(time.Millisecond))
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%d initialized, %d failed\n", len(results)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d environments failed to initialize", failed, len(results))
+	}
+	return nil
+}
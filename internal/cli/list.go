@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/gwuah/mono/internal/mono"
 	"github.com/spf13/cobra"
@@ -27,21 +26,34 @@ func NewListCmd() *cobra.Command {
 				return nil
 			}
 
-			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "NAME\tPATH\tSTATUS")
+			t := newTable(
+				column{header: "NAME", truncate: truncateEnd},
+				column{header: "PATH", truncate: truncateMiddle},
+				column{header: "STATUS"},
+				column{header: "SYNCED"},
+				column{header: "CACHE"},
+				column{header: "SIZE", right: true},
+			)
 
 			for _, s := range statuses {
-				status := getStatus(s.TmuxRunning, s.DockerRunning)
-
 				path := s.Path
 				if home, err := os.UserHomeDir(); err == nil {
 					path = strings.Replace(path, home, "~", 1)
 				}
 
-				fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, path, status)
+				status := getStatus(s.TmuxRunning, s.DockerRunning)
+				cache := cacheState(s.Cache)
+				t.add(
+					cell{text: s.Name},
+					cell{text: path},
+					cell{text: status, color: statusColor(status)},
+					cell{text: formatTimeAgo(s.Cache.LastSync), color: syncColor(s.Cache)},
+					cell{text: cache, color: cacheStateColor(s.Cache)},
+					cell{text: formatSize(s.Cache.DiskUsage)},
+				)
 			}
 
-			return w.Flush()
+			return t.render(os.Stdout)
 		},
 	}
 
@@ -60,3 +72,39 @@ func getStatus(tmux, docker bool) string {
 	}
 	return "stopped"
 }
+
+func statusColor(status string) string {
+	switch status {
+	case "running":
+		return colorGreen
+	case "stopped":
+		return colorDim
+	default:
+		return colorYellow
+	}
+}
+
+func cacheState(c mono.EnvironmentCacheStatus) string {
+	if c.Artifacts == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d cached", c.Cached, c.Artifacts)
+}
+
+func cacheStateColor(c mono.EnvironmentCacheStatus) string {
+	switch {
+	case c.Artifacts == 0:
+		return colorDim
+	case c.Cached == c.Artifacts:
+		return colorGreen
+	default:
+		return colorYellow
+	}
+}
+
+func syncColor(c mono.EnvironmentCacheStatus) string {
+	if c.LastSync.IsZero() {
+		return colorDim
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+var processColors = []string{"36", "33", "32", "35", "34", "31", "96", "93", "92", "95", "94", "91"}
+
+func NewLogsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs [path]",
+		Short: "Show interleaved output of supervised processes",
+		Long:  "Show the stdout and stderr of an environment's supervised processes interleaved, each line prefixed with its process name.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH. Use -f to keep streaming new output.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			absPath, err := resolvePath(args)
+			if err != nil {
+				return err
+			}
+			follow, err := cmd.Flags().GetBool("follow")
+			if err != nil {
+				return err
+			}
+			tail, err := cmd.Flags().GetInt("tail")
+			if err != nil {
+				return err
+			}
+			processes, err := cmd.Flags().GetStringSlice("process")
+			if err != nil {
+				return err
+			}
+			noColor, err := cmd.Flags().GetBool("no-color")
+			if err != nil {
+				return err
+			}
+
+			envName := mono.EnvName(absPath)
+			state, err := mono.ReadSupervisorState(envName)
+			if err != nil {
+				return err
+			}
+			known := map[string]bool{}
+			var names []string
+			if state != nil {
+				for _, proc := range state.Processes {
+					known[proc.Name] = true
+					names = append(names, proc.Name)
+				}
+			}
+			for _, name := range processes {
+				if state != nil && !known[name] {
+					return fmt.Errorf("unknown process %q", name)
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			p := newLogPrinter(names, !noColor && colorEnabled())
+			return mono.StreamProcessLogs(ctx, envName, mono.ProcessLogOptions{
+				Processes: processes,
+				Tail:      tail,
+				Follow:    follow,
+			}, p.print)
+		},
+	}
+
+	cmd.Flags().BoolP("follow", "f", false, "Keep streaming new output")
+	cmd.Flags().IntP("tail", "n", 100, "Number of lines to show from the end of the log (0 for all)")
+	cmd.Flags().StringSliceP("process", "p", nil, "Only show output of these processes")
+	cmd.Flags().Bool("no-color", false, "Disable colored process prefixes")
+
+	return cmd
+}
+
+type logPrinter struct {
+	colors map[string]string
+	width  int
+	color  bool
+}
+
+func newLogPrinter(names []string, color bool) *logPrinter {
+	p := &logPrinter{colors: map[string]string{}, color: color}
+	for _, name := range names {
+		p.add(name)
+	}
+	return p
+}
+
+func (p *logPrinter) add(name string) {
+	p.colors[name] = processColors[len(p.colors)%len(processColors)]
+	p.width = max(p.width, len(name))
+}
+
+func (p *logPrinter) print(line mono.ProcessLogLine) error {
+	if _, ok := p.colors[line.Process]; !ok {
+		p.add(line.Process)
+	}
+	prefix := fmt.Sprintf("%s %-*s |", line.Time.Local().Format("15:04:05"), p.width, line.Process)
+	if p.color {
+		prefix = fmt.Sprintf("\033[%sm%s\033[0m", p.colors[line.Process], prefix)
+	}
+	_, err := fmt.Printf("%s %s\n", prefix, line.Text)
+	return err
+}
+
+func colorEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
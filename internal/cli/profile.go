@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func addProfileFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("profile", false, "Record per-phase and per-worker timings under ~/.mono/profiles")
+	cmd.Flags().Bool("profile-cpu", false, "Also record a pprof CPU profile (implies --profile)")
+}
+
+func profileEnabled(cmd *cobra.Command) (enabled, cpu bool, err error) {
+	enabled, err = cmd.Flags().GetBool("profile")
+	if err != nil {
+		return false, false, err
+	}
+	cpu, err = cmd.Flags().GetBool("profile-cpu")
+	if err != nil {
+		return false, false, err
+	}
+	return enabled || cpu, cpu, nil
+}
+
+func withProfile(cmd *cobra.Command, envName, command string, run func() error) error {
+	enabled, cpu, err := profileEnabled(cmd)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return run()
+	}
+
+	profiler, err := mono.StartProfiler(envName, command, cpu)
+	if err != nil {
+		return err
+	}
+
+	runErr := run()
+	if err := profiler.Finish(runErr); err != nil {
+		return errors.Join(runErr, err)
+	}
+	fmt.Fprintf(os.Stderr, "Profile written to %s\n", profiler.Dir)
+	return runErr
+}
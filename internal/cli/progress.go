@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func addProgressFlag(cmd *cobra.Command) {
+	cmd.Flags().String("progress", "", "Emit progress events on stdout (json: one NDJSON event per line, other output goes to stderr)")
+}
+
+func progressFormat(cmd *cobra.Command) (string, error) {
+	format, err := cmd.Flags().GetString("progress")
+	if err != nil {
+		return "", err
+	}
+	switch format {
+	case "", "json":
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown progress format %q (use json)", format)
+	}
+}
+
+func withProgress(cmd *cobra.Command, envName, command string, run func(out io.Writer) error) error {
+	format, err := progressFormat(cmd)
+	if err != nil {
+		return err
+	}
+	if format == "" {
+		return run(os.Stdout)
+	}
+
+	progress, err := mono.StartProgress(os.Stdout, envName, command)
+	if err != nil {
+		return err
+	}
+
+	runErr := run(os.Stderr)
+	if err := progress.Finish(runErr); err != nil {
+		return errors.Join(runErr, err)
+	}
+	return runErr
+}
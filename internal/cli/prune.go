@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func NewPruneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove orphaned environments, sessions, and containers",
+		Long:  "Find environments whose paths no longer exist, mono tmux sessions without an environment,\nand mono docker projects without an environment, then clean them up after confirmation.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			orphans, err := mono.FindOrphans()
+			if err != nil {
+				return err
+			}
+
+			for _, w := range orphans.Warnings {
+				fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+			}
+
+			if orphans.Empty() {
+				fmt.Println("Nothing to prune.")
+				return nil
+			}
+
+			var items []string
+			for _, path := range orphans.Environments {
+				items = append(items, "environment (path missing): "+path)
+			}
+			for _, session := range orphans.Sessions {
+				items = append(items, "tmux session: "+session)
+			}
+			for _, project := range orphans.DockerProjects {
+				items = append(items, "docker project: "+project)
+			}
+
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				for _, item := range items {
+					fmt.Printf("  %s\n", item)
+				}
+				fmt.Printf("Dry run: would remove %d items\n", len(items))
+				return nil
+			}
+
+			confirmed, err := confirmRemoval(cmd, "remove these", items)
+			if err != nil || !confirmed {
+				return err
+			}
+
+			cleanupStaleState()
+			return mono.Prune(orphans)
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Print what would be removed without removing anything")
+
+	return cmd
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func NewPsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ps [path]",
+		Short: "List an environment's processes and containers",
+		Long:  "Show the supervised processes and docker containers of an environment with their PID, uptime, restarts and port bindings.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH. Use --all to list every environment.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, err := cmd.Flags().GetBool("all")
+			if err != nil {
+				return err
+			}
+
+			var envs []*mono.EnvironmentProcesses
+			if all {
+				envs, err = mono.PsAll()
+				if err != nil {
+					return err
+				}
+			} else {
+				absPath, err := resolvePath(args)
+				if err != nil {
+					return err
+				}
+				env, err := mono.Ps(absPath)
+				if err != nil {
+					return err
+				}
+				envs = append(envs, env)
+			}
+
+			if len(envs) == 0 {
+				fmt.Println("No environments found.")
+				return nil
+			}
+
+			for i, env := range envs {
+				if i > 0 {
+					fmt.Println()
+				}
+				if all {
+					fmt.Printf("%s (%s)\n", env.Name, env.Path)
+				}
+				if err := printProcesses(env); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("all", false, "List processes of every environment")
+
+	return cmd
+}
+
+func printProcesses(env *mono.EnvironmentProcesses) error {
+	for _, w := range env.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s: %s\n", env.Name, w)
+	}
+
+	if len(env.Entries) == 0 {
+		fmt.Println("No processes or containers running.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tSTATUS\tPID\tUPTIME\tRESTARTS\tPORTS")
+	for _, p := range env.Entries {
+		pid, uptime, restarts, ports := "-", "-", "-", "-"
+		if p.PID > 0 {
+			pid = fmt.Sprintf("%d", p.PID)
+		}
+		if p.Uptime != "" {
+			uptime = p.Uptime
+		}
+		if p.Restarts >= 0 {
+			restarts = fmt.Sprintf("%d", p.Restarts)
+		}
+		if len(p.Ports) > 0 {
+			ports = strings.Join(p.Ports, ", ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", p.Name, p.Kind, p.Status, pid, uptime, restarts, ports)
+	}
+	return w.Flush()
+}
+
+func NewSuperviseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:    "supervise <path>",
+		Short:  "Run an environment's process supervisor (started by init)",
+		Args:   cobra.ExactArgs(1),
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return mono.RunSupervisor(ctx, args[0])
+		},
+	}
+}
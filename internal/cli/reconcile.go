@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func NewReconcileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reconcile [path]",
+		Short: "Repair drift in an environment",
+		Long:  "Detect drift such as stopped containers, a missing tmux session, or deleted artifacts,\nand repair only the broken parts without recreating the environment.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			absPath, err := resolvePath(args)
+			if err != nil {
+				return err
+			}
+
+			envName := mono.EnvName(absPath)
+			return withProgress(cmd, envName, "reconcile", func(out io.Writer) error {
+				var result *mono.ReconcileResult
+				err := withProfile(cmd, envName, "reconcile", func() error {
+					var err error
+					result, err = mono.Reconcile(absPath)
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				return printReconcileResult(cmd, out, result)
+			})
+		},
+	}
+
+	addProfileFlags(cmd)
+	addProgressFlag(cmd)
+
+	return cmd
+}
+
+func printReconcileResult(cmd *cobra.Command, out io.Writer, result *mono.ReconcileResult) error {
+	if len(result.Repaired) == 0 && len(result.Unresolved) == 0 {
+		fmt.Fprintln(out, "No drift detected.")
+		return nil
+	}
+
+	for _, r := range result.Repaired {
+		fmt.Fprintf(out, "  repaired: %s\n", r)
+	}
+	for _, c := range result.Unresolved {
+		fmt.Fprintf(out, "  unresolved: %s: %s\n", c.Name, c.Detail)
+	}
+
+	if len(result.Unresolved) > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d issues could not be repaired", len(result.Unresolved))
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/gwuah/mono/internal/mono"
 	"github.com/spf13/cobra"
 )
 
@@ -26,20 +27,80 @@ func resolvePath(args []string) (string, error) {
 	return absPath, nil
 }
 
+func resolveRoot(args []string) (string, error) {
+	root := os.Getenv("CONDUCTOR_ROOT_PATH")
+	if len(args) > 0 && args[0] != "" {
+		root = args[0]
+	}
+	if root == "" {
+		root = "."
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+
+	return absRoot, nil
+}
+
+func cleanupStaleState() {
+	if _, err := mono.CleanupStale(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to clean stale state: %v\n", err)
+	}
+}
+
 func NewRootCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "mono",
 		Short: "Runtime backend for Conductor workspaces",
 		Long:  "mono manages execution environments for Conductor workspaces - Docker containers, tmux sessions, and data directories.",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := mono.LoadGlobalConfig()
+			if err != nil {
+				return err
+			}
+			jobs, err := cmd.Flags().GetInt("jobs")
+			if err != nil {
+				return err
+			}
+			if jobs < 0 {
+				return fmt.Errorf("--jobs must not be negative")
+			}
+			if jobs > 0 {
+				cfg.Workers.Jobs = jobs
+			}
+			mono.SetGlobalConfig(cfg)
+			return nil
+		},
 	}
 
+	cmd.PersistentFlags().BoolP("yes", "y", false, "Skip confirmation prompts of destructive commands")
+	cmd.PersistentFlags().IntP("jobs", "j", 0, "Cap the workers of every cache operation")
+
 	cmd.AddCommand(NewInitCmd())
 	cmd.AddCommand(NewDestroyCmd())
 	cmd.AddCommand(NewRunCmd())
+	cmd.AddCommand(NewStopCmd())
 	cmd.AddCommand(NewListCmd())
 	cmd.AddCommand(NewSyncCmd())
 	cmd.AddCommand(NewCacheCmd())
+	cmd.AddCommand(NewBenchCmd())
+	cmd.AddCommand(NewSccacheCmd())
 	cmd.AddCommand(NewAttachCmd())
+	cmd.AddCommand(NewStatusCmd())
+	cmd.AddCommand(NewPruneCmd())
+	cmd.AddCommand(NewAdoptCmd())
+	cmd.AddCommand(NewHealthCmd())
+	cmd.AddCommand(NewReconcileCmd())
+	cmd.AddCommand(NewDirenvCmd())
+	cmd.AddCommand(NewIDECmd())
+	cmd.AddCommand(NewHooksCmd())
+	cmd.AddCommand(NewWorkspaceCmd())
+	cmd.AddCommand(NewDaemonCmd())
+	cmd.AddCommand(NewPsCmd())
+	cmd.AddCommand(NewLogsCmd())
+	cmd.AddCommand(NewSuperviseCmd())
 
 	return cmd
 }
@@ -1,25 +1,48 @@
 package cli
 
 import (
+	"fmt"
+
 	"github.com/gwuah/mono/internal/mono"
 	"github.com/spf13/cobra"
 )
 
 func NewRunCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "run [path]",
+		Use:   "run [path] [-- args...]",
 		Short: "Execute run script in tmux",
-		Long:  "Send the run script from mono.yml to the tmux session.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH.",
-		Args:  cobra.MaximumNArgs(1),
+		Long:  "Send the run script from mono.yml to the tmux session.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH.\nArguments after -- are passed to the run script as \"$@\".\nUse --direct to run the script in the foreground instead of tmux, or --detach to run it\nin the background (stop it with 'mono stop').",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			var scriptArgs []string
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				args, scriptArgs = args[:dash], args[dash:]
+			}
+			if len(args) > 1 {
+				return fmt.Errorf("accepts at most 1 path, received %d", len(args))
+			}
+
 			absPath, err := resolvePath(args)
 			if err != nil {
 				return err
 			}
 
-			return mono.Run(absPath)
+			direct, err := cmd.Flags().GetBool("direct")
+			if err != nil {
+				return err
+			}
+
+			detach, err := cmd.Flags().GetBool("detach")
+			if err != nil {
+				return err
+			}
+
+			return mono.Run(absPath, mono.RunOptions{Args: scriptArgs, Direct: direct, Detach: detach})
 		},
 	}
 
+	cmd.Flags().Bool("direct", false, "Run the script in the foreground with the environment's variables instead of in tmux")
+	cmd.Flags().BoolP("detach", "d", false, "Run the script as a tracked background process instead of in tmux")
+	cmd.MarkFlagsMutuallyExclusive("direct", "detach")
+
 	return cmd
 }
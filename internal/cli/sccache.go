@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func NewSccacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sccache",
+		Short: "Manage the sccache server",
+		Long:  "Inspect, start and stop the sccache server used as RUSTC_WRAPPER.\nEach project gets its own server with SCCACHE_DIR under ~/.mono/sccache; setting sccache.dir in\n~/.mono/config.yml shares one server instead. SCCACHE_CACHE_SIZE comes from sccache.cache_size.\nIf no root is provided, uses CONDUCTOR_ROOT_PATH or the git root of the current directory.",
+	}
+
+	cmd.AddCommand(newSccacheStatusCmd())
+	cmd.AddCommand(newSccacheStartCmd())
+	cmd.AddCommand(newSccacheStopCmd())
+
+	return cmd
+}
+
+func newSccacheStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status [root]",
+		Short: "Show whether the sccache server is running and its hit rate",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cm, server, err := projectSccacheServer(args)
+			if err != nil {
+				return err
+			}
+
+			stats, err := cm.SccacheStatus(server)
+			if err != nil {
+				return err
+			}
+			if !stats.Running {
+				fmt.Println("sccache server is not running")
+				return nil
+			}
+
+			fmt.Println("sccache server is running")
+			printSccacheStats(stats)
+			return nil
+		},
+	}
+}
+
+func newSccacheStartCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "start [root]",
+		Short: "Start the sccache server",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cm, server, err := projectSccacheServer(args)
+			if err != nil {
+				return err
+			}
+
+			started, err := cm.StartSccache(server)
+			if err != nil {
+				return err
+			}
+			if !started {
+				fmt.Println("sccache server is already running")
+				return nil
+			}
+			fmt.Println("sccache server started")
+			return nil
+		},
+	}
+}
+
+func newSccacheStopCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop [root]",
+		Short: "Stop the sccache server",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cm, server, err := projectSccacheServer(args)
+			if err != nil {
+				return err
+			}
+
+			stopped, err := cm.StopSccache(server)
+			if err != nil {
+				return err
+			}
+			if !stopped {
+				fmt.Println("sccache server is not running")
+				return nil
+			}
+			fmt.Println("sccache server stopped")
+			return nil
+		},
+	}
+}
+
+func projectSccacheServer(args []string) (*mono.CacheManager, mono.SccacheServer, error) {
+	cm, err := mono.NewCacheManager()
+	if err != nil {
+		return nil, mono.SccacheServer{}, err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, mono.SccacheServer{}, err
+	}
+	var explicit string
+	if len(args) > 0 {
+		explicit = args[0]
+	}
+	rootPath, err := mono.ResolveRootPath(cwd, explicit)
+	if err != nil {
+		return nil, mono.SccacheServer{}, err
+	}
+	return cm, cm.SccacheServer(cmp.Or(rootPath, cwd)), nil
+}
+
+func printSccacheStats(stats mono.SccacheStats) {
+	fmt.Printf("  Compilations: %d\n", stats.CompileCount)
+	fmt.Printf("  Hits: %d, misses: %d (%.0f%% hit rate)\n", stats.Hits, stats.Misses, stats.HitRate())
+	if stats.MaxCacheSize > 0 {
+		fmt.Printf("  Size: %s of %s\n", formatSize(stats.CacheSize), formatSize(stats.MaxCacheSize))
+	}
+	if stats.CacheLocation != "" {
+		fmt.Printf("  Location: %s\n", stats.CacheLocation)
+	}
+}
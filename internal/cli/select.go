@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func promptMultiSelect(in io.Reader, out io.Writer, prompt string, items []string) ([]int, error) {
+	for i, item := range items {
+		fmt.Fprintf(out, "%3d) %s\n", i+1, item)
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Fprintf(out, "%s (e.g. 1 3 5-7, all, empty to cancel): ", prompt)
+		answer, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read selection: %w", err)
+		}
+
+		selected, parseErr := parseSelection(answer, len(items))
+		if parseErr == nil {
+			return selected, nil
+		}
+		if errors.Is(err, io.EOF) {
+			return nil, parseErr
+		}
+		fmt.Fprintf(out, "%v\n", parseErr)
+	}
+}
+
+func parseSelection(answer string, count int) ([]int, error) {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "" {
+		return nil, nil
+	}
+	if answer == "a" || answer == "all" {
+		all := make([]int, count)
+		for i := range all {
+			all[i] = i
+		}
+		return all, nil
+	}
+
+	seen := make(map[int]bool)
+	var selected []int
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ' ' || r == ',' }) {
+		lo, hi, isRange := strings.Cut(field, "-")
+		start, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection %q", field)
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(hi)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selection %q", field)
+			}
+		}
+		if start < 1 || end > count || start > end {
+			return nil, fmt.Errorf("selection %q is out of range 1-%d", field, count)
+		}
+		for n := start; n <= end; n++ {
+			if !seen[n] {
+				seen[n] = true
+				selected = append(selected, n-1)
+			}
+		}
+	}
+	return selected, nil
+}
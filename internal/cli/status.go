@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func NewStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status [path]",
+		Short: "Show progress of a running operation",
+		Long:  "Query the environment's status socket for a running init, sync or destroy.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			absPath, err := resolvePath(args)
+			if err != nil {
+				return err
+			}
+
+			state, err := mono.Status(absPath)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Environment: %s\n", state.Name)
+			fmt.Printf("  Path: %s\n", state.Path)
+			if !state.Registered {
+				fmt.Printf("  Registered: no\n")
+			}
+
+			op := state.Operation
+			if op == nil {
+				fmt.Printf("  Operation: none\n")
+				return nil
+			}
+
+			fmt.Printf("  Operation: %s (pid %d, started %s)\n", op.Operation, op.PID, formatTimeAgo(op.StartedAt))
+			if progress := op.Progress(); progress != "" {
+				fmt.Printf("  Progress: %s\n", progress)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func NewStopCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stop [path]",
+		Short: "Stop a background run script",
+		Long:  "Terminate the run script started with 'mono run --detach'.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			absPath, err := resolvePath(args)
+			if err != nil {
+				return err
+			}
+
+			pid, stopped, err := mono.Stop(absPath)
+			if err != nil {
+				return err
+			}
+			if !stopped {
+				fmt.Println("No background run script running.")
+				return nil
+			}
+			fmt.Printf("Stopped run script (pid %d)\n", pid)
+			return nil
+		},
+	}
+
+	return cmd
+}
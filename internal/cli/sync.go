@@ -1,8 +1,15 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/gwuah/mono/internal/mono"
 	"github.com/spf13/cobra"
@@ -12,7 +19,7 @@ func NewSyncCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "sync <path>",
 		Short: "Sync build artifacts to cache",
-		Long:  "Save current build artifacts (target/, node_modules/) to the cache for reuse.",
+		Long:  "Save current build artifacts (target/, node_modules/) to the cache for reuse.\nWith --incremental, artifacts already cached under the current key are updated with files that changed since they were stored instead of being skipped.\nWith --watch, mono keeps running, watches the artifact directories and key files, and syncs incrementally once they have been quiet for --debounce and no build is in progress.",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			path := args[0]
@@ -22,48 +29,179 @@ func NewSyncCmd() *cobra.Command {
 				return fmt.Errorf("invalid path: %w", err)
 			}
 
-			db, err := mono.OpenDB()
+			incremental, err := cmd.Flags().GetBool("incremental")
 			if err != nil {
-				return fmt.Errorf("failed to open database: %w", err)
-			}
-			defer db.Close()
-
-			env, err := db.GetEnvironmentByPath(absPath)
-			if err != nil {
-				return fmt.Errorf("environment not found: %w", err)
+				return err
 			}
-
-			cfg, err := mono.LoadConfig(absPath)
+			watch, err := cmd.Flags().GetBool("watch")
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return err
 			}
-			cfg.ApplyDefaults(absPath)
-
-			cm, err := mono.NewCacheManager()
+			debounce, err := cmd.Flags().GetDuration("debounce")
 			if err != nil {
-				return fmt.Errorf("failed to create cache manager: %w", err)
-			}
-
-			rootPath := ""
-			if env.RootPath.Valid {
-				rootPath = env.RootPath.String
+				return err
 			}
+			cleanupStaleState()
 
-			if rootPath == "" {
-				return fmt.Errorf("environment has no root path set")
+			if watch {
+				format, err := progressFormat(cmd)
+				if err != nil {
+					return err
+				}
+				if format != "" {
+					return fmt.Errorf("--watch cannot be combined with --progress")
+				}
+				if debounce <= 0 {
+					return fmt.Errorf("--debounce must be positive")
+				}
+				return runWatch(absPath, debounce)
 			}
 
-			err = cm.Sync(cfg.Build.Artifacts, rootPath, absPath, mono.SyncOptions{
-				HardlinkBack: true,
+			envName := mono.EnvName(absPath)
+			return withProgress(cmd, envName, "sync", func(out io.Writer) error {
+				return withProfile(cmd, envName, "sync", func() error {
+					return runSync(absPath, incremental, out)
+				})
 			})
-			if err != nil {
-				return err
-			}
-
-			fmt.Println("Sync complete")
-			return nil
 		},
 	}
 
+	cmd.Flags().Bool("incremental", false, "Update existing cache entries with changed files instead of skipping them")
+	cmd.Flags().Bool("watch", false, "Keep running and sync incrementally whenever a build finishes")
+	cmd.Flags().Duration("debounce", 5*time.Second, "With --watch, how long artifacts must be quiet before syncing")
+	addProfileFlags(cmd)
+	addProgressFlag(cmd)
+
 	return cmd
 }
+
+func runSync(absPath string, incremental bool, out io.Writer) (err error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	lock, err := mono.AcquireEnvLock(ctx, mono.EnvName(absPath), "sync", os.Stderr)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, lock.Release())
+	}()
+
+	db, err := mono.OpenDB()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	target, err := newSyncTarget(db, absPath)
+	if err != nil {
+		return err
+	}
+
+	return target.sync(target.cfg.Build.Artifacts, incremental, out)
+}
+
+func runWatch(absPath string, debounce time.Duration) error {
+	db, err := mono.OpenDB()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	target, err := newSyncTarget(db, absPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Watching artifacts in %s (Ctrl-C to stop)\n", absPath)
+	return target.cm.Watch(ctx, target.cfg.Build.Artifacts, absPath, debounce, os.Stderr, func(artifacts []mono.ArtifactConfig) error {
+		lock, err := mono.AcquireEnvLock(ctx, mono.EnvName(absPath), "sync", os.Stderr)
+		if err != nil {
+			return err
+		}
+
+		if err := target.sync(artifacts, true, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: sync failed: %v\n", err)
+		}
+		return lock.Release()
+	})
+}
+
+type syncTarget struct {
+	db       *mono.DB
+	cfg      *mono.Config
+	cm       *mono.CacheManager
+	absPath  string
+	rootPath string
+}
+
+func newSyncTarget(db *mono.DB, absPath string) (*syncTarget, error) {
+	env, err := db.GetEnvironmentByPath(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("environment not found: %w", err)
+	}
+
+	cfg, err := mono.LoadConfig(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.ApplyDefaults(absPath)
+
+	cm, err := mono.NewCacheManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache manager: %w", err)
+	}
+
+	rootPath := ""
+	if env.RootPath.Valid {
+		rootPath = env.RootPath.String
+	}
+
+	if rootPath == "" {
+		return nil, fmt.Errorf("environment has no root path set")
+	}
+
+	return &syncTarget{db: db, cfg: cfg, cm: cm, absPath: absPath, rootPath: rootPath}, nil
+}
+
+func (t *syncTarget) sync(artifacts []mono.ArtifactConfig, incremental bool, out io.Writer) error {
+	status, err := mono.StartStatusServer(mono.EnvName(t.absPath), "sync")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to start status server: %v\n", err)
+	}
+	defer status.Close()
+
+	err = t.cm.Sync(artifacts, t.rootPath, t.absPath, mono.SyncOptions{
+		HardlinkBack: true,
+		Incremental:  incremental,
+		Status:       status,
+	})
+	if err != nil {
+		return err
+	}
+
+	status.SetPhase("recording cache keys")
+	entries, err := t.cm.PrepareArtifactCache(artifacts, t.rootPath, t.absPath)
+	if err != nil {
+		return err
+	}
+	if err := mono.RecordArtifactKeys(t.db, t.absPath, entries); err != nil {
+		return err
+	}
+	if err := t.cm.RecordCacheSizes(t.db, entries); err != nil {
+		return err
+	}
+	result, err := t.cm.EnforceCacheLimit(t.db, entries)
+	if err != nil {
+		return err
+	}
+	if result != nil && len(result.Evictions) > 0 {
+		fmt.Fprintf(out, "Evicted %d cache entries (%s) to stay under cache.max_size\n", len(result.Evictions), formatSize(result.Freed))
+	}
+
+	fmt.Fprintln(out, "Sync complete")
+	return nil
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	tableGap      = 2
+	tableMinWidth = 8
+)
+
+type truncation int
+
+const (
+	truncateNone truncation = iota
+	truncateEnd
+	truncateMiddle
+)
+
+const (
+	colorGreen  = "32"
+	colorYellow = "33"
+	colorDim    = "2"
+)
+
+type column struct {
+	header   string
+	right    bool
+	truncate truncation
+}
+
+type cell struct {
+	text  string
+	color string
+}
+
+type table struct {
+	columns []column
+	rows    [][]cell
+	width   int
+	color   bool
+	rule    bool
+}
+
+func newTable(columns ...column) *table {
+	return &table{
+		columns: columns,
+		width:   terminalWidth(os.Stdout),
+		color:   colorEnabled(),
+	}
+}
+
+func (t *table) add(cells ...cell) {
+	t.rows = append(t.rows, cells)
+}
+
+func (t *table) layout() []int {
+	widths := make([]int, len(t.columns))
+	for i, c := range t.columns {
+		widths[i] = utf8.RuneCountInString(c.header)
+	}
+	for _, row := range t.rows {
+		for i, c := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(c.text))
+		}
+	}
+	if t.width <= 0 {
+		return widths
+	}
+
+	excess := tableGap*(len(widths)-1) - t.width
+	for _, w := range widths {
+		excess += w
+	}
+	for excess > 0 {
+		widest := -1
+		for i, c := range t.columns {
+			if c.truncate == truncateNone || widths[i] <= tableMinWidth {
+				continue
+			}
+			if widest < 0 || widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		excess--
+	}
+	return widths
+}
+
+func (t *table) totalWidth(widths []int) int {
+	total := tableGap * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+	return total
+}
+
+func (t *table) render(w io.Writer) error {
+	widths := t.layout()
+
+	header := make([]cell, len(t.columns))
+	for i, c := range t.columns {
+		header[i] = cell{text: c.header}
+	}
+	if err := t.renderRow(w, header, widths); err != nil {
+		return err
+	}
+	if t.rule {
+		if err := t.renderRule(w, widths); err != nil {
+			return err
+		}
+	}
+	for _, row := range t.rows {
+		if err := t.renderRow(w, row, widths); err != nil {
+			return err
+		}
+	}
+	if t.rule {
+		return t.renderRule(w, widths)
+	}
+	return nil
+}
+
+func (t *table) renderRule(w io.Writer, widths []int) error {
+	_, err := fmt.Fprintln(w, strings.Repeat("─", t.totalWidth(widths)))
+	return err
+}
+
+func (t *table) renderRow(w io.Writer, row []cell, widths []int) error {
+	var b strings.Builder
+	for i, c := range row {
+		col := t.columns[i]
+		text := truncate(c.text, widths[i], col.truncate)
+		pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(text))
+		if t.color && c.color != "" {
+			text = "\033[" + c.color + "m" + text + "\033[0m"
+		}
+		last := i == len(row)-1
+		switch {
+		case col.right:
+			b.WriteString(pad + text)
+		case last:
+			b.WriteString(text)
+		default:
+			b.WriteString(text + pad)
+		}
+		if !last {
+			b.WriteString(strings.Repeat(" ", tableGap))
+		}
+	}
+	_, err := fmt.Fprintln(w, b.String())
+	return err
+}
+
+func truncate(s string, width int, mode truncation) string {
+	runes := []rune(s)
+	if mode == truncateNone || len(runes) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string(runes[:width])
+	}
+	if mode == truncateEnd {
+		return string(runes[:width-1]) + "…"
+	}
+	head := (width - 1) / 2
+	tail := width - 1 - head
+	return string(runes[:head]) + "…" + string(runes[len(runes)-tail:])
+}
+
+func terminalWidth(f *os.File) int {
+	if !isTerminal(f) {
+		return 0
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 {
+		return 0
+	}
+	return int(ws.Col)
+}
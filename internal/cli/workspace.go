@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"github.com/gwuah/mono/internal/mono"
+	"github.com/spf13/cobra"
+)
+
+func NewWorkspaceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workspace",
+		Short: "Create and remove git worktrees with their environments",
+		Long:  "Create a git worktree and initialize its environment, or destroy an environment and remove its worktree, in one step.",
+	}
+
+	cmd.AddCommand(newWorkspaceNewCmd())
+	cmd.AddCommand(newWorkspaceRmCmd())
+
+	return cmd
+}
+
+func newWorkspaceNewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "new <branch>",
+		Short: "Create a worktree for a branch and initialize it",
+		Long:  "Add a git worktree for the branch, creating the branch from --base (default HEAD) if it does not exist, then run init on it.\nThe worktree is placed in ~/.mono/workspaces/<project>/<branch> unless --dir is set.\nThe root repository defaults to CONDUCTOR_ROOT_PATH or the current directory.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootFlag, err := cmd.Flags().GetString("root")
+			if err != nil {
+				return err
+			}
+
+			dir, err := cmd.Flags().GetString("dir")
+			if err != nil {
+				return err
+			}
+
+			base, err := cmd.Flags().GetString("base")
+			if err != nil {
+				return err
+			}
+
+			absRoot, err := resolveRoot([]string{rootFlag})
+			if err != nil {
+				return err
+			}
+
+			_, err = mono.NewWorkspace(absRoot, args[0], mono.WorkspaceOptions{Dir: dir, Base: base})
+			return err
+		},
+	}
+
+	cmd.Flags().String("root", "", "Root repository to add the worktree to")
+	cmd.Flags().String("dir", "", "Directory for the worktree")
+	cmd.Flags().String("base", "", "Commit to start a new branch from (default HEAD)")
+
+	return cmd
+}
+
+func newWorkspaceRmCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rm [path]",
+		Short: "Destroy a workspace's environment and remove its worktree",
+		Long:  "Run destroy on the workspace, then remove its git worktree.\nIf no path is provided, uses CONDUCTOR_WORKSPACE_PATH.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			absPath, err := resolvePath(args)
+			if err != nil {
+				return err
+			}
+
+			force, err := cmd.Flags().GetBool("force")
+			if err != nil {
+				return err
+			}
+
+			deleteBranch, err := cmd.Flags().GetBool("delete-branch")
+			if err != nil {
+				return err
+			}
+
+			opts := mono.RemoveWorkspaceOptions{Force: force, DeleteBranch: deleteBranch}
+			items, err := mono.DescribeRemoveWorkspace(absPath, opts)
+			if err != nil {
+				return err
+			}
+			confirmed, err := confirmRemoval(cmd, "remove this workspace", items)
+			if err != nil || !confirmed {
+				return err
+			}
+
+			return mono.RemoveWorkspace(absPath, opts)
+		},
+	}
+
+	cmd.Flags().Bool("force", false, "Destroy broken environments and remove worktrees with local changes")
+	cmd.Flags().Bool("delete-branch", false, "Delete the worktree's branch after removing it")
+
+	return cmd
+}
@@ -0,0 +1,181 @@
+package mono
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func Adopt(path, root string) (err error) {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("path does not exist: %s", path)
+	}
+
+	envName := EnvName(path)
+
+	logger, err := NewFileLogger(envName)
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer logger.Close()
+
+	logger.Log("mono adopt %s", path)
+
+	ctx, stopSignals := notifyInterrupt("adopt")
+	defer stopSignals()
+
+	lock, err := AcquireEnvLock(ctx, envName, "adopt", os.Stderr)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, lock.Release())
+	}()
+
+	db, err := OpenDB()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	exists, err := db.EnvironmentExists(path)
+	if err != nil {
+		return fmt.Errorf("failed to check environment: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("environment already exists: %s", path)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.ApplyDefaults(path)
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize cache: %w", err)
+	}
+
+	tx := newRollback(logger)
+	defer tx.run()
+
+	dataDir, err := DataDir(envName)
+	if err != nil {
+		return err
+	}
+	if !dirExists(dataDir) {
+		if err := os.MkdirAll(dataDir, 0755); err != nil {
+			return fmt.Errorf("failed to create data directory: %w", err)
+		}
+		tx.add("data directory", func() error {
+			return os.RemoveAll(dataDir)
+		})
+		logger.Log("created data directory")
+	}
+
+	rootPath, err := ResolveRootPath(path, root)
+	if err != nil {
+		return err
+	}
+	composeDir := cfg.ResolveComposeDir(path)
+	_, composeErr := DetectComposeFile(composeDir)
+
+	dockerProject := ""
+	if composeErr == nil {
+		dockerProject = fmt.Sprintf("mono-%s", envName)
+	}
+
+	envID, err := db.InsertEnvironment(path, dockerProject, rootPath, cfg.ComposeDir)
+	if err != nil {
+		return fmt.Errorf("failed to save environment: %w", err)
+	}
+	tx.add("environment registration", func() error {
+		return db.DeleteEnvironment(path)
+	})
+	logger.Log("registered environment (id=%d)", envID)
+
+	var allocations []Allocation
+	if dockerProject != "" {
+		composeProject, composeAllocations, err := buildComposeOverride(composeDir, envName, envID)
+		if err != nil {
+			return err
+		}
+		allocations = composeAllocations
+
+		monoComposePath := filepath.Join(composeDir, "docker-compose.mono.yml")
+		if err := db.RecordComposeOverride(monoComposePath, path); err != nil {
+			return fmt.Errorf("failed to record compose override: %w", err)
+		}
+		tx.add("docker-compose.mono.yml", func() error {
+			if err := os.Remove(monoComposePath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return db.DeleteComposeOverride(monoComposePath)
+		})
+		if err := WriteComposeOverride(monoComposePath, composeProject); err != nil {
+			return fmt.Errorf("failed to write compose override: %w", err)
+		}
+		logger.Log("generated docker-compose.mono.yml")
+	}
+
+	sessionName := SessionName(envName)
+	if SessionExists(sessionName) {
+		logger.Log("adopted existing tmux session %s", sessionName)
+	} else {
+		cacheEnvVars := cm.EnvVars(cfg.Build, path, rootPath)
+		cacheEnvVars = append(cacheEnvVars, "MONO_CACHE_DIR="+cm.LocalCacheDir)
+		sessionEnv := buildScriptEnv(envName, envID, path, rootPath, allocations, cfg.Env, cacheEnvVars)
+		tm := NewTmuxManager(sessionName, path, cfg.Tmux)
+		if err := tm.CreateSession(sessionEnv); err != nil {
+			return fmt.Errorf("failed to create tmux session: %w", err)
+		}
+		logger.Log("created tmux session %s", sessionName)
+	}
+
+	tx.commit()
+
+	var cached []string
+	if len(cfg.Build.Artifacts) > 0 && rootPath != "" {
+		entries, err := cm.PrepareArtifactCache(cfg.Build.Artifacts, rootPath, path)
+		if err != nil {
+			logger.Log("warning: failed to prepare artifact cache: %v", err)
+		}
+		for _, entry := range entries {
+			if entry.Hit {
+				logger.Log("cache entry exists for %s (key: %s)", entry.Name, entry.Key)
+				cached = append(cached, entry.Name)
+				continue
+			}
+			if err := cm.StoreToCache(entry); err != nil {
+				logger.Log("warning: failed to store %s to cache: %v", entry.Name, err)
+				continue
+			}
+			logger.Log("stored %s to cache (key: %s)", entry.Name, entry.Key)
+			cached = append(cached, entry.Name)
+		}
+		if err := RecordArtifactKeys(db, path, entries); err != nil {
+			logger.Log("warning: %v", err)
+		}
+		if err := cm.RecordCacheSizes(db, entries); err != nil {
+			logger.Log("warning: %v", err)
+		}
+	}
+
+	fmt.Printf("Environment adopted: %s\n", envName)
+	fmt.Printf("  Path: %s\n", path)
+	fmt.Printf("  Data: %s\n", dataDir)
+	if dockerProject != "" {
+		fmt.Printf("  Docker: %s\n", dockerProject)
+		for _, alloc := range allocations {
+			fmt.Printf("  %s: %d -> %d\n", alloc.Service, alloc.ContainerPort, alloc.HostPort)
+		}
+	}
+	fmt.Printf("  Tmux: %s\n", sessionName)
+	for _, name := range cached {
+		fmt.Printf("  Cached: %s\n", name)
+	}
+
+	return nil
+}
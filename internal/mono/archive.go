@@ -0,0 +1,500 @@
+package mono
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	ArtifactFormatDir    = "dir"
+	ArtifactFormatTar    = "tar"
+	ArtifactFormatTarGz  = "tar.gz"
+	ArtifactFormatTarZst = "tar.zst"
+)
+
+const archivePrefetchLimit = 1 << 20
+
+func validArtifactFormat(format string) bool {
+	switch format {
+	case "", ArtifactFormatDir, ArtifactFormatTar, ArtifactFormatTarGz, ArtifactFormatTarZst:
+		return true
+	}
+	return false
+}
+
+func isArchiveFormat(format string) bool {
+	return format == ArtifactFormatTar || format == ArtifactFormatTarGz || format == ArtifactFormatTarZst
+}
+
+func archiveName(base, format string) string {
+	return base + "." + format
+}
+
+func findArchive(cachePath, base string) (string, bool) {
+	for _, format := range []string{ArtifactFormatTarZst, ArtifactFormatTarGz, ArtifactFormatTar} {
+		path := filepath.Join(cachePath, archiveName(base, format))
+		if fileExists(path) {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+type archiveItem struct {
+	path string
+	rel  string
+	info fs.FileInfo
+	link string
+	data []byte
+	err  error
+	done chan struct{}
+}
+
+func collectArchiveItems(src, pathType string, filter pathFilter) ([]*archiveItem, error) {
+	var items []*archiveItem
+	var mu sync.Mutex
+
+	err := parallelWalk(src, workerCount(workersWalk, src), func(path, rel string, d fs.DirEntry) error {
+		if rel == "." {
+			return nil
+		}
+		if d.IsDir() && (shouldSkipPath(rel+"/", pathType) || filter.skip(rel, true)) {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && (shouldSkipPath(rel, pathType) || filter.skip(rel, false)) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		item := &archiveItem{path: path, rel: filepath.ToSlash(rel), info: info, done: make(chan struct{})}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if item.link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		mu.Lock()
+		items = append(items, item)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].rel < items[j].rel })
+	return items, nil
+}
+
+func writeArchive(src, dst, format, pathType string, filter pathFilter) error {
+	items, err := collectArchiveItems(src, pathType, filter)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", src, err)
+	}
+
+	tmp := dst + cacheTmpSuffix
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	err = streamArchive(f, items, format)
+	if err == nil && durable() {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write archive %s: %w", dst, err)
+	}
+
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to publish archive %s: %w", dst, err)
+	}
+	return syncParent(dst)
+}
+
+func streamArchive(out io.Writer, items []*archiveItem, format string) error {
+	buf := bufio.NewWriterSize(out, 1<<20)
+	var w io.Writer = buf
+	var compressor io.WriteCloser
+	switch format {
+	case ArtifactFormatTarGz:
+		compressor = gzip.NewWriter(buf)
+	case ArtifactFormatTarZst:
+		zw, err := zstd.NewWriter(buf)
+		if err != nil {
+			return err
+		}
+		compressor = zw
+	}
+	if compressor != nil {
+		w = compressor
+	}
+	tw := tar.NewWriter(w)
+
+	workers := workerCount(workersSeed)
+	jobs := make(chan *archiveItem)
+	window := make(chan struct{}, workers*4)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		defer close(jobs)
+		for _, item := range items {
+			select {
+			case window <- struct{}{}:
+			case <-stop:
+				return
+			}
+			select {
+			case jobs <- item:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for item := range jobs {
+				if item.info.Mode().IsRegular() && item.info.Size() <= archivePrefetchLimit {
+					item.data, item.err = os.ReadFile(item.path)
+				}
+				close(item.done)
+			}
+		}()
+	}
+
+	links := make(map[inodeKey]string)
+	for _, item := range items {
+		<-item.done
+		err := writeArchiveItem(tw, item, links)
+		item.data = nil
+		<-window
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
+
+func writeArchiveItem(tw *tar.Writer, item *archiveItem, links map[inodeKey]string) error {
+	if item.err != nil {
+		return item.err
+	}
+
+	hdr, err := tar.FileInfoHeader(item.info, item.link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = item.rel
+	if item.info.IsDir() {
+		hdr.Name += "/"
+	}
+
+	if item.info.Mode().IsRegular() {
+		if stat, ok := item.info.Sys().(*syscall.Stat_t); ok && stat.Nlink > 1 {
+			key := inodeKey{dev: uint64(stat.Dev), ino: stat.Ino}
+			if first, seen := links[key]; seen {
+				hdr.Typeflag = tar.TypeLink
+				hdr.Linkname = first
+				hdr.Size = 0
+				return tw.WriteHeader(hdr)
+			}
+			links[key] = item.rel
+		}
+	}
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if !item.info.Mode().IsRegular() {
+		return nil
+	}
+
+	if item.data != nil || item.info.Size() == 0 {
+		_, err := tw.Write(item.data)
+		return err
+	}
+
+	f, err := os.Open(item.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+type extractJob struct {
+	path    string
+	mode    fs.FileMode
+	modTime time.Time
+	data    []byte
+}
+
+func extractArchive(archive, dst string, workers int, limiter *ioLimiter) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = bufio.NewReaderSize(f, 1<<20)
+	if strings.HasSuffix(archive, "."+ArtifactFormatTarGz) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", archive, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	if strings.HasSuffix(archive, "."+ArtifactFormatTarZst) {
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", archive, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	tr := tar.NewReader(r)
+
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+
+	jobs := make(chan extractJob)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	fail := func(err error) {
+		once.Do(func() { firstErr = err })
+	}
+
+	workers = limitJobs(workers)
+	if workers <= 0 {
+		workers = workerCount(workersSeed, dst)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			span := profileWorker("extracting " + filepath.Base(archive))
+			defer span.finish()
+			for job := range jobs {
+				start := time.Now()
+				limiter.wait(int64(len(job.data)))
+				err := writeExtractedFile(job)
+				span.item(start)
+				if err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	var hardlinks []*tar.Header
+	err = readArchiveEntries(tr, dst, jobs, &hardlinks)
+	close(jobs)
+	wg.Wait()
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", archive, err)
+	}
+	if firstErr != nil {
+		return fmt.Errorf("failed to extract %s: %w", archive, firstErr)
+	}
+
+	for _, hdr := range hardlinks {
+		target, err := archivePath(dst, hdr.Linkname)
+		if err != nil {
+			return err
+		}
+		path, err := archivePath(dst, hdr.Name)
+		if err != nil {
+			return err
+		}
+		if err := checkArchiveParents(dst, target); err != nil {
+			return err
+		}
+		targetInfo, err := os.Lstat(target)
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", hdr.Name, err)
+		}
+		if !targetInfo.Mode().IsRegular() {
+			return fmt.Errorf("archive entry %s links to non-regular file %s", hdr.Name, hdr.Linkname)
+		}
+		if err := checkArchiveParents(dst, path); err != nil {
+			return err
+		}
+		if err := os.Link(target, path); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", hdr.Name, err)
+		}
+	}
+	return nil
+}
+
+func readArchiveEntries(tr *tar.Reader, dst string, jobs chan<- extractJob, hardlinks *[]*tar.Header) error {
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		path, err := archivePath(dst, hdr.Name)
+		if err != nil {
+			return err
+		}
+		if err := checkArchiveParents(dst, path); err != nil {
+			return err
+		}
+		mode := hdr.FileInfo().Mode()
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+				return fmt.Errorf("archive entry %s replaces symlink %s", hdr.Name, path)
+			}
+			if err := os.MkdirAll(path, mode.Perm()|0700); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := checkArchiveLink(dst, path, hdr); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, path); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			*hardlinks = append(*hardlinks, hdr)
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			job := extractJob{path: path, mode: mode.Perm(), modTime: hdr.ModTime}
+			if hdr.Size > archivePrefetchLimit {
+				if err := writeExtractedStream(job, tr); err != nil {
+					return err
+				}
+				continue
+			}
+			job.data = make([]byte, hdr.Size)
+			if _, err := io.ReadFull(tr, job.data); err != nil {
+				return err
+			}
+			jobs <- job
+		default:
+			return fmt.Errorf("unsupported archive entry %s (type %c)", hdr.Name, hdr.Typeflag)
+		}
+	}
+}
+
+func archivePath(dst, name string) (string, error) {
+	path := filepath.Join(dst, filepath.FromSlash(name))
+	if path != dst && !strings.HasPrefix(path, dst+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive entry %s escapes %s", name, dst)
+	}
+	return path, nil
+}
+
+func checkArchiveLink(dst, path string, hdr *tar.Header) error {
+	if filepath.IsAbs(hdr.Linkname) {
+		return fmt.Errorf("archive entry %s links to absolute path %s", hdr.Name, hdr.Linkname)
+	}
+	target := filepath.Join(filepath.Dir(path), hdr.Linkname)
+	if target != dst && !strings.HasPrefix(target, dst+string(os.PathSeparator)) {
+		return fmt.Errorf("archive entry %s links outside %s", hdr.Name, dst)
+	}
+	return nil
+}
+
+func checkArchiveParents(dst, path string) error {
+	rel, err := filepath.Rel(dst, filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	if rel == "." {
+		return nil
+	}
+	dir := dst
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		dir = filepath.Join(dir, part)
+		info, err := os.Lstat(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("archive entry %s traverses symlink %s", path, dir)
+		}
+	}
+	return nil
+}
+
+func writeExtractedFile(job extractJob) error {
+	f, err := os.OpenFile(job.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|syscall.O_NOFOLLOW, job.mode)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(job.data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(job.path, job.modTime, job.modTime)
+}
+
+func writeExtractedStream(job extractJob, r io.Reader) error {
+	f, err := os.OpenFile(job.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|syscall.O_NOFOLLOW, job.mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(job.path, job.modTime, job.modTime)
+}
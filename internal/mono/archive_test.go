@@ -0,0 +1,234 @@
+package mono
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	for _, format := range []string{ArtifactFormatTar, ArtifactFormatTarGz, ArtifactFormatTarZst} {
+		t.Run(format, func(t *testing.T) {
+			src := filepath.Join(t.TempDir(), "node_modules")
+			if err := os.MkdirAll(filepath.Join(src, "pkg", "lib"), 0755); err != nil {
+				t.Fatalf("failed to create dirs: %v", err)
+			}
+
+			small := filepath.Join(src, "pkg", "index.js")
+			if err := os.WriteFile(small, []byte("module.exports = 1"), 0644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+			big := bytes.Repeat([]byte("x"), archivePrefetchLimit+10)
+			if err := os.WriteFile(filepath.Join(src, "pkg", "lib", "big.bin"), big, 0755); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+			if err := os.Link(small, filepath.Join(src, "pkg", "alias.js")); err != nil {
+				t.Fatalf("failed to link file: %v", err)
+			}
+			if err := os.Symlink("pkg/index.js", filepath.Join(src, "entry.js")); err != nil {
+				t.Fatalf("failed to symlink: %v", err)
+			}
+			mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+			if err := os.Chtimes(small, mtime, mtime); err != nil {
+				t.Fatalf("failed to set mtime: %v", err)
+			}
+
+			archive := filepath.Join(t.TempDir(), archiveName("node_modules", format))
+			if err := writeArchive(src, archive, format, "npm", pathFilter{}); err != nil {
+				t.Fatalf("writeArchive failed: %v", err)
+			}
+			if format != ArtifactFormatTar {
+				archiveInfo, err := os.Stat(archive)
+				if err != nil {
+					t.Fatalf("failed to stat archive: %v", err)
+				}
+				if archiveInfo.Size() > int64(len(big))/4 {
+					t.Errorf("expected %s to compress, got %d bytes", format, archiveInfo.Size())
+				}
+			}
+
+			dst := filepath.Join(t.TempDir(), "out")
+			if err := extractArchive(archive, dst, 0, nil); err != nil {
+				t.Fatalf("extractArchive failed: %v", err)
+			}
+
+			diff, err := DiffDirectories(src, dst)
+			if err != nil {
+				t.Fatalf("DiffDirectories failed: %v", err)
+			}
+			if len(diff.Changes) != 0 {
+				t.Errorf("extracted tree differs: %+v", diff.Changes)
+			}
+
+			info, err := os.Stat(filepath.Join(dst, "pkg", "index.js"))
+			if err != nil {
+				t.Fatalf("failed to stat extracted file: %v", err)
+			}
+			if !info.ModTime().Equal(mtime) {
+				t.Errorf("mtime not preserved: got %v, want %v", info.ModTime(), mtime)
+			}
+			same, err := sameInode(filepath.Join(dst, "pkg", "index.js"), filepath.Join(dst, "pkg", "alias.js"))
+			if err != nil {
+				t.Fatalf("sameInode failed: %v", err)
+			}
+			if !same {
+				t.Error("hardlinks should be preserved")
+			}
+			bigInfo, err := os.Stat(filepath.Join(dst, "pkg", "lib", "big.bin"))
+			if err != nil {
+				t.Fatalf("failed to stat big file: %v", err)
+			}
+			if bigInfo.Mode().Perm() != 0755 {
+				t.Errorf("mode not preserved: %v", bigInfo.Mode())
+			}
+		})
+	}
+}
+
+func TestExtractArchiveRejectsEscapingPaths(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "../evil", Mode: 0644, Size: 1, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("failed to write header: %v", err)
+	}
+	if _, err := tw.Write([]byte("x")); err != nil {
+		t.Fatalf("failed to write body: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar: %v", err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "bad.tar")
+	if err := os.WriteFile(archive, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write archive: %v", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "out")
+	if err := extractArchive(archive, dst, 0, nil); err == nil {
+		t.Fatal("expected escaping entry to be rejected")
+	}
+	if fileExists(filepath.Join(filepath.Dir(dst), "evil")) {
+		t.Error("escaping entry should not be written")
+	}
+}
+
+func TestExtractArchiveRejectsSymlinkEscapes(t *testing.T) {
+	outside := t.TempDir()
+	cases := map[string][]*tar.Header{
+		"absolute symlink": {
+			{Name: "link", Linkname: outside, Typeflag: tar.TypeSymlink},
+			{Name: "link/pwned", Mode: 0644, Size: 1, Typeflag: tar.TypeReg},
+		},
+		"relative symlink": {
+			{Name: "link", Linkname: "../../../../../../../../" + outside, Typeflag: tar.TypeSymlink},
+			{Name: "link/pwned", Mode: 0644, Size: 1, Typeflag: tar.TypeReg},
+		},
+		"write through symlink parent": {
+			{Name: "dir/", Mode: 0755, Typeflag: tar.TypeDir},
+			{Name: "link", Linkname: "dir", Typeflag: tar.TypeSymlink},
+			{Name: "link/pwned", Mode: 0644, Size: 1, Typeflag: tar.TypeReg},
+		},
+		"write over symlink": {
+			{Name: "target", Mode: 0644, Size: 1, Typeflag: tar.TypeReg},
+			{Name: "link", Linkname: "target", Typeflag: tar.TypeSymlink},
+			{Name: "link", Mode: 0644, Size: 1, Typeflag: tar.TypeReg},
+		},
+		"hardlink escape": {
+			{Name: "pwned", Linkname: "../pwned", Typeflag: tar.TypeLink},
+		},
+		"hardlink to symlink": {
+			{Name: "link", Linkname: "target", Typeflag: tar.TypeSymlink},
+			{Name: "pwned", Linkname: "link", Typeflag: tar.TypeLink},
+		},
+	}
+
+	for name, headers := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			for _, hdr := range headers {
+				if err := tw.WriteHeader(hdr); err != nil {
+					t.Fatalf("failed to write header: %v", err)
+				}
+				if hdr.Size > 0 {
+					if _, err := tw.Write([]byte("x")); err != nil {
+						t.Fatalf("failed to write body: %v", err)
+					}
+				}
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatalf("failed to close tar: %v", err)
+			}
+
+			archive := filepath.Join(t.TempDir(), "bad.tar")
+			if err := os.WriteFile(archive, buf.Bytes(), 0644); err != nil {
+				t.Fatalf("failed to write archive: %v", err)
+			}
+
+			dst := filepath.Join(t.TempDir(), "out")
+			if err := extractArchive(archive, dst, 1, nil); err == nil {
+				t.Fatal("expected malicious archive to be rejected")
+			}
+			if fileExists(filepath.Join(outside, "pwned")) {
+				t.Error("entry should not be written outside the destination")
+			}
+			if fileExists(filepath.Join(dst, "dir", "pwned")) {
+				t.Error("entry should not be written through a symlink")
+			}
+		})
+	}
+}
+
+func TestStoreAndRestoreArchiveFormat(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	envPath := filepath.Join(t.TempDir(), "env")
+	modules := filepath.Join(envPath, "node_modules")
+	if err := os.MkdirAll(filepath.Join(modules, "left-pad"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(modules, "left-pad", "index.js"), []byte("pad"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	entry := ArtifactCacheEntry{
+		Name:      "npm",
+		Key:       "key1",
+		CachePath: filepath.Join(cm.LocalCacheDir, "proj", "npm", "key1"),
+		EnvPaths:  []string{modules},
+		Format:    ArtifactFormatTarGz,
+	}
+	if err := cm.StoreToCache(entry); err != nil {
+		t.Fatalf("StoreToCache failed: %v", err)
+	}
+
+	if !fileExists(filepath.Join(entry.CachePath, "node_modules.tar.gz")) {
+		t.Fatal("expected a single archive in the cache entry")
+	}
+	if !dirExists(modules) {
+		t.Fatal("storing an archive should leave the environment untouched")
+	}
+
+	if err := os.RemoveAll(modules); err != nil {
+		t.Fatalf("failed to remove modules: %v", err)
+	}
+	if err := cm.RestoreFromCache(entry, nil); err != nil {
+		t.Fatalf("RestoreFromCache failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(modules, "left-pad", "index.js"))
+	if err != nil {
+		t.Fatalf("failed to read restored file: %v", err)
+	}
+	if string(data) != "pad" {
+		t.Errorf("restored content mismatch: %q", data)
+	}
+}
@@ -0,0 +1,167 @@
+package mono
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigArtifactPaths(t *testing.T) {
+	dir := t.TempDir()
+	monoYml := `build:
+  artifacts:
+    - name: cargo
+      key_files: [Cargo.lock]
+      paths:
+        - target
+        - path: .cargo/registry
+          type: plain
+        - path: web/node_modules
+          type: npm
+`
+	if err := os.WriteFile(filepath.Join(dir, "mono.yml"), []byte(monoYml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	artifact := cfg.Build.Artifacts[0]
+	if len(artifact.Paths) != 3 {
+		t.Fatalf("expected 3 paths, got %+v", artifact.Paths)
+	}
+	var got []string
+	for _, p := range artifact.Paths {
+		got = append(got, p.Path+"="+artifact.PathType(p))
+	}
+	if want := "target=cargo .cargo/registry=plain web/node_modules=npm"; strings.Join(got, " ") != want {
+		t.Errorf("expected %q, got %q", want, strings.Join(got, " "))
+	}
+}
+
+func TestLoadConfigRejectsInvalidArtifactPaths(t *testing.T) {
+	for name, paths := range map[string]string{
+		"unknown type":   "        - path: target\n          type: sbt\n",
+		"empty path":     "        - type: plain\n",
+		"duplicate base": "        - target\n        - path: sub/target\n          type: plain\n",
+		"escaping lock":  "        - target\n      build_lock_file: ../.lock\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			monoYml := "build:\n  artifacts:\n    - name: cargo\n      paths:\n" + paths
+			if err := os.WriteFile(filepath.Join(dir, "mono.yml"), []byte(monoYml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadConfig(dir); err == nil {
+				t.Error("expected LoadConfig to fail")
+			}
+		})
+	}
+}
+
+func TestRestoreAppliesPerPathTypes(t *testing.T) {
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+
+	testDir := t.TempDir()
+	envPath := filepath.Join(testDir, "env")
+	files := []string{
+		"target/debug/main.o",
+		"target/debug/main",
+		"registry/cache/crate.o",
+		"node_modules/.bin/tool",
+		"node_modules/pkg/index.js",
+	}
+	for _, f := range files {
+		path := filepath.Join(envPath, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	artifact := ArtifactConfig{
+		Name: "cargo",
+		Paths: []ArtifactPath{
+			{Path: "target"},
+			{Path: "registry", Type: "plain"},
+			{Path: "node_modules", Type: "npm"},
+		},
+	}
+	entries, err := cm.PrepareArtifactCache([]ArtifactConfig{artifact}, envPath, envPath)
+	if err != nil {
+		t.Fatalf("PrepareArtifactCache failed: %v", err)
+	}
+	entry := entries[0]
+	entry.CachePath = filepath.Join(testDir, "cache", "cargo", entry.Key)
+
+	if err := cm.StoreToCache(entry); err != nil {
+		t.Fatalf("StoreToCache failed: %v", err)
+	}
+	for _, p := range entry.EnvPaths {
+		if err := os.RemoveAll(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cm.RestoreFromCache(entry, nil); err != nil {
+		t.Fatalf("RestoreFromCache failed: %v", err)
+	}
+
+	for f, want := range map[string]bool{
+		"target/debug/main.o":       false,
+		"target/debug/main":         true,
+		"registry/cache/crate.o":    true,
+		"node_modules/.bin/tool":    false,
+		"node_modules/pkg/index.js": true,
+	} {
+		if got := fileExists(filepath.Join(envPath, f)); got != want {
+			t.Errorf("%s: expected exists=%v, got %v", f, want, got)
+		}
+	}
+}
+
+func TestRunPostRestore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+	logger, err := NewFileLogger("env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	envPath := t.TempDir()
+	cfg := &Config{}
+	entry := ArtifactCacheEntry{Name: "gems", PostRestore: `printf '%s' "$MONO_ENV_PATH" > restored`}
+
+	if err := cm.RunPostRestore(context.Background(), cfg, entry, envPath, "/root", logger); err != nil {
+		t.Fatalf("RunPostRestore failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(envPath, "restored"))
+	if err != nil {
+		t.Fatalf("post_restore did not run in the environment: %v", err)
+	}
+	if string(data) != envPath {
+		t.Errorf("expected MONO_ENV_PATH=%s, got %s", envPath, data)
+	}
+
+	entry.PostRestore = "exit 3"
+	if err := cm.RunPostRestore(context.Background(), cfg, entry, envPath, "/root", logger); err == nil || !strings.Contains(err.Error(), "post_restore for gems failed") {
+		t.Errorf("expected post_restore failure, got %v", err)
+	}
+
+	entry.PostRestore = ""
+	if err := cm.RunPostRestore(context.Background(), cfg, entry, envPath, "/root", logger); err != nil {
+		t.Errorf("expected no-op without post_restore, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package mono
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const DefaultBatchJobs = 4
+
+type BatchResult struct {
+	Path     string
+	Result   *InitResult
+	Err      error
+	Duration time.Duration
+}
+
+func InitBatch(paths []string, jobs int, opts InitOptions) []BatchResult {
+	if jobs <= 0 {
+		jobs = DefaultBatchJobs
+	}
+
+	results := make([]BatchResult, len(paths))
+
+	var g errgroup.Group
+	g.SetLimit(jobs)
+
+	for i, path := range paths {
+		g.Go(func() error {
+			start := time.Now()
+			result, err := runInit(path, opts)
+			results[i] = BatchResult{
+				Path:     path,
+				Result:   result,
+				Err:      err,
+				Duration: time.Since(start),
+			}
+			return nil
+		})
+	}
+	g.Wait()
+
+	return results
+}
+
+func ReadBatchFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open batch file: %w", err)
+	}
+	defer f.Close()
+
+	var paths []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(filepath.Dir(path), line)
+		}
+		paths = append(paths, filepath.Clean(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch file: %w", err)
+	}
+
+	return paths, nil
+}
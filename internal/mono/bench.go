@@ -0,0 +1,240 @@
+package mono
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+type BenchOptions struct {
+	Artifacts []string
+}
+
+type BenchResult struct {
+	Name      string
+	Key       string
+	Status    string
+	Err       error
+	ColdBuild time.Duration
+	Store     time.Duration
+	Restore   time.Duration
+	WarmBuild time.Duration
+	Size      int64
+	ColdDisk  int64
+	WarmDisk  int64
+}
+
+func (r BenchResult) Warm() time.Duration {
+	return r.Restore + r.WarmBuild
+}
+
+func (r BenchResult) TimeSaved() time.Duration {
+	return r.ColdBuild - r.Warm()
+}
+
+func (r BenchResult) DiskSaved() int64 {
+	return r.ColdDisk - r.WarmDisk
+}
+
+func Bench(rootPath string, opts BenchOptions) (results []BenchResult, err error) {
+	if !dirExists(rootPath) {
+		return nil, fmt.Errorf("path does not exist: %s", rootPath)
+	}
+
+	logger, err := NewFileLogger(EnvName(rootPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer logger.Close()
+
+	logger.Log("mono bench %s", rootPath)
+
+	ctx, stopSignals := notifyInterrupt("bench")
+	defer stopSignals()
+
+	cfg, err := LoadConfig(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	home, err := GetMonoHome()
+	if err != nil {
+		return nil, err
+	}
+	benchDir := filepath.Join(home, "bench", fmt.Sprintf("%s-%d", EnvName(rootPath), time.Now().UnixNano()))
+	worktree := filepath.Join(benchDir, "worktree")
+	if err := os.MkdirAll(benchDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create bench directory: %w", err)
+	}
+	defer func() {
+		if cleanupErr := removeBenchDir(rootPath, benchDir, worktree); cleanupErr != nil && err == nil {
+			err = cleanupErr
+		}
+	}()
+
+	if _, err := runGit(rootPath, "worktree", "add", "--detach", worktree, "HEAD"); err != nil {
+		return nil, fmt.Errorf("bench needs a git checkout to build from scratch: %w", err)
+	}
+
+	cfg.ApplyDefaults(worktree)
+	artifacts := cfg.Build.Artifacts
+	if len(opts.Artifacts) > 0 {
+		artifacts = nil
+		for _, artifact := range cfg.Build.Artifacts {
+			if slices.Contains(opts.Artifacts, artifact.Name) {
+				artifacts = append(artifacts, artifact)
+			}
+		}
+		for _, name := range opts.Artifacts {
+			if !slices.ContainsFunc(artifacts, func(a ArtifactConfig) bool { return a.Name == name }) {
+				return nil, fmt.Errorf("unknown artifact %s", name)
+			}
+		}
+	}
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cache: %w", err)
+	}
+	cm.LocalCacheDir = filepath.Join(benchDir, "cache")
+	cm.FS, err = probeFilesystem(cm.LocalCacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe bench cache filesystem: %w", err)
+	}
+
+	envVars := []string{"MONO_ROOT_PATH=" + rootPath, "MONO_ENV_PATH=" + worktree, "MONO_CACHE_DIR=" + cm.LocalCacheDir}
+	for _, artifact := range artifacts {
+		result := cm.benchArtifact(ctx, cfg, artifact, worktree, envVars, logger)
+		if err := interruptErr(ctx); err != nil {
+			return results, err
+		}
+		if result.Err != nil {
+			logger.Log("bench %s failed: %v", artifact.Name, result.Err)
+		} else {
+			logger.Log("bench %s: cold %s, warm %s (restore %s, build %s)", artifact.Name, result.ColdBuild, result.Warm(), result.Restore, result.WarmBuild)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (cm *CacheManager) benchArtifact(ctx context.Context, cfg *Config, artifact ArtifactConfig, worktree string, envVars []string, logger *FileLogger) BenchResult {
+	result := BenchResult{Name: artifact.Name}
+	if artifact.WarmCommand == "" {
+		result.Status = "skipped, no warm_command configured"
+		return result
+	}
+
+	entries, err := cm.PrepareArtifactCache([]ArtifactConfig{artifact}, worktree, worktree)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	entry := entries[0]
+	result.Key = entry.Key
+	for _, p := range entry.EnvPaths {
+		if rel, err := filepath.Rel(worktree, p); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			result.Status = "skipped, " + p + " is outside the checkout"
+			return result
+		}
+	}
+
+	removePaths := func() error {
+		for _, p := range entry.EnvPaths {
+			if err := os.RemoveAll(p); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", p, err)
+			}
+		}
+		return nil
+	}
+	build := func() (time.Duration, error) {
+		start := time.Now()
+		err := runEnvScript(ctx, cfg, nil, worktree, artifact.WarmCommand, envVars, logger)
+		return time.Since(start), err
+	}
+
+	if result.Err = removePaths(); result.Err != nil {
+		return result
+	}
+	logger.Log("bench %s: cold build: %s", artifact.Name, artifact.WarmCommand)
+	if result.ColdBuild, err = build(); err != nil {
+		result.Err = fmt.Errorf("cold build failed: %w", err)
+		return result
+	}
+	cold := newUsageTracker()
+	for _, p := range entry.EnvPaths {
+		if !dirExists(p) {
+			continue
+		}
+		size, disk, err := cold.walk(p)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to measure %s: %w", p, err)
+			return result
+		}
+		result.Size += size
+		result.ColdDisk += disk
+	}
+
+	start := time.Now()
+	if err := cm.StoreToCache(entry); err != nil {
+		result.Err = fmt.Errorf("failed to store to cache: %w", err)
+		return result
+	}
+	result.Store = time.Since(start)
+
+	if result.Err = removePaths(); result.Err != nil {
+		return result
+	}
+	start = time.Now()
+	if err := cm.RestoreFromCache(entry, logger); err != nil {
+		result.Err = err
+		return result
+	}
+	if err := cm.RunPostRestore(ctx, cfg, entry, worktree, worktree, logger); err != nil {
+		result.Err = err
+		return result
+	}
+	result.Restore = time.Since(start)
+
+	logger.Log("bench %s: warm build: %s", artifact.Name, artifact.WarmCommand)
+	if result.WarmBuild, err = build(); err != nil {
+		result.Err = fmt.Errorf("warm build failed: %w", err)
+		return result
+	}
+
+	warm := newUsageTracker()
+	if _, _, err := warm.walk(entry.CachePath); err != nil {
+		result.Err = fmt.Errorf("failed to measure cache entry: %w", err)
+		return result
+	}
+	for _, p := range entry.EnvPaths {
+		if !dirExists(p) {
+			continue
+		}
+		_, disk, err := warm.walk(p)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to measure %s: %w", p, err)
+			return result
+		}
+		result.WarmDisk += disk
+	}
+	result.Status = "ok"
+	return result
+}
+
+func removeBenchDir(rootPath, benchDir, worktree string) error {
+	if dirExists(worktree) {
+		if _, err := runGit(rootPath, "worktree", "remove", "--force", worktree); err != nil {
+			return fmt.Errorf("failed to remove bench worktree: %w", err)
+		}
+	}
+	if err := os.RemoveAll(benchDir); err != nil {
+		return fmt.Errorf("failed to remove bench directory: %w", err)
+	}
+	return nil
+}
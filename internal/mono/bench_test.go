@@ -0,0 +1,77 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBench(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	root := initTestRepo(t)
+	monoYml := `build:
+  artifacts:
+    - name: out
+      key_files: [deps.txt]
+      paths: [out]
+      warm_command: test -f out/built || (sleep 0.5 && mkdir -p out && head -c 65536 /dev/zero > out/built)
+    - name: manual
+      paths: [manual]
+`
+	if err := os.WriteFile(filepath.Join(root, "mono.yml"), []byte(monoYml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := Bench(root, BenchOptions{})
+	if err != nil {
+		t.Fatalf("Bench failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", results)
+	}
+
+	out := results[0]
+	if out.Err != nil || out.Status != "ok" {
+		t.Fatalf("unexpected result %+v", out)
+	}
+	if out.Size != 65536 || out.ColdDisk == 0 {
+		t.Errorf("expected the cold build to measure 64KiB, got size %d disk %d", out.Size, out.ColdDisk)
+	}
+	if out.Warm() >= out.ColdBuild || out.TimeSaved() <= 0 {
+		t.Errorf("expected the warm build to be faster, cold %s warm %s", out.ColdBuild, out.Warm())
+	}
+	if out.DiskSaved() < 0 || out.DiskSaved() > out.ColdDisk {
+		t.Errorf("unexpected disk saved %d of %d", out.DiskSaved(), out.ColdDisk)
+	}
+	if !strings.HasPrefix(results[1].Status, "skipped") {
+		t.Errorf("expected an artifact without warm_command to be skipped, got %+v", results[1])
+	}
+
+	home, err := GetMonoHome()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Join(home, "bench"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected the bench directory to be cleaned up, got %v", entries)
+	}
+	if dirExists(filepath.Join(home, "cache_local", ComputeProjectID(root))) {
+		t.Error("expected bench to leave the real cache untouched")
+	}
+	worktrees, err := runGit(root, "worktree", "list", "--porcelain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(worktrees, "worktree ") != 1 {
+		t.Errorf("expected the bench worktree to be removed, got %s", worktrees)
+	}
+
+	if _, err := Bench(root, BenchOptions{Artifacts: []string{"missing"}}); err == nil || !strings.Contains(err.Error(), "unknown artifact missing") {
+		t.Errorf("expected an unknown artifact to fail, got %v", err)
+	}
+}
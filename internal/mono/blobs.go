@@ -0,0 +1,155 @@
+package mono
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sync/errgroup"
+)
+
+type PrunedBlob struct {
+	Path string
+	Size int64
+}
+
+func (cm *CacheManager) BlobStoreDir() string {
+	return filepath.Join(cm.HomeDir, "cache_blobs")
+}
+
+func (cm *CacheManager) blobPath(sum string, mode os.FileMode) string {
+	return filepath.Join(cm.BlobStoreDir(), sum[:2], fmt.Sprintf("%s-%o", sum, mode.Perm()))
+}
+
+func (cm *CacheManager) sealEntry(cachePath string) error {
+	if err := writeManifest(cachePath); err != nil {
+		return err
+	}
+	if err := cm.linkBlobs(cachePath); err != nil {
+		return fmt.Errorf("failed to dedup %s into the blob store: %w", cachePath, err)
+	}
+	return nil
+}
+
+func (cm *CacheManager) linkBlobs(cachePath string) error {
+	strategy, err := cm.Strategy(cachePath)
+	if err != nil || strategy.Link == LinkHardlink {
+		return err
+	}
+
+	m, _, err := readManifest(cachePath)
+	if err != nil || m == nil {
+		return err
+	}
+
+	var g errgroup.Group
+	g.SetLimit(workerCount(workersSeed, cachePath))
+	for rel, f := range m.Files {
+		if f.SHA256 == "" || f.Size == 0 {
+			continue
+		}
+		g.Go(func() error {
+			return cm.linkBlob(filepath.Join(cachePath, filepath.FromSlash(rel)), f)
+		})
+	}
+	return g.Wait()
+}
+
+func (cm *CacheManager) linkBlob(path string, want manifestFile) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() || info.Size() != want.Size {
+		return nil
+	}
+
+	blob := cm.blobPath(want.SHA256, info.Mode())
+	blobInfo, err := os.Lstat(blob)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(blob), 0755); err != nil {
+			return err
+		}
+		return ignoreUnlinkable(os.Link(path, blob))
+	}
+	if err != nil {
+		return err
+	}
+	if os.SameFile(info, blobInfo) {
+		return nil
+	}
+
+	sum, err := fileSHA256(blob)
+	if err != nil {
+		return err
+	}
+	if sum != want.SHA256 {
+		return replaceWithLink(path, blob)
+	}
+	return replaceWithLink(blob, path)
+}
+
+func replaceWithLink(src, dst string) error {
+	tmp := dst + cacheTmpSuffix
+	if err := os.Link(src, tmp); err != nil {
+		return ignoreUnlinkable(err)
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func ignoreUnlinkable(err error) error {
+	if errors.Is(err, syscall.EMLINK) || errors.Is(err, syscall.EXDEV) || errors.Is(err, os.ErrExist) {
+		return nil
+	}
+	return err
+}
+
+func (cm *CacheManager) PruneBlobStore(dryRun bool) ([]PrunedBlob, error) {
+	shards, err := os.ReadDir(cm.BlobStoreDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []PrunedBlob
+	for _, shard := range shards {
+		shardPath := filepath.Join(cm.BlobStoreDir(), shard.Name())
+		blobs, err := os.ReadDir(shardPath)
+		if err != nil {
+			return pruned, err
+		}
+
+		for _, blob := range blobs {
+			if strings.HasSuffix(blob.Name(), cacheTmpSuffix) {
+				continue
+			}
+			info, err := blob.Info()
+			if err != nil {
+				return pruned, err
+			}
+			if stat, ok := info.Sys().(*syscall.Stat_t); ok && stat.Nlink > 1 {
+				continue
+			}
+			blobPath := filepath.Join(shardPath, blob.Name())
+			if !dryRun {
+				if err := os.Remove(blobPath); err != nil {
+					return pruned, err
+				}
+			}
+			pruned = append(pruned, PrunedBlob{Path: blobPath, Size: info.Size()})
+		}
+		if !dryRun {
+			cm.cleanEmptyParentDirs(shardPath)
+		}
+	}
+	return pruned, nil
+}
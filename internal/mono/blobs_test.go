@@ -0,0 +1,162 @@
+package mono
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBlobStoreDedupsAcrossEntries(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	cm.FS.Hardlink = false
+
+	shared := bytes.Repeat([]byte("rlib"), 4096)
+	var entries []ArtifactCacheEntry
+	for _, project := range []string{"proj-a", "proj-b"} {
+		target := filepath.Join(t.TempDir(), "target")
+		if err := os.MkdirAll(filepath.Join(target, "deps"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(target, "deps", "libserde.rlib"), shared, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(target, "deps", "tool"), shared, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(target, "deps", "app.rlib"), []byte(project), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		entry := ArtifactCacheEntry{
+			Name:      "cargo",
+			ProjectID: project,
+			Key:       "key-" + project,
+			CachePath: filepath.Join(cm.LocalCacheDir, project, "cargo", "key-"+project),
+			EnvPaths:  []string{target},
+		}
+		if err := cm.StoreToCache(entry); err != nil {
+			t.Fatalf("StoreToCache failed: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	file := func(entry ArtifactCacheEntry, name string) string {
+		return filepath.Join(entry.CachePath, "target", "deps", name)
+	}
+	same, err := sameInode(file(entries[0], "libserde.rlib"), file(entries[1], "libserde.rlib"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !same {
+		t.Error("expected identical files in different entries to share a blob")
+	}
+	same, err = sameInode(file(entries[0], "libserde.rlib"), file(entries[0], "tool"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same {
+		t.Error("expected files with different modes not to share a blob")
+	}
+	same, err = sameInode(file(entries[0], "app.rlib"), file(entries[1], "app.rlib"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same {
+		t.Error("expected different files not to share a blob")
+	}
+	for _, entry := range entries {
+		if status, problems, err := checkEntry(entry.CachePath, false); err != nil || status != VerifyOK {
+			t.Errorf("expected %s to verify after dedup, got %s %v %v", entry.ProjectID, status, problems, err)
+		}
+	}
+
+	pruned, err := cm.PruneBlobStore(true)
+	if err != nil {
+		t.Fatalf("PruneBlobStore failed: %v", err)
+	}
+	if len(pruned) != 0 {
+		t.Errorf("expected referenced blobs to be kept, got %+v", pruned)
+	}
+
+	for _, entry := range entries {
+		if err := cm.RemoveCacheEntry(entry.ProjectID, entry.Name, entry.Key); err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range entry.EnvPaths {
+			if err := os.RemoveAll(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	planned, err := cm.PruneBlobStore(true)
+	if err != nil {
+		t.Fatalf("PruneBlobStore dry run failed: %v", err)
+	}
+	if len(planned) != 4 {
+		t.Fatalf("expected 4 unreferenced blobs, got %+v", planned)
+	}
+	removed, err := cm.PruneBlobStore(false)
+	if err != nil {
+		t.Fatalf("PruneBlobStore failed: %v", err)
+	}
+	if len(removed) != 4 {
+		t.Errorf("expected 4 blobs removed, got %+v", removed)
+	}
+	for _, blob := range removed {
+		if fileExists(blob.Path) {
+			t.Errorf("expected %s to be removed", blob.Path)
+		}
+	}
+}
+
+func TestBlobStoreReplacesCorruptBlob(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	cm.FS.Hardlink = false
+
+	cachePath := filepath.Join(cm.LocalCacheDir, "proj", "npm", "key1")
+	if err := os.MkdirAll(cachePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(cachePath, "index.js")
+	if err := os.WriteFile(path, []byte("module.exports = 1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := fileSHA256(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blob := cm.blobPath(sum, 0644)
+	if err := os.MkdirAll(filepath.Dir(blob), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(blob, []byte("module.exports = 2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cm.sealEntry(cachePath); err != nil {
+		t.Fatalf("sealEntry failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "module.exports = 1" {
+		t.Errorf("expected the entry to keep its content, got %q: %v", data, err)
+	}
+	same, err := sameInode(path, blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !same {
+		t.Error("expected the corrupt blob to be replaced by the entry's file")
+	}
+}
@@ -1,27 +1,36 @@
 package mono
 
 import (
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"io"
 	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 )
 
+const cacheTmpSuffix = ".mono-tmp"
+
+const cacheSaltEnv = "MONO_CACHE_SALT"
+
 type CacheManager struct {
 	HomeDir          string
 	LocalCacheDir    string
 	SccacheAvailable bool
+	FS               FilesystemCapabilities
+	keys             keyMemo
 }
 
 func NewCacheManager() (*CacheManager, error) {
@@ -37,13 +46,27 @@ func NewCacheManager() (*CacheManager, error) {
 
 	cm.SccacheAvailable = cm.detectSccache()
 
+	caps, err := probeFilesystem(cm.LocalCacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe cache filesystem: %w", err)
+	}
+	cm.FS = caps
+
 	return cm, nil
 }
 
 func GetMonoHome() (string, error) {
+	if customHome := os.Getenv("MONO_HOME"); customHome != "" {
+		abs, err := filepath.Abs(customHome)
+		if err != nil {
+			return "", fmt.Errorf("invalid MONO_HOME: %w", err)
+		}
+		return abs, nil
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
 	return filepath.Join(home, ".mono"), nil
 }
@@ -58,44 +81,66 @@ func ComputeProjectID(rootPath string) string {
 	return hex.EncodeToString(h[:])[:12]
 }
 
+const SharedProjectID = "global"
+
+func ArtifactProjectID(artifact ArtifactConfig, rootPath string) string {
+	if artifact.Shared {
+		return SharedProjectID
+	}
+	return ComputeProjectID(rootPath)
+}
+
 func (cm *CacheManager) GetProjectCacheDir(rootPath string) string {
 	projectID := ComputeProjectID(rootPath)
 	return filepath.Join(cm.LocalCacheDir, projectID)
 }
 
+func (cm *CacheManager) artifactCachePath(artifact ArtifactConfig, rootPath, key string) string {
+	return filepath.Join(cm.LocalCacheDir, ArtifactProjectID(artifact, rootPath), artifact.Name, key)
+}
+
 type ArtifactCacheEntry struct {
-	Name      string
-	Key       string
-	CachePath string
-	EnvPaths  []string
-	Hit       bool
+	Name           string
+	ProjectID      string
+	Key            string
+	CachePath      string
+	EnvPaths       []string
+	PathTypes      []string
+	Format         string
+	PostRestore    string
+	VerifyLockfile bool
+	Workers        int
+	IOLimit        int64
+	Hit            bool
+	RestoreKey     string
+	Include        []string
+	Exclude        []string
+	SkipPatterns   []string
+}
+
+func (e ArtifactCacheEntry) pathType(i int) string {
+	if i < len(e.PathTypes) && e.PathTypes[i] != "" {
+		return e.PathTypes[i]
+	}
+	return e.Name
 }
 
 func (cm *CacheManager) ComputeCacheKey(artifact ArtifactConfig, envPath string) (string, error) {
-	h := sha256.New()
+	inputs, err := cm.keyInputs(artifact, envPath)
+	if err != nil {
+		return "", err
+	}
 
-	for _, keyFile := range artifact.KeyFiles {
-		fullPath := filepath.Join(envPath, keyFile)
-		f, err := os.Open(fullPath)
-		if err != nil {
-			if os.IsNotExist(err) {
-				continue
-			}
-			return "", fmt.Errorf("failed to read key file %s: %w", keyFile, err)
-		}
-		_, err = io.Copy(h, f)
-		f.Close()
-		if err != nil {
-			return "", fmt.Errorf("failed to hash key file %s: %w", keyFile, err)
-		}
+	h := sha256.New()
+	for _, input := range inputs {
+		h.Write(input)
 	}
 
-	for _, cmd := range artifact.KeyCommands {
-		output, err := exec.Command("bash", "-c", cmd).Output()
-		if err != nil {
-			return "", fmt.Errorf("failed to run key command %s: %w", cmd, err)
-		}
-		h.Write(output)
+	if artifact.KeySalt != "" {
+		h.Write([]byte("key_salt\x00" + artifact.KeySalt))
+	}
+	if salt := os.Getenv(cacheSaltEnv); salt != "" {
+		h.Write([]byte(cacheSaltEnv + "\x00" + salt))
 	}
 
 	return hex.EncodeToString(h.Sum(nil))[:16], nil
@@ -115,20 +160,45 @@ func (cm *CacheManager) PrepareArtifactCache(artifacts []ArtifactConfig, rootPat
 			return nil, err
 		}
 
-		cachePath := cm.GetArtifactCachePath(rootPath, artifact.Name, key)
+		ioLimit, err := artifact.ioLimit()
+		if err != nil {
+			return nil, err
+		}
+
+		cachePath := cm.artifactCachePath(artifact, rootPath, key)
 		hit := dirExists(cachePath)
 
-		var envPaths []string
+		var restoreKey string
+		if !hit {
+			restoreKey, err = cm.findRestoreKey(artifact, rootPath, key)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var envPaths, pathTypes []string
 		for _, p := range artifact.Paths {
-			envPaths = append(envPaths, filepath.Join(envPath, p))
+			envPaths = append(envPaths, p.resolve(envPath))
+			pathTypes = append(pathTypes, artifact.PathType(p))
 		}
 
 		entries = append(entries, ArtifactCacheEntry{
-			Name:      artifact.Name,
-			Key:       key,
-			CachePath: cachePath,
-			EnvPaths:  envPaths,
-			Hit:       hit,
+			Name:           artifact.Name,
+			ProjectID:      ArtifactProjectID(artifact, rootPath),
+			Key:            key,
+			CachePath:      cachePath,
+			EnvPaths:       envPaths,
+			PathTypes:      pathTypes,
+			Format:         artifact.Format,
+			PostRestore:    artifact.PostRestore,
+			VerifyLockfile: artifact.VerifyLockfile,
+			Workers:        artifact.Workers,
+			IOLimit:        ioLimit,
+			Hit:            hit,
+			RestoreKey:     restoreKey,
+			Include:        artifact.Include,
+			Exclude:        artifact.Exclude,
+			SkipPatterns:   artifact.SkipPatterns,
 		})
 	}
 
@@ -144,11 +214,13 @@ func (cm *CacheManager) EnsureDirectories() error {
 	return nil
 }
 
-func (cm *CacheManager) EnvVars(cfg BuildConfig) []string {
-	var vars []string
+func (cm *CacheManager) EnvVars(cfg BuildConfig, envPath, rootPath string) []string {
+	vars := append(goCacheEnv(cfg.Artifacts, envPath), pythonEnv(cfg.Artifacts)...)
+	vars = append(vars, bundlerEnv(cfg.Artifacts)...)
 
 	if cm.shouldEnableSccache(cfg) {
 		vars = append(vars, "RUSTC_WRAPPER=sccache")
+		vars = append(vars, cm.SccacheServer(cmp.Or(rootPath, envPath)).Env()...)
 	}
 
 	return vars
@@ -161,45 +233,26 @@ func (cm *CacheManager) shouldEnableSccache(cfg BuildConfig) bool {
 	return cm.SccacheAvailable
 }
 
-func HardlinkTree(src, dst string) error {
-	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		relPath, err := filepath.Rel(src, path)
-		if err != nil {
-			return err
-		}
-		dstPath := filepath.Join(dst, relPath)
-
-		if info.IsDir() {
-			return os.MkdirAll(dstPath, info.Mode())
-		}
-
-		if err := os.Link(path, dstPath); err != nil {
-			if os.IsExist(err) {
-				return nil
-			}
-			if isHardlinkNotSupported(err) {
-				return copyFile(path, dstPath)
-			}
-			return err
-		}
-
-		return nil
-	})
-}
-
-func isHardlinkNotSupported(err error) bool {
-	return strings.Contains(err.Error(), "cross-device link") ||
-		strings.Contains(err.Error(), "operation not supported")
+func validPathType(pathType string) bool {
+	switch pathType {
+	case "", "plain", "cargo", "npm", "yarn", "pnpm", "bun", pathTypeGoMod, pathTypeGoBuild, "poetry", "pip", "uv", "gradle", "maven", "bundler", "turbo", "nx":
+		return true
+	}
+	return false
 }
 
-func shouldSkipPath(relPath string, artifactName string) bool {
-	switch artifactName {
+func shouldSkipPath(relPath string, pathType string) bool {
+	switch pathType {
 	case "cargo":
 		return shouldSkipCargoPath(relPath)
+	case pathTypeGoMod:
+		return strings.HasPrefix(relPath, "cache/vcs/")
+	case "poetry", "pip", "uv":
+		return shouldSkipVenvPath(relPath)
+	case "gradle":
+		return shouldSkipGradlePath(relPath)
+	case "turbo":
+		return shouldSkipTurboPath(relPath)
 	default:
 		return false
 	}
@@ -223,38 +276,39 @@ func shouldSkipCargoPath(relPath string) bool {
 
 type SeedOptions struct {
 	ArtifactName  string
+	PathType      string
 	Logger        *FileLogger
 	NumWorkers    int
 	OperationName string
+	Link          LinkMode
+	IOLimit       int64
+	Include       []string
+	Exclude       []string
+	SkipPatterns  []string
 }
 
-func copyDirectory(src, dst, artifactName string, logger *FileLogger, operation string) error {
-	return SeedDirectory(src, dst, SeedOptions{
-		ArtifactName:  artifactName,
-		Logger:        logger,
-		OperationName: operation,
-	})
+func (o SeedOptions) pathType() string {
+	if o.PathType != "" {
+		return o.PathType
+	}
+	return o.ArtifactName
 }
 
-func countFiles(src string, artifactName string) (int64, error) {
-	var count int64
-	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+func countFiles(src string, pathType string, filter pathFilter) (int64, error) {
+	var count atomic.Int64
+	err := parallelWalk(src, workerCount(workersWalk, src), func(path, relPath string, d fs.DirEntry) error {
 		if d.IsDir() {
+			if filter.skip(relPath, true) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
-		relPath, err := filepath.Rel(src, path)
-		if err != nil {
-			return err
-		}
-		if !shouldSkipPath(relPath, artifactName) {
-			count++
+		if !shouldSkipPath(relPath, pathType) && !filter.skip(relPath, false) {
+			count.Add(1)
 		}
 		return nil
 	})
-	return count, err
+	return count.Load(), err
 }
 
 type fileEntry struct {
@@ -265,16 +319,16 @@ type fileEntry struct {
 }
 
 func SeedDirectory(src, dst string, opts SeedOptions) error {
-	numWorkers := opts.NumWorkers
+	numWorkers := limitJobs(opts.NumWorkers)
 	if numWorkers <= 0 {
-		numWorkers = 16
+		numWorkers = workerCount(workersSeed, src, dst)
 	}
 
 	var totalFiles int64
 	var progress *ProgressLogger
 	if opts.Logger != nil {
 		var err error
-		totalFiles, err = countFiles(src, opts.ArtifactName)
+		totalFiles, err = countFiles(src, opts.pathType(), opts.pathFilter())
 		if err != nil {
 			return fmt.Errorf("failed to count files: %w", err)
 		}
@@ -285,38 +339,26 @@ func SeedDirectory(src, dst string, opts SeedOptions) error {
 		progress = NewProgressLogger(opts.Logger, operation+" "+opts.ArtifactName, totalFiles)
 	}
 
-	var dirs []struct {
-		path string
-		mode fs.FileMode
-	}
+	var dirs []copiedDir
 	var files []fileEntry
+	var mu sync.Mutex
 
-	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		relPath, err := filepath.Rel(src, path)
-		if err != nil {
-			return err
-		}
-
+	err := parallelWalk(src, workerCount(workersWalk, src), func(path, relPath string, d fs.DirEntry) error {
 		if d.IsDir() {
-			if shouldSkipPath(relPath+"/", opts.ArtifactName) {
+			if shouldSkipPath(relPath+"/", opts.pathType()) || opts.pathFilter().skip(relPath, true) {
 				return filepath.SkipDir
 			}
 			info, err := d.Info()
 			if err != nil {
 				return err
 			}
-			dirs = append(dirs, struct {
-				path string
-				mode fs.FileMode
-			}{filepath.Join(dst, relPath), info.Mode()})
+			mu.Lock()
+			dirs = append(dirs, copiedDir{src: path, dst: filepath.Join(dst, relPath), info: info})
+			mu.Unlock()
 			return nil
 		}
 
-		if shouldSkipPath(relPath, opts.ArtifactName) {
+		if shouldSkipPath(relPath, opts.pathType()) || opts.pathFilter().skip(relPath, false) {
 			return nil
 		}
 
@@ -325,6 +367,8 @@ func SeedDirectory(src, dst string, opts SeedOptions) error {
 			return err
 		}
 
+		mu.Lock()
+		defer mu.Unlock()
 		files = append(files, fileEntry{
 			srcPath:  path,
 			dstPath:  filepath.Join(dst, relPath),
@@ -338,9 +382,10 @@ func SeedDirectory(src, dst string, opts SeedOptions) error {
 		return fmt.Errorf("failed to walk source directory: %w", err)
 	}
 
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].dst < dirs[j].dst })
 	for _, dir := range dirs {
-		if err := os.MkdirAll(dir.path, dir.mode); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir.path, err)
+		if err := os.MkdirAll(dir.dst, dir.info.Mode()); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir.dst, err)
 		}
 	}
 
@@ -351,12 +396,20 @@ func SeedDirectory(src, dst string, opts SeedOptions) error {
 	close(fileChan)
 
 	g, ctx := errgroup.WithContext(context.Background())
+	limiter := newIOLimiter(opts.IOLimit)
 
 	var once sync.Once
 	var firstErr error
 
+	operation := opts.OperationName
+	if operation == "" {
+		operation = "seeding"
+	}
+
 	for i := 0; i < numWorkers; i++ {
 		g.Go(func() error {
+			span := profileWorker(operation + " " + opts.ArtifactName)
+			defer span.finish()
 			for {
 				select {
 				case <-ctx.Done():
@@ -366,7 +419,14 @@ func SeedDirectory(src, dst string, opts SeedOptions) error {
 						return nil
 					}
 
-					if err := linkOrCopyFile(f.srcPath, f.dstPath); err != nil {
+					start := time.Now()
+					link := opts.linkFor(f.relPath)
+					err := limiter.waitCopy(f.srcPath, link)
+					if err == nil {
+						err = linkFile(f.srcPath, f.dstPath, link)
+					}
+					span.item(start)
+					if err != nil {
 						once.Do(func() {
 							firstErr = fmt.Errorf("failed to link %s: %w", f.relPath, err)
 						})
@@ -384,6 +444,11 @@ func SeedDirectory(src, dst string, opts SeedOptions) error {
 	if err := g.Wait(); err != nil {
 		return err
 	}
+	if opts.Link == LinkCopy || opts.Link == LinkReflink {
+		if err := copyDirMetadata(dirs); err != nil {
+			return err
+		}
+	}
 
 	if progress != nil {
 		progress.Done()
@@ -392,86 +457,183 @@ func SeedDirectory(src, dst string, opts SeedOptions) error {
 	return nil
 }
 
-func linkOrCopyFile(src, dst string) error {
-	if err := os.Link(src, dst); err != nil {
-		if os.IsExist(err) {
-			return nil
-		}
-		if isHardlinkNotSupported(err) {
-			return copyFile(src, dst)
-		}
+func (cm *CacheManager) RestoreFromCache(entry ArtifactCacheEntry, logger *FileLogger) error {
+	if err := cm.verifyBeforeRestore(entry, logger); err != nil {
 		return err
 	}
-	return nil
-}
-
-func copyFile(src, dst string) error {
-	in, err := os.Open(src)
-	if err != nil {
+	if err := cm.restoreEnvPaths(entry, logger); err != nil {
 		return err
 	}
-	defer in.Close()
+	if entry.VerifyLockfile {
+		for _, envPath := range entry.EnvPaths {
+			if err := cm.verifyRestoredLockfile(entry, envPath, logger); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
 
-	out, err := os.Create(dst)
+func (cm *CacheManager) restoreEnvPaths(entry ArtifactCacheEntry, logger *FileLogger) (err error) {
+	lock, err := cm.shareCacheLock(entry.CachePath)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to lock cache entry: %w", err)
 	}
-	defer out.Close()
-
-	if _, err := io.Copy(out, in); err != nil {
-		return err
+	defer func() {
+		err = errors.Join(err, cm.unlockShared(lock))
+	}()
+	if !dirExists(entry.CachePath) {
+		return fmt.Errorf("%w: %s (key: %s) was removed", ErrCacheCorrupt, entry.Name, entry.Key)
 	}
 
-	info, err := os.Stat(src)
-	if err != nil {
-		return err
-	}
-	return os.Chmod(dst, info.Mode())
-}
+	for i, envPath := range entry.EnvPaths {
+		pathType := entry.pathType(i)
+		if archive, ok := findArchive(entry.CachePath, filepath.Base(envPath)); ok {
+			if err := restoreArchive(archive, envPath, entry, logger); err != nil {
+				return fmt.Errorf("failed to restore cache for %s: %w", entry.Name, err)
+			}
+			if err := cm.ApplyPostRestoreFixes(pathType, envPath); err != nil {
+				return fmt.Errorf("failed to apply post-restore fixes for %s: %w", entry.Name, err)
+			}
+			continue
+		}
 
-func (cm *CacheManager) RestoreFromCache(entry ArtifactCacheEntry, logger *FileLogger) error {
-	for _, envPath := range entry.EnvPaths {
 		srcPath := filepath.Join(entry.CachePath, filepath.Base(envPath))
 		if !dirExists(srcPath) {
 			srcPath = filepath.Join(entry.CachePath, entry.Name)
 		}
 
-		if err := os.RemoveAll(envPath); err != nil {
-			return fmt.Errorf("failed to remove existing %s: %w", envPath, err)
+		strategy, err := cm.Strategy(envPath)
+		if err != nil {
+			return err
 		}
-
-		if err := copyDirectory(srcPath, envPath, entry.Name, logger, "restoring"); err != nil {
+		if err := restoreDirectory(srcPath, envPath, SeedOptions{
+			ArtifactName:  entry.Name,
+			PathType:      pathType,
+			Logger:        logger,
+			NumWorkers:    entry.Workers,
+			OperationName: "restoring",
+			Link:          strategy.Link,
+			IOLimit:       entry.IOLimit,
+			SkipPatterns:  entry.SkipPatterns,
+		}); err != nil {
 			return fmt.Errorf("failed to restore cache for %s: %w", entry.Name, err)
 		}
 
-		if err := cm.ApplyPostRestoreFixes(entry.Name, envPath); err != nil {
+		if err := cm.ApplyPostRestoreFixes(pathType, envPath); err != nil {
 			return fmt.Errorf("failed to apply post-restore fixes for %s: %w", entry.Name, err)
 		}
 	}
 	return nil
 }
 
-func (cm *CacheManager) ApplyPostRestoreFixes(artifactName, envPath string) error {
-	switch artifactName {
+func restoreDirectory(srcPath, envPath string, opts SeedOptions) error {
+	return restoreInto(envPath, func(tmpPath string) error {
+		return SeedDirectory(srcPath, tmpPath, opts)
+	})
+}
+
+func restoreArchive(archive, envPath string, entry ArtifactCacheEntry, logger *FileLogger) error {
+	start := time.Now()
+	err := restoreInto(envPath, func(tmpPath string) error {
+		return extractArchive(archive, tmpPath, entry.Workers, newIOLimiter(entry.IOLimit))
+	})
+	if err == nil && logger != nil {
+		logger.Log("restored %s from %s in %v", envPath, filepath.Base(archive), time.Since(start).Round(time.Millisecond))
+	}
+	return err
+}
+
+func restoreInto(envPath string, fill func(tmpPath string) error) error {
+	tmpPath := envPath + cacheTmpSuffix
+	untrack, err := trackTempDir(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(tmpPath); err != nil {
+		untrack()
+		return fmt.Errorf("failed to clear staging dir: %w", err)
+	}
+
+	if err := fill(tmpPath); err != nil {
+		os.RemoveAll(tmpPath)
+		untrack()
+		return err
+	}
+
+	if err := syncTree(tmpPath); err != nil {
+		os.RemoveAll(tmpPath)
+		untrack()
+		return err
+	}
+
+	if err := os.RemoveAll(envPath); err != nil {
+		os.RemoveAll(tmpPath)
+		untrack()
+		return fmt.Errorf("failed to remove existing %s: %w", envPath, err)
+	}
+
+	if err := os.Rename(tmpPath, envPath); err != nil {
+		os.RemoveAll(tmpPath)
+		untrack()
+		return fmt.Errorf("failed to move restored %s into place: %w", envPath, err)
+	}
+
+	if err := syncParent(envPath); err != nil {
+		untrack()
+		return err
+	}
+
+	return untrack()
+}
+
+func (cm *CacheManager) ApplyPostRestoreFixes(pathType, envPath string) error {
+	switch pathType {
 	case "cargo":
 		return cm.touchCargoFingerprints(envPath)
 	case "npm", "yarn", "pnpm", "bun":
 		return cm.cleanNodeModulesBin(envPath)
+	case pathTypeGoMod:
+		return makeDirsWritable(envPath)
+	case "poetry", "pip", "uv":
+		return relocateVenv(envPath)
+	case "maven":
+		return fixMavenTarget(envPath)
 	default:
 		return nil
 	}
 }
 
+func (cm *CacheManager) RunPostRestore(ctx context.Context, cfg *Config, entry ArtifactCacheEntry, envPath, rootPath string, logger *FileLogger) error {
+	if entry.PostRestore == "" {
+		return nil
+	}
+
+	logger.Log("running post_restore for %s: %s", entry.Name, entry.PostRestore)
+	envVars := append(cm.EnvVars(cfg.Build, envPath, rootPath), "MONO_ENV_PATH="+envPath, "MONO_ROOT_PATH="+rootPath, "MONO_CACHE_DIR="+cm.LocalCacheDir)
+	if err := runEnvScript(ctx, cfg, nil, envPath, entry.PostRestore, envVars, logger); err != nil {
+		return fmt.Errorf("post_restore for %s failed: %w", entry.Name, err)
+	}
+	return nil
+}
+
 func (cm *CacheManager) touchCargoFingerprints(targetDir string) error {
 	now := time.Now()
 
-	for _, profile := range []string{"debug", "release"} {
-		fingerprintDir := filepath.Join(targetDir, profile, ".fingerprint")
-		if !dirExists(fingerprintDir) {
-			continue
-		}
+	profileDirs, err := cargoProfileDirs(targetDir)
+	if err != nil {
+		return err
+	}
 
-		if err := touchDepFilesParallel(fingerprintDir, now, 8); err != nil {
+	for _, profileDir := range profileDirs {
+		if err := rewriteCargoBuildOutputs(profileDir); err != nil {
+			return err
+		}
+		if err := touchDepFilesParallel(filepath.Join(profileDir, ".fingerprint"), now, 0); err != nil {
+			return err
+		}
+		if err := touchCargoOutputs(profileDir, now); err != nil {
 			return err
 		}
 	}
@@ -520,7 +682,7 @@ func touchDepFilesParallel(fingerprintDir string, now time.Time, numWorkers int)
 	}
 
 	if numWorkers <= 0 {
-		numWorkers = 8
+		numWorkers = workerCount(workersTouch, fingerprintDir)
 	}
 
 	var depFiles []string
@@ -546,12 +708,16 @@ func touchDepFilesParallel(fingerprintDir string, now time.Time, numWorkers int)
 		}
 	}
 
-	if len(depFiles) == 0 {
+	return touchFilesParallel(depFiles, now, numWorkers, "touching cargo fingerprints")
+}
+
+func touchFilesParallel(files []string, now time.Time, numWorkers int, operation string) error {
+	if len(files) == 0 {
 		return nil
 	}
 
-	fileChan := make(chan string, len(depFiles))
-	for _, f := range depFiles {
+	fileChan := make(chan string, len(files))
+	for _, f := range files {
 		fileChan <- f
 	}
 	close(fileChan)
@@ -560,6 +726,8 @@ func touchDepFilesParallel(fingerprintDir string, now time.Time, numWorkers int)
 
 	for i := 0; i < numWorkers; i++ {
 		g.Go(func() error {
+			span := profileWorker(operation)
+			defer span.finish()
 			for {
 				select {
 				case <-ctx.Done():
@@ -568,7 +736,10 @@ func touchDepFilesParallel(fingerprintDir string, now time.Time, numWorkers int)
 					if !ok {
 						return nil
 					}
-					if err := os.Chtimes(path, now, now); err != nil {
+					start := time.Now()
+					err := os.Chtimes(path, now, now)
+					span.item(start)
+					if err != nil {
 						return err
 					}
 				}
@@ -590,53 +761,233 @@ func (cm *CacheManager) cleanNodeModulesBin(nodeModulesDir string) error {
 }
 
 func (cm *CacheManager) StoreToCache(entry ArtifactCacheEntry) error {
-	if err := os.MkdirAll(entry.CachePath, 0755); err != nil {
+	lock, err := cm.waitCacheLock(entry.CachePath)
+	if err != nil {
+		return fmt.Errorf("failed to lock cache entry: %w", err)
+	}
+	defer cm.releaseCacheLock(lock)
+
+	if dirExists(entry.CachePath) {
+		return nil
+	}
+
+	tmpPath := entry.CachePath + cacheTmpSuffix
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return fmt.Errorf("failed to clear staging dir: %w", err)
+	}
+	if err := os.MkdirAll(tmpPath, 0755); err != nil {
 		return fmt.Errorf("failed to create cache dir: %w", err)
 	}
 
-	for _, envPath := range entry.EnvPaths {
+	if isArchiveFormat(entry.Format) {
+		return cm.storeArchives(entry, tmpPath)
+	}
+
+	var moved, staged []string
+	links := make(map[string]LinkMode)
+	for i, envPath := range entry.EnvPaths {
 		if !dirExists(envPath) {
 			continue
 		}
 
-		cacheDst := filepath.Join(entry.CachePath, filepath.Base(envPath))
+		strategy, err := cm.Strategy(envPath)
+		if err != nil {
+			restoreErr := restoreMovedPaths(tmpPath, moved)
+			if restoreErr != nil {
+				return fmt.Errorf("%w (recovery error: %v)", err, restoreErr)
+			}
+			return err
+		}
 
-		if err := os.Rename(envPath, cacheDst); err != nil {
+		staging := filepath.Join(tmpPath, filepath.Base(envPath))
+		if !entry.pathFilter().empty() {
+			if err := SeedDirectory(envPath, staging, SeedOptions{
+				ArtifactName: entry.Name,
+				PathType:     entry.pathType(i),
+				NumWorkers:   entry.Workers,
+				Link:         strategy.Link,
+				IOLimit:      entry.IOLimit,
+				Include:      entry.Include,
+				Exclude:      entry.Exclude,
+				SkipPatterns: entry.SkipPatterns,
+			}); err != nil {
+				restoreErr := restoreMovedPaths(tmpPath, moved)
+				if restoreErr != nil {
+					return fmt.Errorf("failed to seed %s into cache: %w (recovery error: %v)", envPath, err, restoreErr)
+				}
+				return fmt.Errorf("failed to seed %s into cache: %w", envPath, err)
+			}
+			staged = append(staged, envPath)
+			continue
+		}
+		if !strategy.SameFilesystem {
+			if err := copyDir(envPath, staging); err != nil {
+				restoreErr := restoreMovedPaths(tmpPath, moved)
+				if restoreErr != nil {
+					return fmt.Errorf("failed to copy %s to cache: %w (recovery error: %v)", envPath, err, restoreErr)
+				}
+				return fmt.Errorf("failed to copy %s to cache: %w", envPath, err)
+			}
+			staged = append(staged, envPath)
+			continue
+		}
+
+		if err := os.Rename(envPath, staging); err != nil {
+			restoreErr := restoreMovedPaths(tmpPath, moved)
+			if restoreErr != nil {
+				return fmt.Errorf("failed to move %s to cache: %w (recovery error: %v)", envPath, err, restoreErr)
+			}
 			return fmt.Errorf("failed to move %s to cache: %w", envPath, err)
 		}
+		moved = append(moved, envPath)
+		staged = append(staged, envPath)
+		links[envPath] = strategy.Link
+	}
+
+	for _, envPath := range staged {
+		if err := cm.dedupIfNodeModules(filepath.Join(tmpPath, filepath.Base(envPath))); err != nil {
+			restoreErr := restoreMovedPaths(tmpPath, moved)
+			if restoreErr != nil {
+				return fmt.Errorf("%w (recovery error: %v)", err, restoreErr)
+			}
+			return err
+		}
+	}
+
+	if err := cm.linkUnchangedFiles(tmpPath, entry.CachePath); err != nil {
+		restoreErr := restoreMovedPaths(tmpPath, moved)
+		if restoreErr != nil {
+			return fmt.Errorf("failed to link unchanged files: %w (recovery error: %v)", err, restoreErr)
+		}
+		return fmt.Errorf("failed to link unchanged files: %w", err)
+	}
+
+	if err := syncTree(tmpPath); err != nil {
+		restoreErr := restoreMovedPaths(tmpPath, moved)
+		if restoreErr != nil {
+			return fmt.Errorf("%w (recovery error: %v)", err, restoreErr)
+		}
+		return err
+	}
+
+	if err := os.Rename(tmpPath, entry.CachePath); err != nil {
+		restoreErr := restoreMovedPaths(tmpPath, moved)
+		if restoreErr != nil {
+			return fmt.Errorf("failed to publish cache entry: %w (recovery error: %v)", err, restoreErr)
+		}
+		return fmt.Errorf("failed to publish cache entry: %w", err)
+	}
+	if err := syncParent(entry.CachePath); err != nil {
+		return err
+	}
 
-		if err := HardlinkTree(cacheDst, envPath); err != nil {
+	for _, envPath := range moved {
+		cacheDst := filepath.Join(entry.CachePath, filepath.Base(envPath))
+		if err := LinkTree(cacheDst, envPath, links[envPath]); err != nil {
 			return fmt.Errorf("failed to hardlink back from cache: %w", err)
 		}
 	}
 
-	return nil
+	return cm.sealEntry(entry.CachePath)
 }
 
+func (cm *CacheManager) storeArchives(entry ArtifactCacheEntry, tmpPath string) error {
+	for i, envPath := range entry.EnvPaths {
+		if !dirExists(envPath) {
+			continue
+		}
+		dst := filepath.Join(tmpPath, archiveName(filepath.Base(envPath), entry.Format))
+		if err := writeArchive(envPath, dst, entry.Format, entry.pathType(i), entry.pathFilter()); err != nil {
+			os.RemoveAll(tmpPath)
+			return err
+		}
+	}
+
+	if err := syncDir(tmpPath); err != nil {
+		os.RemoveAll(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, entry.CachePath); err != nil {
+		os.RemoveAll(tmpPath)
+		return fmt.Errorf("failed to publish cache entry: %w", err)
+	}
+	if err := syncParent(entry.CachePath); err != nil {
+		return err
+	}
+
+	return cm.sealEntry(entry.CachePath)
+}
+
+func restoreMovedPaths(tmpPath string, moved []string) error {
+	for _, envPath := range moved {
+		if err := os.Rename(filepath.Join(tmpPath, filepath.Base(envPath)), envPath); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(tmpPath)
+}
+
+var ErrSyncTimeout = errors.New("sync timed out")
+
 type SyncOptions struct {
 	HardlinkBack bool
+	Incremental  bool
+	Status       *StatusServer
+	Deadline     time.Time
 }
 
 func (cm *CacheManager) acquireCacheLock(cachePath string) (*os.File, error) {
+	f, err := cm.lockCacheEntry(cachePath, syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return nil, nil
+	}
+	return f, err
+}
+
+func (cm *CacheManager) waitCacheLock(cachePath string) (*os.File, error) {
+	return cm.lockCacheEntry(cachePath, syscall.LOCK_EX)
+}
+
+func (cm *CacheManager) lockCacheEntry(cachePath string, how int) (*os.File, error) {
 	lockPath := cachePath + ".lock"
 
 	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
 		return nil, err
 	}
 
-	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	f, err := openLockFile(lockPath, how)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+	if err := writeLockHolder(f, "cache"); err != nil {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
 		f.Close()
-		return nil, nil
+		return nil, err
 	}
 
 	return f, nil
 }
 
+func (cm *CacheManager) shareCacheLock(cachePath string) (*os.File, error) {
+	f, err := openLockFile(cachePath+".lock", syscall.LOCK_SH)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return f, err
+}
+
+func (cm *CacheManager) unlockShared(f *os.File) error {
+	if f == nil {
+		return nil
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_UN); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func (cm *CacheManager) releaseCacheLock(f *os.File) {
 	if f != nil {
 		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
@@ -645,7 +996,15 @@ func (cm *CacheManager) releaseCacheLock(f *os.File) {
 }
 
 func (cm *CacheManager) Sync(artifacts []ArtifactConfig, rootPath, envPath string, opts SyncOptions) error {
-	for _, artifact := range artifacts {
+	for i, artifact := range artifacts {
+		if !opts.Deadline.IsZero() && time.Now().After(opts.Deadline) {
+			var skipped []string
+			for _, a := range artifacts[i:] {
+				skipped = append(skipped, a.Name)
+			}
+			return fmt.Errorf("%w, skipped %s", ErrSyncTimeout, strings.Join(skipped, ", "))
+		}
+		opts.Status.SetPhase("syncing " + artifact.Name)
 		if err := cm.syncArtifact(artifact, rootPath, envPath, opts); err != nil {
 			return err
 		}
@@ -654,13 +1013,21 @@ func (cm *CacheManager) Sync(artifacts []ArtifactConfig, rootPath, envPath strin
 }
 
 func (cm *CacheManager) isBuildInProgress(envPath string, artifact ArtifactConfig) bool {
-	switch artifact.Name {
-	case "cargo":
-		lockFile := filepath.Join(envPath, "target", ".cargo-lock")
-		return fileExists(lockFile)
-	default:
-		return false
+	if artifact.Name == "cargo" && fileExists(filepath.Join(envPath, "target", ".cargo-lock")) {
+		return true
+	}
+	for _, p := range artifact.Paths {
+		if artifact.PathType(p) == "cargo" && fileExists(filepath.Join(p.resolve(envPath), ".cargo-lock")) {
+			return true
+		}
+		if artifact.BuildLockFile == "" {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(p.resolve(envPath), artifact.BuildLockFile)); err == nil {
+			return true
+		}
 	}
+	return false
 }
 
 func (cm *CacheManager) syncArtifact(artifact ArtifactConfig, rootPath, envPath string, opts SyncOptions) error {
@@ -673,19 +1040,39 @@ func (cm *CacheManager) syncArtifact(artifact ArtifactConfig, rootPath, envPath
 		return fmt.Errorf("failed to compute cache key for %s: %w", artifact.Name, err)
 	}
 
-	cachePath := cm.GetArtifactCachePath(rootPath, artifact.Name, key)
+	cachePath := cm.artifactCachePath(artifact, rootPath, key)
 
 	if dirExists(cachePath) {
+		if !opts.Incremental || isArchiveFormat(artifact.Format) {
+			return nil
+		}
+		if _, err := cm.updateCacheEntry(artifact, envPath, cachePath); err != nil {
+			return fmt.Errorf("failed to update %s in the cache: %w", artifact.Name, err)
+		}
 		return nil
 	}
 
 	for _, p := range artifact.Paths {
-		localPath := filepath.Join(envPath, p)
+		localPath := p.resolve(envPath)
 
 		if !dirExists(localPath) {
 			continue
 		}
 
+		if isArchiveFormat(artifact.Format) {
+			if err := cm.archiveToCache(localPath, cachePath, artifact.Format, artifact.PathType(p), artifact.pathFilter(), opts.HardlinkBack); err != nil {
+				return fmt.Errorf("failed to sync %s: %w", artifact.Name, err)
+			}
+			continue
+		}
+
+		if !artifact.pathFilter().empty() {
+			if err := cm.seedFilteredToCache(localPath, cachePath, artifact, artifact.PathType(p), opts.HardlinkBack); err != nil {
+				return fmt.Errorf("failed to sync %s: %w", artifact.Name, err)
+			}
+			continue
+		}
+
 		if err := cm.moveToCache(localPath, cachePath, opts.HardlinkBack); err != nil {
 			return fmt.Errorf("failed to sync %s: %w", artifact.Name, err)
 		}
@@ -714,15 +1101,41 @@ func (cm *CacheManager) moveToCache(localPath, cachePath string, hardlinkBack bo
 		return err
 	}
 
+	strategy, err := cm.Strategy(localPath)
+	if err != nil {
+		return err
+	}
+	if !strategy.SameFilesystem {
+		if err := cm.copyToCache(localPath, targetInCache, hardlinkBack); err != nil {
+			return err
+		}
+		return cm.sealEntry(cachePath)
+	}
+
 	if err := os.Rename(localPath, targetInCache); err != nil {
-		if isCrossDevice(err) {
-			return cm.copyToCache(localPath, targetInCache, hardlinkBack)
+		return err
+	}
+
+	if err := cm.dedupIfNodeModules(targetInCache); err != nil {
+		if recoverErr := os.Rename(targetInCache, localPath); recoverErr != nil {
+			return fmt.Errorf("%w (recovery error: %v)", err, recoverErr)
 		}
 		return err
 	}
 
+	if err := cm.linkUnchangedFiles(cachePath, cachePath); err != nil {
+		return fmt.Errorf("failed to link unchanged files: %w", err)
+	}
+
+	if err := syncTree(targetInCache); err != nil {
+		return err
+	}
+	if err := syncDir(cachePath); err != nil {
+		return err
+	}
+
 	if hardlinkBack {
-		if err := HardlinkTree(targetInCache, localPath); err != nil {
+		if err := LinkTree(targetInCache, localPath, strategy.Link); err != nil {
 			recoverErr := os.Rename(targetInCache, localPath)
 			cleanupErr := os.RemoveAll(cachePath)
 			if recoverErr != nil {
@@ -735,7 +1148,76 @@ func (cm *CacheManager) moveToCache(localPath, cachePath string, hardlinkBack bo
 		}
 	}
 
-	return nil
+	return cm.sealEntry(cachePath)
+}
+
+func (cm *CacheManager) archiveToCache(localPath, cachePath, format, pathType string, filter pathFilter, keepLocal bool) error {
+	lock, err := cm.acquireCacheLock(cachePath)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return nil
+	}
+	defer cm.releaseCacheLock(lock)
+
+	target := filepath.Join(cachePath, archiveName(filepath.Base(localPath), format))
+	if fileExists(target) {
+		return nil
+	}
+
+	if err := os.MkdirAll(cachePath, 0755); err != nil {
+		return err
+	}
+	if err := writeArchive(localPath, target, format, pathType, filter); err != nil {
+		return err
+	}
+
+	if !keepLocal {
+		if err := os.RemoveAll(localPath); err != nil {
+			return err
+		}
+	}
+
+	return cm.sealEntry(cachePath)
+}
+
+func (cm *CacheManager) seedFilteredToCache(localPath, cachePath string, artifact ArtifactConfig, pathType string, keepLocal bool) error {
+	lock, err := cm.acquireCacheLock(cachePath)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return nil
+	}
+	defer cm.releaseCacheLock(lock)
+
+	targetInCache := filepath.Join(cachePath, filepath.Base(localPath))
+	if dirExists(targetInCache) {
+		return nil
+	}
+
+	if err := cm.seedToCache(localPath, cachePath, artifact, pathType, nil); err != nil {
+		os.RemoveAll(targetInCache)
+		return err
+	}
+	if err := cm.dedupIfNodeModules(targetInCache); err != nil {
+		return err
+	}
+	if err := syncTree(targetInCache); err != nil {
+		return err
+	}
+	if err := syncDir(cachePath); err != nil {
+		return err
+	}
+
+	if !keepLocal {
+		if err := os.RemoveAll(localPath); err != nil {
+			return err
+		}
+	}
+
+	return cm.sealEntry(cachePath)
 }
 
 func (cm *CacheManager) copyToCache(localPath, targetInCache string, hardlinkBack bool) error {
@@ -750,13 +1232,9 @@ func (cm *CacheManager) copyToCache(localPath, targetInCache string, hardlinkBac
 	return os.RemoveAll(localPath)
 }
 
-func isCrossDevice(err error) bool {
-	return strings.Contains(err.Error(), "cross-device link") ||
-		strings.Contains(err.Error(), "invalid cross-device link")
-}
-
 func copyDir(src, dst string) error {
-	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+	var dirs []copiedDir
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -768,11 +1246,16 @@ func copyDir(src, dst string) error {
 		dstPath := filepath.Join(dst, relPath)
 
 		if info.IsDir() {
+			dirs = append(dirs, copiedDir{src: path, dst: dstPath, info: info})
 			return os.MkdirAll(dstPath, info.Mode())
 		}
 
 		return copyFile(path, dstPath)
 	})
+	if err != nil {
+		return err
+	}
+	return copyDirMetadata(dirs)
 }
 
 func (cm *CacheManager) SeedFromRoot(artifacts []ArtifactConfig, rootPath, envPath string, logger *FileLogger) error {
@@ -794,7 +1277,7 @@ func (cm *CacheManager) seedArtifactFromRoot(artifact ArtifactConfig, rootPath,
 		return fmt.Errorf("failed to compute cache key for env %s: %w", artifact.Name, err)
 	}
 
-	cachePath := cm.GetArtifactCachePath(rootPath, artifact.Name, envKey)
+	cachePath := cm.artifactCachePath(artifact, rootPath, envKey)
 	if dirExists(cachePath) {
 		return nil
 	}
@@ -812,21 +1295,76 @@ func (cm *CacheManager) seedArtifactFromRoot(artifact ArtifactConfig, rootPath,
 		return nil
 	}
 
+	lock, err := cm.waitCacheLock(cachePath)
+	if err != nil {
+		return fmt.Errorf("failed to lock cache entry: %w", err)
+	}
+	defer cm.releaseCacheLock(lock)
+
+	if dirExists(cachePath) {
+		return nil
+	}
+
+	tmpPath := cachePath + cacheTmpSuffix
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return fmt.Errorf("failed to clear staging dir: %w", err)
+	}
+
+	seeded := false
 	for _, p := range artifact.Paths {
-		rootArtifact := filepath.Join(rootPath, p)
+		rootArtifact := p.resolve(rootPath)
 		if !dirExists(rootArtifact) {
 			continue
 		}
 
-		if err := cm.seedToCache(rootArtifact, cachePath, artifact.Name, logger); err != nil {
+		if isArchiveFormat(artifact.Format) {
+			if err := os.MkdirAll(tmpPath, 0755); err != nil {
+				return err
+			}
+			dst := filepath.Join(tmpPath, archiveName(filepath.Base(rootArtifact), artifact.Format))
+			if err := writeArchive(rootArtifact, dst, artifact.Format, artifact.PathType(p), artifact.pathFilter()); err != nil {
+				os.RemoveAll(tmpPath)
+				return fmt.Errorf("failed to seed %s from root: %w", artifact.Name, err)
+			}
+			seeded = true
+			continue
+		}
+
+		if err := cm.seedToCache(rootArtifact, tmpPath, artifact, artifact.PathType(p), logger); err != nil {
+			os.RemoveAll(tmpPath)
 			return fmt.Errorf("failed to seed %s from root: %w", artifact.Name, err)
 		}
+		if err := cm.dedupIfNodeModules(filepath.Join(tmpPath, filepath.Base(rootArtifact))); err != nil {
+			os.RemoveAll(tmpPath)
+			return err
+		}
+		seeded = true
 	}
 
-	return nil
+	if !seeded {
+		return nil
+	}
+
+	if err := cm.linkUnchangedFiles(tmpPath, cachePath); err != nil {
+		os.RemoveAll(tmpPath)
+		return fmt.Errorf("failed to link unchanged files: %w", err)
+	}
+	if err := syncTree(tmpPath); err != nil {
+		os.RemoveAll(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, cachePath); err != nil {
+		os.RemoveAll(tmpPath)
+		return fmt.Errorf("failed to publish seeded %s: %w", artifact.Name, err)
+	}
+	if err := syncParent(cachePath); err != nil {
+		return err
+	}
+
+	return cm.sealEntry(cachePath)
 }
 
-func (cm *CacheManager) seedToCache(sourcePath, cachePath, artifactName string, logger *FileLogger) error {
+func (cm *CacheManager) seedToCache(sourcePath, cachePath string, artifact ArtifactConfig, pathType string, logger *FileLogger) error {
 	if err := os.MkdirAll(cachePath, 0755); err != nil {
 		return err
 	}
@@ -837,9 +1375,26 @@ func (cm *CacheManager) seedToCache(sourcePath, cachePath, artifactName string,
 		return nil
 	}
 
+	strategy, err := cm.Strategy(sourcePath)
+	if err != nil {
+		return err
+	}
+
+	ioLimit, err := artifact.ioLimit()
+	if err != nil {
+		return err
+	}
+
 	return SeedDirectory(sourcePath, targetInCache, SeedOptions{
-		ArtifactName: artifactName,
+		ArtifactName: artifact.Name,
+		PathType:     pathType,
 		Logger:       logger,
+		NumWorkers:   artifact.Workers,
+		Link:         strategy.Link,
+		IOLimit:      ioLimit,
+		Include:      artifact.Include,
+		Exclude:      artifact.Exclude,
+		SkipPatterns: artifact.SkipPatterns,
 	})
 }
 
@@ -847,10 +1402,13 @@ type CacheSizeEntry struct {
 	ProjectID string
 	Artifact  string
 	CacheKey  string
+	Path      string
 	Size      int64
+	DiskUsage int64
+	CreatedAt time.Time
 }
 
-func (cm *CacheManager) GetCacheSizes() ([]CacheSizeEntry, error) {
+func (cm *CacheManager) ListCacheEntries() ([]CacheSizeEntry, error) {
 	var entries []CacheSizeEntry
 
 	if !dirExists(cm.LocalCacheDir) {
@@ -879,30 +1437,21 @@ func (cm *CacheManager) GetCacheSizes() ([]CacheSizeEntry, error) {
 				continue
 			}
 			artifact := artifactDir.Name()
-			artifactPath := filepath.Join(projectPath, artifact)
 
-			keyDirs, err := os.ReadDir(artifactPath)
+			keyDirs, err := os.ReadDir(filepath.Join(projectPath, artifact))
 			if err != nil {
 				continue
 			}
 
 			for _, keyDir := range keyDirs {
-				if !keyDir.IsDir() {
-					continue
-				}
-				cacheKey := keyDir.Name()
-				keyPath := filepath.Join(artifactPath, cacheKey)
-
-				size, err := cm.calculateDirSize(keyPath)
-				if err != nil {
+				if !keyDir.IsDir() || strings.HasSuffix(keyDir.Name(), cacheTmpSuffix) {
 					continue
 				}
-
 				entries = append(entries, CacheSizeEntry{
 					ProjectID: projectID,
 					Artifact:  artifact,
-					CacheKey:  cacheKey,
-					Size:      size,
+					CacheKey:  keyDir.Name(),
+					Path:      filepath.Join(projectPath, artifact, keyDir.Name()),
 				})
 			}
 		}
@@ -911,23 +1460,44 @@ func (cm *CacheManager) GetCacheSizes() ([]CacheSizeEntry, error) {
 	return entries, nil
 }
 
-func (cm *CacheManager) calculateDirSize(path string) (int64, error) {
-	var size int64
-	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+func (cm *CacheManager) cacheEntryPath(entry CacheSizeEntry) string {
+	return filepath.Join(cm.LocalCacheDir, entry.ProjectID, entry.Artifact, entry.CacheKey)
+}
+
+func (cm *CacheManager) GetCacheUsage() ([]CacheSizeEntry, CacheUsage, error) {
+	listed, err := cm.ListCacheEntries()
+	if err != nil {
+		return nil, CacheUsage{}, err
+	}
+
+	var entries []CacheSizeEntry
+	tracker := newUsageTracker()
+	for _, entry := range listed {
+		size, disk, err := tracker.walk(cm.cacheEntryPath(entry))
 		if err != nil {
-			return err
+			continue
 		}
-		if d.IsDir() {
-			return nil
+		entry.Size, entry.DiskUsage = size, disk
+		entries = append(entries, entry)
+	}
+
+	if dirExists(cm.PackageStoreDir()) {
+		if _, _, err := tracker.walk(cm.PackageStoreDir()); err != nil {
+			return nil, CacheUsage{}, fmt.Errorf("failed to scan package store: %w", err)
 		}
-		info, err := d.Info()
-		if err != nil {
-			return err
+	}
+	if dirExists(cm.BlobStoreDir()) {
+		if _, _, err := tracker.walk(cm.BlobStoreDir()); err != nil {
+			return nil, CacheUsage{}, fmt.Errorf("failed to scan blob store: %w", err)
 		}
-		size += info.Size()
-		return nil
-	})
-	return size, err
+	}
+
+	usage := tracker.usage()
+	for _, e := range entries {
+		usage.Logical += e.Size
+	}
+
+	return entries, usage, nil
 }
 
 func (cm *CacheManager) RemoveCacheEntry(projectID, artifact, cacheKey string) error {
@@ -935,6 +1505,9 @@ func (cm *CacheManager) RemoveCacheEntry(projectID, artifact, cacheKey string) e
 	if err := os.RemoveAll(path); err != nil {
 		return fmt.Errorf("failed to remove cache entry: %w", err)
 	}
+	if err := removeManifest(path); err != nil {
+		return fmt.Errorf("failed to remove cache manifest: %w", err)
+	}
 
 	cm.cleanEmptyParentDirs(filepath.Join(cm.LocalCacheDir, projectID, artifact))
 	cm.cleanEmptyParentDirs(filepath.Join(cm.LocalCacheDir, projectID))
@@ -952,20 +1525,15 @@ func (cm *CacheManager) cleanEmptyParentDirs(path string) {
 	}
 }
 
-func (cm *CacheManager) RemoveAllCache() (int, int64, error) {
-	entries, err := cm.GetCacheSizes()
+func (cm *CacheManager) RemoveAllCache() (int, error) {
+	entries, err := cm.ListCacheEntries()
 	if err != nil {
-		return 0, 0, err
-	}
-
-	var totalSize int64
-	for _, entry := range entries {
-		totalSize += entry.Size
+		return 0, err
 	}
 
 	if err := os.RemoveAll(cm.LocalCacheDir); err != nil {
-		return 0, 0, fmt.Errorf("failed to remove cache directory: %w", err)
+		return 0, fmt.Errorf("failed to remove cache directory: %w", err)
 	}
 
-	return len(entries), totalSize, nil
+	return len(entries), nil
 }
@@ -111,7 +111,7 @@ func TestE2ECacheFlow(t *testing.T) {
 	}
 
 	t.Log("Destroying environment...")
-	cmd = exec.Command(monoBin, "destroy", ".")
+	cmd = exec.Command(monoBin, "destroy", ".", "--yes")
 	cmd.Dir = envPath
 	if out, err := cmd.CombinedOutput(); err != nil {
 		t.Logf("destroy output: %s", out)
@@ -150,7 +150,16 @@ func TestE2ECacheFlow(t *testing.T) {
 	cachedFiles, _ := filepath.Glob(filepath.Join(cachedTarget, "debug", "deps", "*.rlib"))
 	envFiles, _ := filepath.Glob(filepath.Join(targetDir, "debug", "deps", "*.rlib"))
 
-	if len(cachedFiles) > 0 && len(envFiles) > 0 {
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	strategy, err := cm.Strategy(targetDir)
+	if err != nil {
+		t.Fatalf("Strategy failed: %v", err)
+	}
+
+	if strategy.Link == LinkHardlink && len(cachedFiles) > 0 && len(envFiles) > 0 {
 		cachedInfo, _ := os.Stat(cachedFiles[0])
 		envInfo, _ := os.Stat(envFiles[0])
 
@@ -174,7 +183,7 @@ func TestE2ECacheFlow(t *testing.T) {
 	}
 
 	t.Log("Cleaning up...")
-	cmd = exec.Command(monoBin, "destroy", ".")
+	cmd = exec.Command(monoBin, "destroy", ".", "--yes")
 	cmd.Dir = envPath
 	cmd.Run()
 
@@ -233,7 +242,7 @@ func TestE2ESccacheDetection(t *testing.T) {
 		}
 	}
 
-	cmd = exec.Command(monoBin, "destroy", ".")
+	cmd = exec.Command(monoBin, "destroy", ".", "--yes")
 	cmd.Dir = envPath
 	cmd.Run()
 }
@@ -294,7 +303,7 @@ func TestE2ECacheKeyChangesWithLockfile(t *testing.T) {
 	key1 := cargoEntries1[0].Name()
 	t.Logf("First cache key: %s", key1)
 
-	cmd = exec.Command(monoBin, "destroy", ".")
+	cmd = exec.Command(monoBin, "destroy", ".", "--yes")
 	cmd.Dir = envPath
 	cmd.Run()
 	os.RemoveAll(filepath.Join(envPath, "target"))
@@ -333,7 +342,7 @@ func TestE2ECacheKeyChangesWithLockfile(t *testing.T) {
 		t.Logf("Second cache key: %s (different from first)", key2)
 	}
 
-	cmd = exec.Command(monoBin, "destroy", ".")
+	cmd = exec.Command(monoBin, "destroy", ".", "--yes")
 	cmd.Dir = envPath
 	cmd.Run()
 }
@@ -421,7 +430,7 @@ func TestE2ECacheStats(t *testing.T) {
 	}
 
 	t.Log("Destroying and recreating environment (cache hit)...")
-	cmd = exec.Command(monoBin, "destroy", ".")
+	cmd = exec.Command(monoBin, "destroy", ".", "--yes")
 	cmd.Dir = envPath
 	cmd.Run()
 
@@ -453,7 +462,7 @@ func TestE2ECacheStats(t *testing.T) {
 	}
 
 	t.Log("Testing mono cache clean --all...")
-	cmd = exec.Command(monoBin, "cache", "clean", "--all")
+	cmd = exec.Command(monoBin, "cache", "clean", "--all", "--yes")
 	out, err = cmd.CombinedOutput()
 	if err != nil {
 		t.Fatalf("mono cache clean --all failed: %v\n%s", err, out)
@@ -516,7 +525,7 @@ func findProjectRoot(t *testing.T) string {
 func cleanupTestEnvironment(t *testing.T, monoBin, envPath, testDir string) {
 	t.Helper()
 
-	cmd := exec.Command(monoBin, "destroy", ".")
+	cmd := exec.Command(monoBin, "destroy", ".", "--yes")
 	cmd.Dir = envPath
 	cmd.Run()
 
@@ -1,10 +1,16 @@
 package mono
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
@@ -44,7 +50,7 @@ func TestComputeCacheKey(t *testing.T) {
 		Name:        "cargo",
 		KeyFiles:    []string{"Cargo.lock"},
 		KeyCommands: []string{"echo v1.0"},
-		Paths:       []string{"target"},
+		Paths:       []ArtifactPath{{Path: "target"}},
 	}
 
 	key1, err := cm.ComputeCacheKey(artifact, testDir)
@@ -91,7 +97,7 @@ func TestComputeCacheKeyMissingKeyFile(t *testing.T) {
 		Name:        "cargo",
 		KeyFiles:    []string{"Cargo.lock"},
 		KeyCommands: []string{"echo v1.0"},
-		Paths:       []string{"target"},
+		Paths:       []ArtifactPath{{Path: "target"}},
 	}
 
 	key, err := cm.ComputeCacheKey(artifact, testDir)
@@ -104,7 +110,240 @@ func TestComputeCacheKeyMissingKeyFile(t *testing.T) {
 	}
 }
 
-func TestHardlinkTree(t *testing.T) {
+func TestComputeCacheKeyParallelInputs(t *testing.T) {
+	t.Setenv(cacheSaltEnv, "")
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+
+	testDir := t.TempDir()
+	artifact := ArtifactConfig{Name: "npm", Paths: []ArtifactPath{{Path: "node_modules"}}}
+	h := sha256.New()
+	for i := range 24 {
+		name := filepath.Join(fmt.Sprintf("pkg%02d", i), "package-lock.json")
+		if err := os.MkdirAll(filepath.Join(testDir, filepath.Dir(name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := fmt.Sprintf("lockfile %d", i)
+		if err := os.WriteFile(filepath.Join(testDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		artifact.KeyFiles = append(artifact.KeyFiles, name)
+		h.Write([]byte(content))
+	}
+	artifact.KeyFiles = append(artifact.KeyFiles, "missing.lock", "pkg03/package-lock.json")
+	h.Write([]byte("lockfile 3"))
+	for i := range 4 {
+		artifact.KeyCommands = append(artifact.KeyCommands, fmt.Sprintf("sleep 0.5; echo tool-%d", i))
+		fmt.Fprintf(h, "tool-%d\n", i)
+	}
+
+	start := time.Now()
+	key, err := cm.ComputeCacheKey(artifact, testDir)
+	if err != nil {
+		t.Fatalf("ComputeCacheKey failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 1500*time.Millisecond {
+		t.Errorf("expected key commands to run concurrently, took %v", elapsed)
+	}
+	if want := hex.EncodeToString(h.Sum(nil))[:16]; key != want {
+		t.Errorf("expected inputs to be hashed in their configured order, got %s want %s", key, want)
+	}
+
+	artifact.KeyCommands = append(artifact.KeyCommands, "exit 3")
+	if _, err := cm.ComputeCacheKey(artifact, testDir); err == nil || !strings.Contains(err.Error(), "exit 3") {
+		t.Errorf("expected a failing key command to fail the key, got %v", err)
+	}
+}
+
+func TestComputeCacheKeyMemoizesInputs(t *testing.T) {
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+
+	testDir := t.TempDir()
+	lockfile := filepath.Join(testDir, "package-lock.json")
+	if err := os.WriteFile(lockfile, []byte("lockfile v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	counter := filepath.Join(testDir, "runs")
+	command := fmt.Sprintf("echo run >> %q; echo v20", counter)
+
+	var keys []string
+	for _, name := range []string{"npm", "npm-web", "turbo"} {
+		key, err := cm.ComputeCacheKey(ArtifactConfig{Name: name, KeyFiles: []string{"package-lock.json"}, KeyCommands: []string{command}}, testDir)
+		if err != nil {
+			t.Fatalf("ComputeCacheKey failed: %v", err)
+		}
+		keys = append(keys, key)
+	}
+	if keys[0] != keys[1] || keys[1] != keys[2] {
+		t.Errorf("expected identical inputs to produce identical keys, got %v", keys)
+	}
+	runs, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(runs), "run"); n != 1 {
+		t.Errorf("expected the key command to run once per cache manager, ran %d times", n)
+	}
+
+	if err := os.WriteFile(lockfile, []byte("lockfile v2 with more"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := cm.ComputeCacheKey(ArtifactConfig{Name: "npm", KeyFiles: []string{"package-lock.json"}, KeyCommands: []string{command}}, testDir)
+	if err != nil {
+		t.Fatalf("ComputeCacheKey failed: %v", err)
+	}
+	if changed == keys[0] {
+		t.Error("expected a rewritten key file to be read again")
+	}
+
+	fresh, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+	if _, err := fresh.ComputeCacheKey(ArtifactConfig{Name: "npm", KeyCommands: []string{command}}, testDir); err != nil {
+		t.Fatalf("ComputeCacheKey failed: %v", err)
+	}
+	if runs, err = os.ReadFile(counter); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(runs), "run"); n != 2 {
+		t.Errorf("expected a new cache manager to run the key command again, ran %d times", n)
+	}
+}
+
+func TestComputeCacheKeySalt(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(cacheSaltEnv, "")
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+
+	testDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(testDir, "Cargo.lock"), []byte("lockfile"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	artifact := ArtifactConfig{Name: "cargo", KeyFiles: []string{"Cargo.lock"}}
+
+	unsalted, err := cm.ComputeCacheKey(artifact, testDir)
+	if err != nil {
+		t.Fatalf("ComputeCacheKey failed: %v", err)
+	}
+
+	artifact.KeySalt = "v2"
+	salted, err := cm.ComputeCacheKey(artifact, testDir)
+	if err != nil {
+		t.Fatalf("ComputeCacheKey failed: %v", err)
+	}
+	if salted == unsalted {
+		t.Error("expected key_salt to change the key")
+	}
+
+	t.Setenv(cacheSaltEnv, "ci-2")
+	envSalted, err := cm.ComputeCacheKey(artifact, testDir)
+	if err != nil {
+		t.Fatalf("ComputeCacheKey failed: %v", err)
+	}
+	if envSalted == salted || envSalted == unsalted {
+		t.Errorf("expected %s to change the key", cacheSaltEnv)
+	}
+	again, err := cm.ComputeCacheKey(artifact, testDir)
+	if err != nil {
+		t.Fatalf("ComputeCacheKey failed: %v", err)
+	}
+	if again != envSalted {
+		t.Errorf("expected salted keys to be stable, got %s and %s", envSalted, again)
+	}
+}
+
+func TestComputeCacheKeyStatStrategy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+
+	testDir := t.TempDir()
+	lockfile := filepath.Join(testDir, "Cargo.lock")
+	if err := os.WriteFile(lockfile, []byte("lockfile content A"), 0644); err != nil {
+		t.Fatalf("failed to write lockfile: %v", err)
+	}
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(lockfile, mtime, mtime); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+
+	artifact := ArtifactConfig{
+		Name:        "cargo",
+		KeyFiles:    []string{"Cargo.lock"},
+		Paths:       []ArtifactPath{{Path: "target"}},
+		KeyStrategy: KeyStrategyStat,
+	}
+
+	key1, err := cm.ComputeCacheKey(artifact, testDir)
+	if err != nil {
+		t.Fatalf("failed to compute cache key: %v", err)
+	}
+
+	f, err := os.OpenFile(lockfile, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("failed to open lockfile: %v", err)
+	}
+	if _, err := f.WriteAt([]byte("B"), int64(len("lockfile content "))); err != nil {
+		f.Close()
+		t.Fatalf("failed to rewrite lockfile: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("failed to close lockfile: %v", err)
+	}
+	if err := os.Chtimes(lockfile, mtime, mtime); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+
+	key2, err := cm.ComputeCacheKey(artifact, testDir)
+	if err != nil {
+		t.Fatalf("failed to compute cache key: %v", err)
+	}
+	if key1 != key2 {
+		t.Errorf("unchanged size and mtime should reuse the recorded hash: got %s and %s", key1, key2)
+	}
+
+	cfg := DefaultGlobalConfig()
+	cfg.Cache.KeyRevalidate = time.Nanosecond
+	SetGlobalConfig(cfg)
+
+	key3, err := cm.ComputeCacheKey(artifact, testDir)
+	if err != nil {
+		t.Fatalf("failed to compute cache key: %v", err)
+	}
+	if key3 == key1 {
+		t.Errorf("revalidation should rehash the changed lockfile: both got %s", key1)
+	}
+
+	if err := os.WriteFile(lockfile, []byte("lockfile content AB"), 0644); err != nil {
+		t.Fatalf("failed to write lockfile: %v", err)
+	}
+	SetGlobalConfig(nil)
+
+	key4, err := cm.ComputeCacheKey(artifact, testDir)
+	if err != nil {
+		t.Fatalf("failed to compute cache key: %v", err)
+	}
+	if key4 == key3 {
+		t.Errorf("changed size should rehash the lockfile: both got %s", key3)
+	}
+}
+
+func TestLinkTree(t *testing.T) {
 	src := t.TempDir()
 	dst := filepath.Join(t.TempDir(), "dst")
 
@@ -118,8 +357,8 @@ func TestHardlinkTree(t *testing.T) {
 		t.Fatalf("failed to write nested file: %v", err)
 	}
 
-	if err := HardlinkTree(src, dst); err != nil {
-		t.Fatalf("HardlinkTree failed: %v", err)
+	if err := LinkTree(src, dst, LinkHardlink); err != nil {
+		t.Fatalf("LinkTree failed: %v", err)
 	}
 
 	srcInfo, err := os.Stat(filepath.Join(src, "file.txt"))
@@ -144,7 +383,7 @@ func TestHardlinkTree(t *testing.T) {
 	}
 }
 
-func TestHardlinkTreeReplaceBreaksLink(t *testing.T) {
+func TestLinkTreeReplaceBreaksLink(t *testing.T) {
 	src := t.TempDir()
 	dst := filepath.Join(t.TempDir(), "dst")
 
@@ -153,8 +392,8 @@ func TestHardlinkTreeReplaceBreaksLink(t *testing.T) {
 		t.Fatalf("failed to write file: %v", err)
 	}
 
-	if err := HardlinkTree(src, dst); err != nil {
-		t.Fatalf("HardlinkTree failed: %v", err)
+	if err := LinkTree(src, dst, LinkHardlink); err != nil {
+		t.Fatalf("LinkTree failed: %v", err)
 	}
 
 	dstFile := filepath.Join(dst, "file.txt")
@@ -310,7 +549,7 @@ func TestDetectNestedArtifacts(t *testing.T) {
 	if len(a.KeyFiles) != 1 || a.KeyFiles[0] != "web/package-lock.json" {
 		t.Errorf("expected key_files ['web/package-lock.json'], got %v", a.KeyFiles)
 	}
-	if len(a.Paths) != 1 || a.Paths[0] != "web/node_modules" {
+	if len(a.Paths) != 1 || a.Paths[0].Path != "web/node_modules" {
 		t.Errorf("expected paths ['web/node_modules'], got %v", a.Paths)
 	}
 }
@@ -363,6 +602,43 @@ func TestDetectSkipsNodeModules(t *testing.T) {
 	}
 }
 
+func TestDetectRespectsIgnoreFiles(t *testing.T) {
+	testDir := t.TempDir()
+
+	files := map[string]string{
+		".gitignore":                         "/generated/\nexamples/*/\n!examples/keep/\n*.lock\n",
+		"Cargo.lock":                         "",
+		"generated/package-lock.json":        "",
+		"examples/demo/package-lock.json":    "",
+		"examples/keep/package-lock.json":    "",
+		"web/.monoignore":                    "fixtures\nlegacy/yarn.lock\n",
+		"web/package-lock.json":              "",
+		"web/fixtures/app/package-lock.json": "",
+		"web/legacy/yarn.lock":               "",
+		"web/legacy/pnpm-lock.yaml":          "",
+		"other/fixtures/package-lock.json":   "",
+	}
+	for name, content := range files {
+		path := filepath.Join(testDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var names []string
+	for _, a := range detectArtifacts(testDir) {
+		names = append(names, a.Name)
+	}
+	sort.Strings(names)
+	want := []string{"cargo", "npm-examples-keep", "npm-other-fixtures", "npm-web", "pnpm-web-legacy"}
+	if !slices.Equal(names, want) {
+		t.Errorf("expected %v, got %v", want, names)
+	}
+}
+
 func TestDetectDeeplyNestedArtifacts(t *testing.T) {
 	testDir := t.TempDir()
 
@@ -382,8 +658,8 @@ func TestDetectDeeplyNestedArtifacts(t *testing.T) {
 	if a.Name != "yarn-packages-frontend" {
 		t.Errorf("expected name 'yarn-packages-frontend', got %s", a.Name)
 	}
-	if a.Paths[0] != filepath.Join("packages", "frontend", "node_modules") {
-		t.Errorf("expected path 'packages/frontend/node_modules', got %s", a.Paths[0])
+	if a.Paths[0].Path != filepath.Join("packages", "frontend", "node_modules") {
+		t.Errorf("expected path 'packages/frontend/node_modules', got %s", a.Paths[0].Path)
 	}
 }
 
@@ -433,7 +709,7 @@ func TestPrepareArtifactCache(t *testing.T) {
 			Name:        "cargo",
 			KeyFiles:    []string{"Cargo.lock"},
 			KeyCommands: []string{"echo v1"},
-			Paths:       []string{"target"},
+			Paths:       []ArtifactPath{{Path: "target"}},
 		},
 	}
 
@@ -544,6 +820,11 @@ func TestIntegrationCacheHitMiss(t *testing.T) {
 }
 
 func TestSync(t *testing.T) {
+	cfg := DefaultGlobalConfig()
+	cfg.Cache.Link = LinkHardlink
+	SetGlobalConfig(cfg)
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
 	cm, err := NewCacheManager()
 	if err != nil {
 		t.Fatalf("failed to create cache manager: %v", err)
@@ -574,7 +855,7 @@ func TestSync(t *testing.T) {
 			Name:        "cargo",
 			KeyFiles:    []string{"Cargo.lock"},
 			KeyCommands: []string{"echo v1"},
-			Paths:       []string{"target"},
+			Paths:       []ArtifactPath{{Path: "target"}},
 		},
 	}
 
@@ -636,7 +917,7 @@ func TestSyncAlreadyCached(t *testing.T) {
 			Name:        "cargo",
 			KeyFiles:    []string{"Cargo.lock"},
 			KeyCommands: []string{"echo v1"},
-			Paths:       []string{"target"},
+			Paths:       []ArtifactPath{{Path: "target"}},
 		},
 	}
 
@@ -713,7 +994,7 @@ func TestSyncBuildInProgress(t *testing.T) {
 			Name:        "cargo",
 			KeyFiles:    []string{"Cargo.lock"},
 			KeyCommands: []string{"echo v1"},
-			Paths:       []string{"target"},
+			Paths:       []ArtifactPath{{Path: "target"}},
 		},
 	}
 
@@ -746,7 +1027,7 @@ func TestSyncNoArtifacts(t *testing.T) {
 			Name:        "cargo",
 			KeyFiles:    []string{"Cargo.lock"},
 			KeyCommands: []string{"echo v1"},
-			Paths:       []string{"target"},
+			Paths:       []ArtifactPath{{Path: "target"}},
 		},
 	}
 
@@ -756,6 +1037,39 @@ func TestSyncNoArtifacts(t *testing.T) {
 	}
 }
 
+func TestSyncDeadline(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+
+	envPath := t.TempDir()
+	artifacts := []ArtifactConfig{
+		{Name: "cargo", KeyFiles: []string{"Cargo.lock"}, Paths: []ArtifactPath{{Path: "target"}}},
+		{Name: "npm", KeyFiles: []string{"package-lock.json"}, Paths: []ArtifactPath{{Path: "node_modules"}}},
+	}
+
+	err = cm.Sync(artifacts, t.TempDir(), envPath, SyncOptions{Deadline: time.Now().Add(-time.Second)})
+	if !errors.Is(err, ErrSyncTimeout) || !strings.Contains(err.Error(), "skipped cargo, npm") {
+		t.Errorf("expected an expired deadline to skip every artifact, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(envPath, "mono.yml"), []byte("destroy:\n  sync: false\n  sync_timeout: 30s\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(envPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Destroy.syncEnabled() || cfg.Destroy.SyncTimeout != 30*time.Second {
+		t.Errorf("unexpected destroy config: %+v", cfg.Destroy)
+	}
+	if !(DestroyConfig{}).syncEnabled() {
+		t.Error("expected destroy to sync by default")
+	}
+}
+
 func TestSyncMissingLockfile(t *testing.T) {
 	cm, err := NewCacheManager()
 	if err != nil {
@@ -780,7 +1094,7 @@ func TestSyncMissingLockfile(t *testing.T) {
 			Name:        "cargo",
 			KeyFiles:    []string{"Cargo.lock"},
 			KeyCommands: []string{"echo v1"},
-			Paths:       []string{"target"},
+			Paths:       []ArtifactPath{{Path: "target"}},
 		},
 	}
 
@@ -821,9 +1135,29 @@ func TestIsBuildInProgress(t *testing.T) {
 	if cm.isBuildInProgress(testDir, npmArtifact) {
 		t.Error("npm should not detect cargo's build lock")
 	}
+
+	zigArtifact := ArtifactConfig{Name: "zig", Paths: []ArtifactPath{{Path: ".zig-cache"}}, BuildLockFile: "tmp/build.lock"}
+	if cm.isBuildInProgress(testDir, zigArtifact) {
+		t.Error("should not detect a build in progress without the build_lock_file")
+	}
+	zigLock := filepath.Join(testDir, ".zig-cache", "tmp", "build.lock")
+	if err := os.MkdirAll(filepath.Dir(zigLock), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(zigLock, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !cm.isBuildInProgress(testDir, zigArtifact) {
+		t.Error("should detect a build in progress with the build_lock_file")
+	}
 }
 
 func TestSeedFromRoot(t *testing.T) {
+	cfg := DefaultGlobalConfig()
+	cfg.Cache.Link = LinkHardlink
+	SetGlobalConfig(cfg)
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
 	cm, err := NewCacheManager()
 	if err != nil {
 		t.Fatalf("failed to create cache manager: %v", err)
@@ -861,7 +1195,7 @@ func TestSeedFromRoot(t *testing.T) {
 			Name:        "cargo",
 			KeyFiles:    []string{"Cargo.lock"},
 			KeyCommands: []string{"echo v1"},
-			Paths:       []string{"target"},
+			Paths:       []ArtifactPath{{Path: "target"}},
 		},
 	}
 
@@ -918,7 +1252,7 @@ func TestSeedSkipsSameDirectory(t *testing.T) {
 			Name:        "cargo",
 			KeyFiles:    []string{"Cargo.lock"},
 			KeyCommands: []string{"echo v1"},
-			Paths:       []string{"target"},
+			Paths:       []ArtifactPath{{Path: "target"}},
 		},
 	}
 
@@ -972,7 +1306,7 @@ func TestSeedSkipsDifferentLockfiles(t *testing.T) {
 			Name:        "cargo",
 			KeyFiles:    []string{"Cargo.lock"},
 			KeyCommands: []string{"echo v1"},
-			Paths:       []string{"target"},
+			Paths:       []ArtifactPath{{Path: "target"}},
 		},
 	}
 
@@ -1019,7 +1353,7 @@ func TestSeedSkipsNoRootArtifacts(t *testing.T) {
 			Name:        "cargo",
 			KeyFiles:    []string{"Cargo.lock"},
 			KeyCommands: []string{"echo v1"},
-			Paths:       []string{"target"},
+			Paths:       []ArtifactPath{{Path: "target"}},
 		},
 	}
 
@@ -1077,7 +1411,7 @@ func TestSeedSkipsBuildInProgress(t *testing.T) {
 			Name:        "cargo",
 			KeyFiles:    []string{"Cargo.lock"},
 			KeyCommands: []string{"echo v1"},
-			Paths:       []string{"target"},
+			Paths:       []ArtifactPath{{Path: "target"}},
 		},
 	}
 
@@ -1132,7 +1466,7 @@ func TestSeedAlreadyCached(t *testing.T) {
 			Name:        "cargo",
 			KeyFiles:    []string{"Cargo.lock"},
 			KeyCommands: []string{"echo v1"},
-			Paths:       []string{"target"},
+			Paths:       []ArtifactPath{{Path: "target"}},
 		},
 	}
 
@@ -1198,7 +1532,7 @@ func TestSeedThenRestore(t *testing.T) {
 			Name:        "cargo",
 			KeyFiles:    []string{"Cargo.lock"},
 			KeyCommands: []string{"echo v1"},
-			Paths:       []string{"target"},
+			Paths:       []ArtifactPath{{Path: "target"}},
 		},
 	}
 
@@ -1275,7 +1609,7 @@ func TestConcurrentSync(t *testing.T) {
 			Name:        "cargo",
 			KeyFiles:    []string{"Cargo.lock"},
 			KeyCommands: []string{"echo v1"},
-			Paths:       []string{"target"},
+			Paths:       []ArtifactPath{{Path: "target"}},
 		},
 	}
 
@@ -1475,7 +1809,7 @@ func TestCountFiles(t *testing.T) {
 		}
 	}
 
-	count, err := countFiles(testDir, "cargo")
+	count, err := countFiles(testDir, "cargo", pathFilter{})
 	if err != nil {
 		t.Fatalf("countFiles failed: %v", err)
 	}
@@ -1484,7 +1818,7 @@ func TestCountFiles(t *testing.T) {
 		t.Errorf("expected 2 files (rlib, rmeta), got %d", count)
 	}
 
-	countAll, err := countFiles(testDir, "")
+	countAll, err := countFiles(testDir, "", pathFilter{})
 	if err != nil {
 		t.Fatalf("countFiles failed: %v", err)
 	}
@@ -1564,3 +1898,406 @@ func BenchmarkTouchDepFiles(b *testing.B) {
 		})
 	}
 }
+
+func TestSharedArtifactAcrossProjects(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+
+	testDir := t.TempDir()
+	firstRoot := filepath.Join(testDir, "first")
+	secondRoot := filepath.Join(testDir, "second")
+
+	for _, dir := range []string{firstRoot, secondRoot} {
+		if err := os.MkdirAll(filepath.Join(dir, "store"), 0755); err != nil {
+			t.Fatalf("failed to create store dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "pnpm-lock.yaml"), []byte("same deps"), 0644); err != nil {
+			t.Fatalf("failed to write lockfile: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(firstRoot, "store", "pkg.txt"), []byte("pkg"), 0644); err != nil {
+		t.Fatalf("failed to write store file: %v", err)
+	}
+
+	artifact := ArtifactConfig{
+		Name:        "pnpm-store",
+		KeyFiles:    []string{"pnpm-lock.yaml"},
+		KeyCommands: []string{"echo v1"},
+		Paths:       []ArtifactPath{{Path: "store"}},
+		Shared:      true,
+	}
+
+	if err := cm.Sync([]ArtifactConfig{artifact}, firstRoot, firstRoot, SyncOptions{HardlinkBack: true}); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	entries, err := cm.PrepareArtifactCache([]ArtifactConfig{artifact}, secondRoot, secondRoot)
+	if err != nil {
+		t.Fatalf("PrepareArtifactCache failed: %v", err)
+	}
+
+	if !entries[0].Hit {
+		t.Error("expected shared artifact from another project to hit")
+	}
+	if entries[0].ProjectID != SharedProjectID {
+		t.Errorf("expected project id %q, got %q", SharedProjectID, entries[0].ProjectID)
+	}
+
+	artifact.Shared = false
+	entries, err = cm.PrepareArtifactCache([]ArtifactConfig{artifact}, secondRoot, secondRoot)
+	if err != nil {
+		t.Fatalf("PrepareArtifactCache failed: %v", err)
+	}
+	if entries[0].Hit {
+		t.Error("expected project-scoped artifact not to hit across projects")
+	}
+}
+
+func TestGetCacheUsageDedupsHardlinks(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	content := make([]byte, 64*1024)
+	first := filepath.Join(cm.LocalCacheDir, "proj", "node_modules", "key1", "node_modules")
+	if err := os.MkdirAll(first, 0755); err != nil {
+		t.Fatalf("failed to create entry: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(first, "big.js"), content, 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	second := filepath.Join(cm.LocalCacheDir, "proj", "node_modules", "key2", "node_modules")
+	if err := LinkTree(first, second, LinkHardlink); err != nil {
+		t.Fatalf("LinkTree failed: %v", err)
+	}
+
+	envDir := filepath.Join(t.TempDir(), "node_modules")
+	if err := LinkTree(first, envDir, LinkHardlink); err != nil {
+		t.Fatalf("LinkTree failed: %v", err)
+	}
+
+	entries, usage, err := cm.GetCacheUsage()
+	if err != nil {
+		t.Fatalf("GetCacheUsage failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	if usage.Logical != int64(2*len(content)) {
+		t.Errorf("expected logical size %d, got %d", 2*len(content), usage.Logical)
+	}
+	if usage.Disk >= usage.Logical {
+		t.Errorf("disk usage %d should be less than logical size %d", usage.Disk, usage.Logical)
+	}
+
+	var entryDisk int64
+	for _, e := range entries {
+		entryDisk += e.DiskUsage
+	}
+	if entryDisk != usage.Disk {
+		t.Errorf("per-entry disk usage %d should sum to total %d", entryDisk, usage.Disk)
+	}
+
+	if usage.SharedWithEnv != usage.Disk {
+		t.Errorf("expected all %d bytes shared with environment, got %d", usage.Disk, usage.SharedWithEnv)
+	}
+	if usage.Reclaimable() != 0 {
+		t.Errorf("expected nothing reclaimable, got %d", usage.Reclaimable())
+	}
+}
+
+func TestDiffCacheEntries(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	from := filepath.Join(cm.LocalCacheDir, "proj", "node_modules", "aaa111", "node_modules")
+	to := filepath.Join(cm.LocalCacheDir, "proj", "node_modules", "bbb222", "node_modules")
+	for _, dir := range []string{from, to} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+	}
+
+	files := map[string]string{
+		filepath.Join(from, "same.js"):    "same",
+		filepath.Join(to, "same.js"):      "same",
+		filepath.Join(from, "changed.js"): "old!",
+		filepath.Join(to, "changed.js"):   "new!",
+		filepath.Join(from, "removed.js"): "gone",
+		filepath.Join(to, "added.js"):     "brand new file",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	diff, err := cm.DiffCacheEntries("node_modules", "aaa", "bbb")
+	if err != nil {
+		t.Fatalf("DiffCacheEntries failed: %v", err)
+	}
+
+	if diff.From.CacheKey != "aaa111" || diff.To.CacheKey != "bbb222" {
+		t.Errorf("unexpected keys: %s -> %s", diff.From.CacheKey, diff.To.CacheKey)
+	}
+
+	got := make(map[string]string)
+	for _, c := range diff.Changes {
+		got[c.Path] = c.Kind
+	}
+	want := map[string]string{
+		"node_modules/changed.js": FileChanged,
+		"node_modules/removed.js": FileRemoved,
+		"node_modules/added.js":   FileAdded,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d changes, got %v", len(want), got)
+	}
+	for path, kind := range want {
+		if got[path] != kind {
+			t.Errorf("%s: expected %s, got %s", path, kind, got[path])
+		}
+	}
+
+	if diff.Changes[0].Path != "node_modules/added.js" {
+		t.Errorf("expected largest change first, got %s", diff.Changes[0].Path)
+	}
+	if diff.SizeDelta() != 10 {
+		t.Errorf("expected size delta 10, got %d", diff.SizeDelta())
+	}
+
+	if _, err := cm.DiffCacheEntries("node_modules", "zzz", "bbb"); err == nil {
+		t.Error("expected error for unknown key")
+	}
+}
+
+func TestCacheIndex(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("MONO_HOME", "")
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	db, err := OpenDB()
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	targetDir := filepath.Join(t.TempDir(), "target")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatalf("failed to create target dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "out"), []byte("0123456789"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	stored := ArtifactCacheEntry{
+		Name:      "cargo",
+		ProjectID: "proj",
+		Key:       "key1",
+		CachePath: filepath.Join(cm.LocalCacheDir, "proj", "cargo", "key1"),
+		EnvPaths:  []string{targetDir},
+	}
+	if err := cm.StoreToCache(stored); err != nil {
+		t.Fatalf("StoreToCache failed: %v", err)
+	}
+	if err := cm.RecordCacheSizes(db, []ArtifactCacheEntry{stored}); err != nil {
+		t.Fatalf("RecordCacheSizes failed: %v", err)
+	}
+
+	index, err := db.GetCacheEntryIndex()
+	if err != nil {
+		t.Fatalf("GetCacheEntryIndex failed: %v", err)
+	}
+	if got := index["proj/cargo/key1"]; got.Size != 10 || got.Path != stored.CachePath || got.CreatedAt.IsZero() {
+		t.Errorf("expected the entry to be indexed at store time, got %+v", index)
+	}
+
+	addEntry := func(key string) {
+		dir := filepath.Join(cm.LocalCacheDir, "proj", "node_modules", key, "node_modules")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create entry: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "a.js"), []byte("abc"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	addEntry("key2")
+
+	sizes, err := cm.GetCacheSizes(db)
+	if err != nil {
+		t.Fatalf("GetCacheSizes failed: %v", err)
+	}
+	got := make(map[string]int64)
+	for _, s := range sizes {
+		got[s.Artifact] = s.Size
+	}
+	if got["cargo"] != 10 || got["node_modules"] != 3 {
+		t.Errorf("expected the first read to reconcile entries from disk, got %v", got)
+	}
+
+	addEntry("key3")
+	if err := cm.RemoveCacheEntry("proj", "cargo", "key1"); err != nil {
+		t.Fatalf("RemoveCacheEntry failed: %v", err)
+	}
+	sizes, err = cm.GetCacheSizes(db)
+	if err != nil {
+		t.Fatalf("GetCacheSizes failed: %v", err)
+	}
+	if len(sizes) != 1 || sizes[0].CacheKey != "key2" {
+		t.Errorf("expected only the indexed key2 without a directory scan, got %+v", sizes)
+	}
+	index, err = db.GetCacheEntryIndex()
+	if err != nil {
+		t.Fatalf("GetCacheEntryIndex failed: %v", err)
+	}
+	if _, ok := index["proj/cargo/key1"]; ok {
+		t.Error("removed entry should be pruned from the index")
+	}
+
+	if _, _, err := cm.RecalculateCacheSizes(db); err != nil {
+		t.Fatalf("RecalculateCacheSizes failed: %v", err)
+	}
+	sizes, err = cm.GetCacheSizes(db)
+	if err != nil {
+		t.Fatalf("GetCacheSizes failed: %v", err)
+	}
+	if len(sizes) != 2 {
+		t.Errorf("expected --recalculate to index key3, got %+v", sizes)
+	}
+}
+
+func TestStoreLinksUnchangedFilesFromPreviousKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := DefaultGlobalConfig()
+	cfg.Cache.Link = LinkCopy
+	SetGlobalConfig(cfg)
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	store := func(key string, files map[string]string) ArtifactCacheEntry {
+		targetDir := filepath.Join(t.TempDir(), "target")
+		for name, content := range files {
+			path := filepath.Join(targetDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("failed to create dir: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+		}
+		entry := ArtifactCacheEntry{
+			Name:      "cargo",
+			Key:       key,
+			CachePath: filepath.Join(cm.LocalCacheDir, "proj", "cargo", key),
+			EnvPaths:  []string{targetDir},
+		}
+		if err := cm.StoreToCache(entry); err != nil {
+			t.Fatalf("StoreToCache failed: %v", err)
+		}
+		return entry
+	}
+
+	first := store("key1", map[string]string{
+		"debug/deps/libserde.rlib": "serde v1",
+		"debug/app":                "app v1",
+	})
+	second := store("key2", map[string]string{
+		"debug/deps/libserde.rlib": "serde v1",
+		"debug/app":                "app v2",
+	})
+
+	same, err := sameInode(
+		filepath.Join(first.CachePath, "target", "debug", "deps", "libserde.rlib"),
+		filepath.Join(second.CachePath, "target", "debug", "deps", "libserde.rlib"),
+	)
+	if err != nil {
+		t.Fatalf("sameInode failed: %v", err)
+	}
+	if !same {
+		t.Error("unchanged file should be hardlinked from the previous key")
+	}
+
+	same, err = sameInode(
+		filepath.Join(first.CachePath, "target", "debug", "app"),
+		filepath.Join(second.CachePath, "target", "debug", "app"),
+	)
+	if err != nil {
+		t.Fatalf("sameInode failed: %v", err)
+	}
+	if same {
+		t.Error("changed file must not be linked to the previous key")
+	}
+
+	results, err := cm.VerifyCache(false)
+	if err != nil {
+		t.Fatalf("VerifyCache failed: %v", err)
+	}
+	for _, r := range results {
+		if r.Status != VerifyOK {
+			t.Errorf("%s should verify, got %s %v", r.Entry.CacheKey, r.Status, r.Problems)
+		}
+	}
+}
+
+func TestMigrateCacheSizes(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	db, err := OpenDB()
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.conn.Exec(`CREATE TABLE cache_sizes (
+    project_id TEXT NOT NULL,
+    artifact TEXT NOT NULL,
+    cache_key TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    disk_usage INTEGER NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, artifact, cache_key)
+);
+INSERT INTO cache_sizes (project_id, artifact, cache_key, size, disk_usage) VALUES ('proj', 'cargo', 'key1', 10, 8);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	index, err := db.GetCacheEntryIndex()
+	if err != nil {
+		t.Fatalf("GetCacheEntryIndex failed: %v", err)
+	}
+	if got := index["proj/cargo/key1"]; got.Size != 10 || got.DiskUsage != 8 {
+		t.Errorf("expected the cache_sizes row to be migrated, got %+v", index)
+	}
+	var count int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'cache_sizes'`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Error("expected cache_sizes to be dropped")
+	}
+}
@@ -0,0 +1,99 @@
+package mono
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+type ProjectCacheUsage struct {
+	ProjectID string
+	Entries   int
+	Size      int64
+	DiskUsage int64
+}
+
+type CacheActivity struct {
+	Operations []OperationStatus
+	Recent     []CacheEvent
+	Projects   []ProjectCacheUsage
+}
+
+func ActiveOperations(db *DB) ([]OperationStatus, error) {
+	envs, err := db.ListEnvironments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environments: %w", err)
+	}
+	var names []string
+	for _, env := range envs {
+		names = append(names, EnvName(env.Path))
+	}
+
+	home, err := GetMonoHome()
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := os.ReadDir(filepath.Join(home, "data"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read data directory: %w", err)
+	}
+	for _, dir := range dirs {
+		if dir.IsDir() && !slices.Contains(names, dir.Name()) {
+			names = append(names, dir.Name())
+		}
+	}
+
+	var ops []OperationStatus
+	for _, name := range names {
+		op, err := QueryStatus(name)
+		if err != nil {
+			continue
+		}
+		ops = append(ops, *op)
+	}
+	slices.SortFunc(ops, func(a, b OperationStatus) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return ops, nil
+}
+
+func (cm *CacheManager) Activity(db *DB, recent int) (*CacheActivity, error) {
+	ops, err := ActiveOperations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := db.RecentCacheEvents(recent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache events: %w", err)
+	}
+
+	sizes, err := cm.GetCacheSizes(db)
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[string]*ProjectCacheUsage)
+	var projects []*ProjectCacheUsage
+	for _, entry := range sizes {
+		p, ok := usage[entry.ProjectID]
+		if !ok {
+			p = &ProjectCacheUsage{ProjectID: entry.ProjectID}
+			usage[entry.ProjectID] = p
+			projects = append(projects, p)
+		}
+		p.Entries++
+		p.Size += entry.Size
+		p.DiskUsage += entry.DiskUsage
+	}
+	slices.SortFunc(projects, func(a, b *ProjectCacheUsage) int {
+		return cmp.Or(cmp.Compare(b.DiskUsage, a.DiskUsage), cmp.Compare(a.ProjectID, b.ProjectID))
+	})
+
+	activity := &CacheActivity{Operations: ops, Recent: events}
+	for _, p := range projects {
+		activity.Projects = append(activity.Projects, *p)
+	}
+	return activity, nil
+}
@@ -0,0 +1,66 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCacheActivity(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("MONO_HOME", "")
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	db, err := OpenDB()
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	envPath := filepath.Join(t.TempDir(), "feature")
+	if _, err := db.InsertEnvironment(envPath, "", "", ""); err != nil {
+		t.Fatalf("InsertEnvironment failed: %v", err)
+	}
+	status, err := StartStatusServer(EnvName(envPath), "init")
+	if err != nil {
+		t.Fatalf("StartStatusServer failed: %v", err)
+	}
+	defer status.Close()
+	status.SetPhase("restoring cargo")
+
+	for _, entry := range []CacheSizeEntry{
+		{ProjectID: "small", Artifact: "npm", CacheKey: "k1", Size: 1, DiskUsage: 1},
+		{ProjectID: "big", Artifact: "cargo", CacheKey: "k1", Size: 10, DiskUsage: 8},
+		{ProjectID: "big", Artifact: "cargo", CacheKey: "k2", Size: 10, DiskUsage: 4},
+	} {
+		if err := os.MkdirAll(filepath.Join(cm.LocalCacheDir, entry.ProjectID, entry.Artifact, entry.CacheKey), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.RecordCacheEntry(entry); err != nil {
+			t.Fatalf("RecordCacheEntry failed: %v", err)
+		}
+	}
+	for _, event := range []string{"miss", "hit", "hit"} {
+		if err := db.RecordCacheEvent(event, "big", "cargo", "k1"); err != nil {
+			t.Fatalf("RecordCacheEvent failed: %v", err)
+		}
+	}
+
+	activity, err := cm.Activity(db, 2)
+	if err != nil {
+		t.Fatalf("Activity failed: %v", err)
+	}
+
+	if len(activity.Operations) != 1 || activity.Operations[0].Operation != "init" || activity.Operations[0].Phase != "restoring cargo" {
+		t.Errorf("unexpected operations: %+v", activity.Operations)
+	}
+	if len(activity.Recent) != 2 || activity.Recent[0].Event != "hit" || activity.Recent[0].Time.IsZero() {
+		t.Errorf("expected the two most recent events, got %+v", activity.Recent)
+	}
+	if len(activity.Projects) != 2 || activity.Projects[0].ProjectID != "big" || activity.Projects[0].Entries != 2 || activity.Projects[0].DiskUsage != 12 {
+		t.Errorf("unexpected project usage: %+v", activity.Projects)
+	}
+}
@@ -0,0 +1,167 @@
+package mono
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const CacheEventEvict = "evict"
+
+type CacheEviction struct {
+	Entry  CacheSizeEntry
+	Reason string
+}
+
+type ArtifactCleanResult struct {
+	ProjectID string
+	Artifact  string
+	Entries   int
+	Size      int64
+	Evictions []CacheEviction
+}
+
+func (cm *CacheManager) EvictCacheEntries(db *DB, evictions []CacheEviction) ([]CacheEviction, error) {
+	var evicted []CacheEviction
+	for _, eviction := range evictions {
+		removed, err := cm.evictCacheEntry(db, eviction)
+		if err != nil {
+			return evicted, err
+		}
+		if removed {
+			evicted = append(evicted, eviction)
+		}
+	}
+	return evicted, nil
+}
+
+func (cm *CacheManager) evictCacheEntry(db *DB, eviction CacheEviction) (bool, error) {
+	e := eviction.Entry
+	cachePath := cm.cacheEntryPath(e)
+	lock, err := cm.acquireCacheLock(cachePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to lock %s/%s: %w", e.ProjectID, e.Artifact, err)
+	}
+	if lock == nil {
+		return false, nil
+	}
+	defer cm.releaseCacheLock(lock)
+
+	if err := cm.RemoveCacheEntry(e.ProjectID, e.Artifact, e.CacheKey); err != nil {
+		return false, fmt.Errorf("failed to remove %s/%s: %w", e.ProjectID, e.Artifact, err)
+	}
+	if err := db.DeleteCacheEvents(e.ProjectID, e.Artifact, e.CacheKey); err != nil {
+		return false, fmt.Errorf("failed to delete cache events: %w", err)
+	}
+	if err := db.DeleteCacheEntry(e.ProjectID, e.Artifact, e.CacheKey); err != nil {
+		return false, fmt.Errorf("failed to delete cache index entry: %w", err)
+	}
+	if err := db.RecordCacheEviction(eviction); err != nil {
+		return false, fmt.Errorf("failed to record eviction of %s/%s: %w", e.ProjectID, e.Artifact, err)
+	}
+	return true, nil
+}
+
+func RecordCacheEvictions(db *DB, evictions []CacheEviction) error {
+	for _, eviction := range evictions {
+		if err := db.RecordCacheEviction(eviction); err != nil {
+			return fmt.Errorf("failed to record eviction of %s/%s: %w", eviction.Entry.ProjectID, eviction.Entry.Artifact, err)
+		}
+	}
+	return nil
+}
+
+func ResolveCacheProject(db *DB, name string, candidates []string) (string, error) {
+	if name == "shared" || name == SharedProjectID {
+		return SharedProjectID, nil
+	}
+
+	rootPaths, err := db.GetAllRootPaths()
+	if err != nil {
+		return "", fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	var matches []string
+	add := func(projectID string) {
+		if !slices.Contains(matches, projectID) {
+			matches = append(matches, projectID)
+		}
+	}
+	for _, root := range rootPaths {
+		projectID := ComputeProjectID(root)
+		short := filepath.Join(filepath.Base(filepath.Dir(root)), filepath.Base(root))
+		if name == projectID || name == root || name == filepath.Base(root) || name == short {
+			add(projectID)
+		}
+	}
+	if len(matches) == 0 {
+		for _, projectID := range candidates {
+			if strings.HasPrefix(projectID, name) {
+				add(projectID)
+			}
+		}
+	}
+
+	if len(matches) > 1 {
+		var narrowed []string
+		for _, projectID := range matches {
+			if slices.Contains(candidates, projectID) {
+				narrowed = append(narrowed, projectID)
+			}
+		}
+		if len(narrowed) > 0 {
+			matches = narrowed
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no project matches %q", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("project %q is ambiguous, matches %s", name, strings.Join(matches, ", "))
+	}
+}
+
+func (cm *CacheManager) CleanArtifact(db *DB, projectID, artifact string, dryRun bool) (*ArtifactCleanResult, error) {
+	sizes, err := cm.GetCacheSizes(db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ArtifactCleanResult{ProjectID: projectID, Artifact: artifact}
+	for _, entry := range sizes {
+		if entry.ProjectID == projectID && entry.Artifact == artifact {
+			result.Entries++
+			result.Size += entry.Size
+			result.Evictions = append(result.Evictions, CacheEviction{Entry: entry, Reason: "cache clean --artifact " + artifact})
+		}
+	}
+
+	artifactDir := filepath.Join(cm.LocalCacheDir, projectID, artifact)
+	if !dirExists(artifactDir) {
+		return nil, fmt.Errorf("no cache entries for artifact %s in project %s", artifact, projectID)
+	}
+	if dryRun {
+		return result, nil
+	}
+	if err := os.RemoveAll(artifactDir); err != nil {
+		return nil, fmt.Errorf("failed to remove %s: %w", artifactDir, err)
+	}
+	cm.cleanEmptyParentDirs(filepath.Join(cm.LocalCacheDir, projectID))
+
+	if err := db.DeleteArtifactCacheEvents(projectID, artifact); err != nil {
+		return nil, fmt.Errorf("failed to delete cache events: %w", err)
+	}
+	if err := db.DeleteArtifactCacheEntries(projectID, artifact); err != nil {
+		return nil, fmt.Errorf("failed to delete cache index entries: %w", err)
+	}
+	if err := RecordCacheEvictions(db, result.Evictions); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,132 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanArtifact(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("MONO_HOME", "")
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	db, err := OpenDB()
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	root := filepath.Join(t.TempDir(), "work", "foo")
+	if _, err := db.InsertEnvironment(filepath.Join(root, "env"), "", root, ""); err != nil {
+		t.Fatalf("InsertEnvironment failed: %v", err)
+	}
+	projectID := ComputeProjectID(root)
+
+	for _, entry := range []CacheSizeEntry{
+		{ProjectID: projectID, Artifact: "npm-web", CacheKey: "key1", Size: 3},
+		{ProjectID: projectID, Artifact: "npm-web", CacheKey: "key2", Size: 4},
+		{ProjectID: projectID, Artifact: "cargo", CacheKey: "key1", Size: 5},
+	} {
+		dir := filepath.Join(cm.LocalCacheDir, entry.ProjectID, entry.Artifact, entry.CacheKey)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.RecordCacheEntry(entry); err != nil {
+			t.Fatalf("RecordCacheEntry failed: %v", err)
+		}
+		if err := db.RecordCacheEvent("hit", entry.ProjectID, entry.Artifact, entry.CacheKey); err != nil {
+			t.Fatalf("RecordCacheEvent failed: %v", err)
+		}
+	}
+
+	for _, name := range []string{"foo", "work/foo", root, projectID} {
+		got, err := ResolveCacheProject(db, name, nil)
+		if err != nil || got != projectID {
+			t.Errorf("ResolveCacheProject(%q) = %q, %v; want %q", name, got, err, projectID)
+		}
+	}
+	if got, err := ResolveCacheProject(db, projectID[:6], []string{projectID}); err != nil || got != projectID {
+		t.Errorf("expected project ID prefix to resolve, got %q, %v", got, err)
+	}
+	if _, err := ResolveCacheProject(db, "bar", nil); err == nil {
+		t.Error("expected unknown project to fail")
+	}
+
+	planned, err := cm.CleanArtifact(db, projectID, "npm-web", true)
+	if err != nil {
+		t.Fatalf("CleanArtifact dry run failed: %v", err)
+	}
+	if planned.Entries != 2 || planned.Size != 7 || len(planned.Evictions) != 2 || planned.Evictions[0].Reason != "cache clean --artifact npm-web" {
+		t.Errorf("unexpected dry run result %+v", planned)
+	}
+	if !dirExists(filepath.Join(cm.LocalCacheDir, projectID, "npm-web")) {
+		t.Fatal("dry run should not remove the artifact directory")
+	}
+
+	result, err := cm.CleanArtifact(db, projectID, "npm-web", false)
+	if err != nil {
+		t.Fatalf("CleanArtifact failed: %v", err)
+	}
+	if result.Entries != 2 || result.Size != 7 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	if dirExists(filepath.Join(cm.LocalCacheDir, projectID, "npm-web")) {
+		t.Error("artifact directory should be removed")
+	}
+	if !dirExists(filepath.Join(cm.LocalCacheDir, projectID, "cargo", "key1")) {
+		t.Error("other artifacts should be kept")
+	}
+
+	stats, err := db.GetCacheStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range stats {
+		if s.Artifact == "npm-web" {
+			t.Errorf("cache events for npm-web should be deleted: %+v", s)
+		}
+	}
+	if len(stats) != 1 {
+		t.Errorf("expected cargo events to be kept, got %+v", stats)
+	}
+	index, err := db.GetCacheEntryIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index) != 1 {
+		t.Errorf("expected only the cargo size to remain, got %+v", index)
+	}
+
+	events, err := db.RecentCacheEvents(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var evicted int64
+	for _, e := range events {
+		if e.Event == CacheEventEvict && e.Artifact == "npm-web" && e.Reason == "cache clean --artifact npm-web" {
+			evicted += e.Size
+		}
+	}
+	if evicted != 7 {
+		t.Errorf("expected evict events totalling 7 bytes, got %d in %+v", evicted, events)
+	}
+
+	if err := db.DeleteAllCacheEvents(); err != nil {
+		t.Fatal(err)
+	}
+	if stats, err = db.GetCacheStats(); err != nil || len(stats) != 0 {
+		t.Errorf("expected hit statistics to be cleared, got %+v, %v", stats, err)
+	}
+	if events, err = db.RecentCacheEvents(10); err != nil || len(events) != 2 {
+		t.Errorf("expected the eviction audit to survive clearing statistics, got %+v, %v", events, err)
+	}
+
+	if _, err := cm.CleanArtifact(db, projectID, "npm-web", false); err == nil {
+		t.Error("expected cleaning a missing artifact to fail")
+	}
+}
@@ -0,0 +1,227 @@
+package mono
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	FileAdded   = "added"
+	FileRemoved = "removed"
+	FileChanged = "changed"
+)
+
+type FileChange struct {
+	Path    string
+	Kind    string
+	OldSize int64
+	NewSize int64
+}
+
+func (c FileChange) Delta() int64 {
+	return c.NewSize - c.OldSize
+}
+
+type CacheDiff struct {
+	From    CacheSizeEntry
+	To      CacheSizeEntry
+	Changes []FileChange
+}
+
+func (d *CacheDiff) Count(kind string) int {
+	n := 0
+	for _, c := range d.Changes {
+		if c.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+func (d *CacheDiff) SizeDelta() int64 {
+	return d.To.Size - d.From.Size
+}
+
+type diffFile struct {
+	path string
+	info fs.FileInfo
+}
+
+func (cm *CacheManager) ResolveCacheEntry(artifact, key string) (CacheSizeEntry, string, error) {
+	projectDirs, err := os.ReadDir(cm.LocalCacheDir)
+	if os.IsNotExist(err) {
+		return CacheSizeEntry{}, "", fmt.Errorf("no cache entry %s/%s", artifact, key)
+	}
+	if err != nil {
+		return CacheSizeEntry{}, "", fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	var matches []CacheSizeEntry
+	for _, projectDir := range projectDirs {
+		if !projectDir.IsDir() {
+			continue
+		}
+		artifactPath := filepath.Join(cm.LocalCacheDir, projectDir.Name(), artifact)
+		keyDirs, err := os.ReadDir(artifactPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return CacheSizeEntry{}, "", fmt.Errorf("failed to read %s: %w", artifactPath, err)
+		}
+		for _, keyDir := range keyDirs {
+			name := keyDir.Name()
+			if !keyDir.IsDir() || strings.HasSuffix(name, cacheTmpSuffix) || !strings.HasPrefix(name, key) {
+				continue
+			}
+			matches = append(matches, CacheSizeEntry{ProjectID: projectDir.Name(), Artifact: artifact, CacheKey: name})
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return CacheSizeEntry{}, "", fmt.Errorf("no cache entry %s/%s", artifact, key)
+	case 1:
+	default:
+		return CacheSizeEntry{}, "", fmt.Errorf("cache key %q is ambiguous for %s (%d matches)", key, artifact, len(matches))
+	}
+
+	entry := matches[0]
+	return entry, filepath.Join(cm.LocalCacheDir, entry.ProjectID, entry.Artifact, entry.CacheKey), nil
+}
+
+func (cm *CacheManager) DiffCacheEntries(artifact, fromKey, toKey string) (*CacheDiff, error) {
+	from, fromPath, err := cm.ResolveCacheEntry(artifact, fromKey)
+	if err != nil {
+		return nil, err
+	}
+	to, toPath, err := cm.ResolveCacheEntry(artifact, toKey)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := DiffDirectories(fromPath, toPath)
+	if err != nil {
+		return nil, err
+	}
+	from.Size, to.Size = diff.From.Size, diff.To.Size
+	diff.From, diff.To = from, to
+	return diff, nil
+}
+
+func DiffDirectories(fromPath, toPath string) (*CacheDiff, error) {
+	fromFiles, fromSize, err := listDiffFiles(fromPath)
+	if err != nil {
+		return nil, err
+	}
+	toFiles, toSize, err := listDiffFiles(toPath)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &CacheDiff{
+		From: CacheSizeEntry{Size: fromSize},
+		To:   CacheSizeEntry{Size: toSize},
+	}
+
+	for rel, old := range fromFiles {
+		cur, ok := toFiles[rel]
+		if !ok {
+			diff.Changes = append(diff.Changes, FileChange{Path: rel, Kind: FileRemoved, OldSize: old.info.Size()})
+			continue
+		}
+		changed, err := filesDiffer(old, cur)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			diff.Changes = append(diff.Changes, FileChange{Path: rel, Kind: FileChanged, OldSize: old.info.Size(), NewSize: cur.info.Size()})
+		}
+	}
+	for rel, cur := range toFiles {
+		if _, ok := fromFiles[rel]; !ok {
+			diff.Changes = append(diff.Changes, FileChange{Path: rel, Kind: FileAdded, NewSize: cur.info.Size()})
+		}
+	}
+
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		di, dj := abs64(diff.Changes[i].Delta()), abs64(diff.Changes[j].Delta())
+		if di != dj {
+			return di > dj
+		}
+		return diff.Changes[i].Path < diff.Changes[j].Path
+	})
+
+	return diff, nil
+}
+
+func listDiffFiles(root string) (map[string]diffFile, int64, error) {
+	files := make(map[string]diffFile)
+	var total int64
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files[rel] = diffFile{path: p, info: info}
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+	return files, total, nil
+}
+
+func filesDiffer(a, b diffFile) (bool, error) {
+	if a.info.Mode().Type() != b.info.Mode().Type() {
+		return true, nil
+	}
+	if a.info.Mode()&os.ModeSymlink != 0 {
+		at, err := os.Readlink(a.path)
+		if err != nil {
+			return false, err
+		}
+		bt, err := os.Readlink(b.path)
+		if err != nil {
+			return false, err
+		}
+		return at != bt, nil
+	}
+	if a.info.Size() != b.info.Size() {
+		return true, nil
+	}
+	if os.SameFile(a.info, b.info) {
+		return false, nil
+	}
+	ah, err := fileSHA256(a.path)
+	if err != nil {
+		return false, err
+	}
+	bh, err := fileSHA256(b.path)
+	if err != nil {
+		return false, err
+	}
+	return ah != bh, nil
+}
+
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
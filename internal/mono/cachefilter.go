@@ -0,0 +1,54 @@
+package mono
+
+import (
+	"fmt"
+	"path"
+	"time"
+)
+
+type CacheFilter struct {
+	ProjectID string
+	Artifact  string
+	OlderThan time.Duration
+	MinSize   int64
+}
+
+func (f CacheFilter) Validate() error {
+	if _, err := path.Match(f.Artifact, ""); err != nil {
+		return fmt.Errorf("invalid artifact pattern %q: %w", f.Artifact, err)
+	}
+	return nil
+}
+
+func (f CacheFilter) Apply(sizes []CacheSizeEntry, stats []CacheEntry, now time.Time) []CacheSizeEntry {
+	lastUsed := make(map[string]time.Time, len(stats))
+	for _, s := range stats {
+		lastUsed[s.ProjectID+"/"+s.Artifact+"/"+s.CacheKey] = s.LastUsed
+	}
+
+	var matched []CacheSizeEntry
+	for _, entry := range sizes {
+		if f.ProjectID != "" && entry.ProjectID != f.ProjectID {
+			continue
+		}
+		if f.Artifact != "" {
+			if ok, _ := path.Match(f.Artifact, entry.Artifact); !ok {
+				continue
+			}
+		}
+		if f.MinSize > 0 && entry.Size < f.MinSize {
+			continue
+		}
+		if f.OlderThan > 0 {
+			used, ok := lastUsed[entry.ProjectID+"/"+entry.Artifact+"/"+entry.CacheKey]
+			if !ok {
+				used = entry.CreatedAt
+			}
+			if now.Sub(used) <= f.OlderThan {
+				continue
+			}
+		}
+		matched = append(matched, entry)
+	}
+	return matched
+}
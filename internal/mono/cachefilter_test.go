@@ -0,0 +1,60 @@
+package mono
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheFilter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	sizes := []CacheSizeEntry{
+		{ProjectID: "p1", Artifact: "npm", CacheKey: "old", Size: 100, CreatedAt: now.Add(-60 * 24 * time.Hour)},
+		{ProjectID: "p1", Artifact: "npm-web", CacheKey: "used", Size: 200, CreatedAt: now.Add(-60 * 24 * time.Hour)},
+		{ProjectID: "p1", Artifact: "cargo", CacheKey: "big", Size: 5000, CreatedAt: now.Add(-40 * 24 * time.Hour)},
+		{ProjectID: "p2", Artifact: "npm", CacheKey: "new", Size: 50, CreatedAt: now.Add(-time.Hour)},
+	}
+	stats := []CacheEntry{
+		{ProjectID: "p1", Artifact: "npm-web", CacheKey: "used", LastUsed: now.Add(-2 * 24 * time.Hour)},
+	}
+
+	keys := func(f CacheFilter) []string {
+		t.Helper()
+		if err := f.Validate(); err != nil {
+			t.Fatalf("Validate(%+v) failed: %v", f, err)
+		}
+		var got []string
+		for _, entry := range f.Apply(sizes, stats, now) {
+			got = append(got, entry.ProjectID+"/"+entry.Artifact+"/"+entry.CacheKey)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name   string
+		filter CacheFilter
+		want   []string
+	}{
+		{"no filter", CacheFilter{}, []string{"p1/npm/old", "p1/npm-web/used", "p1/cargo/big", "p2/npm/new"}},
+		{"exact artifact", CacheFilter{Artifact: "npm"}, []string{"p1/npm/old", "p2/npm/new"}},
+		{"artifact glob older than", CacheFilter{Artifact: "npm*", OlderThan: 30 * 24 * time.Hour}, []string{"p1/npm/old"}},
+		{"project", CacheFilter{ProjectID: "p2"}, []string{"p2/npm/new"}},
+		{"min size", CacheFilter{MinSize: 200}, []string{"p1/npm-web/used", "p1/cargo/big"}},
+	}
+	for _, tt := range tests {
+		got := keys(tt.filter)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+
+	if err := (CacheFilter{Artifact: "npm["}).Validate(); err == nil {
+		t.Error("expected a malformed artifact pattern to fail")
+	}
+}
@@ -0,0 +1,167 @@
+package mono
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CacheGCOptions struct {
+	OlderThan time.Duration
+	MaxSize   int64
+	DryRun    bool
+	Keep      []ArtifactCacheEntry
+	Reason    string
+}
+
+type CacheGCResult struct {
+	Evictions []CacheEviction
+	Freed     int64
+	Kept      int
+	KeptSize  int64
+}
+
+type gcCandidate struct {
+	entry    CacheSizeEntry
+	lastUsed time.Time
+	size     int64
+}
+
+func ParseAge(s string) (time.Duration, error) {
+	value := strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(value, suffix); ok {
+			days, err := strconv.ParseFloat(n, 64)
+			if err != nil || days <= 0 {
+				return 0, fmt.Errorf("invalid age %q (use a duration such as 14d, 2w or 36h)", s)
+			}
+			return time.Duration(days * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid age %q (use a duration such as 14d, 2w or 36h)", s)
+	}
+	return d, nil
+}
+
+func (cm *CacheManager) GC(db *DB, opts CacheGCOptions) (*CacheGCResult, error) {
+	sizes, err := cm.GetCacheSizes(db)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := db.GetCacheStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache events: %w", err)
+	}
+	lastUsed := make(map[string]time.Time, len(stats))
+	for _, s := range stats {
+		lastUsed[s.ProjectID+"/"+s.Artifact+"/"+s.CacheKey] = s.LastUsed
+	}
+
+	candidates := make([]gcCandidate, 0, len(sizes))
+	for _, entry := range sizes {
+		used, ok := lastUsed[entry.ProjectID+"/"+entry.Artifact+"/"+entry.CacheKey]
+		if !ok {
+			used = entry.CreatedAt
+		}
+		candidates = append(candidates, gcCandidate{entry: entry, lastUsed: used, size: cmp.Or(entry.DiskUsage, entry.Size)})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].lastUsed.Before(candidates[j].lastUsed) })
+
+	protected := make(map[string]bool, len(opts.Keep))
+	for _, e := range opts.Keep {
+		protected[e.ProjectID+"/"+e.Name+"/"+e.Key] = true
+	}
+	reason := cmp.Or(opts.Reason, "gc")
+
+	result := &CacheGCResult{}
+	now := time.Now()
+	var kept []gcCandidate
+	for _, c := range candidates {
+		if opts.OlderThan > 0 && now.Sub(c.lastUsed) > opts.OlderThan && !protected[c.key()] {
+			result.evict(c, reason+": not used since "+c.lastUsed.UTC().Format(time.DateTime))
+			continue
+		}
+		kept = append(kept, c)
+		result.KeptSize += c.size
+	}
+
+	remaining := kept[:0:0]
+	for _, c := range kept {
+		if opts.MaxSize <= 0 || result.KeptSize <= opts.MaxSize || protected[c.key()] {
+			remaining = append(remaining, c)
+			continue
+		}
+		result.KeptSize -= c.size
+		result.evict(c, reason+": least recently used over the size budget, last used "+c.lastUsed.UTC().Format(time.DateTime))
+	}
+	result.Kept = len(remaining)
+
+	if opts.DryRun {
+		return result, nil
+	}
+	var evicted []CacheEviction
+	for _, eviction := range result.Evictions {
+		removed, err := cm.evictCacheEntry(db, eviction)
+		if err != nil {
+			return nil, err
+		}
+		if removed {
+			evicted = append(evicted, eviction)
+			continue
+		}
+		size := cmp.Or(eviction.Entry.DiskUsage, eviction.Entry.Size)
+		result.Freed -= size
+		result.Kept++
+		result.KeptSize += size
+	}
+	result.Evictions = evicted
+	return result, nil
+}
+
+func (c gcCandidate) key() string {
+	return c.entry.ProjectID + "/" + c.entry.Artifact + "/" + c.entry.CacheKey
+}
+
+func (r *CacheGCResult) evict(c gcCandidate, reason string) {
+	r.Evictions = append(r.Evictions, CacheEviction{Entry: c.entry, Reason: reason})
+	r.Freed += c.size
+}
+
+func (cm *CacheManager) EnforceCacheLimit(db *DB, keep []ArtifactCacheEntry) (*CacheGCResult, error) {
+	limit, err := globalConfig().Cache.maxSizeBytes()
+	if err != nil || limit <= 0 {
+		return nil, err
+	}
+	result, err := cm.GC(db, CacheGCOptions{MaxSize: limit, Keep: keep, Reason: "cache.max_size"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enforce cache.max_size: %w", err)
+	}
+	if len(result.Evictions) == 0 {
+		return result, nil
+	}
+	if _, err := cm.PrunePackageStore(false); err != nil {
+		return result, fmt.Errorf("failed to prune package store: %w", err)
+	}
+	if _, err := cm.PruneBlobStore(false); err != nil {
+		return result, fmt.Errorf("failed to prune blob store: %w", err)
+	}
+	return result, nil
+}
+
+func (cm *CacheManager) enforceCacheLimit(db *DB, keep []ArtifactCacheEntry, logger *FileLogger) {
+	result, err := cm.EnforceCacheLimit(db, keep)
+	if err != nil {
+		logger.Log("warning: %v", err)
+	}
+	if result == nil {
+		return
+	}
+	for _, e := range result.Evictions {
+		logger.Log("evicted %s/%s/%s from cache (%s)", e.Entry.ProjectID, e.Entry.Artifact, e.Entry.CacheKey, e.Reason)
+	}
+}
@@ -0,0 +1,226 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheGC(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	db, err := OpenDB()
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	for _, entry := range []CacheSizeEntry{
+		{ProjectID: "proj", Artifact: "cargo", CacheKey: "old", Size: 100},
+		{ProjectID: "proj", Artifact: "cargo", CacheKey: "mid", Size: 40},
+		{ProjectID: "proj", Artifact: "npm", CacheKey: "recent", Size: 30},
+		{ProjectID: "proj", Artifact: "npm", CacheKey: "unused", Size: 20},
+	} {
+		if err := os.MkdirAll(filepath.Join(cm.LocalCacheDir, entry.ProjectID, entry.Artifact, entry.CacheKey), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.RecordCacheEntry(entry); err != nil {
+			t.Fatalf("RecordCacheEntry failed: %v", err)
+		}
+		if entry.CacheKey == "unused" {
+			continue
+		}
+		if err := db.RecordCacheEvent("hit", entry.ProjectID, entry.Artifact, entry.CacheKey); err != nil {
+			t.Fatalf("RecordCacheEvent failed: %v", err)
+		}
+	}
+	for key, age := range map[string]string{"old": "-30 days", "mid": "-5 days"} {
+		if _, err := db.conn.Exec(`UPDATE cache_events SET timestamp = datetime('now', ?) WHERE cache_key = ?`, age, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	planned, err := cm.GC(db, CacheGCOptions{OlderThan: 14 * 24 * time.Hour, MaxSize: 60, DryRun: true})
+	if err != nil {
+		t.Fatalf("GC dry run failed: %v", err)
+	}
+	if len(planned.Evictions) != 2 || planned.Evictions[0].Entry.CacheKey != "old" || planned.Evictions[1].Entry.CacheKey != "mid" {
+		t.Fatalf("expected old then mid to be evicted, got %+v", planned.Evictions)
+	}
+	if !strings.HasPrefix(planned.Evictions[0].Reason, "gc: not used since") || !strings.Contains(planned.Evictions[1].Reason, "size budget") {
+		t.Errorf("unexpected eviction reasons %+v", planned.Evictions)
+	}
+	if planned.Freed != 140 || planned.Kept != 2 || planned.KeptSize != 50 {
+		t.Errorf("unexpected dry run totals %+v", planned)
+	}
+	if !dirExists(filepath.Join(cm.LocalCacheDir, "proj", "cargo", "old")) {
+		t.Fatal("dry run should not remove entries")
+	}
+
+	result, err := cm.GC(db, CacheGCOptions{OlderThan: 14 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("GC failed: %v", err)
+	}
+	if len(result.Evictions) != 1 || result.Kept != 3 {
+		t.Errorf("expected only the old entry to be evicted by age, got %+v", result)
+	}
+	if dirExists(filepath.Join(cm.LocalCacheDir, "proj", "cargo", "old")) {
+		t.Error("expected the old entry to be removed")
+	}
+
+	result, err = cm.GC(db, CacheGCOptions{MaxSize: 60})
+	if err != nil {
+		t.Fatalf("GC failed: %v", err)
+	}
+	if len(result.Evictions) != 1 || result.Evictions[0].Entry.CacheKey != "mid" {
+		t.Errorf("expected the least recently used entry to be evicted, got %+v", result.Evictions)
+	}
+
+	sizes, err := cm.GetCacheSizes(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 2 {
+		t.Errorf("expected 2 entries left in the index, got %+v", sizes)
+	}
+	events, err := db.RecentCacheEvents(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evicted := 0
+	for _, e := range events {
+		if e.Event == CacheEventEvict {
+			evicted++
+		}
+	}
+	if evicted != 2 {
+		t.Errorf("expected 2 recorded evictions, got %+v", events)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"14d":  14 * 24 * time.Hour,
+		"2w":   14 * 24 * time.Hour,
+		"36h":  36 * time.Hour,
+		"1.5d": 36 * time.Hour,
+	} {
+		got, err := ParseAge(in)
+		if err != nil || got != want {
+			t.Errorf("ParseAge(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "soon", "-3d", "0h"} {
+		if _, err := ParseAge(in); err == nil {
+			t.Errorf("expected ParseAge(%q) to fail", in)
+		}
+	}
+}
+
+func TestEnforceCacheLimit(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	db, err := OpenDB()
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	SetGlobalConfig(DefaultGlobalConfig())
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := os.MkdirAll(filepath.Join(cm.LocalCacheDir, "proj", "cargo", key), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.RecordCacheEntry(CacheSizeEntry{ProjectID: "proj", Artifact: "cargo", CacheKey: key, Size: 100}); err != nil {
+			t.Fatalf("RecordCacheEntry failed: %v", err)
+		}
+		if err := db.RecordCacheEvent("hit", "proj", "cargo", key); err != nil {
+			t.Fatalf("RecordCacheEvent failed: %v", err)
+		}
+	}
+	for key, age := range map[string]string{"a": "-3 days", "b": "-2 days", "c": "-1 days"} {
+		if _, err := db.conn.Exec(`UPDATE cache_events SET timestamp = datetime('now', ?) WHERE cache_key = ?`, age, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := cm.EnforceCacheLimit(db, nil)
+	if err != nil || result != nil {
+		t.Fatalf("expected no eviction without cache.max_size, got %+v, %v", result, err)
+	}
+
+	cfg := DefaultGlobalConfig()
+	cfg.Cache.MaxSize = "150B"
+	SetGlobalConfig(cfg)
+
+	stored := ArtifactCacheEntry{Name: "cargo", ProjectID: "proj", Key: "a"}
+	result, err = cm.EnforceCacheLimit(db, []ArtifactCacheEntry{stored})
+	if err != nil {
+		t.Fatalf("EnforceCacheLimit failed: %v", err)
+	}
+	if len(result.Evictions) != 2 || result.Evictions[0].Entry.CacheKey != "b" || result.Evictions[1].Entry.CacheKey != "c" {
+		t.Fatalf("expected b then c to be evicted while keeping the stored entry, got %+v", result.Evictions)
+	}
+	if !strings.HasPrefix(result.Evictions[0].Reason, "cache.max_size:") {
+		t.Errorf("unexpected eviction reason %q", result.Evictions[0].Reason)
+	}
+	if !dirExists(filepath.Join(cm.LocalCacheDir, "proj", "cargo", "a")) || dirExists(filepath.Join(cm.LocalCacheDir, "proj", "cargo", "b")) {
+		t.Error("expected only the kept entry to remain on disk")
+	}
+}
+
+func TestCacheGCSkipsBusyEntries(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	db, err := OpenDB()
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	for _, key := range []string{"busy", "idle"} {
+		if err := os.MkdirAll(filepath.Join(cm.LocalCacheDir, "proj", "cargo", key), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.RecordCacheEntry(CacheSizeEntry{ProjectID: "proj", Artifact: "cargo", CacheKey: key, Size: 100}); err != nil {
+			t.Fatalf("RecordCacheEntry failed: %v", err)
+		}
+	}
+
+	busy := filepath.Join(cm.LocalCacheDir, "proj", "cargo", "busy")
+	lock, err := cm.shareCacheLock(busy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cm.unlockShared(lock)
+
+	result, err := cm.GC(db, CacheGCOptions{MaxSize: 1})
+	if err != nil {
+		t.Fatalf("GC failed: %v", err)
+	}
+	if len(result.Evictions) != 1 || result.Evictions[0].Entry.CacheKey != "idle" {
+		t.Fatalf("expected only the idle entry to be evicted, got %+v", result.Evictions)
+	}
+	if result.Freed != 100 || result.Kept != 1 || result.KeptSize != 100 {
+		t.Errorf("expected the busy entry to be counted as kept, got %+v", result)
+	}
+	if !dirExists(busy) {
+		t.Error("expected the entry being restored to stay on disk")
+	}
+}
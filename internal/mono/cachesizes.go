@@ -0,0 +1,211 @@
+package mono
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+func (cm *CacheManager) manifestSize(cachePath string) (int64, int64, bool, error) {
+	m, _, err := readManifest(cachePath)
+	if err != nil || m == nil || m.Size == 0 {
+		return 0, 0, false, err
+	}
+	return m.Size, m.DiskUsage, true, nil
+}
+
+const cacheIndexMetadata = "cache_index_reconciled"
+
+func (cm *CacheManager) GetCacheSizes(db *DB) ([]CacheSizeEntry, error) {
+	_, reconciled, err := db.GetMetadata(cacheIndexMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache index state: %w", err)
+	}
+	if !reconciled {
+		if err := cm.ReconcileCacheIndex(db); err != nil {
+			return nil, err
+		}
+	}
+
+	indexed, err := db.GetCacheEntries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache index: %w", err)
+	}
+
+	var entries []CacheSizeEntry
+	for _, entry := range indexed {
+		if entry.Path == "" {
+			entry.Path = cm.cacheEntryPath(entry)
+		}
+		if !dirExists(entry.Path) {
+			if err := db.DeleteCacheEntry(entry.ProjectID, entry.Artifact, entry.CacheKey); err != nil {
+				return nil, fmt.Errorf("failed to prune cache index: %w", err)
+			}
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (cm *CacheManager) ReconcileCacheIndex(db *DB) error {
+	listed, err := cm.ListCacheEntries()
+	if err != nil {
+		return err
+	}
+
+	index, err := db.GetCacheEntryIndex()
+	if err != nil {
+		return fmt.Errorf("failed to read cache index: %w", err)
+	}
+
+	for _, entry := range listed {
+		id := entry.ProjectID + "/" + entry.Artifact + "/" + entry.CacheKey
+		if indexed, ok := index[id]; ok {
+			delete(index, id)
+			if indexed.Path != "" {
+				continue
+			}
+			indexed.Path = entry.Path
+			if err := db.RecordCacheEntry(indexed); err != nil {
+				return fmt.Errorf("failed to record cache entry: %w", err)
+			}
+			continue
+		}
+
+		size, disk, ok, err := cm.manifestSize(entry.Path)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			size, disk, err = newUsageTracker().walk(entry.Path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to measure %s: %w", id, err)
+			}
+		}
+
+		entry.Size, entry.DiskUsage = size, disk
+		if err := db.RecordCacheEntry(entry); err != nil {
+			return fmt.Errorf("failed to record cache entry: %w", err)
+		}
+	}
+
+	for _, stale := range index {
+		if err := db.DeleteCacheEntry(stale.ProjectID, stale.Artifact, stale.CacheKey); err != nil {
+			return fmt.Errorf("failed to prune cache index: %w", err)
+		}
+	}
+
+	if err := db.SetMetadata(cacheIndexMetadata, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("failed to record cache index state: %w", err)
+	}
+	return nil
+}
+
+func (cm *CacheManager) RecalculateCacheSizes(db *DB) ([]CacheSizeEntry, CacheUsage, error) {
+	entries, usage, err := cm.GetCacheUsage()
+	if err != nil {
+		return nil, CacheUsage{}, err
+	}
+
+	if err := db.DeleteAllCacheEntries(); err != nil {
+		return nil, CacheUsage{}, fmt.Errorf("failed to reset cache index: %w", err)
+	}
+	for _, entry := range entries {
+		if err := db.RecordCacheEntry(entry); err != nil {
+			return nil, CacheUsage{}, fmt.Errorf("failed to record cache entry: %w", err)
+		}
+	}
+	if err := db.SetMetadata(cacheIndexMetadata, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return nil, CacheUsage{}, fmt.Errorf("failed to record cache index state: %w", err)
+	}
+
+	return entries, usage, nil
+}
+
+func (cm *CacheManager) RecordCacheSizes(db *DB, entries []ArtifactCacheEntry) error {
+	for _, entry := range entries {
+		if !dirExists(entry.CachePath) {
+			continue
+		}
+		size, disk, ok, err := cm.manifestSize(entry.CachePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s cache manifest: %w", entry.Name, err)
+		}
+		if !ok {
+			size, disk, err = newUsageTracker().walk(entry.CachePath)
+			if err != nil {
+				return fmt.Errorf("failed to measure %s cache entry: %w", entry.Name, err)
+			}
+		}
+
+		err = db.RecordCacheEntry(CacheSizeEntry{
+			ProjectID: entry.ProjectID,
+			Artifact:  entry.Name,
+			CacheKey:  entry.Key,
+			Path:      entry.CachePath,
+			Size:      size,
+			DiskUsage: disk,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record %s cache entry: %w", entry.Name, err)
+		}
+	}
+	return nil
+}
+
+type EnvironmentCacheStatus struct {
+	LastSync  time.Time
+	Artifacts int
+	Cached    int
+	Size      int64
+	DiskUsage int64
+}
+
+func (cm *CacheManager) EnvironmentCache(db *DB, index map[string]CacheSizeEntry, envPath, rootPath string) (EnvironmentCacheStatus, error) {
+	var status EnvironmentCacheStatus
+
+	artifacts, err := db.ListEnvironmentArtifacts(envPath)
+	if err != nil {
+		return status, fmt.Errorf("failed to read artifact records: %w", err)
+	}
+
+	projectIDs := []string{SharedProjectID}
+	if rootPath != "" {
+		projectIDs = append([]string{ComputeProjectID(rootPath)}, projectIDs...)
+	}
+
+	for _, artifact := range artifacts {
+		status.Artifacts++
+		if artifact.UpdatedAt.After(status.LastSync) {
+			status.LastSync = artifact.UpdatedAt
+		}
+
+		for _, projectID := range projectIDs {
+			entry := CacheSizeEntry{ProjectID: projectID, Artifact: artifact.Artifact, CacheKey: artifact.CacheKey}
+			cachePath := cm.cacheEntryPath(entry)
+			if !dirExists(cachePath) {
+				continue
+			}
+			status.Cached++
+
+			if indexed, ok := index[projectID+"/"+artifact.Artifact+"/"+artifact.CacheKey]; ok {
+				status.Size += indexed.Size
+				status.DiskUsage += indexed.DiskUsage
+				break
+			}
+			size, disk, _, err := cm.manifestSize(cachePath)
+			if err != nil {
+				return status, fmt.Errorf("failed to read %s cache manifest: %w", artifact.Artifact, err)
+			}
+			status.Size += size
+			status.DiskUsage += disk
+			break
+		}
+	}
+
+	return status, nil
+}
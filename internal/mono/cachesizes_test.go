@@ -0,0 +1,72 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvironmentCache(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	db, err := OpenDB()
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	rootPath := t.TempDir()
+	envPath := filepath.Join(t.TempDir(), "feature")
+	projectID := ComputeProjectID(rootPath)
+
+	status, err := cm.EnvironmentCache(db, nil, envPath, rootPath)
+	if err != nil {
+		t.Fatalf("EnvironmentCache failed: %v", err)
+	}
+	if status.Artifacts != 0 || !status.LastSync.IsZero() {
+		t.Errorf("expected no cache status for an unsynced environment, got %+v", status)
+	}
+
+	for _, entry := range []CacheSizeEntry{
+		{ProjectID: projectID, Artifact: "cargo", CacheKey: "k1", Size: 10, DiskUsage: 8},
+		{ProjectID: SharedProjectID, Artifact: "npm", CacheKey: "k2", Size: 4, DiskUsage: 2},
+	} {
+		if err := os.MkdirAll(cm.cacheEntryPath(entry), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.RecordCacheEntry(entry); err != nil {
+			t.Fatalf("RecordCacheEntry failed: %v", err)
+		}
+	}
+	err = RecordArtifactKeys(db, envPath, []ArtifactCacheEntry{
+		{Name: "cargo", Key: "k1"},
+		{Name: "npm", Key: "k2"},
+		{Name: "pip", Key: "evicted"},
+	})
+	if err != nil {
+		t.Fatalf("RecordArtifactKeys failed: %v", err)
+	}
+
+	index, err := db.GetCacheEntryIndex()
+	if err != nil {
+		t.Fatalf("GetCacheEntryIndex failed: %v", err)
+	}
+	status, err = cm.EnvironmentCache(db, index, envPath, rootPath)
+	if err != nil {
+		t.Fatalf("EnvironmentCache failed: %v", err)
+	}
+
+	if status.Artifacts != 3 || status.Cached != 2 {
+		t.Errorf("expected 2/3 cached artifacts, got %d/%d", status.Cached, status.Artifacts)
+	}
+	if status.Size != 14 || status.DiskUsage != 10 {
+		t.Errorf("expected size 14 and disk usage 10, got %d and %d", status.Size, status.DiskUsage)
+	}
+	if status.LastSync.IsZero() {
+		t.Error("expected a last sync time")
+	}
+}
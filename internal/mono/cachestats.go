@@ -0,0 +1,208 @@
+package mono
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CacheStatsFormatTable = "table"
+	CacheStatsFormatCSV   = "csv"
+	CacheStatsFormatJSON  = "json"
+)
+
+type CacheStatsRecord struct {
+	ProjectID   string     `json:"project_id"`
+	RootPath    string     `json:"root_path"`
+	Artifact    string     `json:"artifact"`
+	CacheKey    string     `json:"cache_key"`
+	Size        int64      `json:"size"`
+	DiskUsage   int64      `json:"disk_usage"`
+	Hits        int        `json:"hits"`
+	Misses      int        `json:"misses"`
+	LastUsed    *time.Time `json:"last_used"`
+	KeyStrategy string     `json:"key_strategy"`
+	KeyFiles    []string   `json:"key_files"`
+	KeyCommands []string   `json:"key_commands"`
+}
+
+type CacheProjectTotals struct {
+	ProjectID string     `json:"project_id"`
+	RootPath  string     `json:"root_path"`
+	Entries   int        `json:"entries"`
+	Size      int64      `json:"size"`
+	DiskUsage int64      `json:"disk_usage"`
+	Hits      int        `json:"hits"`
+	Misses    int        `json:"misses"`
+	LastUsed  *time.Time `json:"last_used"`
+}
+
+type CacheStatsReport struct {
+	Entries  []CacheStatsRecord   `json:"entries"`
+	Projects []CacheProjectTotals `json:"projects"`
+}
+
+func BuildCacheStatsRecords(sizes []CacheSizeEntry, stats []CacheEntry, rootPaths []string) ([]CacheStatsRecord, error) {
+	statsMap := make(map[string]CacheEntry)
+	for _, s := range stats {
+		statsMap[s.ProjectID+"/"+s.Artifact+"/"+s.CacheKey] = s
+	}
+
+	roots := make(map[string]string)
+	artifacts := make(map[string]map[string]ArtifactConfig)
+	shared := make(map[string]ArtifactConfig)
+	for _, rootPath := range rootPaths {
+		projectID := ComputeProjectID(rootPath)
+		roots[projectID] = rootPath
+		if !dirExists(rootPath) {
+			continue
+		}
+		cfg, err := LoadConfig(rootPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config for %s: %w", rootPath, err)
+		}
+		cfg.ApplyDefaults(rootPath)
+		byName := make(map[string]ArtifactConfig)
+		for _, artifact := range cfg.Build.Artifacts {
+			byName[artifact.Name] = artifact
+			if _, ok := shared[artifact.Name]; artifact.Shared && !ok {
+				shared[artifact.Name] = artifact
+			}
+		}
+		artifacts[projectID] = byName
+	}
+
+	records := make([]CacheStatsRecord, 0, len(sizes))
+	for _, entry := range sizes {
+		record := CacheStatsRecord{
+			ProjectID:   entry.ProjectID,
+			RootPath:    roots[entry.ProjectID],
+			Artifact:    entry.Artifact,
+			CacheKey:    entry.CacheKey,
+			Size:        entry.Size,
+			DiskUsage:   entry.DiskUsage,
+			KeyFiles:    []string{},
+			KeyCommands: []string{},
+		}
+		if s, ok := statsMap[entry.ProjectID+"/"+entry.Artifact+"/"+entry.CacheKey]; ok {
+			record.Hits = s.Hits
+			record.Misses = s.Misses
+			lastUsed := s.LastUsed
+			record.LastUsed = &lastUsed
+		}
+
+		artifact, ok := artifacts[entry.ProjectID][entry.Artifact]
+		if entry.ProjectID == SharedProjectID {
+			artifact, ok = shared[entry.Artifact]
+		}
+		if ok {
+			record.KeyStrategy = cmp.Or(artifact.KeyStrategy, KeyStrategyContent)
+			record.KeyFiles = append(record.KeyFiles, artifact.KeyFiles...)
+			record.KeyCommands = append(record.KeyCommands, artifact.KeyCommands...)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func cacheProjectTotals(records []CacheStatsRecord) []CacheProjectTotals {
+	byProject := make(map[string]*CacheProjectTotals)
+	var order []string
+	for _, r := range records {
+		totals, ok := byProject[r.ProjectID]
+		if !ok {
+			totals = &CacheProjectTotals{ProjectID: r.ProjectID, RootPath: r.RootPath}
+			byProject[r.ProjectID] = totals
+			order = append(order, r.ProjectID)
+		}
+		totals.Entries++
+		totals.Size += r.Size
+		totals.DiskUsage += r.DiskUsage
+		totals.Hits += r.Hits
+		totals.Misses += r.Misses
+		if r.LastUsed != nil && (totals.LastUsed == nil || r.LastUsed.After(*totals.LastUsed)) {
+			lastUsed := *r.LastUsed
+			totals.LastUsed = &lastUsed
+		}
+	}
+	slices.Sort(order)
+
+	projects := make([]CacheProjectTotals, 0, len(order))
+	for _, projectID := range order {
+		projects = append(projects, *byProject[projectID])
+	}
+	return projects
+}
+
+func formatLastUsed(lastUsed *time.Time) string {
+	if lastUsed == nil {
+		return ""
+	}
+	return lastUsed.UTC().Format(time.RFC3339)
+}
+
+func WriteCacheStats(w io.Writer, records []CacheStatsRecord, format string) error {
+	switch format {
+	case CacheStatsFormatJSON:
+		report := CacheStatsReport{Entries: records, Projects: cacheProjectTotals(records)}
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode cache stats: %w", err)
+		}
+		_, err = w.Write(append(out, '\n'))
+		return err
+	case CacheStatsFormatCSV:
+		cw := csv.NewWriter(w)
+		header := []string{"project_id", "root_path", "artifact", "cache_key", "size", "disk_usage", "hits", "misses", "last_used", "key_strategy", "key_files", "key_commands"}
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		for _, r := range records {
+			if err := cw.Write([]string{
+				r.ProjectID,
+				r.RootPath,
+				r.Artifact,
+				r.CacheKey,
+				strconv.FormatInt(r.Size, 10),
+				strconv.FormatInt(r.DiskUsage, 10),
+				strconv.Itoa(r.Hits),
+				strconv.Itoa(r.Misses),
+				formatLastUsed(r.LastUsed),
+				r.KeyStrategy,
+				strings.Join(r.KeyFiles, ";"),
+				strings.Join(r.KeyCommands, ";"),
+			}); err != nil {
+				return err
+			}
+		}
+		for _, p := range cacheProjectTotals(records) {
+			if err := cw.Write([]string{
+				p.ProjectID,
+				p.RootPath,
+				"",
+				"",
+				strconv.FormatInt(p.Size, 10),
+				strconv.FormatInt(p.DiskUsage, 10),
+				strconv.Itoa(p.Hits),
+				strconv.Itoa(p.Misses),
+				formatLastUsed(p.LastUsed),
+				"",
+				"",
+				"",
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown cache stats format %q (use table, csv or json)", format)
+	}
+}
@@ -0,0 +1,121 @@
+package mono
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheStatsExport(t *testing.T) {
+	root := t.TempDir()
+	monoYml := `build:
+  artifacts:
+    - name: node_modules
+      key_files: [package-lock.json]
+      key_commands: [node --version]
+      paths: [node_modules]
+    - name: cargo
+      key_files: [Cargo.lock]
+      key_strategy: stat
+      shared: true
+      paths: [target]
+`
+	if err := os.WriteFile(filepath.Join(root, "mono.yml"), []byte(monoYml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	projectID := ComputeProjectID(root)
+	lastUsed := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	sizes := []CacheSizeEntry{
+		{ProjectID: projectID, Artifact: "node_modules", CacheKey: "abc", Size: 100, DiskUsage: 80},
+		{ProjectID: SharedProjectID, Artifact: "cargo", CacheKey: "def", Size: 200, DiskUsage: 200},
+		{ProjectID: "gone", Artifact: "vendor", CacheKey: "ghi", Size: 5, DiskUsage: 5},
+	}
+	stats := []CacheEntry{
+		{ProjectID: projectID, Artifact: "node_modules", CacheKey: "abc", Hits: 3, Misses: 1, LastUsed: lastUsed},
+	}
+
+	records, err := BuildCacheStatsRecords(sizes, stats, []string{root, filepath.Join(root, "missing")})
+	if err != nil {
+		t.Fatalf("BuildCacheStatsRecords failed: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %+v", records)
+	}
+
+	node := records[0]
+	if node.RootPath != root || node.Hits != 3 || node.Misses != 1 || node.LastUsed == nil || !node.LastUsed.Equal(lastUsed) {
+		t.Errorf("unexpected node_modules record %+v", node)
+	}
+	if node.KeyStrategy != KeyStrategyContent || len(node.KeyFiles) != 1 || len(node.KeyCommands) != 1 {
+		t.Errorf("unexpected node_modules key components %+v", node)
+	}
+	if cargo := records[1]; cargo.KeyStrategy != KeyStrategyStat || len(cargo.KeyFiles) != 1 || cargo.KeyFiles[0] != "Cargo.lock" {
+		t.Errorf("expected the shared entry to take key components from the project config, got %+v", cargo)
+	}
+	if vendor := records[2]; vendor.LastUsed != nil || vendor.KeyStrategy != "" || vendor.RootPath != "" {
+		t.Errorf("expected an unknown project to have no stats or key components, got %+v", vendor)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCacheStats(&buf, records, CacheStatsFormatCSV); err != nil {
+		t.Fatalf("WriteCacheStats csv failed: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 7 {
+		t.Fatalf("expected a header, 3 entries and 3 project totals, got %v", rows)
+	}
+	want := []string{projectID, root, "node_modules", "abc", "100", "80", "3", "1", "2025-03-01T12:00:00Z", "content", "package-lock.json", "node --version"}
+	for i, v := range want {
+		if rows[1][i] != v {
+			t.Errorf("csv column %s = %q, want %q", rows[0][i], rows[1][i], v)
+		}
+	}
+
+	var totalsRow []string
+	for _, row := range rows[4:] {
+		if row[0] == projectID {
+			totalsRow = row
+		}
+	}
+	if totalsRow == nil || totalsRow[2] != "" || totalsRow[4] != "100" || totalsRow[6] != "3" || totalsRow[8] != "2025-03-01T12:00:00Z" {
+		t.Errorf("expected a totals row for the project after the entries, got %v", rows[4:])
+	}
+
+	buf.Reset()
+	if err := WriteCacheStats(&buf, records, CacheStatsFormatJSON); err != nil {
+		t.Fatalf("WriteCacheStats json failed: %v", err)
+	}
+	var decoded struct {
+		Entries  []map[string]any     `json:"entries"`
+		Projects []CacheProjectTotals `json:"projects"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Entries) != 3 || decoded.Entries[0]["misses"] != float64(1) || decoded.Entries[2]["last_used"] != nil {
+		t.Errorf("unexpected json export %s", buf.String())
+	}
+	if len(decoded.Projects) != 3 {
+		t.Fatalf("expected totals for 3 projects, got %+v", decoded.Projects)
+	}
+	for _, p := range decoded.Projects {
+		if p.ProjectID != projectID {
+			continue
+		}
+		if p.RootPath != root || p.Entries != 1 || p.Size != 100 || p.DiskUsage != 80 || p.Hits != 3 || p.Misses != 1 || p.LastUsed == nil {
+			t.Errorf("unexpected project totals %+v", p)
+		}
+	}
+
+	if err := WriteCacheStats(&buf, records, "xml"); err == nil {
+		t.Error("expected an unknown format to fail")
+	}
+}
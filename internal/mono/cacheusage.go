@@ -0,0 +1,88 @@
+package mono
+
+import (
+	"io/fs"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+const statBlockSize = 512
+
+type CacheUsage struct {
+	Logical       int64
+	Disk          int64
+	SharedWithEnv int64
+}
+
+func (u CacheUsage) Reclaimable() int64 {
+	return u.Disk - u.SharedWithEnv
+}
+
+type inodeKey struct {
+	dev uint64
+	ino uint64
+}
+
+type inodeUsage struct {
+	bytes int64
+	nlink uint64
+	seen  uint64
+}
+
+type usageTracker struct {
+	mu     sync.Mutex
+	inodes map[inodeKey]*inodeUsage
+}
+
+func newUsageTracker() *usageTracker {
+	return &usageTracker{inodes: make(map[inodeKey]*inodeUsage)}
+}
+
+func (t *usageTracker) walk(root string) (int64, int64, error) {
+	var logical, disk atomic.Int64
+	err := parallelWalk(root, workerCount(workersWalk, root), func(p, rel string, d fs.DirEntry) error {
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		logical.Add(info.Size())
+		disk.Add(t.add(info))
+		return nil
+	})
+	return logical.Load(), disk.Load(), err
+}
+
+func (t *usageTracker) add(info fs.FileInfo) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.Size()
+	}
+
+	key := inodeKey{dev: uint64(stat.Dev), ino: stat.Ino}
+	if u, exists := t.inodes[key]; exists {
+		u.seen++
+		return 0
+	}
+
+	bytes := int64(stat.Blocks) * statBlockSize
+	t.inodes[key] = &inodeUsage{bytes: bytes, nlink: uint64(stat.Nlink), seen: 1}
+	return bytes
+}
+
+func (t *usageTracker) usage() CacheUsage {
+	var u CacheUsage
+	for _, inode := range t.inodes {
+		u.Disk += inode.bytes
+		if inode.nlink > inode.seen {
+			u.SharedWithEnv += inode.bytes
+		}
+	}
+	return u
+}
@@ -0,0 +1,186 @@
+package mono
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+)
+
+const (
+	EventInitCompleted = "init.completed"
+	EventInitFailed    = "init.failed"
+)
+
+type CallbackConfig struct {
+	URL     string        `yaml:"url"`
+	Command string        `yaml:"command"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+func (c CallbackConfig) enabled() bool {
+	return c.URL != "" || c.Command != ""
+}
+
+type LifecycleEvent struct {
+	Event         string           `json:"event"`
+	Name          string           `json:"name"`
+	Path          string           `json:"path"`
+	DataDir       string           `json:"data_dir,omitempty"`
+	DockerProject string           `json:"docker_project,omitempty"`
+	Devcontainer  string           `json:"devcontainer,omitempty"`
+	Session       string           `json:"session,omitempty"`
+	Reconciled    bool             `json:"reconciled"`
+	CacheHit      bool             `json:"cache_hit"`
+	Artifacts     []ArtifactStatus `json:"artifacts"`
+	Ports         []PortStatus     `json:"ports"`
+	Scripts       []ScriptStatus   `json:"scripts"`
+	Phases        []PhaseStatus    `json:"phases"`
+	DurationMS    int64            `json:"duration_ms"`
+	Error         string           `json:"error,omitempty"`
+	Timestamp     time.Time        `json:"timestamp"`
+}
+
+type PortStatus struct {
+	Service       string `json:"service"`
+	ContainerPort int    `json:"container_port"`
+	HostPort      int    `json:"host_port"`
+}
+
+func newInitEvent(path string, result *InitResult, err error, duration time.Duration) LifecycleEvent {
+	event := LifecycleEvent{
+		Event:      EventInitCompleted,
+		Name:       EnvName(path),
+		Path:       path,
+		Artifacts:  []ArtifactStatus{},
+		Ports:      []PortStatus{},
+		Scripts:    []ScriptStatus{},
+		Phases:     []PhaseStatus{},
+		DurationMS: duration.Milliseconds(),
+		Timestamp:  time.Now().UTC(),
+	}
+	if err != nil {
+		event.Event = EventInitFailed
+		event.Error = err.Error()
+	}
+	if result == nil {
+		return event
+	}
+
+	event.DataDir = result.DataDir
+	event.DockerProject = result.DockerProject
+	event.Devcontainer = result.Devcontainer
+	event.Session = result.SessionName
+	event.Reconciled = result.Reconciled
+	event.CacheHit = result.CacheHit
+	if result.Artifacts != nil {
+		event.Artifacts = result.Artifacts
+	}
+	if result.Scripts != nil {
+		event.Scripts = result.Scripts
+	}
+	if result.Phases != nil {
+		event.Phases = result.Phases
+	}
+	for _, alloc := range result.Allocations {
+		event.Ports = append(event.Ports, PortStatus{
+			Service:       alloc.Service,
+			ContainerPort: alloc.ContainerPort,
+			HostPort:      alloc.HostPort,
+		})
+	}
+	return event
+}
+
+func notifyLifecycle(event LifecycleEvent) error {
+	cfg := globalConfig().Callbacks
+	if !cfg.enabled() {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+
+	if cfg.URL != "" {
+		if err := postCallback(ctx, cfg.URL, payload); err != nil {
+			return err
+		}
+	}
+	if cfg.Command != "" {
+		if err := execCallback(ctx, cfg.Command, event.Event, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func postCallback(ctx context.Context, url string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("invalid callback url: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("callback request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned %s", resp.Status)
+	}
+	return nil
+}
+
+func execCallback(ctx context.Context, command, event string, payload []byte) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), "MONO_EVENT="+event)
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("callback command timed out")
+	}
+	if err != nil {
+		return fmt.Errorf("callback command failed: %w: %s", err, bytes.TrimSpace(output))
+	}
+	return nil
+}
+
+func runInit(path string, opts InitOptions) (*InitResult, error) {
+	start := time.Now()
+	result, err := initEnvironment(path, opts)
+
+	event := newInitEvent(path, result, err, time.Since(start))
+	if err == nil {
+		if writeErr := writeInitResult(event); writeErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", writeErr)
+		}
+	}
+	if cbErr := notifyLifecycle(event); cbErr != nil {
+		warnCallbackFailure(event, cbErr)
+	}
+	return result, err
+}
+
+func warnCallbackFailure(event LifecycleEvent, cbErr error) {
+	fmt.Fprintf(os.Stderr, "warning: %s callback for %s failed: %v\n", event.Event, event.Name, cbErr)
+
+	logger, err := NewFileLogger(event.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to open log for %s: %v\n", event.Name, err)
+		return
+	}
+	defer logger.Close()
+	logger.Log("warning: %s callback failed: %v", event.Event, cbErr)
+}
@@ -0,0 +1,134 @@
+package mono
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewInitEvent(t *testing.T) {
+	result := &InitResult{
+		Name:          "feature",
+		Path:          "/work/feature",
+		DataDir:       "/home/.mono/data/feature",
+		DockerProject: "mono-feature",
+		SessionName:   "mono-feature",
+		Allocations:   []Allocation{{Service: "db", ContainerPort: 5432, HostPort: 15432}},
+		CacheHit:      true,
+		Artifacts:     []ArtifactStatus{{Name: "cargo", Key: "abc", Hit: true}},
+	}
+
+	event := newInitEvent("/work/feature", result, nil, 1500*time.Millisecond)
+	if event.Event != EventInitCompleted || event.Error != "" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if !event.CacheHit || len(event.Artifacts) != 1 || event.DurationMS != 1500 {
+		t.Errorf("unexpected cache status: %+v", event)
+	}
+	if len(event.Ports) != 1 || event.Ports[0].HostPort != 15432 {
+		t.Errorf("unexpected ports: %+v", event.Ports)
+	}
+
+	failed := newInitEvent("/work/feature", nil, errors.New("init script failed"), time.Second)
+	if failed.Event != EventInitFailed || failed.Error != "init script failed" {
+		t.Errorf("unexpected failed event: %+v", failed)
+	}
+	if failed.Artifacts == nil || failed.Ports == nil {
+		t.Error("failed event should encode empty lists")
+	}
+}
+
+func TestNotifyLifecycleHTTP(t *testing.T) {
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	received := make(chan LifecycleEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected content type: %s", r.Header.Get("Content-Type"))
+		}
+		var event LifecycleEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("failed to decode event: %v", err)
+		}
+		received <- event
+	}))
+	defer server.Close()
+
+	cfg := DefaultGlobalConfig()
+	cfg.Callbacks.URL = server.URL
+	SetGlobalConfig(cfg)
+
+	if err := notifyLifecycle(LifecycleEvent{Event: EventInitCompleted, Name: "feature"}); err != nil {
+		t.Fatalf("notifyLifecycle failed: %v", err)
+	}
+
+	event := <-received
+	if event.Event != EventInitCompleted || event.Name != "feature" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	cfg.Callbacks.URL = failing.URL
+	SetGlobalConfig(cfg)
+	if err := notifyLifecycle(LifecycleEvent{Event: EventInitFailed}); err == nil {
+		t.Error("expected non-2xx response to fail")
+	}
+}
+
+func TestNotifyLifecycleCommand(t *testing.T) {
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "event.json")
+	name := filepath.Join(dir, "event.name")
+	cfg := DefaultGlobalConfig()
+	cfg.Callbacks.Command = `cat > "` + out + `" && printf %s "$MONO_EVENT" > "` + name + `"`
+	SetGlobalConfig(cfg)
+
+	if err := notifyLifecycle(LifecycleEvent{Event: EventInitFailed, Name: "feature"}); err != nil {
+		t.Fatalf("notifyLifecycle failed: %v", err)
+	}
+
+	event, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("failed to read event name: %v", err)
+	}
+	if string(event) != EventInitFailed {
+		t.Errorf("expected MONO_EVENT %s, got %q", EventInitFailed, event)
+	}
+	payload, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("failed to read callback payload: %v", err)
+	}
+	var decoded LifecycleEvent
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if decoded.Name != "feature" {
+		t.Errorf("unexpected payload: %+v", decoded)
+	}
+
+	cfg.Callbacks.Command = "exit 3"
+	SetGlobalConfig(cfg)
+	if err := notifyLifecycle(LifecycleEvent{Event: EventInitFailed}); err == nil {
+		t.Error("expected failing command to return an error")
+	}
+}
+
+func TestNotifyLifecycleDisabled(t *testing.T) {
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+	SetGlobalConfig(DefaultGlobalConfig())
+
+	if err := notifyLifecycle(LifecycleEvent{Event: EventInitCompleted}); err != nil {
+		t.Errorf("expected no-op without callbacks, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func cargoTargetDir(workDir string) string {
+	for _, name := range []string{"CARGO_TARGET_DIR", "CARGO_BUILD_TARGET_DIR"} {
+		if dir := os.Getenv(name); dir != "" {
+			return resolveCargoPath(workDir, dir)
+		}
+	}
+
+	for dir := workDir; ; dir = filepath.Dir(dir) {
+		if targetDir, ok := cargoConfigTargetDir(filepath.Join(dir, ".cargo")); ok {
+			return resolveCargoPath(dir, targetDir)
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	cargoHome := os.Getenv("CARGO_HOME")
+	if cargoHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			cargoHome = filepath.Join(home, ".cargo")
+		}
+	}
+	if cargoHome != "" {
+		if targetDir, ok := cargoConfigTargetDir(cargoHome); ok {
+			return resolveCargoPath(filepath.Dir(cargoHome), targetDir)
+		}
+	}
+
+	return filepath.Join(workDir, "target")
+}
+
+func resolveCargoPath(base, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(base, path)
+}
+
+func cargoConfigTargetDir(configDir string) (string, bool) {
+	for _, name := range []string{"config.toml", "config"} {
+		data, err := os.ReadFile(filepath.Join(configDir, name))
+		if err != nil {
+			continue
+		}
+		if targetDir, ok := parseCargoTargetDir(string(data)); ok {
+			return targetDir, true
+		}
+	}
+	return "", false
+}
+
+func parseCargoTargetDir(config string) (string, bool) {
+	section := ""
+	for _, line := range strings.Split(config, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			section = strings.TrimSpace(strings.Trim(line, "[]"))
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if section != "" {
+			key = section + "." + key
+		}
+		if key != "build.target-dir" {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) < 2 || (value[0] != '"' && value[0] != '\'') {
+			continue
+		}
+		end := strings.IndexByte(value[1:], value[0])
+		if end < 0 {
+			continue
+		}
+		return value[1 : end+1], true
+	}
+	return "", false
+}
@@ -0,0 +1,64 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCargoTargetDir(t *testing.T) {
+	for config, want := range map[string]string{
+		"[build]\ntarget-dir = \"out/cargo\"\n":            "out/cargo",
+		"[build]\njobs = 4\ntarget-dir = 'tgt' # shared\n": "tgt",
+		"build.target-dir = \"/var/cache/target\"\n":       "/var/cache/target",
+		"[profile.dev]\ntarget-dir = \"nope\"\n":           "",
+		"[net]\nretry = 2\n":                               "",
+	} {
+		got, ok := parseCargoTargetDir(config)
+		if got != want || ok != (want != "") {
+			t.Errorf("parseCargoTargetDir(%q) = %q, %v; want %q", config, got, ok, want)
+		}
+	}
+}
+
+func TestDetectArtifactsCargoTargetDir(t *testing.T) {
+	t.Setenv("CARGO_TARGET_DIR", "")
+	t.Setenv("CARGO_BUILD_TARGET_DIR", "")
+	t.Setenv("CARGO_HOME", t.TempDir())
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	envPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(envPath, ".cargo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(envPath, "Cargo.lock"), []byte(""), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(envPath, ".cargo", "config.toml"), []byte("[build]\ntarget-dir = \"build/cargo\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	artifacts := detectArtifacts(envPath)
+	if len(artifacts) != 1 || artifacts[0].Paths[0].Path != filepath.Join("build", "cargo") {
+		t.Fatalf("expected target dir from .cargo/config.toml, got %+v", artifacts)
+	}
+
+	shared := filepath.Join(t.TempDir(), "shared-target")
+	t.Setenv("CARGO_TARGET_DIR", shared)
+	artifacts = detectArtifacts(envPath)
+	if artifacts[0].Paths[0].Path != shared {
+		t.Errorf("expected CARGO_TARGET_DIR %s to win, got %s", shared, artifacts[0].Paths[0].Path)
+	}
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := cm.PrepareArtifactCache(artifacts, envPath, envPath)
+	if err != nil {
+		t.Fatalf("PrepareArtifactCache failed: %v", err)
+	}
+	if entries[0].EnvPaths[0] != shared {
+		t.Errorf("expected the shared target dir to be cached as is, got %s", entries[0].EnvPaths[0])
+	}
+}
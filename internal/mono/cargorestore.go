@@ -0,0 +1,145 @@
+package mono
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+func cargoProfileDirs(targetDir string) ([]string, error) {
+	if dirExists(filepath.Join(targetDir, ".fingerprint")) {
+		return []string{targetDir}, nil
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(targetDir, entry.Name())
+		if dirExists(filepath.Join(dir, ".fingerprint")) {
+			dirs = append(dirs, dir)
+			continue
+		}
+
+		nested, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range nested {
+			sub := filepath.Join(dir, n.Name())
+			if n.IsDir() && dirExists(filepath.Join(sub, ".fingerprint")) {
+				dirs = append(dirs, sub)
+			}
+		}
+	}
+	return dirs, nil
+}
+
+func rewriteCargoBuildOutputs(profileDir string) error {
+	buildDir := filepath.Join(profileDir, "build")
+	entries, err := os.ReadDir(buildDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		scriptDir := filepath.Join(buildDir, entry.Name())
+		data, err := os.ReadFile(filepath.Join(scriptDir, "root-output"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		oldRoot, newRoot, ok := cargoPathRemap(strings.TrimSpace(string(data)), filepath.Join(scriptDir, "out"))
+		if !ok {
+			continue
+		}
+		pattern := regexp.MustCompile(`(?m)` + regexp.QuoteMeta(oldRoot) + `([^\w.-]|$)`)
+		replacement := strings.ReplaceAll(newRoot, "$", "$$") + "${1}"
+		for _, name := range []string{"root-output", "output"} {
+			if err := rewriteFile(filepath.Join(scriptDir, name), pattern, replacement); err != nil {
+				return fmt.Errorf("failed to rewrite %s: %w", filepath.Join(scriptDir, name), err)
+			}
+		}
+	}
+	return nil
+}
+
+func cargoPathRemap(oldPath, newPath string) (string, string, bool) {
+	if oldPath == newPath || !filepath.IsAbs(oldPath) {
+		return "", "", false
+	}
+	for filepath.Base(oldPath) == filepath.Base(newPath) {
+		oldParent, newParent := filepath.Dir(oldPath), filepath.Dir(newPath)
+		if oldParent == oldPath || newParent == newPath {
+			break
+		}
+		oldPath, newPath = oldParent, newParent
+	}
+	if oldPath == string(filepath.Separator) || newPath == string(filepath.Separator) {
+		return "", "", false
+	}
+	return oldPath, newPath, true
+}
+
+func rewriteFile(path string, pattern *regexp.Regexp, replacement string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	rewritten := pattern.ReplaceAll(data, []byte(replacement))
+	if bytes.Equal(rewritten, data) {
+		return nil
+	}
+	return writeFileAtomic(path, rewritten, info.Mode().Perm())
+}
+
+func touchCargoOutputs(profileDir string, now time.Time) error {
+	var files []string
+
+	deps, err := os.ReadDir(filepath.Join(profileDir, "deps"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, dep := range deps {
+		if dep.Type().IsRegular() {
+			files = append(files, filepath.Join(profileDir, "deps", dep.Name()))
+		}
+	}
+
+	outputs, err := filepath.Glob(filepath.Join(profileDir, "build", "*", "output"))
+	if err != nil {
+		return err
+	}
+	files = append(files, outputs...)
+
+	return touchFilesParallel(files, now, workerCount(workersTouch, profileDir), "touching cargo outputs")
+}
@@ -0,0 +1,199 @@
+package mono
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRewriteCargoBuildOutputs(t *testing.T) {
+	testDir := t.TempDir()
+	oldEnv := filepath.Join(testDir, "old")
+	newEnv := filepath.Join(testDir, "new")
+	scriptDir := filepath.Join(newEnv, "crates", "api", "target", "debug", "build", "api-1234")
+	if err := os.MkdirAll(scriptDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	oldOut := filepath.Join(oldEnv, "crates", "api", "target", "debug", "build", "api-1234", "out")
+	output := "cargo:rerun-if-changed=" + oldEnv + "/crates/api/proto.txt\n" +
+		"cargo:rustc-env=ROOT=" + oldEnv + "\n" +
+		"cargo:rustc-env=OTHER=" + oldEnv + "-sibling/x\n"
+	if err := os.WriteFile(filepath.Join(scriptDir, "root-output"), []byte(oldOut), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(scriptDir, "output"), []byte(output), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rewriteCargoBuildOutputs(filepath.Join(newEnv, "crates", "api", "target", "debug")); err != nil {
+		t.Fatalf("rewriteCargoBuildOutputs failed: %v", err)
+	}
+
+	rootOutput, err := os.ReadFile(filepath.Join(scriptDir, "root-output"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rootOutput) != filepath.Join(scriptDir, "out") {
+		t.Errorf("expected root-output to point at %s, got %s", filepath.Join(scriptDir, "out"), rootOutput)
+	}
+
+	rewritten, err := os.ReadFile(filepath.Join(scriptDir, "output"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "cargo:rerun-if-changed=" + newEnv + "/crates/api/proto.txt\n" +
+		"cargo:rustc-env=ROOT=" + newEnv + "\n" +
+		"cargo:rustc-env=OTHER=" + oldEnv + "-sibling/x\n"
+	if string(rewritten) != want {
+		t.Errorf("unexpected output:\n got %q\nwant %q", rewritten, want)
+	}
+}
+
+func TestCargoRestoreStaysFresh(t *testing.T) {
+	if _, err := exec.LookPath("cargo"); err != nil {
+		t.Skip("cargo not installed, skipping integration test")
+	}
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+
+	testDir := t.TempDir()
+	files := map[string]string{
+		"Cargo.toml":  "[package]\nname = \"demo\"\nversion = \"0.1.0\"\nedition = \"2021\"\n",
+		"build.rs":    "fn main() {\n    let dir = std::env::var(\"CARGO_MANIFEST_DIR\").unwrap();\n    println!(\"cargo:rerun-if-changed={}/proto.txt\", dir);\n    println!(\"cargo:rustc-env=PROTO={}/proto.txt\", dir);\n}\n",
+		"proto.txt":   "v1\n",
+		"src/main.rs": "fn main() {\n    println!(\"{}\", env!(\"PROTO\"));\n}\n",
+	}
+	checkout := func(name string) string {
+		dir := filepath.Join(testDir, name)
+		for path, content := range files {
+			if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+	cargo := func(dir string, args ...string) string {
+		cmd := exec.Command("cargo", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("cargo %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return string(out)
+	}
+
+	rootPath := checkout("root")
+	cargo(rootPath, "generate-lockfile", "--offline", "--quiet")
+	cargo(rootPath, "build", "--offline", "--quiet")
+
+	artifacts := detectArtifacts(rootPath)
+	entries, err := cm.PrepareArtifactCache(artifacts, rootPath, rootPath)
+	if err != nil {
+		t.Fatalf("PrepareArtifactCache failed: %v", err)
+	}
+	if err := cm.StoreToCache(entries[0]); err != nil {
+		t.Fatalf("StoreToCache failed: %v", err)
+	}
+
+	envPath := checkout("env")
+	lock, err := os.ReadFile(filepath.Join(rootPath, "Cargo.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(envPath, "Cargo.lock"), lock, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err = cm.PrepareArtifactCache(artifacts, rootPath, envPath)
+	if err != nil {
+		t.Fatalf("PrepareArtifactCache failed: %v", err)
+	}
+	if !entries[0].Hit {
+		t.Fatal("expected a cache hit for the new environment")
+	}
+	if err := cm.RestoreFromCache(entries[0], nil); err != nil {
+		t.Fatalf("RestoreFromCache failed: %v", err)
+	}
+
+	out := cargo(envPath, "build", "--offline", "-v")
+	if strings.Contains(out, "Compiling") || strings.Contains(out, "Dirty") {
+		t.Errorf("expected a fresh build after restore, got:\n%s", out)
+	}
+}
+
+func TestCargoProfileScopedRestore(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+
+	envPath := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	for _, f := range []string{
+		"Cargo.lock",
+		"target/debug/.fingerprint/demo-1/dep-bin-demo",
+		"target/debug/deps/demo-1",
+		"target/debug/deps/demo-1.o",
+		"target/release/deps/demo-2",
+	} {
+		path := filepath.Join(envPath, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	artifact := ArtifactConfig{
+		Name:     "cargo-debug",
+		KeyFiles: []string{"Cargo.lock"},
+		Paths:    []ArtifactPath{{Path: "target/debug", Type: "cargo"}},
+	}
+	entries, err := cm.PrepareArtifactCache([]ArtifactConfig{artifact}, envPath, envPath)
+	if err != nil {
+		t.Fatalf("PrepareArtifactCache failed: %v", err)
+	}
+	if err := cm.StoreToCache(entries[0]); err != nil {
+		t.Fatalf("StoreToCache failed: %v", err)
+	}
+	if dirExists(filepath.Join(entries[0].CachePath, "release")) {
+		t.Error("release profile should not be cached")
+	}
+
+	if err := os.RemoveAll(filepath.Join(envPath, "target", "debug")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.RestoreFromCache(entries[0], nil); err != nil {
+		t.Fatalf("RestoreFromCache failed: %v", err)
+	}
+
+	if fileExists(filepath.Join(envPath, "target", "debug", "deps", "demo-1.o")) {
+		t.Error("cargo skip rules should apply to a profile path")
+	}
+	for _, f := range []string{"target/debug/.fingerprint/demo-1/dep-bin-demo", "target/debug/deps/demo-1"} {
+		info, err := os.Stat(filepath.Join(envPath, f))
+		if err != nil {
+			t.Fatalf("%s not restored: %v", f, err)
+		}
+		if !info.ModTime().After(old) {
+			t.Errorf("expected %s to be touched after restore", f)
+		}
+	}
+}
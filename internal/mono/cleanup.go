@@ -0,0 +1,276 @@
+package mono
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+type tempDirMarker struct {
+	PID  int    `json:"pid"`
+	Path string `json:"path"`
+}
+
+type CleanupReport struct {
+	Locks        int
+	TempDirs     int
+	ComposeFiles int
+}
+
+func (r CleanupReport) Total() int {
+	return r.Locks + r.TempDirs + r.ComposeFiles
+}
+
+func tempMarkerDir() (string, error) {
+	home, err := GetMonoHome()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, "tmp"), nil
+}
+
+func trackTempDir(path string) (func() error, error) {
+	dir, err := tempMarkerDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temp marker directory: %w", err)
+	}
+
+	h := sha256.Sum256([]byte(path))
+	markerPath := filepath.Join(dir, fmt.Sprintf("%d-%s.json", os.Getpid(), hex.EncodeToString(h[:])[:12]))
+
+	data, err := json.Marshal(tempDirMarker{PID: os.Getpid(), Path: path})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode temp marker: %w", err)
+	}
+	if err := os.WriteFile(markerPath, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write temp marker: %w", err)
+	}
+
+	return func() error {
+		if err := os.Remove(markerPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove temp marker: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+func CleanupStale() (*CleanupReport, error) {
+	report := &CleanupReport{}
+
+	if err := cleanupTempMarkers(report); err != nil {
+		return report, err
+	}
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		return report, err
+	}
+	if err := cm.cleanupStaleEntries(report); err != nil {
+		return report, err
+	}
+
+	home, err := GetMonoHome()
+	if err != nil {
+		return report, err
+	}
+	if err := cleanupStaleLocks(filepath.Join(home, "locks"), report); err != nil {
+		return report, err
+	}
+
+	if err := cleanupOrphanComposeFiles(report); err != nil {
+		return report, err
+	}
+
+	if report.Total() > 0 {
+		logger, err := NewFileLogger("cleanup")
+		if err != nil {
+			return report, err
+		}
+		defer logger.Close()
+		logger.Log("removed stale state: %d locks, %d temp dirs, %d compose overrides", report.Locks, report.TempDirs, report.ComposeFiles)
+	}
+
+	return report, nil
+}
+
+func cleanupTempMarkers(report *CleanupReport) error {
+	dir, err := tempMarkerDir()
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read temp markers: %w", err)
+	}
+
+	for _, e := range entries {
+		markerPath := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(markerPath)
+		if err != nil {
+			return fmt.Errorf("failed to read temp marker: %w", err)
+		}
+
+		var marker tempDirMarker
+		if err := json.Unmarshal(data, &marker); err != nil {
+			if err := os.Remove(markerPath); err != nil {
+				return fmt.Errorf("failed to remove corrupt temp marker: %w", err)
+			}
+			continue
+		}
+
+		if processAlive(marker.PID) {
+			continue
+		}
+
+		if strings.HasSuffix(marker.Path, cacheTmpSuffix) {
+			if err := os.RemoveAll(marker.Path); err != nil {
+				return fmt.Errorf("failed to remove stale temp dir %s: %w", marker.Path, err)
+			}
+			report.TempDirs++
+		}
+		if err := os.Remove(markerPath); err != nil {
+			return fmt.Errorf("failed to remove temp marker: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (cm *CacheManager) cleanupStaleEntries(report *CleanupReport) error {
+	artifactDirs, err := filepath.Glob(filepath.Join(cm.LocalCacheDir, "*", "*"))
+	if err != nil {
+		return err
+	}
+
+	for _, artifactDir := range artifactDirs {
+		entries, err := os.ReadDir(artifactDir)
+		if os.IsNotExist(err) || errors.Is(err, syscall.ENOTDIR) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", artifactDir, err)
+		}
+
+		for _, e := range entries {
+			name := e.Name()
+			if !e.IsDir() || !strings.HasSuffix(name, cacheTmpSuffix) {
+				continue
+			}
+
+			cachePath := filepath.Join(artifactDir, strings.TrimSuffix(name, cacheTmpSuffix))
+			lock, err := cm.acquireCacheLock(cachePath)
+			if err != nil {
+				return err
+			}
+			if lock == nil {
+				continue
+			}
+
+			err = os.RemoveAll(filepath.Join(artifactDir, name))
+			cm.releaseCacheLock(lock)
+			if err != nil {
+				return fmt.Errorf("failed to remove stale staging dir: %w", err)
+			}
+			report.TempDirs++
+		}
+
+		if err := cleanupStaleLocks(artifactDir, report); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func cleanupStaleLocks(dir string, report *CleanupReport) error {
+	locks, err := filepath.Glob(filepath.Join(dir, "*.lock"))
+	if err != nil {
+		return err
+	}
+
+	for _, lockPath := range locks {
+		removed, err := removeUnheldLock(lockPath)
+		if err != nil {
+			return err
+		}
+		if removed {
+			report.Locks++
+		}
+	}
+
+	return nil
+}
+
+func removeUnheldLock(lockPath string) (bool, error) {
+	f, err := os.OpenFile(lockPath, os.O_RDWR, 0644)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+
+	if holder, err := readLockHolder(lockPath); err == nil && holder.PID != os.Getpid() && processAlive(holder.PID) {
+		return false, nil
+	}
+
+	if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to remove stale lock %s: %w", lockPath, err)
+	}
+	return true, nil
+}
+
+func cleanupOrphanComposeFiles(report *CleanupReport) error {
+	db, err := OpenDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	orphans, err := db.ListOrphanComposeOverrides()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range orphans {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove orphaned compose override %s: %w", path, err)
+		}
+		if err := db.DeleteComposeOverride(path); err != nil {
+			return err
+		}
+		report.ComposeFiles++
+	}
+
+	return nil
+}
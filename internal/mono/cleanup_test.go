@@ -0,0 +1,59 @@
+package mono
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanupStaleRemovesAbandonedState(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+
+	artifactDir := filepath.Join(cm.LocalCacheDir, "project", "cargo")
+	stagingDir := filepath.Join(artifactDir, "abc123"+cacheTmpSuffix)
+	if err := os.MkdirAll(stagingDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	staleLock := filepath.Join(artifactDir, "abc123.lock")
+	if err := os.WriteFile(staleLock, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	held, err := AcquireEnvLock(context.Background(), "held", "init", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("failed to acquire lock: %v", err)
+	}
+	defer held.Release()
+
+	report, err := CleanupStale()
+	if err != nil {
+		t.Fatalf("cleanup failed: %v", err)
+	}
+
+	if _, err := os.Stat(stagingDir); !os.IsNotExist(err) {
+		t.Errorf("expected staging dir to be removed, got %v", err)
+	}
+	if _, err := os.Stat(staleLock); !os.IsNotExist(err) {
+		t.Errorf("expected stale lock to be removed, got %v", err)
+	}
+
+	heldPath, err := envLockPath("held")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(heldPath); err != nil {
+		t.Errorf("expected held lock to survive cleanup: %v", err)
+	}
+
+	if report.TempDirs != 1 {
+		t.Errorf("expected 1 temp dir removed, got %d", report.TempDirs)
+	}
+}
@@ -15,7 +15,7 @@ func Command(name string, args ...string) *Cmd {
 	return &Cmd{
 		name:    name,
 		args:    args,
-		timeout: DefaultTimeout,
+		timeout: timeouts().Command,
 	}
 }
 
@@ -6,15 +6,87 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type ArtifactConfig struct {
-	Name        string   `yaml:"name"`
-	KeyFiles    []string `yaml:"key_files"`
-	KeyCommands []string `yaml:"key_commands"`
-	Paths       []string `yaml:"paths"`
+	Name            string         `yaml:"name"`
+	KeyFiles        []string       `yaml:"key_files"`
+	KeyCommands     []string       `yaml:"key_commands"`
+	Paths           []ArtifactPath `yaml:"paths"`
+	WarmCommand     string         `yaml:"warm_command"`
+	Shared          bool           `yaml:"shared"`
+	Format          string         `yaml:"format"`
+	KeyStrategy     string         `yaml:"key_strategy"`
+	KeySalt         string         `yaml:"key_salt"`
+	RestoreFallback bool           `yaml:"restore_fallback"`
+	PostRestore     string         `yaml:"post_restore"`
+	VerifyLockfile  bool           `yaml:"verify_lockfile"`
+	Workers         int            `yaml:"workers"`
+	IOLimit         string         `yaml:"io_limit"`
+	Include         []string       `yaml:"include"`
+	Exclude         []string       `yaml:"exclude"`
+	SkipPatterns    []string       `yaml:"skip_patterns"`
+	BuildLockFile   string         `yaml:"build_lock_file"`
+}
+
+type ArtifactPath struct {
+	Path string `yaml:"path"`
+	Type string `yaml:"type"`
+}
+
+func (p *ArtifactPath) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		p.Path = value.Value
+		return nil
+	}
+	type plain ArtifactPath
+	return value.Decode((*plain)(p))
+}
+
+func (a ArtifactConfig) ioLimit() (int64, error) {
+	if a.IOLimit == "" {
+		return 0, nil
+	}
+	rate, err := parseByteRate(a.IOLimit)
+	if err != nil {
+		return 0, fmt.Errorf("artifact %s io_limit: %w", a.Name, err)
+	}
+	return rate, nil
+}
+
+func (p ArtifactPath) resolve(base string) string {
+	if filepath.IsAbs(p.Path) {
+		return p.Path
+	}
+	return filepath.Join(base, p.Path)
+}
+
+func (a ArtifactConfig) PathType(p ArtifactPath) string {
+	if p.Type != "" {
+		return p.Type
+	}
+	return a.Name
+}
+
+func validateArtifactPaths(artifact ArtifactConfig) error {
+	bases := make(map[string]string)
+	for _, p := range artifact.Paths {
+		if strings.TrimSpace(p.Path) == "" {
+			return fmt.Errorf("artifact %s has an empty path", artifact.Name)
+		}
+		if !validPathType(p.Type) {
+			return fmt.Errorf("artifact %s path %s has unknown type %q (use cargo, npm, yarn, pnpm, bun, gomod, gobuild, poetry, pip, uv, gradle, maven, bundler, turbo, nx or plain)", artifact.Name, p.Path, p.Type)
+		}
+		base := filepath.Base(p.Path)
+		if other, ok := bases[base]; ok {
+			return fmt.Errorf("artifact %s paths %s and %s share the directory name %s", artifact.Name, other, p.Path, base)
+		}
+		bases[base] = p.Path
+	}
+	return nil
 }
 
 type BuildConfig struct {
@@ -23,11 +95,16 @@ type BuildConfig struct {
 }
 
 type Config struct {
-	Scripts    Scripts           `yaml:"scripts"`
-	Build      BuildConfig       `yaml:"build"`
-	Env        map[string]string `yaml:"env"`
-	ComposeDir string            `yaml:"compose_dir"`
-	Tmux       TmuxConfig        `yaml:"tmux"`
+	Scripts    Scripts                  `yaml:"scripts"`
+	Build      BuildConfig              `yaml:"build"`
+	Env        map[string]string        `yaml:"env"`
+	ComposeDir string                   `yaml:"compose_dir"`
+	Tmux       TmuxConfig               `yaml:"tmux"`
+	Nix        NixConfig                `yaml:"nix"`
+	Docker     DockerConfig             `yaml:"docker"`
+	Processes  map[string]ProcessConfig `yaml:"processes"`
+	WaitFor    []WaitForConfig          `yaml:"wait_for"`
+	Destroy    DestroyConfig            `yaml:"destroy"`
 }
 
 type Scripts struct {
@@ -42,7 +119,8 @@ type TmuxRunConfig struct {
 }
 
 type TmuxConfig struct {
-	Run TmuxRunConfig `yaml:"run"`
+	Run           TmuxRunConfig `yaml:"run"`
+	ScriptsWindow bool          `yaml:"scripts_window"`
 }
 
 func (tc *TmuxConfig) ApplyDefaults() {
@@ -51,21 +129,72 @@ func (tc *TmuxConfig) ApplyDefaults() {
 	}
 }
 
+type DestroyConfig struct {
+	Sync        *bool         `yaml:"sync"`
+	SyncTimeout time.Duration `yaml:"sync_timeout"`
+}
+
+func (d DestroyConfig) syncEnabled() bool {
+	return d.Sync == nil || *d.Sync
+}
+
 func LoadConfig(dir string) (*Config, error) {
 	path := filepath.Join(dir, "mono.yml")
 
+	var cfg Config
 	data, err := os.ReadFile(path)
-	if os.IsNotExist(err) {
-		return &Config{}, nil
-	}
-	if err != nil {
+	if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to read mono.yml: %w", err)
 	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("invalid mono.yml: %w", err)
+		}
+	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	for _, artifact := range cfg.Build.Artifacts {
+		if !validArtifactFormat(artifact.Format) {
+			return nil, fmt.Errorf("invalid mono.yml: artifact %s has unknown format %q (use dir, tar, tar.gz or tar.zst)", artifact.Name, artifact.Format)
+		}
+		if !validKeyStrategy(artifact.KeyStrategy) {
+			return nil, fmt.Errorf("invalid mono.yml: artifact %s has unknown key_strategy %q (use content or stat)", artifact.Name, artifact.KeyStrategy)
+		}
+		if err := validateArtifactPaths(artifact); err != nil {
+			return nil, fmt.Errorf("invalid mono.yml: %w", err)
+		}
+		if artifact.Workers < 0 {
+			return nil, fmt.Errorf("invalid mono.yml: artifact %s has negative workers %d", artifact.Name, artifact.Workers)
+		}
+		if _, err := artifact.ioLimit(); err != nil {
+			return nil, fmt.Errorf("invalid mono.yml: %w", err)
+		}
+		if err := artifact.validateGlobs(); err != nil {
+			return nil, fmt.Errorf("invalid mono.yml: %w", err)
+		}
+		if lock := artifact.BuildLockFile; lock != "" && (filepath.IsAbs(lock) || !filepath.IsLocal(lock)) {
+			return nil, fmt.Errorf("invalid mono.yml: artifact %s build_lock_file %q must be relative to its paths", artifact.Name, lock)
+		}
+	}
+
+	if err := validateProcesses(cfg.Processes); err != nil {
+		return nil, fmt.Errorf("invalid mono.yml: %w", err)
+	}
+	if err := validateWaitFor(cfg.WaitFor); err != nil {
 		return nil, fmt.Errorf("invalid mono.yml: %w", err)
 	}
+	if cfg.Destroy.SyncTimeout < 0 {
+		return nil, fmt.Errorf("invalid mono.yml: destroy.sync_timeout must not be negative")
+	}
+	if len(cfg.Processes) == 0 {
+		processes, err := LoadProcfile(dir)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateProcesses(processes); err != nil {
+			return nil, fmt.Errorf("invalid Procfile: %w", err)
+		}
+		cfg.Processes = processes
+	}
 
 	return &cfg, nil
 }
@@ -74,7 +203,15 @@ func (c *Config) ApplyDefaults(envPath string) {
 	if len(c.Build.Artifacts) == 0 {
 		c.Build.Artifacts = detectArtifacts(envPath)
 	}
+	c.Nix.applyKeyFiles(c.Build.Artifacts, envPath)
 	c.Tmux.ApplyDefaults()
+	for name, proc := range c.Processes {
+		proc.applyDefaults()
+		c.Processes[name] = proc
+	}
+	for i := range c.WaitFor {
+		c.WaitFor[i].applyDefaults()
+	}
 }
 
 func (c *Config) ResolveComposeDir(basePath string) string {
@@ -89,15 +226,26 @@ type lockFileSpec struct {
 	artifactDir string
 	keyCommand  string
 	baseType    string
+	warmCommand string
 }
 
 var lockFileSpecs = []lockFileSpec{
-	{"Cargo.lock", "target", "rustc --version", "cargo"},
-	{"package-lock.json", "node_modules", "node --version", "npm"},
-	{"yarn.lock", "node_modules", "node --version", "yarn"},
-	{"pnpm-lock.yaml", "node_modules", "node --version", "pnpm"},
-	{"bun.lock", "node_modules", "bun --version", "bun"},
-	{"bun.lockb", "node_modules", "bun --version", "bun"},
+	{"Cargo.lock", "target", "rustc --version", "cargo", "cargo build"},
+	{"package-lock.json", "node_modules", "node --version", "npm", "npm ci"},
+	{"yarn.lock", "node_modules", "node --version", "yarn", "yarn install --frozen-lockfile"},
+	{"pnpm-lock.yaml", "node_modules", "node --version", "pnpm", "pnpm install --frozen-lockfile"},
+	{"bun.lock", "node_modules", "bun --version", "bun", "bun install --frozen-lockfile"},
+	{"bun.lockb", "node_modules", "bun --version", "bun", "bun install --frozen-lockfile"},
+	{"go.mod", goCacheDir, "go version", "go", "go mod download && go build ./..."},
+	{"go.sum", goCacheDir, "go version", "go", ""},
+	{"poetry.lock", venvDir, pythonVersionCommand, "poetry", "poetry install"},
+	{"uv.lock", venvDir, pythonVersionCommand, "uv", "uv sync --frozen"},
+	{"gradle.lockfile", "build", jvmVersionCommand, "gradle", "if [ -x ./gradlew ]; then ./gradlew assemble; else gradle assemble; fi"},
+	{"pom.xml", "target", jvmVersionCommand, "maven", "mvn -B package -DskipTests"},
+	{"Gemfile.lock", bundlePath, "ruby --version", "bundler", "bundle install"},
+	{"turbo.json", ".turbo", "node --version", "turbo", ""},
+	{"nx.json", filepath.Join(".nx", "cache"), "node --version", "nx", ""},
+	{"requirements.txt", venvDir, pythonVersionCommand, "pip", "python3 -m venv .venv && .venv/bin/pip install -r requirements.txt"},
 }
 
 var skipDirs = map[string]bool{
@@ -109,19 +257,43 @@ var skipDirs = map[string]bool{
 	"build":        true,
 	".next":        true,
 	".nuxt":        true,
+	goCacheDir:     true,
+	venvDir:        true,
+	gradleCacheDir: true,
+	".turbo":       true,
+	".nx":          true,
+	"__pycache__":  true,
 }
 
 func detectArtifacts(envPath string) []ArtifactConfig {
 	var artifacts []ArtifactConfig
 	lockFiles := findLockFiles(envPath)
 
-	seen := make(map[string]bool)
+	seen := make(map[string]int)
+	venvs := make(map[string]bool)
 	for _, lf := range lockFiles {
-		cfg := lf.toArtifactConfig()
-		if seen[cfg.Name] {
+		cfg := lf.toArtifactConfig(envPath)
+		if isJVMBaseType(lf.spec.baseType) {
+			if root, ok := jvmModuleRoot(lf, lockFiles); ok {
+				cfg = jvmModuleArtifact(cfg, root, lf)
+			}
+		}
+		if isTaskCacheType(lf.spec.baseType) {
+			cfg = addTaskCacheLockFiles(cfg, lf, lockFiles)
+		}
+		if lf.spec.artifactDir == venvDir {
+			if venvs[cfg.Paths[0].Path] {
+				continue
+			}
+			venvs[cfg.Paths[0].Path] = true
+		}
+		if i, ok := seen[cfg.Name]; ok {
+			if lf.spec.baseType == "go" {
+				artifacts[i] = mergeGoArtifact(artifacts[i], cfg)
+			}
 			continue
 		}
-		seen[cfg.Name] = true
+		seen[cfg.Name] = len(artifacts)
 		artifacts = append(artifacts, cfg)
 	}
 
@@ -133,21 +305,43 @@ type foundLockFile struct {
 	spec     lockFileSpec
 }
 
-func (f foundLockFile) toArtifactConfig() ArtifactConfig {
+func (f foundLockFile) toArtifactConfig(envPath string) ArtifactConfig {
 	dir := filepath.Dir(f.relPath)
 	name := f.spec.baseType
 	artifactPath := f.spec.artifactDir
+	warmCommand := f.spec.warmCommand
 
 	if dir != "." {
 		name = f.spec.baseType + "-" + sanitizeName(dir)
 		artifactPath = filepath.Join(dir, f.spec.artifactDir)
+		if warmCommand != "" {
+			warmCommand = fmt.Sprintf("cd %q && %s", dir, f.spec.warmCommand)
+		}
+	}
+
+	if f.spec.baseType == "go" {
+		return goArtifact(f.relPath, f.spec.warmCommand)
+	}
+
+	if f.spec.baseType == "gradle" {
+		return gradleArtifact(name, f.relPath, warmCommand)
+	}
+
+	if f.spec.baseType == "cargo" {
+		targetDir := cargoTargetDir(filepath.Join(envPath, dir))
+		if rel, err := filepath.Rel(envPath, targetDir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			artifactPath = rel
+		} else {
+			artifactPath = targetDir
+		}
 	}
 
 	return ArtifactConfig{
 		Name:        name,
 		KeyFiles:    []string{f.relPath},
 		KeyCommands: []string{f.spec.keyCommand},
-		Paths:       []string{artifactPath},
+		Paths:       []ArtifactPath{{Path: artifactPath, Type: f.spec.baseType}},
+		WarmCommand: warmCommand,
 	}
 }
 
@@ -164,25 +358,31 @@ func findLockFiles(envPath string) []foundLockFile {
 		specMap[spec.filename] = spec
 	}
 
+	var rules ignoreRules
 	filepath.WalkDir(envPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
 
+		relPath, err := filepath.Rel(envPath, path)
+		if err != nil {
+			return nil
+		}
+
 		if d.IsDir() {
-			if skipDirs[d.Name()] {
+			if relPath == "." {
+				relPath = ""
+			} else if skipDirs[d.Name()] || rules.ignored(relPath, true) {
+				return filepath.SkipDir
+			}
+			if err := rules.load(path, filepath.ToSlash(relPath)); err != nil {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
 		spec, ok := specMap[d.Name()]
-		if !ok {
-			return nil
-		}
-
-		relPath, err := filepath.Rel(envPath, path)
-		if err != nil {
+		if !ok || rules.ignored(relPath, false) {
 			return nil
 		}
 
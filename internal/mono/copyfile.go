@@ -0,0 +1,196 @@
+package mono
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+func copyFile(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		if err := copySymlink(src, dst); err != nil {
+			return err
+		}
+	case info.Mode()&os.ModeNamedPipe != 0:
+		if err := unix.Mkfifo(dst, uint32(info.Mode().Perm())); err != nil {
+			return err
+		}
+	case !info.Mode().IsRegular():
+		return nil
+	default:
+		if err := copyRegularFile(src, dst, info); err != nil {
+			return err
+		}
+	}
+	return copyMetadata(src, dst, info)
+}
+
+func copyMetadata(src, dst string, info fs.FileInfo) error {
+	if info.Mode()&os.ModeSymlink == 0 {
+		if err := os.Chmod(dst, info.Mode()&(fs.ModePerm|fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky)); err != nil {
+			return err
+		}
+	}
+	if err := copyXattrs(src, dst); err != nil {
+		return err
+	}
+	mtime := unix.NsecToTimespec(info.ModTime().UnixNano())
+	return unix.UtimesNanoAt(unix.AT_FDCWD, dst, []unix.Timespec{mtime, mtime}, unix.AT_SYMLINK_NOFOLLOW)
+}
+
+type copiedDir struct {
+	src  string
+	dst  string
+	info fs.FileInfo
+}
+
+func copyDirMetadata(dirs []copiedDir) error {
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := copyMetadata(dirs[i].src, dirs[i].dst, dirs[i].info); err != nil {
+			return fmt.Errorf("failed to copy metadata of %s: %w", dirs[i].src, err)
+		}
+	}
+	return nil
+}
+
+func copySymlink(src, dst string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(target, dst)
+}
+
+func copyRegularFile(src, dst string, info fs.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if err := copyContents(out, in, info); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func copyContents(out, in *os.File, info fs.FileInfo) error {
+	if cloneContents(out, in) == nil {
+		return nil
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || int64(stat.Blocks)*statBlockSize >= info.Size() {
+		_, err := io.Copy(out, in)
+		return err
+	}
+	return copySparse(out, in, info.Size())
+}
+
+func copySparse(out, in *os.File, size int64) error {
+	var off int64
+	for off < size {
+		data, err := in.Seek(off, unix.SEEK_DATA)
+		if errors.Is(err, syscall.ENXIO) {
+			break
+		}
+		if errors.Is(err, syscall.EINVAL) && off == 0 {
+			_, err := io.Copy(out, in)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		hole, err := in.Seek(data, unix.SEEK_HOLE)
+		if err != nil {
+			return err
+		}
+		if _, err := in.Seek(data, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := out.Seek(data, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(out, in, hole-data); err != nil {
+			return err
+		}
+		off = hole
+	}
+	return out.Truncate(size)
+}
+
+func copyXattrs(src, dst string) error {
+	names, err := listXattrs(src)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		size, err := unix.Lgetxattr(src, name, nil)
+		if err != nil {
+			return err
+		}
+		value := make([]byte, size)
+		if size > 0 {
+			if size, err = unix.Lgetxattr(src, name, value); err != nil {
+				return err
+			}
+		}
+		err = unix.Lsetxattr(dst, name, value[:size], 0)
+		if errors.Is(err, unix.EPERM) || errors.Is(err, unix.ENOTSUP) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func listXattrs(path string) ([]string, error) {
+	size, err := unix.Llistxattr(path, nil)
+	if errors.Is(err, unix.ENOTSUP) || size == 0 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, size)
+	size, err = unix.Llistxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	start := 0
+	for i := 0; i < size; i++ {
+		if buf[i] == 0 {
+			if i > start {
+				names = append(names, string(buf[start:i]))
+			}
+			start = i + 1
+		}
+	}
+	return names, nil
+}
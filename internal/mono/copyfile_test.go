@@ -0,0 +1,189 @@
+package mono
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestCopyFilePreservesSparseness(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "sparse.bin")
+
+	f, err := os.Create(src)
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	const size = 64 << 20
+	if _, err := f.WriteAt([]byte("head"), 0); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	if _, err := f.WriteAt([]byte("tail"), size-4); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		t.Fatalf("failed to stat: %v", err)
+	}
+	if srcInfo.Sys().(*syscall.Stat_t).Blocks*statBlockSize >= size {
+		t.Skip("filesystem does not support sparse files")
+	}
+
+	dst := filepath.Join(dir, "copy.bin")
+	if err := copyFile(src, dst); err != nil {
+		t.Fatalf("copyFile failed: %v", err)
+	}
+
+	dstInfo, err := os.Stat(dst)
+	if err != nil {
+		t.Fatalf("failed to stat copy: %v", err)
+	}
+	if dstInfo.Size() != size {
+		t.Errorf("size mismatch: got %d, want %d", dstInfo.Size(), size)
+	}
+	if blocks := dstInfo.Sys().(*syscall.Stat_t).Blocks * statBlockSize; blocks >= size/2 {
+		t.Errorf("copy should stay sparse, uses %d bytes on disk", blocks)
+	}
+
+	srcSum, err := fileSHA256(src)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	dstSum, err := fileSHA256(dst)
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+	if srcSum != dstSum {
+		t.Error("sparse copy content differs from source")
+	}
+}
+
+func TestCopyFilePreservesMetadata(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	if err := os.WriteFile(src, []byte("content"), 0750); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	mtime := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(src, mtime, mtime); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+
+	xattrs := true
+	if err := unix.Lsetxattr(src, "user.mono.test", []byte("value"), 0); err != nil {
+		if !errors.Is(err, unix.ENOTSUP) && !errors.Is(err, unix.EPERM) {
+			t.Fatalf("failed to set xattr: %v", err)
+		}
+		xattrs = false
+	}
+
+	dst := filepath.Join(dir, "dst.txt")
+	if err := copyFile(src, dst); err != nil {
+		t.Fatalf("copyFile failed: %v", err)
+	}
+
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatalf("failed to stat copy: %v", err)
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("mtime not preserved: got %v, want %v", info.ModTime(), mtime)
+	}
+	if info.Mode().Perm() != 0750 {
+		t.Errorf("mode not preserved: %v", info.Mode())
+	}
+
+	if xattrs {
+		buf := make([]byte, 16)
+		n, err := unix.Lgetxattr(dst, "user.mono.test", buf)
+		if err != nil {
+			t.Fatalf("xattr not copied: %v", err)
+		}
+		if string(buf[:n]) != "value" {
+			t.Errorf("xattr mismatch: %q", buf[:n])
+		}
+	}
+
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink("src.txt", link); err != nil {
+		t.Fatalf("failed to symlink: %v", err)
+	}
+	linkCopy := filepath.Join(dir, "link-copy")
+	if err := copyFile(link, linkCopy); err != nil {
+		t.Fatalf("copyFile on symlink failed: %v", err)
+	}
+	target, err := os.Readlink(linkCopy)
+	if err != nil {
+		t.Fatalf("copy of symlink should be a symlink: %v", err)
+	}
+	if target != "src.txt" {
+		t.Errorf("symlink target mismatch: %s", target)
+	}
+}
+
+func TestCopyTreePreservesDirectoryMetadata(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "target")
+	fingerprint := filepath.Join(src, "debug", ".fingerprint", "app-1234")
+	if err := os.MkdirAll(fingerprint, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(fingerprint, "lib-app"), []byte("hash"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("lib-app", filepath.Join(fingerprint, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(src, "debug"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	mtime := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	linkTime := unix.NsecToTimespec(mtime.UnixNano())
+	if err := unix.UtimesNanoAt(unix.AT_FDCWD, filepath.Join(fingerprint, "link"), []unix.Timespec{linkTime, linkTime}, unix.AT_SYMLINK_NOFOLLOW); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{fingerprint, filepath.Join(src, "debug", ".fingerprint"), filepath.Join(src, "debug"), src} {
+		if err := os.Chtimes(dir, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	copies := map[string]func(dst string) error{
+		"copyDir": func(dst string) error { return copyDir(src, dst) },
+		"SeedDirectory": func(dst string) error {
+			return SeedDirectory(src, dst, SeedOptions{ArtifactName: "cargo", Link: LinkCopy})
+		},
+	}
+	for name, copyTree := range copies {
+		dst := filepath.Join(t.TempDir(), "target")
+		if err := copyTree(dst); err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		for _, rel := range []string{".", "debug", "debug/.fingerprint", "debug/.fingerprint/app-1234", "debug/.fingerprint/app-1234/link"} {
+			info, err := os.Lstat(filepath.Join(dst, filepath.FromSlash(rel)))
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !info.ModTime().Equal(mtime) {
+				t.Errorf("%s: mtime of %s not preserved: got %v, want %v", name, rel, info.ModTime(), mtime)
+			}
+		}
+		info, err := os.Stat(filepath.Join(dst, "debug"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0700 {
+			t.Errorf("%s: directory mode not preserved: %v", name, info.Mode())
+		}
+	}
+}
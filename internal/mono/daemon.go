@@ -0,0 +1,219 @@
+package mono
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type DaemonConfig struct {
+	Interval time.Duration `yaml:"interval"`
+}
+
+type DaemonHeartbeat struct {
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+	LastTick  time.Time `json:"last_tick"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+type DaemonHealth struct {
+	ServicePath string
+	Installed   bool
+	Loaded      bool
+	Heartbeat   *DaemonHeartbeat
+	Running     bool
+	Stale       bool
+}
+
+func (h *DaemonHealth) Healthy() bool {
+	return h.Installed && h.Loaded && h.Running && !h.Stale
+}
+
+func daemonHeartbeatPath() (string, error) {
+	home, err := GetMonoHome()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, "daemon.json"), nil
+}
+
+func DaemonLogPath() (string, error) {
+	home, err := GetMonoHome()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, "daemon.log"), nil
+}
+
+func writeDaemonHeartbeat(hb DaemonHeartbeat) error {
+	path, err := daemonHeartbeatPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create mono home: %w", err)
+	}
+	data, err := json.Marshal(hb)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write daemon heartbeat: %w", err)
+	}
+	return nil
+}
+
+func readDaemonHeartbeat() (*DaemonHeartbeat, error) {
+	path, err := daemonHeartbeatPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read daemon heartbeat: %w", err)
+	}
+	var hb DaemonHeartbeat
+	if err := json.Unmarshal(data, &hb); err != nil {
+		return nil, fmt.Errorf("invalid daemon heartbeat: %w", err)
+	}
+	return &hb, nil
+}
+
+func RunDaemon(ctx context.Context) error {
+	interval := globalConfig().Daemon.Interval
+
+	logger, err := NewFileLogger("daemon")
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer logger.Close()
+
+	logger.Log("daemon started (pid %d, interval %s)", os.Getpid(), interval)
+
+	hb := DaemonHeartbeat{PID: os.Getpid(), StartedAt: time.Now().UTC()}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		hb.LastTick = time.Now().UTC()
+		hb.LastError = ""
+		if _, err := CleanupStale(); err != nil {
+			hb.LastError = err.Error()
+			logger.Log("warning: cleanup failed: %v", err)
+		}
+		if err := writeDaemonHeartbeat(hb); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Log("daemon stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func InstallDaemon() (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate mono executable: %w", err)
+	}
+	executable, err = filepath.EvalSymlinks(executable)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve mono executable: %w", err)
+	}
+
+	path, err := daemonServicePath()
+	if err != nil {
+		return "", err
+	}
+	logPath, err := DaemonLogPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create mono home: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create service directory: %w", err)
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if err := unloadDaemonService(path); err != nil {
+			return "", err
+		}
+	}
+	var monoHome string
+	if os.Getenv("MONO_HOME") != "" {
+		monoHome, err = GetMonoHome()
+		if err != nil {
+			return "", err
+		}
+	}
+	if err := os.WriteFile(path, []byte(renderDaemonService(executable, logPath, monoHome)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write service file: %w", err)
+	}
+	if err := loadDaemonService(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func UninstallDaemon() (string, bool, error) {
+	path, err := daemonServicePath()
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path, false, nil
+	}
+
+	if err := unloadDaemonService(path); err != nil {
+		return "", false, err
+	}
+	if err := os.Remove(path); err != nil {
+		return "", false, fmt.Errorf("failed to remove service file: %w", err)
+	}
+	return path, true, nil
+}
+
+func CheckDaemon() (*DaemonHealth, error) {
+	path, err := daemonServicePath()
+	if err != nil {
+		return nil, err
+	}
+
+	health := &DaemonHealth{ServicePath: path}
+	if _, err := os.Stat(path); err == nil {
+		health.Installed = true
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to stat service file: %w", err)
+	}
+
+	if health.Installed {
+		loaded, err := daemonServiceLoaded()
+		if err != nil {
+			return nil, err
+		}
+		health.Loaded = loaded
+	}
+
+	hb, err := readDaemonHeartbeat()
+	if err != nil {
+		return nil, err
+	}
+	health.Heartbeat = hb
+	if hb != nil {
+		health.Running = processAlive(hb.PID)
+		health.Stale = time.Since(hb.LastTick) > 3*globalConfig().Daemon.Interval
+	}
+	return health, nil
+}
@@ -0,0 +1,76 @@
+package mono
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const daemonLabel = "dev.mono.daemon"
+
+func daemonServicePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", daemonLabel+".plist"), nil
+}
+
+func renderDaemonService(executable, logPath, monoHome string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString(`<plist version="1.0">` + "\n<dict>\n")
+	fmt.Fprintf(&b, "  <key>Label</key>\n  <string>%s</string>\n", daemonLabel)
+	b.WriteString("  <key>ProgramArguments</key>\n  <array>\n")
+	fmt.Fprintf(&b, "    <string>%s</string>\n    <string>daemon</string>\n    <string>run</string>\n", html.EscapeString(executable))
+	b.WriteString("  </array>\n")
+	if monoHome != "" {
+		b.WriteString("  <key>EnvironmentVariables</key>\n  <dict>\n")
+		fmt.Fprintf(&b, "    <key>MONO_HOME</key>\n    <string>%s</string>\n", html.EscapeString(monoHome))
+		b.WriteString("  </dict>\n")
+	}
+	b.WriteString("  <key>RunAtLoad</key>\n  <true/>\n")
+	b.WriteString("  <key>KeepAlive</key>\n  <true/>\n")
+	fmt.Fprintf(&b, "  <key>StandardOutPath</key>\n  <string>%s</string>\n", html.EscapeString(logPath))
+	fmt.Fprintf(&b, "  <key>StandardErrorPath</key>\n  <string>%s</string>\n", html.EscapeString(logPath))
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+func launchdDomain() string {
+	return fmt.Sprintf("gui/%d", os.Getuid())
+}
+
+func launchctl(args ...string) error {
+	output, err := Command("launchctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("launchctl %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func loadDaemonService(path string) error {
+	return launchctl("bootstrap", launchdDomain(), path)
+}
+
+func unloadDaemonService(path string) error {
+	loaded, err := daemonServiceLoaded()
+	if err != nil {
+		return err
+	}
+	if !loaded {
+		return nil
+	}
+	return launchctl("bootout", launchdDomain()+"/"+daemonLabel)
+}
+
+func daemonServiceLoaded() (bool, error) {
+	result, err := Command("launchctl", "print", launchdDomain()+"/"+daemonLabel).RunCapture()
+	if err != nil {
+		return false, fmt.Errorf("failed to query %s: %w", daemonLabel, err)
+	}
+	return result.ExitCode == 0, nil
+}
@@ -0,0 +1,70 @@
+package mono
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const daemonUnit = "mono-daemon.service"
+
+func daemonServicePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, "systemd", "user", daemonUnit), nil
+}
+
+func renderDaemonService(executable, logPath, monoHome string) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=mono daemon\n\n")
+	b.WriteString("[Service]\n")
+	fmt.Fprintf(&b, "ExecStart=%s daemon run\n", systemdQuote(executable))
+	if monoHome != "" {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote("MONO_HOME="+monoHome))
+	}
+	b.WriteString("Restart=always\n")
+	b.WriteString("RestartSec=5\n")
+	fmt.Fprintf(&b, "StandardOutput=append:%s\n", logPath)
+	fmt.Fprintf(&b, "StandardError=append:%s\n\n", logPath)
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+func systemdQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%").Replace(s) + `"`
+}
+
+func systemctl(args ...string) error {
+	output, err := Command("systemctl", append([]string{"--user"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl --user %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func loadDaemonService(path string) error {
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl("enable", "--now", daemonUnit)
+}
+
+func unloadDaemonService(path string) error {
+	if err := systemctl("disable", "--now", daemonUnit); err != nil {
+		return err
+	}
+	return systemctl("daemon-reload")
+}
+
+func daemonServiceLoaded() (bool, error) {
+	result, err := Command("systemctl", "--user", "is-active", "--quiet", daemonUnit).RunCapture()
+	if err != nil {
+		return false, fmt.Errorf("failed to query %s: %w", daemonUnit, err)
+	}
+	return result.ExitCode == 0, nil
+}
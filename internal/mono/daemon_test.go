@@ -0,0 +1,84 @@
+package mono
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunDaemonWritesHeartbeat(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	cfg := DefaultGlobalConfig()
+	cfg.Daemon.Interval = 10 * time.Millisecond
+	SetGlobalConfig(cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := RunDaemon(ctx); err != nil {
+		t.Fatalf("RunDaemon failed: %v", err)
+	}
+
+	health, err := CheckDaemon()
+	if err != nil {
+		t.Fatalf("CheckDaemon failed: %v", err)
+	}
+	if health.Installed || health.Loaded {
+		t.Errorf("daemon should not be installed: %+v", health)
+	}
+	if health.Heartbeat == nil || health.Heartbeat.PID != os.Getpid() {
+		t.Fatalf("unexpected heartbeat: %+v", health.Heartbeat)
+	}
+	if !health.Running || health.Stale {
+		t.Errorf("expected fresh heartbeat from a live process: %+v", health)
+	}
+	if health.Healthy() {
+		t.Error("an uninstalled daemon should not be reported healthy")
+	}
+
+	cfg.Daemon.Interval = time.Nanosecond
+	SetGlobalConfig(cfg)
+	health, err = CheckDaemon()
+	if err != nil {
+		t.Fatalf("CheckDaemon failed: %v", err)
+	}
+	if !health.Stale {
+		t.Error("expected heartbeat older than three intervals to be stale")
+	}
+}
+
+func TestCheckDaemonWithoutHeartbeat(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	health, err := CheckDaemon()
+	if err != nil {
+		t.Fatalf("CheckDaemon failed: %v", err)
+	}
+	if health.Heartbeat != nil || health.Running {
+		t.Errorf("expected no heartbeat: %+v", health)
+	}
+}
+
+func TestRenderDaemonService(t *testing.T) {
+	service := renderDaemonService("/opt/mono tools/mono", "/home/me/.mono/daemon.log", "")
+
+	if !strings.Contains(service, "/opt/mono tools/mono") || !strings.Contains(service, "/home/me/.mono/daemon.log") {
+		t.Errorf("service should reference the executable and log:\n%s", service)
+	}
+	if !strings.Contains(service, "run") {
+		t.Errorf("service should run the daemon:\n%s", service)
+	}
+	if strings.Contains(service, "MONO_HOME") {
+		t.Errorf("service should not set MONO_HOME by default:\n%s", service)
+	}
+
+	service = renderDaemonService("/opt/mono", "/srv/mono/daemon.log", "/srv/mono")
+	if !strings.Contains(service, "MONO_HOME") || !strings.Contains(service, "/srv/mono") {
+		t.Errorf("service should pass a relocated MONO_HOME:\n%s", service)
+	}
+}
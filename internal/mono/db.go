@@ -32,22 +32,61 @@ CREATE TABLE IF NOT EXISTS cache_events (
 CREATE INDEX IF NOT EXISTS idx_cache_events_key ON cache_events(project_id, artifact, cache_key);
 `
 
+const composeOverridesSchema = `
+CREATE TABLE IF NOT EXISTS compose_overrides (
+    path TEXT PRIMARY KEY,
+    env_path TEXT NOT NULL
+);
+`
+
+const environmentArtifactsSchema = `
+CREATE TABLE IF NOT EXISTS environment_artifacts (
+    env_path TEXT NOT NULL,
+    artifact TEXT NOT NULL,
+    cache_key TEXT NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (env_path, artifact)
+);
+`
+
+const cacheEntriesSchema = `
+CREATE TABLE IF NOT EXISTS cache_entries (
+    project_id TEXT NOT NULL,
+    artifact TEXT NOT NULL,
+    cache_key TEXT NOT NULL,
+    path TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    disk_usage INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, artifact, cache_key)
+);
+`
+
+const metadataSchema = `
+CREATE TABLE IF NOT EXISTS metadata (
+    name TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
+`
+
+const detachedRunsSchema = `
+CREATE TABLE IF NOT EXISTS detached_runs (
+    env_path TEXT PRIMARY KEY,
+    pid INTEGER NOT NULL,
+    started_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+`
+
 type DB struct {
 	conn *sql.DB
 	path string
 }
 
 func DBPath() (string, error) {
-	var monoDir string
-
-	if customHome := os.Getenv("MONO_HOME"); customHome != "" {
-		monoDir = customHome
-	} else {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("failed to get home directory: %w", err)
-		}
-		monoDir = filepath.Join(home, ".mono")
+	monoDir, err := GetMonoHome()
+	if err != nil {
+		return "", err
 	}
 
 	if err := os.MkdirAll(monoDir, 0755); err != nil {
@@ -100,15 +139,97 @@ func (db *DB) Initialize() error {
 
 	db.conn.Exec(`ALTER TABLE environments ADD COLUMN root_path TEXT`)
 	db.conn.Exec(`ALTER TABLE environments ADD COLUMN compose_dir TEXT`)
+	db.conn.Exec(`ALTER TABLE environments ADD COLUMN devcontainer TEXT`)
 
 	_, err = db.conn.Exec(cacheEventsSchema)
 	if err != nil {
 		return fmt.Errorf("failed to create cache_events schema: %w", err)
 	}
 
+	db.conn.Exec(`ALTER TABLE cache_events ADD COLUMN size INTEGER`)
+	db.conn.Exec(`ALTER TABLE cache_events ADD COLUMN reason TEXT`)
+
+	_, err = db.conn.Exec(composeOverridesSchema)
+	if err != nil {
+		return fmt.Errorf("failed to create compose_overrides schema: %w", err)
+	}
+
+	_, err = db.conn.Exec(environmentArtifactsSchema)
+	if err != nil {
+		return fmt.Errorf("failed to create environment_artifacts schema: %w", err)
+	}
+
+	_, err = db.conn.Exec(cacheEntriesSchema)
+	if err != nil {
+		return fmt.Errorf("failed to create cache_entries schema: %w", err)
+	}
+	if err := db.migrateCacheSizes(); err != nil {
+		return err
+	}
+
+	_, err = db.conn.Exec(metadataSchema)
+	if err != nil {
+		return fmt.Errorf("failed to create metadata schema: %w", err)
+	}
+
+	_, err = db.conn.Exec(detachedRunsSchema)
+	if err != nil {
+		return fmt.Errorf("failed to create detached_runs schema: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) migrateCacheSizes() error {
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'cache_sizes'`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to inspect schema: %w", err)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to migrate cache_sizes: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT OR IGNORE INTO cache_entries (project_id, artifact, cache_key, path, size, disk_usage, created_at, updated_at)
+		SELECT project_id, artifact, cache_key, '', size, disk_usage, updated_at, updated_at FROM cache_sizes`)
+	if err != nil {
+		return fmt.Errorf("failed to migrate cache_sizes: %w", err)
+	}
+	if _, err := tx.Exec(`DROP TABLE cache_sizes`); err != nil {
+		return fmt.Errorf("failed to drop cache_sizes: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to migrate cache_sizes: %w", err)
+	}
 	return nil
 }
 
+func (db *DB) GetMetadata(name string) (string, bool, error) {
+	var value string
+	err := db.conn.QueryRow(`SELECT value FROM metadata WHERE name = ?`, name).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+func (db *DB) SetMetadata(name, value string) error {
+	_, err := db.conn.Exec(
+		`INSERT INTO metadata (name, value) VALUES (?, ?) ON CONFLICT(name) DO UPDATE SET value = excluded.value`,
+		name, value,
+	)
+	return err
+}
+
 func (db *DB) RecordCacheEvent(event, projectID, artifact, cacheKey string) error {
 	_, err := db.conn.Exec(
 		`INSERT INTO cache_events (event, project_id, artifact, cache_key) VALUES (?, ?, ?, ?)`,
@@ -117,6 +238,15 @@ func (db *DB) RecordCacheEvent(event, projectID, artifact, cacheKey string) erro
 	return err
 }
 
+func (db *DB) RecordCacheEviction(eviction CacheEviction) error {
+	e := eviction.Entry
+	_, err := db.conn.Exec(
+		`INSERT INTO cache_events (event, project_id, artifact, cache_key, size, reason) VALUES (?, ?, ?, ?, ?, ?)`,
+		CacheEventEvict, e.ProjectID, e.Artifact, e.CacheKey, e.Size, eviction.Reason,
+	)
+	return err
+}
+
 type CacheEntry struct {
 	ProjectID string
 	Artifact  string
@@ -136,6 +266,7 @@ func (db *DB) GetCacheStats() ([]CacheEntry, error) {
 			SUM(CASE WHEN event = 'miss' THEN 1 ELSE 0 END) as misses,
 			MAX(timestamp) as last_used
 		FROM cache_events
+		WHERE event IN ('hit', 'miss')
 		GROUP BY project_id, artifact, cache_key
 		ORDER BY last_used DESC
 	`)
@@ -161,16 +292,55 @@ func (db *DB) GetCacheStats() ([]CacheEntry, error) {
 	return entries, rows.Err()
 }
 
+type CacheEvent struct {
+	Time      time.Time
+	Event     string
+	ProjectID string
+	Artifact  string
+	CacheKey  string
+	Size      int64
+	Reason    string
+}
+
+func (db *DB) RecentCacheEvents(limit int) ([]CacheEvent, error) {
+	rows, err := db.conn.Query(
+		`SELECT timestamp, event, project_id, artifact, cache_key, COALESCE(size, 0), COALESCE(reason, '') FROM cache_events ORDER BY id DESC LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []CacheEvent
+	for rows.Next() {
+		var e CacheEvent
+		if err := rows.Scan(&e.Time, &e.Event, &e.ProjectID, &e.Artifact, &e.CacheKey, &e.Size, &e.Reason); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
 func (db *DB) DeleteCacheEvents(projectID, artifact, cacheKey string) error {
 	_, err := db.conn.Exec(
-		`DELETE FROM cache_events WHERE project_id = ? AND artifact = ? AND cache_key = ?`,
-		projectID, artifact, cacheKey,
+		`DELETE FROM cache_events WHERE project_id = ? AND artifact = ? AND cache_key = ? AND event != ?`,
+		projectID, artifact, cacheKey, CacheEventEvict,
+	)
+	return err
+}
+
+func (db *DB) DeleteArtifactCacheEvents(projectID, artifact string) error {
+	_, err := db.conn.Exec(
+		`DELETE FROM cache_events WHERE project_id = ? AND artifact = ? AND event != ?`,
+		projectID, artifact, CacheEventEvict,
 	)
 	return err
 }
 
 func (db *DB) DeleteAllCacheEvents() error {
-	_, err := db.conn.Exec(`DELETE FROM cache_events`)
+	_, err := db.conn.Exec(`DELETE FROM cache_events WHERE event != ?`, CacheEventEvict)
 	return err
 }
 
@@ -191,3 +361,205 @@ func (db *DB) GetAllRootPaths() ([]string, error) {
 	}
 	return paths, rows.Err()
 }
+
+func (db *DB) RecordComposeOverride(path, envPath string) error {
+	_, err := db.conn.Exec(
+		`INSERT INTO compose_overrides (path, env_path) VALUES (?, ?) ON CONFLICT(path) DO UPDATE SET env_path = excluded.env_path`,
+		path, envPath,
+	)
+	return err
+}
+
+func (db *DB) DeleteComposeOverride(path string) error {
+	_, err := db.conn.Exec(`DELETE FROM compose_overrides WHERE path = ?`, path)
+	return err
+}
+
+func (db *DB) RecordDetachedRun(envPath string, pid int) error {
+	_, err := db.conn.Exec(
+		`INSERT INTO detached_runs (env_path, pid) VALUES (?, ?)
+		ON CONFLICT(env_path) DO UPDATE SET pid = excluded.pid, started_at = CURRENT_TIMESTAMP`,
+		envPath, pid,
+	)
+	return err
+}
+
+func (db *DB) GetDetachedRun(envPath string) (int, bool, error) {
+	var pid int
+	err := db.conn.QueryRow(`SELECT pid FROM detached_runs WHERE env_path = ?`, envPath).Scan(&pid)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return pid, true, nil
+}
+
+func (db *DB) DeleteDetachedRun(envPath string) error {
+	_, err := db.conn.Exec(`DELETE FROM detached_runs WHERE env_path = ?`, envPath)
+	return err
+}
+
+func (db *DB) ListComposeOverrides(envPath string) ([]string, error) {
+	rows, err := db.conn.Query(`SELECT path FROM compose_overrides WHERE env_path = ?`, envPath)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+func (db *DB) ListOrphanComposeOverrides() ([]string, error) {
+	rows, err := db.conn.Query(`
+		SELECT path FROM compose_overrides
+		WHERE env_path NOT IN (SELECT path FROM environments)
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+func (db *DB) RecordEnvironmentArtifact(envPath, artifact, cacheKey string) error {
+	_, err := db.conn.Exec(
+		`INSERT INTO environment_artifacts (env_path, artifact, cache_key) VALUES (?, ?, ?)
+		ON CONFLICT(env_path, artifact) DO UPDATE SET cache_key = excluded.cache_key, updated_at = CURRENT_TIMESTAMP`,
+		envPath, artifact, cacheKey,
+	)
+	return err
+}
+
+func (db *DB) GetEnvironmentArtifacts(envPath string) (map[string]string, error) {
+	rows, err := db.conn.Query(`SELECT artifact, cache_key FROM environment_artifacts WHERE env_path = ?`, envPath)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make(map[string]string)
+	for rows.Next() {
+		var artifact, key string
+		if err := rows.Scan(&artifact, &key); err != nil {
+			return nil, err
+		}
+		keys[artifact] = key
+	}
+	return keys, rows.Err()
+}
+
+type EnvironmentArtifact struct {
+	Artifact  string
+	CacheKey  string
+	UpdatedAt time.Time
+}
+
+func (db *DB) ListEnvironmentArtifacts(envPath string) ([]EnvironmentArtifact, error) {
+	rows, err := db.conn.Query(
+		`SELECT artifact, cache_key, updated_at FROM environment_artifacts WHERE env_path = ? ORDER BY artifact`,
+		envPath,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var artifacts []EnvironmentArtifact
+	for rows.Next() {
+		var a EnvironmentArtifact
+		if err := rows.Scan(&a.Artifact, &a.CacheKey, &a.UpdatedAt); err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, a)
+	}
+	return artifacts, rows.Err()
+}
+
+func (db *DB) RecordCacheEntry(entry CacheSizeEntry) error {
+	_, err := db.conn.Exec(
+		`INSERT INTO cache_entries (project_id, artifact, cache_key, path, size, disk_usage) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(project_id, artifact, cache_key) DO UPDATE SET path = excluded.path, size = excluded.size, disk_usage = excluded.disk_usage, updated_at = CURRENT_TIMESTAMP`,
+		entry.ProjectID, entry.Artifact, entry.CacheKey, entry.Path, entry.Size, entry.DiskUsage,
+	)
+	return err
+}
+
+func (db *DB) GetCacheEntries() ([]CacheSizeEntry, error) {
+	rows, err := db.conn.Query(`SELECT project_id, artifact, cache_key, path, size, disk_usage, created_at FROM cache_entries
+		ORDER BY project_id, artifact, created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []CacheSizeEntry
+	for rows.Next() {
+		var e CacheSizeEntry
+		if err := rows.Scan(&e.ProjectID, &e.Artifact, &e.CacheKey, &e.Path, &e.Size, &e.DiskUsage, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (db *DB) GetCacheEntryIndex() (map[string]CacheSizeEntry, error) {
+	entries, err := db.GetCacheEntries()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]CacheSizeEntry, len(entries))
+	for _, e := range entries {
+		index[e.ProjectID+"/"+e.Artifact+"/"+e.CacheKey] = e
+	}
+	return index, nil
+}
+
+func (db *DB) DeleteCacheEntry(projectID, artifact, cacheKey string) error {
+	_, err := db.conn.Exec(
+		`DELETE FROM cache_entries WHERE project_id = ? AND artifact = ? AND cache_key = ?`,
+		projectID, artifact, cacheKey,
+	)
+	return err
+}
+
+func (db *DB) DeleteArtifactCacheEntries(projectID, artifact string) error {
+	_, err := db.conn.Exec(
+		`DELETE FROM cache_entries WHERE project_id = ? AND artifact = ?`,
+		projectID, artifact,
+	)
+	return err
+}
+
+func (db *DB) DeleteAllCacheEntries() error {
+	_, err := db.conn.Exec(`DELETE FROM cache_entries`)
+	return err
+}
+
+func RecordArtifactKeys(db *DB, envPath string, entries []ArtifactCacheEntry) error {
+	for _, entry := range entries {
+		if err := db.RecordEnvironmentArtifact(envPath, entry.Name, entry.Key); err != nil {
+			return fmt.Errorf("failed to record %s cache key: %w", entry.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package mono
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func RunPIDPath(envName string) (string, error) {
+	dataDir, err := DataDir(envName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "run.pid"), nil
+}
+
+func RunLogPath(envName string) (string, error) {
+	dataDir, err := DataDir(envName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "run.log"), nil
+}
+
+func runDetached(path string, args []string, logger *FileLogger) error {
+	envName := EnvName(path)
+
+	db, err := OpenDB()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	pid, err := detachedRunPID(db, path)
+	if err != nil {
+		return err
+	}
+	if processAlive(pid) {
+		return fmt.Errorf("run script already running in the background (pid %d), stop it with 'mono stop %s'", pid, path)
+	}
+
+	env, cfg, vars, err := loadEnvironmentVars(path)
+	if err != nil {
+		return err
+	}
+	if cfg.Scripts.Run == "" {
+		return fmt.Errorf("no run script defined in mono.yml")
+	}
+	script, err := wrapRunScript(env, cfg, cfg.Scripts.Run, args, false)
+	if err != nil {
+		return err
+	}
+
+	pidPath, err := RunPIDPath(envName)
+	if err != nil {
+		return err
+	}
+	logPath, err := RunLogPath(envName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	out, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open run log: %w", err)
+	}
+	defer out.Close()
+
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Dir = path
+	cmd.Env = append(os.Environ(), vars...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start run script: %w", err)
+	}
+	pid = cmd.Process.Pid
+
+	if err := writeFileAtomic(pidPath, []byte(strconv.Itoa(pid)+"\n"), 0644); err != nil {
+		return errors.Join(fmt.Errorf("failed to write %s: %w", pidPath, err), stopProcessGroup(pid))
+	}
+	if err := db.RecordDetachedRun(path, pid); err != nil {
+		return errors.Join(fmt.Errorf("failed to record background run: %w", err), stopProcessGroup(pid))
+	}
+	if err := cmd.Process.Release(); err != nil {
+		return fmt.Errorf("failed to detach run script: %w", err)
+	}
+
+	logger.Log("started run script in the background (pid %d)", pid)
+	fmt.Printf("Started run script in the background (pid %d)\n", pid)
+	fmt.Printf("  Logs: %s\n", logPath)
+	return nil
+}
+
+func detachedRunPID(db *DB, path string) (int, error) {
+	pid, ok, err := db.GetDetachedRun(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read background run: %w", err)
+	}
+	if ok {
+		return pid, nil
+	}
+
+	pidPath, err := RunPIDPath(EnvName(path))
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(pidPath)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", pidPath, err)
+	}
+	pid, err = strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", pidPath, err)
+	}
+	return pid, nil
+}
+
+func Stop(path string) (int, bool, error) {
+	envName := EnvName(path)
+
+	logger, err := NewFileLogger(envName)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer logger.Close()
+
+	logger.Log("mono stop %s", path)
+
+	db, err := OpenDB()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	return stopDetachedRun(db, path, logger)
+}
+
+func stopDetachedRun(db *DB, path string, logger *FileLogger) (int, bool, error) {
+	pid, err := detachedRunPID(db, path)
+	if err != nil {
+		return 0, false, err
+	}
+
+	stopped := false
+	if processAlive(pid) {
+		if err := stopProcessGroup(pid); err != nil {
+			return pid, false, err
+		}
+		logger.Log("stopped background run script (pid %d)", pid)
+		stopped = true
+	}
+
+	pidPath, err := RunPIDPath(EnvName(path))
+	if err != nil {
+		return pid, stopped, err
+	}
+	if err := os.Remove(pidPath); err != nil && !os.IsNotExist(err) {
+		return pid, stopped, fmt.Errorf("failed to remove %s: %w", pidPath, err)
+	}
+	if err := db.DeleteDetachedRun(path); err != nil {
+		return pid, stopped, fmt.Errorf("failed to clear background run: %w", err)
+	}
+	return pid, stopped, nil
+}
+
+func stopProcessGroup(pid int) error {
+	if err := syscall.Kill(-pid, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("failed to stop pid %d: %w", pid, err)
+	}
+	deadline := time.Now().Add(processStopTimeout)
+	for processAlive(pid) && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if processAlive(pid) {
+		if err := syscall.Kill(-pid, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return fmt.Errorf("failed to kill pid %d: %w", pid, err)
+		}
+	}
+	return nil
+}
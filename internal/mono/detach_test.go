@@ -0,0 +1,122 @@
+package mono
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunDetached(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	logger, err := NewFileLogger("env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	path := t.TempDir()
+	if err := os.WriteFile(filepath.Join(path, "mono.yml"), []byte("scripts:\n  run: echo \"$MONO_ENV_NAME $*\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	db, err := OpenDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.InsertEnvironment(path, "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runDetached(path, []string{"--port", "3000"}, logger); err != nil {
+		t.Fatalf("runDetached failed: %v", err)
+	}
+
+	pid, ok, err := db.GetDetachedRun(path)
+	if err != nil || !ok {
+		t.Fatalf("expected the background run to be recorded, got %v, %v", ok, err)
+	}
+	pidPath, err := RunPIDPath(EnvName(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(pidPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(data)) != strconv.Itoa(pid) {
+		t.Errorf("expected pid file to contain %d, got %q", pid, data)
+	}
+
+	logPath, err := RunLogPath(EnvName(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := EnvName(path) + " --port 3000\n"
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		out, err := os.ReadFile(logPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) == want {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected run log %q, got %q", want, out)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestStopDetachedRun(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	logger, err := NewFileLogger("env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	db, err := OpenDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	path := filepath.Join(t.TempDir(), "feature")
+	cmd := exec.Command("sh", "-c", "sleep 30 & wait")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	if err := db.RecordDetachedRun(path, cmd.Process.Pid); err != nil {
+		t.Fatal(err)
+	}
+	pid, stopped, err := stopDetachedRun(db, path, logger)
+	if err != nil || !stopped || pid != cmd.Process.Pid {
+		t.Fatalf("stopDetachedRun = %d, %v, %v", pid, stopped, err)
+	}
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the run script to exit")
+	}
+	if _, ok, err := db.GetDetachedRun(path); err != nil || ok {
+		t.Errorf("expected the background run record to be cleared, got %v, %v", ok, err)
+	}
+
+	if _, stopped, err := stopDetachedRun(db, path, logger); err != nil || stopped {
+		t.Errorf("expected a second stop to be a no-op, got %v, %v", stopped, err)
+	}
+}
@@ -0,0 +1,421 @@
+package mono
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var devcontainerFilenames = []string{
+	filepath.Join(".devcontainer", "devcontainer.json"),
+	".devcontainer.json",
+}
+
+type DevcontainerBuild struct {
+	Dockerfile string            `json:"dockerfile"`
+	Context    string            `json:"context"`
+	Args       map[string]string `json:"args"`
+	Target     string            `json:"target"`
+}
+
+type DevcontainerConfig struct {
+	Name              string             `json:"name"`
+	Image             string             `json:"image"`
+	Build             *DevcontainerBuild `json:"build"`
+	DockerFile        string             `json:"dockerFile"`
+	DockerComposeFile json.RawMessage    `json:"dockerComposeFile"`
+	ForwardPorts      []json.RawMessage  `json:"forwardPorts"`
+	AppPort           json.RawMessage    `json:"appPort"`
+	ContainerEnv      map[string]string  `json:"containerEnv"`
+	RemoteUser        string             `json:"remoteUser"`
+	ContainerUser     string             `json:"containerUser"`
+	WorkspaceFolder   string             `json:"workspaceFolder"`
+	RunArgs           []string           `json:"runArgs"`
+	Mounts            []json.RawMessage  `json:"mounts"`
+
+	dir string
+}
+
+func DetectDevcontainer(dir string) (string, error) {
+	for _, name := range devcontainerFilenames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no devcontainer.json found (tried: %v)", devcontainerFilenames)
+}
+
+func LoadDevcontainer(envPath string) (*DevcontainerConfig, error) {
+	path, err := DetectDevcontainer(envPath)
+	if err != nil {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read devcontainer.json: %w", err)
+	}
+
+	var dc DevcontainerConfig
+	if err := json.Unmarshal(stripJSONC(data), &dc); err != nil {
+		return nil, fmt.Errorf("invalid devcontainer.json: %w", err)
+	}
+	dc.dir = filepath.Dir(path)
+
+	if len(dc.DockerComposeFile) > 0 {
+		return nil, fmt.Errorf("devcontainer.json uses dockerComposeFile, which is not supported (use compose mode instead)")
+	}
+	if dc.Build == nil && dc.DockerFile != "" {
+		dc.Build = &DevcontainerBuild{Dockerfile: dc.DockerFile}
+	}
+	if dc.Image == "" && (dc.Build == nil || dc.Build.Dockerfile == "") {
+		return nil, fmt.Errorf("invalid devcontainer.json: either image or build.dockerfile is required")
+	}
+
+	return &dc, nil
+}
+
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			i++
+		case c == ',':
+			j := i + 1
+			for j < len(data) && strings.ContainsRune(" \t\r\n", rune(data[j])) {
+				j++
+			}
+			if j < len(data) && (data[j] == '}' || data[j] == ']') {
+				continue
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (dc *DevcontainerConfig) Ports() []int {
+	seen := make(map[int]bool)
+	var ports []int
+	add := func(raw json.RawMessage) {
+		var n int
+		if err := json.Unmarshal(raw, &n); err == nil {
+			if n > 0 && !seen[n] {
+				seen[n] = true
+				ports = append(ports, n)
+			}
+			return
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return
+		}
+		if idx := strings.LastIndex(s, ":"); idx >= 0 {
+			if host := s[:idx]; host != "localhost" && host != "127.0.0.1" {
+				return
+			}
+			s = s[idx+1:]
+		}
+		if n, err := strconv.Atoi(s); err == nil && n > 0 && !seen[n] {
+			seen[n] = true
+			ports = append(ports, n)
+		}
+	}
+
+	for _, p := range dc.ForwardPorts {
+		add(p)
+	}
+	if len(dc.AppPort) > 0 {
+		var list []json.RawMessage
+		if err := json.Unmarshal(dc.AppPort, &list); err == nil {
+			for _, p := range list {
+				add(p)
+			}
+		} else {
+			add(dc.AppPort)
+		}
+	}
+
+	sort.Ints(ports)
+	return ports
+}
+
+func (dc *DevcontainerConfig) WorkspaceFolderFor(envPath string) string {
+	if dc.WorkspaceFolder != "" {
+		return dc.WorkspaceFolder
+	}
+	return envPath
+}
+
+func DevcontainerName(envName string) string {
+	return fmt.Sprintf("mono-%s-devcontainer", envName)
+}
+
+func DevcontainerEnvFile(envName string) (string, error) {
+	dataDir, err := DataDir(envName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "devcontainer.env"), nil
+}
+
+func devcontainerAllocations(envID int64, dc *DevcontainerConfig) []Allocation {
+	servicePorts := make(map[string][]int)
+	for _, port := range dc.Ports() {
+		servicePorts[fmt.Sprintf("devcontainer-%d", port)] = []int{port}
+	}
+	return Allocate(envID, servicePorts)
+}
+
+func writeDevcontainerEnv(path string, env []string) error {
+	var b strings.Builder
+	for _, kv := range env {
+		if strings.ContainsAny(kv, "\n\r") {
+			return fmt.Errorf("environment variable %s contains a newline and cannot be passed to the devcontainer", strings.SplitN(kv, "=", 2)[0])
+		}
+		b.WriteString(kv)
+		b.WriteByte('\n')
+	}
+	return os.WriteFile(path, []byte(b.String()), 0600)
+}
+
+func (dc *DevcontainerConfig) runArgs(envName, envPath string, allocations []Allocation, image string) []string {
+	workspace := dc.WorkspaceFolderFor(envPath)
+	args := []string{"run", "-d",
+		"--name", DevcontainerName(envName),
+		"--label", "mono.env=" + envName,
+		"--init",
+		"-v", envPath + ":" + workspace,
+		"-w", workspace,
+	}
+	for _, alloc := range allocations {
+		args = append(args, "-p", fmt.Sprintf("127.0.0.1:%d:%d", alloc.HostPort, alloc.ContainerPort))
+	}
+
+	keys := make([]string, 0, len(dc.ContainerEnv))
+	for k := range dc.ContainerEnv {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "-e", k+"="+dc.ContainerEnv[k])
+	}
+
+	if dc.ContainerUser != "" {
+		args = append(args, "-u", dc.ContainerUser)
+	}
+	args = append(args, dc.RunArgs...)
+	args = append(args, image, "sleep", "infinity")
+	return args
+}
+
+func (dc *DevcontainerConfig) image(envName string) string {
+	if dc.Build != nil && dc.Build.Dockerfile != "" {
+		return DevcontainerName(envName)
+	}
+	return dc.Image
+}
+
+func (dc *DevcontainerConfig) buildArgs(envName string) []string {
+	buildContext := filepath.Join(dc.dir, dc.Build.Context)
+	args := []string{"build",
+		"-t", dc.image(envName),
+		"-f", filepath.Join(dc.dir, dc.Build.Dockerfile),
+	}
+
+	keys := make([]string, 0, len(dc.Build.Args))
+	for k := range dc.Build.Args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--build-arg", k+"="+dc.Build.Args[k])
+	}
+
+	if dc.Build.Target != "" {
+		args = append(args, "--target", dc.Build.Target)
+	}
+	return append(args, buildContext)
+}
+
+func StartDevcontainer(ctx context.Context, dc *DevcontainerConfig, envName, envPath string, allocations []Allocation, stdout, stderr io.Writer) error {
+	name := DevcontainerName(envName)
+
+	state, err := devcontainerState(name)
+	if err != nil {
+		return err
+	}
+	switch state {
+	case "running":
+		return nil
+	case "":
+	default:
+		return runDocker(ctx, "start devcontainer", stdout, stderr, "start", name)
+	}
+
+	if dc.Build != nil && dc.Build.Dockerfile != "" {
+		if err := runDocker(ctx, "build devcontainer", stdout, stderr, dc.buildArgs(envName)...); err != nil {
+			return err
+		}
+	}
+	return runDocker(ctx, "start devcontainer", stdout, stderr, dc.runArgs(envName, envPath, allocations, dc.image(envName))...)
+}
+
+func StopDevcontainer(ctx context.Context, envName string, stdout, stderr io.Writer) error {
+	name := DevcontainerName(envName)
+	state, err := devcontainerState(name)
+	if err != nil {
+		return err
+	}
+	if state == "" {
+		return nil
+	}
+	return runDocker(ctx, "remove devcontainer", stdout, stderr, "rm", "-f", name)
+}
+
+func DevcontainerRunning(envName string) bool {
+	state, err := devcontainerState(DevcontainerName(envName))
+	return err == nil && state == "running"
+}
+
+func devcontainerState(name string) (string, error) {
+	result, err := Command("docker", "ps", "-a", "--filter", "name=^"+name+"$", "--format", "{{.State}}").RunCapture()
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect devcontainer: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("failed to inspect devcontainer: %s", strings.TrimSpace(string(result.Stderr)))
+	}
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
+
+func runDocker(ctx context.Context, action string, stdout, stderr io.Writer, args ...string) error {
+	timeout := timeouts().ComposeUp
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := dockerCommand(ctx, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("docker %s timed out after %v (raise timeouts.compose_up in the global config)", args[0], timeout)
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("docker %s %w", args[0], ErrInterrupted)
+		}
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	return nil
+}
+
+func devcontainerExecArgs(envName, workspace, envFile string, interactive bool, script string) []string {
+	args := []string{"exec"}
+	if interactive {
+		args = append(args, "-it")
+	}
+	args = append(args, "--env-file", envFile, "-w", workspace, DevcontainerName(envName), "sh", "-c", script)
+	return args
+}
+
+func runDevcontainerScript(ctx context.Context, envName, workspace, envFile, script string, logger *FileLogger) error {
+	return runProcess(ctx, "", nil, logger, "docker", devcontainerExecArgs(envName, workspace, envFile, false, script)...)
+}
+
+func devcontainerRunScript(envName, workspace, envFile, script string, scriptArgs []string, tty bool) string {
+	args := devcontainerExecArgs(envName, workspace, envFile, tty, script)
+	if len(scriptArgs) > 0 {
+		args = append(append(args, "sh"), scriptArgs...)
+	}
+	return "exec docker " + shellQuoteArgs(args) + "\n"
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func shellQuoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func prepareDevcontainerEnv(envName string, env []string) (string, error) {
+	envFile, err := DevcontainerEnvFile(envName)
+	if err != nil {
+		return "", err
+	}
+	if err := writeDevcontainerEnv(envFile, env); err != nil {
+		return "", fmt.Errorf("failed to write devcontainer environment: %w", err)
+	}
+	return envFile, nil
+}
+
+func runEnvScript(ctx context.Context, cfg *Config, dc *DevcontainerConfig, path, script string, scriptEnv []string, logger *FileLogger) error {
+	if dc == nil {
+		if cfg != nil && cfg.Nix.active(path) {
+			return runNixScript(ctx, cfg.Nix, path, script, scriptEnv, logger)
+		}
+		return runScript(ctx, path, script, scriptEnv, logger)
+	}
+	envName := EnvName(path)
+	envFile, err := prepareDevcontainerEnv(envName, scriptEnv)
+	if err != nil {
+		return err
+	}
+	return runDevcontainerScript(ctx, envName, dc.WorkspaceFolderFor(path), envFile, script, logger)
+}
+
+func loadEnvironmentDevcontainer(env *Environment) (*DevcontainerConfig, error) {
+	if !env.UsesDevcontainer() {
+		return nil, nil
+	}
+	dc, err := LoadDevcontainer(env.Path)
+	if err != nil {
+		return nil, err
+	}
+	if dc == nil {
+		return nil, fmt.Errorf("environment was created from a devcontainer but %s has no devcontainer.json", env.Path)
+	}
+	return dc, nil
+}
@@ -0,0 +1,162 @@
+package mono
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func writeDevcontainer(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, ".devcontainer"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".devcontainer", "devcontainer.json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDevcontainer(t *testing.T) {
+	dir := t.TempDir()
+
+	dc, err := LoadDevcontainer(dir)
+	if err != nil || dc != nil {
+		t.Fatalf("expected no devcontainer without a devcontainer.json, got %v, %v", dc, err)
+	}
+
+	writeDevcontainer(t, dir, `{
+		// base image
+		"name": "app",
+		"build": {"dockerfile": "Dockerfile", "context": "..", "args": {"VERSION": "1"}},
+		/* ports the app listens on */
+		"forwardPorts": [3000, "5432", "db:6379", 3000],
+		"appPort": 8080,
+		"containerEnv": {"URL": "http://localhost:3000"},
+		"runArgs": ["--cap-add=SYS_PTRACE"],
+	}`)
+
+	dc, err = LoadDevcontainer(dir)
+	if err != nil {
+		t.Fatalf("LoadDevcontainer failed: %v", err)
+	}
+
+	if ports := dc.Ports(); !slices.Equal(ports, []int{3000, 5432, 8080}) {
+		t.Errorf("unexpected ports: %v", ports)
+	}
+	if dc.ContainerEnv["URL"] != "http://localhost:3000" {
+		t.Errorf("comment stripping corrupted strings: %q", dc.ContainerEnv["URL"])
+	}
+	if dc.WorkspaceFolderFor(dir) != dir {
+		t.Errorf("expected workspace to default to the host path, got %s", dc.WorkspaceFolderFor(dir))
+	}
+
+	build := dc.buildArgs("ws")
+	if build[len(build)-1] != dir {
+		t.Errorf("expected build context %s, got %s", dir, build[len(build)-1])
+	}
+	if !slices.Contains(build, filepath.Join(dir, ".devcontainer", "Dockerfile")) || !slices.Contains(build, "VERSION=1") {
+		t.Errorf("unexpected build args: %v", build)
+	}
+
+	allocations := devcontainerAllocations(1, dc)
+	if len(allocations) != 3 {
+		t.Fatalf("expected 3 allocations, got %v", allocations)
+	}
+	run := dc.runArgs("ws", dir, allocations, dc.image("ws"))
+	for _, alloc := range allocations {
+		if !slices.Contains(run, "127.0.0.1:"+strconv.Itoa(alloc.HostPort)+":"+strconv.Itoa(alloc.ContainerPort)) {
+			t.Errorf("expected port %v to be published: %v", alloc, run)
+		}
+	}
+	if !slices.Contains(run, dir+":"+dir) || !slices.Contains(run, "--cap-add=SYS_PTRACE") || run[len(run)-3] != "mono-ws-devcontainer" {
+		t.Errorf("unexpected run args: %v", run)
+	}
+
+	env := buildScriptEnv("ws", 1, dir, "", allocations, nil, nil)
+	found := false
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "MONO_DEVCONTAINER_3000_PORT=") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected MONO_DEVCONTAINER_3000_PORT in %v", env)
+	}
+}
+
+func TestLoadDevcontainerRejectsComposeAndEmpty(t *testing.T) {
+	dir := t.TempDir()
+	writeDevcontainer(t, dir, `{"dockerComposeFile": "compose.yml", "service": "app"}`)
+	if _, err := LoadDevcontainer(dir); err == nil {
+		t.Error("expected dockerComposeFile devcontainers to be rejected")
+	}
+
+	writeDevcontainer(t, dir, `{"name": "app"}`)
+	if _, err := LoadDevcontainer(dir); err == nil {
+		t.Error("expected a devcontainer without image or build to be rejected")
+	}
+}
+
+func TestDevcontainerRunScriptQuoting(t *testing.T) {
+	script := devcontainerRunScript("ws", "/work space", "/tmp/env", `echo 'hi' && npm run dev`, nil, true)
+
+	out, err := exec.Command("sh", "-c", "docker() { printf '%s\\n' \"$@\"; }; "+strings.Replace(script, "exec docker", "docker", 1)).Output()
+	if err != nil {
+		t.Fatalf("run script is not valid shell: %v", err)
+	}
+	args := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	want := []string{"exec", "-it", "--env-file", "/tmp/env", "-w", "/work space", "mono-ws-devcontainer", "sh", "-c", `echo 'hi' && npm run dev`}
+	if !slices.Equal(args, want) {
+		t.Errorf("unexpected args:\n got %q\nwant %q", args, want)
+	}
+}
+
+func TestWrapRunScriptArgs(t *testing.T) {
+	dir := t.TempDir()
+	args := []string{"--port", "3000", "it's spaced"}
+
+	script, err := wrapRunScript(&Environment{Path: dir}, &Config{}, `printf '%s\n' "$@"`, args, true)
+	if err != nil {
+		t.Fatalf("wrapRunScript failed: %v", err)
+	}
+	out, err := exec.Command("sh", "-c", script).Output()
+	if err != nil {
+		t.Fatalf("run script is not valid shell: %v", err)
+	}
+	if got := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n"); !slices.Equal(got, args) {
+		t.Errorf("unexpected script args:\n got %q\nwant %q", got, args)
+	}
+
+	script = devcontainerRunScript("ws", "/work", "/tmp/env", "npm run dev", args, true)
+	out, err = exec.Command("sh", "-c", "docker() { printf '%s\\n' \"$@\"; }; "+strings.Replace(script, "exec docker", "docker", 1)).Output()
+	if err != nil {
+		t.Fatalf("run script is not valid shell: %v", err)
+	}
+	got := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	if want := append([]string{"sh", "-c", "npm run dev", "sh"}, args...); !slices.Equal(got[len(got)-len(want):], want) {
+		t.Errorf("unexpected devcontainer args:\n got %q\nwant suffix %q", got, want)
+	}
+}
+
+func TestRenderRunScriptExportsEnv(t *testing.T) {
+	cfg := &Config{Scripts: Scripts{Run: `echo "$MONO_WEB_PORT $MONO_ENV_NAME $1"`}}
+	vars := []string{"MONO_WEB_PORT=19180", "MONO_ENV_NAME=it's"}
+
+	script, err := renderRunScript(&Environment{Path: t.TempDir()}, cfg, vars, []string{"--watch"})
+	if err != nil {
+		t.Fatalf("renderRunScript failed: %v", err)
+	}
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "MONO_WEB_PORT=stale"}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("run script is not valid shell: %v", err)
+	}
+	if got := string(out); got != "19180 it's --watch\n" {
+		t.Errorf("expected the run script to see the current environment, got %q", got)
+	}
+}
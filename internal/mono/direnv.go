@@ -0,0 +1,171 @@
+package mono
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	monoBlockBegin = "# >>> mono >>>"
+	monoBlockEnd   = "# <<< mono <<<"
+)
+
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func EnvironmentVars(path string) ([]string, error) {
+	_, _, vars, err := loadEnvironmentVars(path)
+	return vars, err
+}
+
+func loadEnvironmentVars(path string) (*Environment, *Config, []string, error) {
+	db, err := OpenDB()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	env, err := db.GetEnvironmentByPath(path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("environment not found: %s", path)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.ApplyDefaults(path)
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to initialize cache: %w", err)
+	}
+
+	envName := EnvName(path)
+	allocations, err := environmentAllocations(env, envName, cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	rootPath := ""
+	if env.RootPath.Valid {
+		rootPath = env.RootPath.String
+	}
+
+	cacheEnvVars := cm.EnvVars(cfg.Build, path, rootPath)
+	cacheEnvVars = append(cacheEnvVars, "MONO_CACHE_DIR="+cm.LocalCacheDir)
+	return env, cfg, buildScriptEnv(envName, env.ID, path, rootPath, allocations, cfg.Env, cacheEnvVars), nil
+}
+
+func environmentAllocations(env *Environment, envName string, cfg *Config) ([]Allocation, error) {
+	if env.DockerProject.Valid && env.DockerProject.String != "" {
+		composeDir := env.Path
+		if env.ComposeDir.Valid && env.ComposeDir.String != "" {
+			composeDir = filepath.Join(env.Path, env.ComposeDir.String)
+		}
+		_, allocations, err := buildComposeOverride(composeDir, envName, env.ID)
+		return allocations, err
+	}
+
+	devcontainer, err := loadEnvironmentDevcontainer(env)
+	if err != nil {
+		return nil, err
+	}
+	if devcontainer != nil {
+		return devcontainerAllocations(env.ID, devcontainer), nil
+	}
+	if len(cfg.Processes) > 0 {
+		return processAllocations(env.ID, cfg.Processes), nil
+	}
+	return nil, nil
+}
+
+func RenderEnvrc(vars []string) string {
+	return monoBlockBegin + "\n" +
+		"# managed by mono, regenerate with: mono direnv --write\n" +
+		renderExports(vars) +
+		monoBlockEnd + "\n"
+}
+
+func renderExports(vars []string) string {
+	sorted := append([]string(nil), vars...)
+	sort.Strings(sorted)
+
+	var b strings.Builder
+	for _, kv := range sorted {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !envVarName.MatchString(key) {
+			continue
+		}
+		fmt.Fprintf(&b, "export %s=%s\n", key, shellQuote(value))
+	}
+	return b.String()
+}
+
+func EnvrcPath(path string) string {
+	return filepath.Join(path, ".envrc")
+}
+
+func WriteEnvrc(path string, vars []string) (bool, error) {
+	envrc := EnvrcPath(path)
+	existing, err := os.ReadFile(envrc)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read .envrc: %w", err)
+	}
+
+	updated, err := replaceMonoBlock(string(existing), RenderEnvrc(vars))
+	if err != nil {
+		return false, fmt.Errorf("invalid .envrc: %w", err)
+	}
+	if updated == string(existing) {
+		return false, nil
+	}
+	if err := os.WriteFile(envrc, []byte(updated), 0644); err != nil {
+		return false, fmt.Errorf("failed to write .envrc: %w", err)
+	}
+	return true, nil
+}
+
+func replaceMonoBlock(content, block string) (string, error) {
+	return replaceBlock(content, monoBlockBegin, monoBlockEnd, block)
+}
+
+func replaceBlock(content, begin, end, block string) (string, error) {
+	start := strings.Index(content, begin)
+	if start < 0 {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		if content != "" {
+			content += "\n"
+		}
+		return content + block, nil
+	}
+
+	stop := strings.Index(content[start:], end)
+	if stop < 0 {
+		return "", fmt.Errorf("found %q without a matching %q", begin, end)
+	}
+	stop += start + len(end)
+	if stop < len(content) && content[stop] == '\n' {
+		stop++
+	}
+	return content[:start] + block + content[stop:], nil
+}
+
+func refreshEnvrc(path string, vars []string) (bool, error) {
+	existing, err := os.ReadFile(EnvrcPath(path))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read .envrc: %w", err)
+	}
+	if !strings.Contains(string(existing), monoBlockBegin) {
+		return false, nil
+	}
+	return WriteEnvrc(path, vars)
+}
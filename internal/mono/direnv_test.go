@@ -0,0 +1,121 @@
+package mono
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteEnvrcMaintainsBlock(t *testing.T) {
+	dir := t.TempDir()
+	envrc := EnvrcPath(dir)
+	if err := os.WriteFile(envrc, []byte("use nix\nexport FOO=bar"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vars := []string{"MONO_ENV_NAME=ws", "MONO_WEB_PORT=19103", "GREETING=it's here", "not-valid=x"}
+	changed, err := WriteEnvrc(dir, vars)
+	if err != nil || !changed {
+		t.Fatalf("WriteEnvrc = %v, %v", changed, err)
+	}
+
+	changed, err = WriteEnvrc(dir, vars)
+	if err != nil || changed {
+		t.Fatalf("expected rewrite with the same vars to be a no-op, got %v, %v", changed, err)
+	}
+
+	if _, err := WriteEnvrc(dir, []string{"MONO_ENV_NAME=ws", "MONO_WEB_PORT=19203"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(envrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if !strings.HasPrefix(content, "use nix\nexport FOO=bar\n\n"+monoBlockBegin) {
+		t.Errorf("user content not preserved:\n%s", content)
+	}
+	if strings.Count(content, monoBlockBegin) != 1 || strings.Contains(content, "19103") || strings.Contains(content, "not-valid") {
+		t.Errorf("block not replaced cleanly:\n%s", content)
+	}
+
+	out, err := exec.Command("sh", "-c", "use() { :; }; . "+envrc+" && echo \"$FOO $MONO_WEB_PORT\"").Output()
+	if err != nil {
+		t.Fatalf("failed to source .envrc: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "bar 19203" {
+		t.Errorf("unexpected sourced values: %q", got)
+	}
+}
+
+func TestRenderEnvrcQuotes(t *testing.T) {
+	block := RenderEnvrc([]string{"GREETING=it's $HOME"})
+	out, err := exec.Command("sh", "-c", block+"\nprintf '%s' \"$GREETING\"").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "it's $HOME" {
+		t.Errorf("expected value to be exported verbatim, got %q", out)
+	}
+}
+
+func TestRefreshEnvrcOnlyTouchesManagedFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	refreshed, err := refreshEnvrc(dir, []string{"MONO_ENV_NAME=ws"})
+	if err != nil || refreshed {
+		t.Fatalf("expected no .envrc to be created, got %v, %v", refreshed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".envrc")); !os.IsNotExist(err) {
+		t.Fatalf("refresh created .envrc: %v", err)
+	}
+
+	if err := os.WriteFile(EnvrcPath(dir), []byte("export FOO=bar\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	refreshed, err = refreshEnvrc(dir, []string{"MONO_ENV_NAME=ws"})
+	if err != nil || refreshed {
+		t.Fatalf("expected unmanaged .envrc to be left alone, got %v, %v", refreshed, err)
+	}
+
+	if _, err := WriteEnvrc(dir, []string{"MONO_ENV_NAME=ws"}); err != nil {
+		t.Fatal(err)
+	}
+	refreshed, err = refreshEnvrc(dir, []string{"MONO_ENV_NAME=ws2"})
+	if err != nil || !refreshed {
+		t.Fatalf("expected managed .envrc to be refreshed, got %v, %v", refreshed, err)
+	}
+}
+
+func TestEnvironmentVars(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("MONO_HOME", "")
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mono.yml"), []byte("env:\n  DATA: \"${MONO_DATA_DIR}/app\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := OpenDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.InsertEnvironment(dir, "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	vars, err := EnvironmentVars(dir)
+	if err != nil {
+		t.Fatalf("EnvironmentVars failed: %v", err)
+	}
+	joined := strings.Join(vars, "\n")
+	for _, want := range []string{"MONO_ENV_NAME=" + EnvName(dir), "MONO_ENV_PATH=" + dir, "DATA=" + filepath.Join(os.Getenv("HOME"), ".mono", "data", EnvName(dir), "app")} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected %q in:\n%s", want, joined)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package mono
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -24,7 +25,10 @@ func CheckDockerAvailable() error {
 			strings.Contains(outputStr, "connection refused") {
 			return fmt.Errorf("docker daemon isn't running, please (re)start it")
 		}
-		return fmt.Errorf("docker unavailable: %s", strings.TrimSpace(string(output)))
+		if trimmed := strings.TrimSpace(string(output)); trimmed != "" {
+			return fmt.Errorf("docker unavailable: %s", trimmed)
+		}
+		return fmt.Errorf("docker unavailable: %w", err)
 	}
 	return nil
 }
@@ -146,6 +150,20 @@ func ApplyOverrides(project *types.Project, envName string, allocations []Alloca
 	project.Volumes = newVolumes
 }
 
+func buildComposeOverride(composeDir, envName string, envID int64) (*types.Project, []Allocation, error) {
+	composeConfig, err := ParseComposeConfig(composeDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse compose config: %w", err)
+	}
+
+	allocations := Allocate(envID, composeConfig.GetServicePorts())
+	project := composeConfig.Project()
+	ApplyOverrides(project, envName, allocations)
+	describeConnections(project, allocations)
+
+	return project, allocations, nil
+}
+
 func WriteComposeOverride(path string, project *types.Project) error {
 	data, err := project.MarshalYAML()
 	if err != nil {
@@ -157,11 +175,12 @@ func WriteComposeOverride(path string, project *types.Project) error {
 	return nil
 }
 
-func StartContainers(projectName, workDir string, stdout, stderr io.Writer) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+func StartContainers(ctx context.Context, projectName, workDir string, stdout, stderr io.Writer) error {
+	timeout := timeouts().ComposeUp
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "docker", "compose",
+	cmd := dockerCommand(ctx, "compose",
 		"-p", projectName,
 		"-f", "docker-compose.mono.yml",
 		"up", "-d")
@@ -171,15 +190,19 @@ func StartContainers(projectName, workDir string, stdout, stderr io.Writer) erro
 
 	if err := cmd.Run(); err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return fmt.Errorf("docker compose up timed out")
+			return fmt.Errorf("docker compose up timed out after %v (raise timeouts.compose_up in the global config)", timeout)
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("docker compose up %w", ErrInterrupted)
 		}
 		return fmt.Errorf("failed to start containers: %w", err)
 	}
 	return nil
 }
 
-func StopContainers(projectName, workDir string, removeVolumes bool, stdout, stderr io.Writer) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+func StopContainers(ctx context.Context, projectName, workDir string, removeVolumes bool, stdout, stderr io.Writer) error {
+	timeout := timeouts().ComposeDown
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	args := []string{"compose", "-p", projectName, "down"}
@@ -187,20 +210,96 @@ func StopContainers(projectName, workDir string, removeVolumes bool, stdout, std
 		args = append(args, "-v")
 	}
 
-	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd := dockerCommand(ctx, args...)
 	cmd.Dir = workDir
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
 	if err := cmd.Run(); err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return fmt.Errorf("docker compose down timed out")
+			return fmt.Errorf("docker compose down timed out after %v (raise timeouts.compose_down in the global config)", timeout)
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("docker compose down %w", ErrInterrupted)
 		}
 		return fmt.Errorf("failed to stop containers: %w", err)
 	}
 	return nil
 }
 
+func dockerCommand(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = 10 * time.Second
+	return cmd
+}
+
+type DockerProject struct {
+	Name        string `json:"Name"`
+	Status      string `json:"Status"`
+	ConfigFiles string `json:"ConfigFiles"`
+}
+
+func ListDockerProjects() ([]DockerProject, error) {
+	output, err := exec.Command("docker", "compose", "ls", "-a", "--format", "json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list docker projects: %w", err)
+	}
+
+	var projects []DockerProject
+	if err := json.Unmarshal(output, &projects); err != nil {
+		return nil, fmt.Errorf("failed to parse docker projects: %w", err)
+	}
+	return projects, nil
+}
+
+type ServiceStatus struct {
+	Name       string             `json:"Name"`
+	Service    string             `json:"Service"`
+	State      string             `json:"State"`
+	Health     string             `json:"Health"`
+	Status     string             `json:"Status"`
+	Publishers []ServicePublisher `json:"Publishers"`
+}
+
+type ServicePublisher struct {
+	URL           string `json:"URL"`
+	TargetPort    int    `json:"TargetPort"`
+	PublishedPort int    `json:"PublishedPort"`
+	Protocol      string `json:"Protocol"`
+}
+
+func ListServiceStatuses(projectName string) ([]ServiceStatus, error) {
+	output, err := exec.Command("docker", "compose", "-p", projectName, "ps", "-a", "--format", "json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	trimmed := strings.TrimSpace(string(output))
+	if trimmed == "" {
+		return nil, nil
+	}
+
+	var statuses []ServiceStatus
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal([]byte(trimmed), &statuses); err != nil {
+			return nil, fmt.Errorf("failed to parse service status: %w", err)
+		}
+		return statuses, nil
+	}
+
+	for _, line := range strings.Split(trimmed, "\n") {
+		var status ServiceStatus
+		if err := json.Unmarshal([]byte(line), &status); err != nil {
+			return nil, fmt.Errorf("failed to parse service status: %w", err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
 func ContainersRunning(projectName string) bool {
 	cmd := exec.Command("docker", "compose", "-p", projectName, "ps", "-q")
 	output, err := cmd.Output()
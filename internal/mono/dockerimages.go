@@ -0,0 +1,348 @@
+package mono
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+const dockerImagesDir = "docker-images"
+
+type DockerConfig struct {
+	PreloadImages bool `yaml:"preload_images"`
+}
+
+type composeImage struct {
+	Service  string
+	Ref      string
+	BuildKey string
+	Built    bool
+}
+
+type cachedImage struct {
+	Ref      string `json:"ref"`
+	ID       string `json:"id"`
+	BuildKey string `json:"build_key,omitempty"`
+}
+
+func composeImages(project *types.Project, projectName string) ([]composeImage, error) {
+	names := make([]string, 0, len(project.Services))
+	for name := range project.Services {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var images []composeImage
+	for _, name := range names {
+		svc := project.Services[name]
+		image := composeImage{Service: name, Ref: svc.Image}
+		if svc.Build != nil {
+			if image.Ref == "" {
+				image.Ref = projectName + "-" + name
+			}
+			key, err := buildKey(project.WorkingDir, *svc.Build)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compute build key for %s: %w", name, err)
+			}
+			image.Built = true
+			image.BuildKey = key
+		}
+		if image.Ref == "" {
+			continue
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
+func buildKey(workDir string, build types.BuildConfig) (string, error) {
+	contextDir := build.Context
+	if contextDir == "" {
+		contextDir = workDir
+	}
+	if strings.Contains(contextDir, "://") || strings.HasPrefix(contextDir, "git@") {
+		return "", nil
+	}
+	if !filepath.IsAbs(contextDir) {
+		contextDir = filepath.Join(workDir, contextDir)
+	}
+	if !dirExists(contextDir) {
+		return "", nil
+	}
+
+	tree, err := gitContextTree(contextDir)
+	if err != nil || tree == "" {
+		return "", err
+	}
+
+	dockerfile := []byte(build.DockerfileInline)
+	if len(dockerfile) == 0 {
+		path := build.Dockerfile
+		if path == "" {
+			path = "Dockerfile"
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(contextDir, path)
+		}
+		dockerfile, err = os.ReadFile(path)
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	args := make([]string, 0, len(build.Args))
+	for name, value := range build.Args {
+		if value == nil {
+			args = append(args, name)
+			continue
+		}
+		args = append(args, name+"="+*value)
+	}
+	slices.Sort(args)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "tree:%s\ntarget:%s\n", tree, build.Target)
+	for _, arg := range args {
+		fmt.Fprintf(h, "arg:%s\n", arg)
+	}
+	h.Write(dockerfile)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func gitContextTree(dir string) (string, error) {
+	result, err := Command("git", "-C", dir, "status", "--porcelain", "--", ".").RunCapture()
+	if err != nil {
+		return "", fmt.Errorf("failed to check %s for changes: %w", dir, err)
+	}
+	if result.ExitCode != 0 || len(bytes.TrimSpace(result.Stdout)) > 0 {
+		return "", nil
+	}
+	result, err = Command("git", "-C", dir, "rev-parse", "HEAD:./").RunCapture()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the git tree of %s: %w", dir, err)
+	}
+	if result.ExitCode != 0 {
+		return "", nil
+	}
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
+
+func dockerImageID(ref string) (string, error) {
+	result, err := Command("docker", "image", "inspect", "--format", "{{.Id}}", ref).RunCapture()
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image %s: %w", ref, err)
+	}
+	if result.ExitCode != 0 {
+		if bytes.Contains(bytes.ToLower(result.Stderr), []byte("no such image")) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to inspect image %s: %s", ref, bytes.TrimSpace(result.Stderr))
+	}
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
+
+func (cm *CacheManager) dockerImageCacheDir(rootPath string) string {
+	return filepath.Join(cm.GetProjectCacheDir(rootPath), dockerImagesDir)
+}
+
+func dockerImageArchive(dir, id string) string {
+	return filepath.Join(dir, strings.ReplaceAll(id, ":", "-")+".tar")
+}
+
+func readImageIndex(dir string) (map[string]cachedImage, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if os.IsNotExist(err) {
+		return map[string]cachedImage{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	index := map[string]cachedImage{}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse image index: %w", err)
+	}
+	return index, nil
+}
+
+func writeImageIndex(dir string, index map[string]cachedImage) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, "index.json"), data, 0644)
+}
+
+func (cm *CacheManager) ExportComposeImages(ctx context.Context, rootPath, composeDir string, logger *FileLogger) (int, error) {
+	composeConfig, err := ParseComposeConfig(composeDir)
+	if err != nil {
+		return 0, err
+	}
+	project := composeConfig.Project()
+	images, err := composeImages(project, project.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	dir := cm.dockerImageCacheDir(rootPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	index, err := readImageIndex(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	exported := 0
+	for _, image := range images {
+		if err := interruptErr(ctx); err != nil {
+			return exported, err
+		}
+		if image.Built && image.BuildKey == "" {
+			continue
+		}
+		id, err := dockerImageID(image.Ref)
+		if err != nil {
+			return exported, err
+		}
+		if id == "" {
+			continue
+		}
+
+		archive := dockerImageArchive(dir, id)
+		if !fileExists(archive) {
+			logger.Log("exporting image %s for %s", image.Ref, image.Service)
+			if err := saveDockerImage(ctx, image.Ref, archive); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+		if previous, ok := index[image.Service]; ok && previous.ID != id {
+			if err := removeUnreferencedArchive(dir, index, image.Service, previous.ID); err != nil {
+				return exported, err
+			}
+		}
+		index[image.Service] = cachedImage{Ref: image.Ref, ID: id, BuildKey: image.BuildKey}
+	}
+	return exported, writeImageIndex(dir, index)
+}
+
+func removeUnreferencedArchive(dir string, index map[string]cachedImage, service, id string) error {
+	for name, image := range index {
+		if name != service && image.ID == id {
+			return nil
+		}
+	}
+	if err := os.Remove(dockerImageArchive(dir, id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func saveDockerImage(ctx context.Context, ref, archive string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts().ComposeUp)
+	defer cancel()
+
+	tmp := archive + cacheTmpSuffix
+	output, err := dockerCommand(ctx, "image", "save", "-o", tmp, ref).CombinedOutput()
+	if err != nil {
+		if rmErr := os.Remove(tmp); rmErr != nil && !os.IsNotExist(rmErr) {
+			return fmt.Errorf("failed to save image %s: %w (cleanup failed: %v)", ref, err, rmErr)
+		}
+		return fmt.Errorf("failed to save image %s: %w: %s", ref, err, bytes.TrimSpace(output))
+	}
+	return os.Rename(tmp, archive)
+}
+
+func loadDockerImage(ctx context.Context, archive string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts().ComposeUp)
+	defer cancel()
+
+	output, err := dockerCommand(ctx, "image", "load", "-q", "-i", archive).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w: %s", archive, err, bytes.TrimSpace(output))
+	}
+	return nil
+}
+
+func (cm *CacheManager) PreloadComposeImages(ctx context.Context, rootPath string, project *types.Project, dockerProject string, logger *FileLogger) (int, error) {
+	images, err := composeImages(project, dockerProject)
+	if err != nil {
+		return 0, err
+	}
+
+	dir := cm.dockerImageCacheDir(rootPath)
+	index, err := readImageIndex(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	preloaded := 0
+	for _, image := range images {
+		if err := interruptErr(ctx); err != nil {
+			return preloaded, err
+		}
+		if image.Built && image.BuildKey == "" {
+			continue
+		}
+		cached, ok := index[image.Service]
+		if !ok || cached.BuildKey != image.BuildKey || (!image.Built && cached.Ref != image.Ref) {
+			continue
+		}
+		id, err := dockerImageID(image.Ref)
+		if err != nil {
+			return preloaded, err
+		}
+		if id != "" {
+			continue
+		}
+
+		loadedID, err := dockerImageID(cached.ID)
+		if err != nil {
+			return preloaded, err
+		}
+		if loadedID == "" {
+			archive := dockerImageArchive(dir, cached.ID)
+			if !fileExists(archive) {
+				continue
+			}
+			logger.Log("loading cached image for %s", image.Service)
+			if err := loadDockerImage(ctx, archive); err != nil {
+				return preloaded, err
+			}
+		}
+		if err := Command("docker", "image", "tag", cached.ID, image.Ref).Run(); err != nil {
+			return preloaded, fmt.Errorf("failed to tag image %s: %w", image.Ref, err)
+		}
+		logger.Log("preloaded image %s for %s", image.Ref, image.Service)
+		preloaded++
+	}
+	return preloaded, nil
+}
+
+func preloadComposeImages(ctx context.Context, cm *CacheManager, rootPath, rootComposeDir string, project *types.Project, dockerProject string, logger *FileLogger) {
+	exported, err := cm.ExportComposeImages(ctx, rootPath, rootComposeDir, logger)
+	if err != nil {
+		logger.Log("warning: failed to export root images: %v", err)
+	} else if exported > 0 {
+		logger.Log("exported %d image(s) from the root to the cache", exported)
+	}
+
+	preloaded, err := cm.PreloadComposeImages(ctx, rootPath, project, dockerProject, logger)
+	if err != nil {
+		logger.Log("warning: failed to preload images: %v", err)
+		return
+	}
+	logger.Log("preloaded %d image(s)", preloaded)
+}
@@ -0,0 +1,110 @@
+package mono
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func composeRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"docker-compose.yml": "services:\n  db:\n    image: postgres:16\n  api:\n    build: ./api\n  worker:\n    image: acme/worker:dev\n    build:\n      context: ./worker\n      args:\n        MODE: fast\n",
+		"api/Dockerfile":     "FROM golang:1.24\n",
+		"api/main.go":        "package main\n",
+		"worker/Dockerfile":  "FROM python:3.12\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=mono", "-c", "user.email=mono@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestComposeImages(t *testing.T) {
+	root := composeRepo(t)
+	worktree := composeRepo(t)
+
+	images := func(dir, projectName string) map[string]composeImage {
+		t.Helper()
+		composeConfig, err := ParseComposeConfig(dir)
+		if err != nil {
+			t.Fatalf("ParseComposeConfig failed: %v", err)
+		}
+		list, err := composeImages(composeConfig.Project(), projectName)
+		if err != nil {
+			t.Fatalf("composeImages failed: %v", err)
+		}
+		byService := make(map[string]composeImage)
+		for _, image := range list {
+			byService[image.Service] = image
+		}
+		return byService
+	}
+
+	rootImages := images(root, "app")
+	if db := rootImages["db"]; db.Ref != "postgres:16" || db.Built || db.BuildKey != "" {
+		t.Errorf("expected db to use its pulled image, got %+v", db)
+	}
+	if api := rootImages["api"]; api.Ref != "app-api" || !api.Built || api.BuildKey == "" {
+		t.Errorf("expected api to be built as app-api with a build key, got %+v", api)
+	}
+	if worker := rootImages["worker"]; worker.Ref != "acme/worker:dev" || worker.BuildKey == "" || worker.BuildKey == rootImages["api"].BuildKey {
+		t.Errorf("expected worker to keep its image name and its own build key, got %+v", worker)
+	}
+
+	envImages := images(worktree, "mono-feature")
+	if api := envImages["api"]; api.Ref != "mono-feature-api" || api.BuildKey != rootImages["api"].BuildKey {
+		t.Errorf("expected an identical build context to share the root's build key, got %+v", api)
+	}
+
+	if err := os.WriteFile(filepath.Join(worktree, "api", "Dockerfile"), []byte("FROM golang:1.25\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if api := images(worktree, "mono-feature")["api"]; api.BuildKey != "" {
+		t.Errorf("expected a dirty build context to have no build key, got %q", api.BuildKey)
+	}
+	if worker := images(worktree, "mono-feature")["worker"]; worker.BuildKey != rootImages["worker"].BuildKey {
+		t.Error("expected an unrelated change not to affect the worker's build key")
+	}
+}
+
+func TestImageIndexRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	index, err := readImageIndex(dir)
+	if err != nil || len(index) != 0 {
+		t.Fatalf("expected an empty index for a fresh cache, got %v %v", index, err)
+	}
+
+	index["api"] = cachedImage{Ref: "app-api", ID: "sha256:abc", BuildKey: "key"}
+	if err := writeImageIndex(dir, index); err != nil {
+		t.Fatalf("writeImageIndex failed: %v", err)
+	}
+	got, err := readImageIndex(dir)
+	if err != nil {
+		t.Fatalf("readImageIndex failed: %v", err)
+	}
+	if got["api"] != index["api"] {
+		t.Errorf("expected %+v, got %+v", index["api"], got["api"])
+	}
+	if archive := dockerImageArchive(dir, "sha256:abc"); filepath.Base(archive) != "sha256-abc.tar" {
+		t.Errorf("unexpected archive path %s", archive)
+	}
+}
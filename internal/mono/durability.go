@@ -0,0 +1,86 @@
+package mono
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	DurabilityFast = "fast"
+	DurabilitySafe = "safe"
+)
+
+func durable() bool {
+	return globalConfig().Cache.Durability == DurabilitySafe
+}
+
+func syncTree(root string) error {
+	if !durable() {
+		return nil
+	}
+
+	err := parallelWalk(root, workerCount(workersSeed, root), func(path, rel string, d fs.DirEntry) error {
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+		return fsyncPath(path)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sync %s: %w", root, err)
+	}
+	return nil
+}
+
+func syncDir(dir string) error {
+	if !durable() {
+		return nil
+	}
+	if err := fsyncPath(dir); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", dir, err)
+	}
+	return nil
+}
+
+func syncParent(path string) error {
+	return syncDir(filepath.Dir(path))
+}
+
+func fsyncPath(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp := path + cacheTmpSuffix
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if err == nil && durable() {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return syncParent(path)
+}
@@ -1,6 +1,7 @@
 package mono
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 )
@@ -17,3 +18,18 @@ func DeriveNames(path string) (project, workspace string) {
 	return project, workspace
 }
 
+func EnvName(path string) string {
+	project, workspace := DeriveNames(path)
+	if project == "" || workspace == "" {
+		return filepath.Base(path)
+	}
+	return fmt.Sprintf("%s-%s", project, workspace)
+}
+
+func DataDir(envName string) (string, error) {
+	home, err := GetMonoHome()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, "data", envName), nil
+}
@@ -0,0 +1,58 @@
+package mono
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMonoHomeOverride(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	custom := filepath.Join(t.TempDir(), "relocated")
+	t.Setenv("MONO_HOME", custom)
+
+	home, err := GetMonoHome()
+	if err != nil {
+		t.Fatalf("GetMonoHome failed: %v", err)
+	}
+	if home != custom {
+		t.Errorf("expected MONO_HOME %s, got %s", custom, home)
+	}
+
+	dbPath, err := DBPath()
+	if err != nil {
+		t.Fatalf("DBPath failed: %v", err)
+	}
+	dataDir, err := DataDir("env")
+	if err != nil {
+		t.Fatalf("DataDir failed: %v", err)
+	}
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	logger, err := NewFileLogger("env")
+	if err != nil {
+		t.Fatalf("NewFileLogger failed: %v", err)
+	}
+	defer logger.Close()
+	configPath, err := GlobalConfigPath()
+	if err != nil {
+		t.Fatalf("GlobalConfigPath failed: %v", err)
+	}
+
+	for _, path := range []string{dbPath, dataDir, cm.LocalCacheDir, logger.file.Name(), configPath} {
+		if !strings.HasPrefix(path, custom+string(filepath.Separator)) {
+			t.Errorf("%s is not under MONO_HOME %s", path, custom)
+		}
+	}
+
+	t.Setenv("MONO_HOME", "")
+	home, err = GetMonoHome()
+	if err != nil {
+		t.Fatalf("GetMonoHome failed: %v", err)
+	}
+	if filepath.Base(home) != ".mono" {
+		t.Errorf("expected ~/.mono without MONO_HOME, got %s", home)
+	}
+}
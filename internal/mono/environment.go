@@ -13,6 +13,7 @@ type Environment struct {
 	DockerProject sql.NullString
 	RootPath      sql.NullString
 	ComposeDir    sql.NullString
+	Devcontainer  sql.NullString
 	CreatedAt     time.Time
 }
 
@@ -48,14 +49,26 @@ func (db *DB) InsertEnvironment(path, dockerProject, rootPath, composeDir string
 	return id, nil
 }
 
+func (db *DB) SetEnvironmentDevcontainer(path, container string) error {
+	_, err := db.conn.Exec(`UPDATE environments SET devcontainer = ? WHERE path = ?`, container, path)
+	if err != nil {
+		return fmt.Errorf("failed to record devcontainer: %w", err)
+	}
+	return nil
+}
+
+func (e *Environment) UsesDevcontainer() bool {
+	return e.Devcontainer.Valid && e.Devcontainer.String != ""
+}
+
 func (db *DB) GetEnvironmentByPath(path string) (*Environment, error) {
 	row := db.conn.QueryRow(
-		`SELECT id, path, docker_project, root_path, compose_dir, created_at FROM environments WHERE path = ?`,
+		`SELECT id, path, docker_project, root_path, compose_dir, devcontainer, created_at FROM environments WHERE path = ?`,
 		path,
 	)
 
 	var e Environment
-	err := row.Scan(&e.ID, &e.Path, &e.DockerProject, &e.RootPath, &e.ComposeDir, &e.CreatedAt)
+	err := row.Scan(&e.ID, &e.Path, &e.DockerProject, &e.RootPath, &e.ComposeDir, &e.Devcontainer, &e.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, errors.New("environment not found")
 	}
@@ -68,7 +81,7 @@ func (db *DB) GetEnvironmentByPath(path string) (*Environment, error) {
 
 func (db *DB) ListEnvironments() ([]*Environment, error) {
 	rows, err := db.conn.Query(
-		`SELECT id, path, docker_project, root_path, compose_dir, created_at FROM environments ORDER BY created_at DESC`,
+		`SELECT id, path, docker_project, root_path, compose_dir, devcontainer, created_at FROM environments ORDER BY created_at DESC`,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list environments: %w", err)
@@ -78,7 +91,7 @@ func (db *DB) ListEnvironments() ([]*Environment, error) {
 	var environments []*Environment
 	for rows.Next() {
 		var e Environment
-		err := rows.Scan(&e.ID, &e.Path, &e.DockerProject, &e.RootPath, &e.ComposeDir, &e.CreatedAt)
+		err := rows.Scan(&e.ID, &e.Path, &e.DockerProject, &e.RootPath, &e.ComposeDir, &e.Devcontainer, &e.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan environment: %w", err)
 		}
@@ -101,6 +114,13 @@ func (db *DB) EnvironmentExists(path string) (bool, error) {
 }
 
 func (db *DB) DeleteEnvironment(path string) error {
+	if _, err := db.conn.Exec(`DELETE FROM environment_artifacts WHERE env_path = ?`, path); err != nil {
+		return fmt.Errorf("failed to delete environment artifacts: %w", err)
+	}
+	if _, err := db.conn.Exec(`DELETE FROM detached_runs WHERE env_path = ?`, path); err != nil {
+		return fmt.Errorf("failed to delete detached run: %w", err)
+	}
+
 	result, err := db.conn.Exec(
 		`DELETE FROM environments WHERE path = ?`,
 		path,
@@ -119,3 +139,14 @@ func (db *DB) DeleteEnvironment(path string) error {
 
 	return nil
 }
+
+func (db *DB) NextEnvironmentID() (int64, error) {
+	var id int64
+	err := db.conn.QueryRow(
+		`SELECT COALESCE((SELECT seq FROM sqlite_sequence WHERE name = 'environments'), 0) + 1`,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get next environment id: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,127 @@
+package mono
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+func previousCacheEntry(cachePath string) (string, error) {
+	artifactDir := filepath.Dir(cachePath)
+	entries, err := os.ReadDir(artifactDir)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var latest string
+	var latestTime time.Time
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || strings.HasSuffix(name, cacheTmpSuffix) || name == filepath.Base(cachePath) {
+			continue
+		}
+		info, err := os.Stat(manifestPath(filepath.Join(artifactDir, name)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.ModTime().After(latestTime) {
+			latest, latestTime = filepath.Join(artifactDir, name), info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (cm *CacheManager) linkUnchangedFiles(dir, cachePath string) error {
+	strategy, err := cm.Strategy(dir)
+	if err != nil || strategy.Link == LinkHardlink {
+		return err
+	}
+
+	previous, err := previousCacheEntry(cachePath)
+	if err != nil || previous == "" {
+		return err
+	}
+
+	status, _, err := checkEntry(previous, false)
+	if err != nil || status != VerifyOK {
+		return err
+	}
+	m, _, err := readManifest(previous)
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	var candidates []string
+	err = parallelWalk(dir, workerCount(workersWalk, dir), func(path, rel string, d fs.DirEntry) error {
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		want, ok := m.Files[filepath.ToSlash(rel)]
+		if !ok || want.SHA256 == "" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() != want.Size {
+			return nil
+		}
+		mu.Lock()
+		candidates = append(candidates, rel)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range candidates {
+		if err := linkIfIdentical(filepath.Join(previous, rel), filepath.Join(dir, rel), m.Files[filepath.ToSlash(rel)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func linkIfIdentical(src, dst string, want manifestFile) error {
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	dstInfo, err := os.Lstat(dst)
+	if err != nil {
+		return err
+	}
+	if os.SameFile(srcInfo, dstInfo) || srcInfo.Mode() != dstInfo.Mode() {
+		return nil
+	}
+
+	sum, err := fileSHA256(dst)
+	if err != nil {
+		return err
+	}
+	if sum != want.SHA256 {
+		return nil
+	}
+
+	tmp := dst + cacheTmpSuffix
+	if err := os.Link(src, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
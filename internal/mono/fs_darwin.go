@@ -0,0 +1,40 @@
+package mono
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+var networkFilesystems = map[string]bool{
+	"nfs":     true,
+	"smbfs":   true,
+	"afpfs":   true,
+	"webdav":  true,
+	"macfuse": true,
+}
+
+func isNetworkFilesystem(path string) (bool, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false, err
+	}
+
+	var name []byte
+	for _, c := range st.Fstypename {
+		if c == 0 {
+			break
+		}
+		name = append(name, byte(c))
+	}
+	return networkFilesystems[string(name)], nil
+}
+
+func cloneContents(out, in *os.File) error {
+	return unix.ENOTSUP
+}
+
+func cloneFile(src, dst string) error {
+	return unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW)
+}
@@ -0,0 +1,58 @@
+package mono
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+var networkFilesystems = map[int64]bool{
+	0x6969:     true,
+	0x517b:     true,
+	0xff534d42: true,
+	0xfe534d42: true,
+	0x5346414f: true,
+	0x00c36400: true,
+	0x65735546: true,
+}
+
+func isNetworkFilesystem(path string) (bool, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false, err
+	}
+	return networkFilesystems[int64(st.Type)], nil
+}
+
+func cloneContents(out, in *os.File) error {
+	return unix.IoctlFileClone(int(out.Fd()), int(in.Fd()))
+}
+
+func cloneFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if err := unix.IoctlFileClone(int(out.Fd()), int(in.Fd())); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(dst, info.Mode())
+}
@@ -0,0 +1,195 @@
+package mono
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+type LinkMode string
+
+const (
+	LinkAuto     LinkMode = "auto"
+	LinkHardlink LinkMode = "hardlink"
+	LinkReflink  LinkMode = "reflink"
+	LinkCopy     LinkMode = "copy"
+)
+
+type FilesystemCapabilities struct {
+	Device   uint64
+	Hardlink bool
+	Reflink  bool
+}
+
+type CacheStrategy struct {
+	Link           LinkMode
+	SameFilesystem bool
+}
+
+func (s CacheStrategy) String() string {
+	switch {
+	case !s.SameFilesystem:
+		return "copy (environment and cache are on different filesystems)"
+	case s.Link == LinkHardlink:
+		return "hardlink (same filesystem as the cache)"
+	case s.Link == LinkReflink:
+		return "reflink (same filesystem as the cache, copy-on-write)"
+	default:
+		return "copy (same filesystem as the cache, neither hardlinks nor reflinks supported)"
+	}
+}
+
+func probeFilesystem(dir string) (FilesystemCapabilities, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return FilesystemCapabilities{}, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	device, err := deviceID(dir)
+	if err != nil {
+		return FilesystemCapabilities{}, err
+	}
+
+	probeDir, err := os.MkdirTemp(dir, ".fsprobe-*"+cacheTmpSuffix)
+	if err != nil {
+		return FilesystemCapabilities{}, fmt.Errorf("failed to create filesystem probe: %w", err)
+	}
+
+	src := filepath.Join(probeDir, "src")
+	if err := os.WriteFile(src, []byte("mono"), 0644); err != nil {
+		os.RemoveAll(probeDir)
+		return FilesystemCapabilities{}, fmt.Errorf("failed to write filesystem probe: %w", err)
+	}
+
+	caps := FilesystemCapabilities{
+		Device:   device,
+		Hardlink: os.Link(src, filepath.Join(probeDir, "link")) == nil,
+		Reflink:  cloneFile(src, filepath.Join(probeDir, "clone")) == nil,
+	}
+
+	if err := os.RemoveAll(probeDir); err != nil {
+		return FilesystemCapabilities{}, fmt.Errorf("failed to remove filesystem probe: %w", err)
+	}
+	return caps, nil
+}
+
+func deviceID(path string) (uint64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("cannot determine the device of %s", path)
+	}
+	return uint64(st.Dev), nil
+}
+
+func existingDeviceID(path string) (uint64, error) {
+	for {
+		device, err := deviceID(path)
+		if !os.IsNotExist(err) {
+			return device, err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return 0, err
+		}
+		path = parent
+	}
+}
+
+func (cm *CacheManager) Strategy(path string) (CacheStrategy, error) {
+	device, err := existingDeviceID(path)
+	if err != nil {
+		return CacheStrategy{}, fmt.Errorf("failed to inspect filesystem of %s: %w", path, err)
+	}
+
+	if device != cm.FS.Device {
+		return CacheStrategy{Link: LinkCopy}, nil
+	}
+
+	strategy := CacheStrategy{Link: LinkCopy, SameFilesystem: true}
+	for _, mode := range globalConfig().Cache.Link.preference() {
+		if (mode == LinkReflink && cm.FS.Reflink) || (mode == LinkHardlink && cm.FS.Hardlink) {
+			strategy.Link = mode
+			break
+		}
+	}
+	return strategy, nil
+}
+
+func (m LinkMode) preference() []LinkMode {
+	switch m {
+	case LinkHardlink:
+		return []LinkMode{LinkHardlink, LinkReflink}
+	case LinkCopy:
+		return nil
+	default:
+		return []LinkMode{LinkReflink, LinkHardlink}
+	}
+}
+
+func (m LinkMode) valid() bool {
+	switch m {
+	case LinkAuto, LinkHardlink, LinkReflink, LinkCopy:
+		return true
+	}
+	return false
+}
+
+func linkFile(src, dst string, mode LinkMode) error {
+	var err error
+	switch mode {
+	case LinkCopy:
+		return copyFile(src, dst)
+	case LinkReflink:
+		err = reflinkFile(src, dst)
+	default:
+		err = os.Link(src, dst)
+	}
+	if os.IsExist(err) {
+		return nil
+	}
+	return err
+}
+
+func reflinkFile(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return copyFile(src, dst)
+	}
+	if err := cloneFile(src, dst); err != nil {
+		return err
+	}
+	return copyMetadata(src, dst, info)
+}
+
+func LinkTree(src, dst string, mode LinkMode) error {
+	var dirs []copiedDir
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		dstPath := filepath.Join(dst, relPath)
+
+		if info.IsDir() {
+			dirs = append(dirs, copiedDir{src: path, dst: dstPath, info: info})
+			return os.MkdirAll(dstPath, info.Mode())
+		}
+
+		return linkFile(path, dstPath, mode)
+	})
+	if err != nil || (mode != LinkCopy && mode != LinkReflink) {
+		return err
+	}
+	return copyDirMetadata(dirs)
+}
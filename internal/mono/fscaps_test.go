@@ -0,0 +1,132 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProbeFilesystem(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache_local")
+
+	caps, err := probeFilesystem(dir)
+	if err != nil {
+		t.Fatalf("probeFilesystem failed: %v", err)
+	}
+	if !caps.Hardlink {
+		t.Error("expected hardlinks to be supported on the test filesystem")
+	}
+
+	device, err := deviceID(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps.Device != device {
+		t.Errorf("expected device %d, got %d", device, caps.Device)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected the probe to clean up, found %d entries", len(entries))
+	}
+}
+
+func TestCacheStrategy(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	envPath := filepath.Join(t.TempDir(), "env", "target")
+
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+	for _, tc := range []struct {
+		name string
+		link LinkMode
+		fs   FilesystemCapabilities
+		want CacheStrategy
+	}{
+		{"reflink preferred", LinkAuto, FilesystemCapabilities{Device: cm.FS.Device, Hardlink: true, Reflink: true}, CacheStrategy{Link: LinkReflink, SameFilesystem: true}},
+		{"hardlink", LinkAuto, FilesystemCapabilities{Device: cm.FS.Device, Hardlink: true}, CacheStrategy{Link: LinkHardlink, SameFilesystem: true}},
+		{"reflink", LinkAuto, FilesystemCapabilities{Device: cm.FS.Device, Reflink: true}, CacheStrategy{Link: LinkReflink, SameFilesystem: true}},
+		{"hardlink configured", LinkHardlink, FilesystemCapabilities{Device: cm.FS.Device, Hardlink: true, Reflink: true}, CacheStrategy{Link: LinkHardlink, SameFilesystem: true}},
+		{"hardlink configured without hardlinks", LinkHardlink, FilesystemCapabilities{Device: cm.FS.Device, Reflink: true}, CacheStrategy{Link: LinkReflink, SameFilesystem: true}},
+		{"copy configured", LinkCopy, FilesystemCapabilities{Device: cm.FS.Device, Hardlink: true, Reflink: true}, CacheStrategy{Link: LinkCopy, SameFilesystem: true}},
+		{"copy", LinkAuto, FilesystemCapabilities{Device: cm.FS.Device}, CacheStrategy{Link: LinkCopy, SameFilesystem: true}},
+		{"other filesystem", LinkAuto, FilesystemCapabilities{Device: cm.FS.Device + 1, Hardlink: true}, CacheStrategy{Link: LinkCopy}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := DefaultGlobalConfig()
+			cfg.Cache.Link = tc.link
+			SetGlobalConfig(cfg)
+			cm.FS = tc.fs
+			got, err := cm.Strategy(envPath)
+			if err != nil {
+				t.Fatalf("Strategy failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRestoreAcrossFilesystemsCopies(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	testDir := t.TempDir()
+	envPath := filepath.Join(testDir, "env")
+	file := filepath.Join(envPath, "node_modules", "pkg", "index.js")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("module.exports = 1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	artifact := ArtifactConfig{Name: "npm", Paths: []ArtifactPath{{Path: "node_modules"}}}
+	entries, err := cm.PrepareArtifactCache([]ArtifactConfig{artifact}, envPath, envPath)
+	if err != nil {
+		t.Fatalf("PrepareArtifactCache failed: %v", err)
+	}
+	entry := entries[0]
+
+	cm.FS.Device++
+	if err := cm.StoreToCache(entry); err != nil {
+		t.Fatalf("StoreToCache failed: %v", err)
+	}
+	cached := filepath.Join(entry.CachePath, "node_modules", "pkg", "index.js")
+	assertDistinctFiles(t, cached, file)
+
+	if err := os.RemoveAll(filepath.Join(envPath, "node_modules")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.RestoreFromCache(entry, nil); err != nil {
+		t.Fatalf("RestoreFromCache failed: %v", err)
+	}
+	assertDistinctFiles(t, cached, file)
+}
+
+func assertDistinctFiles(t *testing.T, a, b string) {
+	t.Helper()
+	aInfo, err := os.Stat(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bInfo, err := os.Stat(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if os.SameFile(aInfo, bInfo) {
+		t.Errorf("expected %s to be a copy of %s, not a link", b, a)
+	}
+}
@@ -0,0 +1,193 @@
+package mono
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	githubActionsCacheURLEnv   = "ACTIONS_CACHE_URL"
+	githubActionsCacheTokenEnv = "ACTIONS_RUNTIME_TOKEN"
+	githubActionsAccept        = "application/json;api-version=6.0-preview.1"
+	githubActionsChunkSize     = 32 << 20
+)
+
+type githubActionsStore struct {
+	url   string
+	token string
+}
+
+type githubActionsCacheEntry struct {
+	CacheKey        string `json:"cacheKey"`
+	ArchiveLocation string `json:"archiveLocation"`
+}
+
+func githubActionsStoreFromEnv(cfg CacheConfig) (githubActionsStore, bool) {
+	if cfg.GitHubActions != nil && !*cfg.GitHubActions {
+		return githubActionsStore{}, false
+	}
+	base, token := os.Getenv(githubActionsCacheURLEnv), os.Getenv(githubActionsCacheTokenEnv)
+	if base == "" || token == "" {
+		return githubActionsStore{}, false
+	}
+	return githubActionsStore{url: strings.TrimRight(base, "/") + "/_apis/artifactcache/", token: token}, true
+}
+
+func githubActionsKey(entry ArtifactCacheEntry) string {
+	return "mono-" + entry.ProjectID + "-" + entry.Name + "-" + entry.Key
+}
+
+func githubActionsVersion() string {
+	sum := sha256.Sum256([]byte("mono|" + remoteBlobName))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s githubActionsStore) name() string {
+	return "github actions cache"
+}
+
+func (s githubActionsStore) do(ctx context.Context, method, resource string, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.url+resource, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s url: %w", githubActionsCacheURLEnv, err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	req.Header.Set("Accept", githubActionsAccept)
+	if body != nil {
+		req.ContentLength = size
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (s githubActionsStore) postJSON(ctx context.Context, resource string, payload, out any) (int, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := s.do(ctx, http.MethodPost, resource, bytes.NewReader(data), int64(len(data)), http.Header{"Content-Type": {"application/json"}})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || out == nil {
+		return resp.StatusCode, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("invalid response to POST %s: %w", resource, err)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s githubActionsStore) lookup(ctx context.Context, entry ArtifactCacheEntry) (*githubActionsCacheEntry, error) {
+	key := githubActionsKey(entry)
+	query := url.Values{"keys": {key}, "version": {githubActionsVersion()}}
+	resp, err := s.do(ctx, http.MethodGet, "cache?"+query.Encode(), nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusNotFound:
+		return nil, nil
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("cache lookup returned %s", resp.Status)
+	}
+
+	var found githubActionsCacheEntry
+	if err := json.NewDecoder(resp.Body).Decode(&found); err != nil {
+		return nil, fmt.Errorf("invalid cache lookup response: %w", err)
+	}
+	if found.CacheKey != key || found.ArchiveLocation == "" {
+		return nil, nil
+	}
+	return &found, nil
+}
+
+func (s githubActionsStore) has(ctx context.Context, entry ArtifactCacheEntry) (bool, error) {
+	found, err := s.lookup(ctx, entry)
+	return found != nil, err
+}
+
+func (s githubActionsStore) get(ctx context.Context, entry ArtifactCacheEntry) (*remoteBlob, error) {
+	found, err := s.lookup(ctx, entry)
+	if err != nil || found == nil {
+		return nil, err
+	}
+	body, err := remoteGet(ctx, found.ArchiveLocation)
+	if err != nil || body == nil {
+		return nil, err
+	}
+	return &remoteBlob{body: body}, nil
+}
+
+func (s githubActionsStore) put(ctx context.Context, entry ArtifactCacheEntry, bundle *remoteBundle) error {
+	var reserved struct {
+		CacheID int64 `json:"cacheId"`
+	}
+	status, err := s.postJSON(ctx, "caches", map[string]any{
+		"key":       githubActionsKey(entry),
+		"version":   githubActionsVersion(),
+		"cacheSize": bundle.size,
+	}, &reserved)
+	if err != nil {
+		return err
+	}
+	switch {
+	case status == http.StatusConflict:
+		return nil
+	case status < 200 || status >= 300:
+		return fmt.Errorf("cache reservation returned %d", status)
+	}
+	resource := "caches/" + strconv.FormatInt(reserved.CacheID, 10)
+
+	f, err := os.Open(bundle.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for offset := int64(0); offset < bundle.size; offset += githubActionsChunkSize {
+		n := min(githubActionsChunkSize, bundle.size-offset)
+		resp, err := s.do(ctx, http.MethodPatch, resource, io.NewSectionReader(f, offset, n), n, http.Header{
+			"Content-Type":  {"application/octet-stream"},
+			"Content-Range": {fmt.Sprintf("bytes %d-%d/*", offset, offset+n-1)},
+		})
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("cache upload returned %s", resp.Status)
+		}
+	}
+
+	status, err = s.postJSON(ctx, resource, map[string]any{"size": bundle.size}, nil)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("cache commit returned %d", status)
+	}
+	return nil
+}
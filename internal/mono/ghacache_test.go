@@ -0,0 +1,179 @@
+package mono
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type fakeGitHubActionsCache struct {
+	mu        sync.Mutex
+	url       string
+	pending   map[int64][]byte
+	committed map[string][]byte
+	keys      map[int64]string
+	nextID    int64
+	reserves  int
+}
+
+func (f *fakeGitHubActionsCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/archives" {
+		data, ok := f.committed[r.URL.Query().Get("key")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("Accept") != githubActionsAccept {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	resource := strings.TrimPrefix(r.URL.Path, "/_apis/artifactcache/")
+	switch {
+	case r.Method == http.MethodGet && resource == "cache":
+		key := r.URL.Query().Get("keys")
+		if _, ok := f.committed[key]; !ok || r.URL.Query().Get("version") != githubActionsVersion() {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		json.NewEncoder(w).Encode(githubActionsCacheEntry{CacheKey: key, ArchiveLocation: f.url + "/archives?key=" + key})
+	case r.Method == http.MethodPost && resource == "caches":
+		var req struct {
+			Key string `json:"key"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.reserves++
+		for _, key := range f.keys {
+			if key == req.Key {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+		}
+		f.nextID++
+		f.keys[f.nextID] = req.Key
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]int64{"cacheId": f.nextID})
+	case strings.HasPrefix(resource, "caches/"):
+		id, err := strconv.ParseInt(strings.TrimPrefix(resource, "caches/"), 10, 64)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodPatch:
+			var start, end int64
+			if _, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/*", &start, &end); err != nil || start != int64(len(f.pending[id])) {
+				http.Error(w, "bad range", http.StatusBadRequest)
+				return
+			}
+			data, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			f.pending[id] = append(f.pending[id], data...)
+		case http.MethodPost:
+			f.committed[f.keys[id]] = f.pending[id]
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestGitHubActionsCacheRoundTrip(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+	SetGlobalConfig(DefaultGlobalConfig())
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	server := &fakeGitHubActionsCache{pending: make(map[int64][]byte), committed: make(map[string][]byte), keys: make(map[int64]string)}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	server.url = ts.URL
+	t.Setenv(githubActionsCacheURLEnv, ts.URL+"/")
+	t.Setenv(githubActionsCacheTokenEnv, "token")
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	target := filepath.Join(t.TempDir(), "target")
+	if err := os.MkdirAll(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(target, "lib.rlib"), []byte("compiled"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entry := ArtifactCacheEntry{
+		Name:      "cargo",
+		ProjectID: "proj",
+		Key:       "key123",
+		CachePath: filepath.Join(cm.LocalCacheDir, "proj", "cargo", "key123"),
+		EnvPaths:  []string{target},
+	}
+	if err := cm.StoreToCache(entry); err != nil {
+		t.Fatalf("StoreToCache failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := cm.UploadToRemote(ctx, entry); err != nil {
+		t.Fatalf("UploadToRemote failed: %v", err)
+	}
+	if _, ok := server.committed[githubActionsKey(entry)]; !ok {
+		t.Fatalf("expected the entry to be committed, got %v", server.keys)
+	}
+	if err := cm.UploadToRemote(ctx, entry); err != nil || server.reserves != 1 {
+		t.Errorf("expected a committed entry not to be reserved again, got %d reserves: %v", server.reserves, err)
+	}
+
+	for _, p := range []string{entry.CachePath, manifestPath(entry.CachePath), signaturePath(entry.CachePath)} {
+		if err := os.RemoveAll(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fetched, err := cm.FetchFromRemote(ctx, entry)
+	if err != nil || !fetched {
+		t.Fatalf("FetchFromRemote = %v, %v", fetched, err)
+	}
+	data, err := os.ReadFile(filepath.Join(entry.CachePath, "target", "lib.rlib"))
+	if err != nil || string(data) != "compiled" {
+		t.Errorf("unexpected fetched content %q: %v", data, err)
+	}
+	if status, problems, err := checkEntry(entry.CachePath, false); err != nil || status != VerifyOK {
+		t.Errorf("expected the fetched entry to verify, got %s %v %v", status, problems, err)
+	}
+
+	other := entry
+	other.Key = "other"
+	other.CachePath = filepath.Join(cm.LocalCacheDir, "proj", "cargo", "other")
+	if fetched, err := cm.FetchFromRemote(ctx, other); err != nil || fetched {
+		t.Errorf("expected a miss, got %v, %v", fetched, err)
+	}
+
+	disabled := false
+	cfg := DefaultGlobalConfig()
+	cfg.Cache.GitHubActions = &disabled
+	SetGlobalConfig(cfg)
+	if remoteCacheEnabled() {
+		t.Error("expected github_actions: false to disable the actions cache")
+	}
+}
@@ -0,0 +1,164 @@
+package mono
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type TimeoutConfig struct {
+	ComposeUp   time.Duration `yaml:"compose_up"`
+	ComposeDown time.Duration `yaml:"compose_down"`
+	Script      time.Duration `yaml:"script"`
+	Tmux        time.Duration `yaml:"tmux"`
+	Command     time.Duration `yaml:"command"`
+}
+
+type CacheConfig struct {
+	TrustedKeys       []string          `yaml:"trusted_keys"`
+	RequireSignatures bool              `yaml:"require_signatures"`
+	Durability        string            `yaml:"durability"`
+	KeyRevalidate     time.Duration     `yaml:"key_revalidate"`
+	Remote            RemoteCacheConfig `yaml:"remote"`
+	GitHubActions     *bool             `yaml:"github_actions"`
+	MaxSize           string            `yaml:"max_size"`
+	Link              LinkMode          `yaml:"link"`
+}
+
+func (c CacheConfig) maxSizeBytes() (int64, error) {
+	if c.MaxSize == "" {
+		return 0, nil
+	}
+	size, err := ParseByteSize(c.MaxSize)
+	if err != nil {
+		return 0, fmt.Errorf("cache.max_size: %w", err)
+	}
+	return size, nil
+}
+
+type WorkerConfig struct {
+	Seed  int `yaml:"seed"`
+	Touch int `yaml:"touch"`
+	Walk  int `yaml:"walk"`
+	Jobs  int `yaml:"jobs"`
+}
+
+type GlobalConfig struct {
+	Timeouts  TimeoutConfig  `yaml:"timeouts"`
+	Cache     CacheConfig    `yaml:"cache"`
+	Workers   WorkerConfig   `yaml:"workers"`
+	Sccache   SccacheConfig  `yaml:"sccache"`
+	Callbacks CallbackConfig `yaml:"callbacks"`
+	Daemon    DaemonConfig   `yaml:"daemon"`
+	Hosts     HostsConfig    `yaml:"hosts"`
+}
+
+var activeGlobalConfig atomic.Pointer[GlobalConfig]
+
+func DefaultGlobalConfig() *GlobalConfig {
+	cfg := &GlobalConfig{}
+	cfg.ApplyDefaults()
+	return cfg
+}
+
+func (c *GlobalConfig) ApplyDefaults() {
+	if c.Timeouts.ComposeUp <= 0 {
+		c.Timeouts.ComposeUp = 5 * time.Minute
+	}
+	if c.Timeouts.ComposeDown <= 0 {
+		c.Timeouts.ComposeDown = 2 * time.Minute
+	}
+	if c.Timeouts.Script <= 0 {
+		c.Timeouts.Script = 10 * time.Minute
+	}
+	if c.Timeouts.Tmux <= 0 {
+		c.Timeouts.Tmux = 5 * time.Second
+	}
+	if c.Timeouts.Command <= 0 {
+		c.Timeouts.Command = DefaultTimeout
+	}
+	if c.Cache.Durability == "" {
+		c.Cache.Durability = DurabilityFast
+	}
+	if c.Cache.Link == "" {
+		c.Cache.Link = LinkAuto
+	}
+	if c.Daemon.Interval <= 0 {
+		c.Daemon.Interval = time.Minute
+	}
+	if c.Callbacks.Timeout <= 0 {
+		c.Callbacks.Timeout = 5 * time.Second
+	}
+	if c.Cache.KeyRevalidate <= 0 {
+		c.Cache.KeyRevalidate = 24 * time.Hour
+	}
+	if c.Cache.Remote.Timeout <= 0 {
+		c.Cache.Remote.Timeout = 5 * time.Minute
+	}
+	if c.Hosts.Domain == "" {
+		c.Hosts.Domain = "test"
+	}
+}
+
+func GlobalConfigPath() (string, error) {
+	home, err := GetMonoHome()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, "config.yml"), nil
+}
+
+func LoadGlobalConfig() (*GlobalConfig, error) {
+	path, err := GlobalConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return DefaultGlobalConfig(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var cfg GlobalConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	cfg.ApplyDefaults()
+
+	if cfg.Cache.Durability != DurabilityFast && cfg.Cache.Durability != DurabilitySafe {
+		return nil, fmt.Errorf("invalid %s: cache.durability must be %s or %s, got %q", path, DurabilityFast, DurabilitySafe, cfg.Cache.Durability)
+	}
+	if !cfg.Cache.Link.valid() {
+		return nil, fmt.Errorf("invalid %s: cache.link must be %s, %s, %s or %s, got %q", path, LinkAuto, LinkReflink, LinkHardlink, LinkCopy, cfg.Cache.Link)
+	}
+	if err := cfg.Cache.Remote.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	if _, err := cfg.Cache.maxSizeBytes(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+func SetGlobalConfig(cfg *GlobalConfig) {
+	activeGlobalConfig.Store(cfg)
+}
+
+func globalConfig() *GlobalConfig {
+	if cfg := activeGlobalConfig.Load(); cfg != nil {
+		return cfg
+	}
+	return DefaultGlobalConfig()
+}
+
+func timeouts() TimeoutConfig {
+	return globalConfig().Timeouts
+}
@@ -0,0 +1,157 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadGlobalConfigDefaults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := LoadGlobalConfig()
+	if err != nil {
+		t.Fatalf("failed to load global config: %v", err)
+	}
+
+	if cfg.Timeouts.ComposeUp != 5*time.Minute {
+		t.Errorf("expected default compose_up of 5m, got %v", cfg.Timeouts.ComposeUp)
+	}
+	if cfg.Timeouts.Command != DefaultTimeout {
+		t.Errorf("expected default command timeout of %v, got %v", DefaultTimeout, cfg.Timeouts.Command)
+	}
+}
+
+func TestLoadGlobalConfigTimeouts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path := filepath.Join(home, ".mono", "config.yml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	data := "timeouts:\n  compose_up: 20m\n  tmux: 10s\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadGlobalConfig()
+	if err != nil {
+		t.Fatalf("failed to load global config: %v", err)
+	}
+
+	if cfg.Timeouts.ComposeUp != 20*time.Minute {
+		t.Errorf("expected compose_up of 20m, got %v", cfg.Timeouts.ComposeUp)
+	}
+	if cfg.Timeouts.Tmux != 10*time.Second {
+		t.Errorf("expected tmux of 10s, got %v", cfg.Timeouts.Tmux)
+	}
+	if cfg.Timeouts.ComposeDown != 2*time.Minute {
+		t.Errorf("expected default compose_down of 2m, got %v", cfg.Timeouts.ComposeDown)
+	}
+}
+
+func TestLoadGlobalConfigDurability(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cfg, err := LoadGlobalConfig()
+	if err != nil {
+		t.Fatalf("failed to load global config: %v", err)
+	}
+	if cfg.Cache.Durability != DurabilityFast {
+		t.Errorf("expected default durability %s, got %s", DurabilityFast, cfg.Cache.Durability)
+	}
+
+	path := filepath.Join(home, ".mono", "config.yml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("cache:\n  durability: safe\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err = LoadGlobalConfig()
+	if err != nil {
+		t.Fatalf("failed to load global config: %v", err)
+	}
+	if cfg.Cache.Durability != DurabilitySafe {
+		t.Errorf("expected durability %s, got %s", DurabilitySafe, cfg.Cache.Durability)
+	}
+
+	if err := os.WriteFile(path, []byte("cache:\n  durability: paranoid\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadGlobalConfig(); err == nil {
+		t.Error("expected unknown durability to be rejected")
+	}
+}
+
+func TestSafeDurabilityStoresAndRestores(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	cfg := DefaultGlobalConfig()
+	cfg.Cache.Durability = DurabilitySafe
+	SetGlobalConfig(cfg)
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	targetDir := filepath.Join(t.TempDir(), "target")
+	if err := os.MkdirAll(filepath.Join(targetDir, "debug"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "debug", "app"), []byte("bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := ArtifactCacheEntry{
+		Name:      "cargo",
+		Key:       "key1",
+		CachePath: filepath.Join(cm.LocalCacheDir, "proj", "cargo", "key1"),
+		EnvPaths:  []string{targetDir},
+	}
+	if err := cm.StoreToCache(entry); err != nil {
+		t.Fatalf("StoreToCache failed: %v", err)
+	}
+	if err := os.RemoveAll(targetDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.RestoreFromCache(entry, nil); err != nil {
+		t.Fatalf("RestoreFromCache failed: %v", err)
+	}
+	if !fileExists(filepath.Join(targetDir, "debug", "app")) {
+		t.Error("expected restored file")
+	}
+}
+
+func TestLoadGlobalConfigCacheMaxSize(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path := filepath.Join(home, ".mono", "config.yml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("cache:\n  max_size: 50GB\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadGlobalConfig()
+	if err != nil {
+		t.Fatalf("failed to load global config: %v", err)
+	}
+	if size, err := cfg.Cache.maxSizeBytes(); err != nil || size != 50<<30 {
+		t.Errorf("expected max_size of 50GB, got %d, %v", size, err)
+	}
+
+	if err := os.WriteFile(path, []byte("cache:\n  max_size: lots\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadGlobalConfig(); err == nil {
+		t.Error("expected an invalid max_size to be rejected")
+	}
+}
@@ -0,0 +1,85 @@
+package mono
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	goCacheDir      = ".gocache"
+	pathTypeGoMod   = "gomod"
+	pathTypeGoBuild = "gobuild"
+)
+
+func goArtifact(keyFile, warmCommand string) ArtifactConfig {
+	if dir := filepath.Dir(keyFile); dir != "." && warmCommand != "" {
+		warmCommand = fmt.Sprintf("(cd %q && %s)", dir, warmCommand)
+	}
+	return ArtifactConfig{
+		Name:        "go",
+		KeyFiles:    []string{keyFile},
+		KeyCommands: []string{"go version"},
+		Paths: []ArtifactPath{
+			{Path: filepath.Join(goCacheDir, "mod"), Type: pathTypeGoMod},
+			{Path: filepath.Join(goCacheDir, "build"), Type: pathTypeGoBuild},
+		},
+		WarmCommand: warmCommand,
+	}
+}
+
+func mergeGoArtifact(a, b ArtifactConfig) ArtifactConfig {
+	a.KeyFiles = append(a.KeyFiles, b.KeyFiles...)
+	switch {
+	case b.WarmCommand == "":
+	case a.WarmCommand == "":
+		a.WarmCommand = b.WarmCommand
+	default:
+		a.WarmCommand += " && " + b.WarmCommand
+	}
+	return a
+}
+
+func goCacheEnv(artifacts []ArtifactConfig, envPath string) []string {
+	var modCache, buildCache string
+	for _, artifact := range artifacts {
+		for _, p := range artifact.Paths {
+			switch {
+			case p.Type == pathTypeGoMod && modCache == "":
+				modCache = p.resolve(envPath)
+			case p.Type == pathTypeGoBuild && buildCache == "":
+				buildCache = p.resolve(envPath)
+			}
+		}
+	}
+
+	var vars []string
+	if modCache != "" {
+		vars = append(vars, "GOMODCACHE="+modCache, "GOFLAGS="+strings.TrimSpace(os.Getenv("GOFLAGS")+" -modcacherw"))
+	}
+	if buildCache != "" {
+		vars = append(vars, "GOCACHE="+buildCache)
+	}
+	return vars
+}
+
+func makeDirsWritable(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Mode().Perm()&0200 != 0 {
+			return nil
+		}
+		return os.Chmod(path, info.Mode().Perm()|0200)
+	})
+}
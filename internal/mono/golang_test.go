@@ -0,0 +1,105 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDetectGoArtifacts(t *testing.T) {
+	testDir := t.TempDir()
+
+	for _, name := range []string{"go.mod", "go.sum", "services/api/go.mod", "services/api/go.sum", "tools/go.mod", ".gocache/mod/github.com/pkg/errors@v0.9.1/go.mod"} {
+		path := filepath.Join(testDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	artifacts := detectArtifacts(testDir)
+	if len(artifacts) != 1 {
+		t.Fatalf("expected a single go artifact, got %+v", artifacts)
+	}
+	a := artifacts[0]
+	if a.Name != "go" {
+		t.Errorf("expected name 'go', got %s", a.Name)
+	}
+	wantKeys := []string{"go.mod", "go.sum", "services/api/go.mod", "services/api/go.sum", "tools/go.mod"}
+	if !slices.Equal(a.KeyFiles, wantKeys) {
+		t.Errorf("expected key_files %v, got %v", wantKeys, a.KeyFiles)
+	}
+	if !slices.Equal(a.KeyCommands, []string{"go version"}) {
+		t.Errorf("expected the go version in the key, got %v", a.KeyCommands)
+	}
+	wantPaths := []ArtifactPath{{Path: ".gocache/mod", Type: pathTypeGoMod}, {Path: ".gocache/build", Type: pathTypeGoBuild}}
+	if !slices.Equal(a.Paths, wantPaths) {
+		t.Errorf("expected paths %v, got %v", wantPaths, a.Paths)
+	}
+	wantWarm := `go mod download && go build ./... && (cd "services/api" && go mod download && go build ./...) && (cd "tools" && go mod download && go build ./...)`
+	if a.WarmCommand != wantWarm {
+		t.Errorf("expected warm command %q, got %q", wantWarm, a.WarmCommand)
+	}
+	if err := validateArtifactPaths(a); err != nil {
+		t.Errorf("expected the detected go artifact to be valid: %v", err)
+	}
+}
+
+func TestGoCacheEnvVars(t *testing.T) {
+	t.Setenv("GOFLAGS", "-mod=mod")
+	envPath := t.TempDir()
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	disabled := false
+	env := cm.EnvVars(BuildConfig{Sccache: &disabled, Artifacts: []ArtifactConfig{goArtifact("go.sum", "")}}, envPath, envPath)
+	want := []string{
+		"GOMODCACHE=" + filepath.Join(envPath, ".gocache", "mod"),
+		"GOFLAGS=-mod=mod -modcacherw",
+		"GOCACHE=" + filepath.Join(envPath, ".gocache", "build"),
+	}
+	if !slices.Equal(env, want) {
+		t.Errorf("expected %v, got %v", want, env)
+	}
+
+	if env := cm.EnvVars(BuildConfig{Sccache: &disabled}, envPath, envPath); len(env) != 0 {
+		t.Errorf("expected no go variables without a go artifact, got %v", env)
+	}
+}
+
+func TestGoModCacheRestoreFixes(t *testing.T) {
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	if !shouldSkipPath("cache/vcs/", pathTypeGoMod) || shouldSkipPath("cache/download/", pathTypeGoMod) {
+		t.Error("expected only VCS checkouts to be skipped from the module cache")
+	}
+
+	modCache := filepath.Join(t.TempDir(), "mod")
+	module := filepath.Join(modCache, "github.com", "pkg", "errors@v0.9.1")
+	if err := os.MkdirAll(module, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(module, "errors.go"), []byte("package errors"), 0444); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{module, filepath.Dir(module)} {
+		if err := os.Chmod(dir, 0555); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := cm.ApplyPostRestoreFixes(pathTypeGoMod, modCache); err != nil {
+		t.Fatalf("ApplyPostRestoreFixes failed: %v", err)
+	}
+	if err := os.RemoveAll(modCache); err != nil {
+		t.Errorf("expected a restored module cache to be removable: %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+package mono
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	checkWorkspace = "workspace"
+	checkDataDir   = "data dir"
+	checkTmux      = "tmux"
+	checkConfig    = "config"
+	checkDocker    = "docker"
+	checkService   = "service"
+	checkProcess   = "process"
+	checkArtifact  = "artifact"
+	checkCache     = "cache"
+)
+
+type HealthCheck struct {
+	Name   string
+	Kind   string
+	Target string
+	OK     bool
+	Detail string
+}
+
+type HealthReport struct {
+	Name   string
+	Path   string
+	Checks []HealthCheck
+}
+
+func (r *HealthReport) Healthy() bool {
+	for _, c := range r.Checks {
+		if !c.OK {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *HealthReport) Failed() int {
+	failed := 0
+	for _, c := range r.Checks {
+		if !c.OK {
+			failed++
+		}
+	}
+	return failed
+}
+
+func (r *HealthReport) add(kind, target string, ok bool, format string, args ...any) {
+	name := kind
+	if target != "" {
+		name = kind + " " + target
+	}
+	r.Checks = append(r.Checks, HealthCheck{
+		Name:   name,
+		Kind:   kind,
+		Target: target,
+		OK:     ok,
+		Detail: fmt.Sprintf(format, args...),
+	})
+}
+
+func Health(path string) (*HealthReport, error) {
+	db, err := OpenDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	env, err := db.GetEnvironmentByPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("environment not found: %s", path)
+	}
+
+	envName := EnvName(path)
+	report := &HealthReport{Name: envName, Path: path}
+
+	if dirExists(path) {
+		report.add(checkWorkspace, "", true, "%s", path)
+	} else {
+		report.add(checkWorkspace, "", false, "%s does not exist", path)
+	}
+
+	dataDir, err := DataDir(envName)
+	if err != nil {
+		return nil, err
+	}
+	if dirExists(dataDir) {
+		report.add(checkDataDir, "", true, "%s", dataDir)
+	} else {
+		report.add(checkDataDir, "", false, "%s is missing", dataDir)
+	}
+
+	sessionName := SessionName(envName)
+	if !SessionExists(sessionName) {
+		report.add(checkTmux, "", false, "session %s not found", sessionName)
+	} else if err := ProbeSession(sessionName); err != nil {
+		report.add(checkTmux, "", false, "session %s not responding: %v", sessionName, err)
+	} else {
+		report.add(checkTmux, "", true, "session %s responding", sessionName)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		report.add(checkConfig, "", false, "%v", err)
+		return report, nil
+	}
+	cfg.ApplyDefaults(path)
+
+	if env.DockerProject.Valid && env.DockerProject.String != "" {
+		composeDir := path
+		if env.ComposeDir.Valid && env.ComposeDir.String != "" {
+			composeDir = filepath.Join(path, env.ComposeDir.String)
+		}
+		checkServices(report, env.DockerProject.String, composeDir)
+	}
+
+	if env.UsesDevcontainer() {
+		checkDevcontainer(report, envName)
+	}
+
+	if len(cfg.Processes) > 0 && !env.DockerProject.Valid && !env.UsesDevcontainer() {
+		if err := checkProcesses(report, cfg, envName); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkArtifacts(report, db, cfg, path); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func checkServices(report *HealthReport, dockerProject, composeDir string) {
+	if err := CheckDockerAvailable(); err != nil {
+		report.add(checkDocker, "", false, "%v", err)
+		return
+	}
+
+	composeConfig, err := ParseComposeConfig(composeDir)
+	if err != nil {
+		report.add(checkDocker, "", false, "%v", err)
+		return
+	}
+
+	statuses, err := ListServiceStatuses(dockerProject)
+	if err != nil {
+		report.add(checkDocker, "", false, "%v", err)
+		return
+	}
+
+	byService := make(map[string]ServiceStatus)
+	for _, s := range statuses {
+		byService[s.Service] = s
+	}
+
+	for _, service := range composeConfig.GetServiceNames() {
+		s, ok := byService[service]
+		switch {
+		case !ok:
+			report.add(checkService, service, false, "no container")
+		case s.State != "running":
+			report.add(checkService, service, false, "%s", s.State)
+		case s.Health != "" && s.Health != "healthy":
+			report.add(checkService, service, false, "running, %s", s.Health)
+		case s.Health == "healthy":
+			report.add(checkService, service, true, "running, healthy")
+		default:
+			report.add(checkService, service, true, "running")
+		}
+	}
+}
+
+func checkDevcontainer(report *HealthReport, envName string) {
+	if err := CheckDockerAvailable(); err != nil {
+		report.add(checkDocker, "", false, "%v", err)
+		return
+	}
+
+	name := DevcontainerName(envName)
+	state, err := devcontainerState(name)
+	switch {
+	case err != nil:
+		report.add(checkDocker, "", false, "%v", err)
+	case state == "":
+		report.add(checkService, "devcontainer", false, "no container %s", name)
+	case state != "running":
+		report.add(checkService, "devcontainer", false, "%s", state)
+	default:
+		report.add(checkService, "devcontainer", true, "running")
+	}
+}
+
+func checkProcesses(report *HealthReport, cfg *Config, envName string) error {
+	state, err := ReadSupervisorState(envName)
+	if err != nil {
+		return err
+	}
+
+	if !state.Running() {
+		report.add(checkProcess, "supervisor", false, "not running (run mono reconcile)")
+		return nil
+	}
+
+	byName := make(map[string]ProcessState)
+	for _, p := range state.Processes {
+		byName[p.Name] = p
+	}
+	for _, name := range cfg.ProcessNames() {
+		p, ok := byName[name]
+		switch {
+		case !ok:
+			report.add(checkProcess, name, false, "not supervised (restart the supervisor with mono reconcile)")
+		case p.Status == ProcessRunning && !p.Ready:
+			report.add(checkProcess, name, false, "running, pid %d, not ready", p.PID)
+		case p.Status == ProcessRunning:
+			report.add(checkProcess, name, true, "running, pid %d, %d restarts", p.PID, p.Restarts)
+		default:
+			report.add(checkProcess, name, false, "%s, exit code %d, %d restarts", p.Status, p.ExitCode, p.Restarts)
+		}
+	}
+	return nil
+}
+
+func checkArtifacts(report *HealthReport, db *DB, cfg *Config, path string) error {
+	if len(cfg.Build.Artifacts) == 0 {
+		return nil
+	}
+
+	recorded, err := db.GetEnvironmentArtifacts(path)
+	if err != nil {
+		return fmt.Errorf("failed to load recorded cache keys: %w", err)
+	}
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize cache: %w", err)
+	}
+
+	strategy, err := cm.Strategy(path)
+	if err != nil {
+		report.add(checkCache, "", false, "%v", err)
+	} else {
+		report.add(checkCache, "", true, "%s", strategy)
+	}
+
+	for _, artifact := range cfg.Build.Artifacts {
+		var missing []string
+		for _, p := range artifact.Paths {
+			if !dirExists(p.resolve(path)) {
+				missing = append(missing, p.Path)
+			}
+		}
+		if len(missing) > 0 {
+			report.add(checkArtifact, artifact.Name, false, "missing %s", strings.Join(missing, ", "))
+			continue
+		}
+
+		key, err := cm.ComputeCacheKey(artifact, path)
+		if err != nil {
+			report.add(checkArtifact, artifact.Name, false, "failed to compute cache key: %v", err)
+			continue
+		}
+
+		switch recordedKey, ok := recorded[artifact.Name]; {
+		case !ok:
+			report.add(checkArtifact, artifact.Name, true, "present, no recorded cache key (current: %s)", key)
+		case recordedKey != key:
+			report.add(checkArtifact, artifact.Name, false, "stale, recorded key %s but key files now hash to %s", recordedKey, key)
+		default:
+			report.add(checkArtifact, artifact.Name, true, "key %s", key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package mono
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	HookPostCheckout = "post-checkout"
+	HookPostMerge    = "post-merge"
+
+	nullCommit = "0000000000000000000000000000000000000000"
+)
+
+var GitHooks = []string{HookPostCheckout, HookPostMerge}
+
+type HookStatus struct {
+	Hook    string
+	Path    string
+	Changed bool
+}
+
+func GitHooksDir(rootPath string) (string, error) {
+	output, err := Command("git", "-C", rootPath, "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git hooks directory in %s: %w", rootPath, err)
+	}
+
+	dir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(rootPath, dir)
+	}
+	return dir, nil
+}
+
+func renderHookBlock(executable, hook string) string {
+	var b strings.Builder
+	b.WriteString(monoBlockBegin + "\n")
+	b.WriteString("# managed by mono, remove with: mono hooks uninstall\n")
+	fmt.Fprintf(&b, "if [ -x %s ]; then\n", shellQuote(executable))
+	fmt.Fprintf(&b, "  (%s hooks run %s \"$@\" </dev/null >/dev/null 2>&1 &)\n", shellQuote(executable), hook)
+	b.WriteString("fi\n")
+	b.WriteString(monoBlockEnd + "\n")
+	return b.String()
+}
+
+func InstallHooks(rootPath string) ([]HookStatus, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate mono executable: %w", err)
+	}
+	executable, err = filepath.EvalSymlinks(executable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve mono executable: %w", err)
+	}
+	return installHooks(rootPath, executable)
+}
+
+func installHooks(rootPath, executable string) ([]HookStatus, error) {
+	dir, err := GitHooksDir(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	var statuses []HookStatus
+	for _, hook := range GitHooks {
+		path := filepath.Join(dir, hook)
+		existing, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return statuses, fmt.Errorf("failed to read %s hook: %w", hook, err)
+		}
+
+		content := string(existing)
+		if content == "" {
+			content = "#!/bin/sh\n"
+		}
+		updated, err := replaceMonoBlock(content, renderHookBlock(executable, hook))
+		if err != nil {
+			return statuses, fmt.Errorf("invalid %s hook: %w", hook, err)
+		}
+
+		status := HookStatus{Hook: hook, Path: path, Changed: updated != string(existing)}
+		if status.Changed {
+			if err := os.WriteFile(path, []byte(updated), 0755); err != nil {
+				return statuses, fmt.Errorf("failed to write %s hook: %w", hook, err)
+			}
+		}
+		if err := os.Chmod(path, 0755); err != nil {
+			return statuses, fmt.Errorf("failed to make %s hook executable: %w", hook, err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func UninstallHooks(rootPath string) ([]HookStatus, error) {
+	dir, err := GitHooksDir(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []HookStatus
+	for _, hook := range GitHooks {
+		path := filepath.Join(dir, hook)
+		existing, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return statuses, fmt.Errorf("failed to read %s hook: %w", hook, err)
+		}
+		if !strings.Contains(string(existing), monoBlockBegin) {
+			continue
+		}
+
+		updated, err := replaceMonoBlock(string(existing), "")
+		if err != nil {
+			return statuses, fmt.Errorf("invalid %s hook: %w", hook, err)
+		}
+		updated = strings.TrimRight(updated, "\n") + "\n"
+
+		if strings.TrimSpace(updated) == "#!/bin/sh" {
+			if err := os.Remove(path); err != nil {
+				return statuses, fmt.Errorf("failed to remove %s hook: %w", hook, err)
+			}
+		} else if err := os.WriteFile(path, []byte(updated), 0755); err != nil {
+			return statuses, fmt.Errorf("failed to write %s hook: %w", hook, err)
+		}
+		statuses = append(statuses, HookStatus{Hook: hook, Path: path, Changed: true})
+	}
+	return statuses, nil
+}
+
+func hookRange(hook string, args []string) (string, string, bool, error) {
+	switch hook {
+	case HookPostCheckout:
+		if len(args) < 3 {
+			return "", "", false, fmt.Errorf("post-checkout expects 3 arguments, got %d", len(args))
+		}
+		if args[2] != "1" {
+			return "", "", false, nil
+		}
+		return args[0], args[1], true, nil
+	case HookPostMerge:
+		return "ORIG_HEAD", "HEAD", true, nil
+	default:
+		return "", "", false, fmt.Errorf("unsupported hook: %s", hook)
+	}
+}
+
+func changedFiles(rootPath, from, to string) ([]string, error) {
+	output, err := Command("git", "-C", rootPath, "diff", "--name-only", from, to).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s..%s: %w", from, to, err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+func keyFilesChanged(artifacts []ArtifactConfig, changed []string) bool {
+	for _, artifact := range artifacts {
+		for _, keyFile := range artifact.KeyFiles {
+			if slices.Contains(changed, filepath.ToSlash(filepath.Clean(keyFile))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func RunHook(rootPath, hook string, args []string) (_ []WarmResult, err error) {
+	from, to, ok, err := hookRange(hook, args)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	mainWorktree, err := DiscoverGitRoot(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	if mainWorktree != "" {
+		return nil, nil
+	}
+
+	cfg, err := LoadConfig(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.ApplyDefaults(rootPath)
+
+	if from != nullCommit {
+		changed, err := changedFiles(rootPath, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if !keyFilesChanged(cfg.Build.Artifacts, changed) {
+			return nil, nil
+		}
+	}
+
+	ctx, stopSignals := notifyInterrupt(hook + " hook")
+	defer stopSignals()
+
+	lock, err := AcquireEnvLock(ctx, EnvName(rootPath), hook+" hook", io.Discard)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, lock.Release())
+	}()
+
+	return WarmCache(rootPath)
+}
@@ -0,0 +1,108 @@
+package mono
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallAndUninstallHooks(t *testing.T) {
+	root := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", root).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, out)
+	}
+
+	hooksDir, err := GitHooksDir(root)
+	if err != nil {
+		t.Fatalf("GitHooksDir failed: %v", err)
+	}
+	existing := "#!/bin/sh\necho custom\n"
+	if err := os.WriteFile(filepath.Join(hooksDir, HookPostMerge), []byte(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := installHooks(root, "/opt/mono bin/mono")
+	if err != nil {
+		t.Fatalf("installHooks failed: %v", err)
+	}
+	if len(statuses) != 2 || !statuses[0].Changed || !statuses[1].Changed {
+		t.Fatalf("unexpected statuses: %+v", statuses)
+	}
+
+	merge, err := os.ReadFile(filepath.Join(hooksDir, HookPostMerge))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(merge), existing) || !strings.Contains(string(merge), `'/opt/mono bin/mono' hooks run post-merge "$@"`) {
+		t.Errorf("unexpected post-merge hook:\n%s", merge)
+	}
+
+	info, err := os.Stat(filepath.Join(hooksDir, HookPostCheckout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		t.Error("post-checkout hook should be executable")
+	}
+
+	statuses, err = installHooks(root, "/opt/mono bin/mono")
+	if err != nil {
+		t.Fatalf("second installHooks failed: %v", err)
+	}
+	if statuses[0].Changed || statuses[1].Changed {
+		t.Error("reinstalling should be a no-op")
+	}
+
+	if _, err := UninstallHooks(root); err != nil {
+		t.Fatalf("UninstallHooks failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(hooksDir, HookPostCheckout)); !os.IsNotExist(err) {
+		t.Error("post-checkout hook with only the mono block should be removed")
+	}
+	merge, err = os.ReadFile(filepath.Join(hooksDir, HookPostMerge))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(merge) != existing {
+		t.Errorf("expected original post-merge hook, got:\n%s", merge)
+	}
+}
+
+func TestHookRange(t *testing.T) {
+	from, to, ok, err := hookRange(HookPostCheckout, []string{"aaa", "bbb", "1"})
+	if err != nil || !ok || from != "aaa" || to != "bbb" {
+		t.Errorf("unexpected branch checkout range: %s %s %v %v", from, to, ok, err)
+	}
+
+	if _, _, ok, err := hookRange(HookPostCheckout, []string{"aaa", "bbb", "0"}); err != nil || ok {
+		t.Error("file checkouts should be ignored")
+	}
+
+	if _, _, _, err := hookRange(HookPostCheckout, nil); err == nil {
+		t.Error("expected missing post-checkout arguments to fail")
+	}
+
+	if from, to, ok, _ := hookRange(HookPostMerge, []string{"0"}); !ok || from != "ORIG_HEAD" || to != "HEAD" {
+		t.Errorf("unexpected post-merge range: %s %s", from, to)
+	}
+
+	if _, _, _, err := hookRange("pre-commit", nil); err == nil {
+		t.Error("expected unsupported hook to fail")
+	}
+}
+
+func TestKeyFilesChanged(t *testing.T) {
+	artifacts := []ArtifactConfig{
+		{Name: "cargo", KeyFiles: []string{"Cargo.lock"}},
+		{Name: "npm", KeyFiles: []string{"./web/package-lock.json"}},
+	}
+
+	if !keyFilesChanged(artifacts, []string{"src/main.rs", "web/package-lock.json"}) {
+		t.Error("expected nested lockfile change to be detected")
+	}
+	if keyFilesChanged(artifacts, []string{"src/main.rs", "README.md"}) {
+		t.Error("expected unrelated changes to be ignored")
+	}
+}
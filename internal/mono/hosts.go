@@ -0,0 +1,148 @@
+package mono
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+type HostsConfig struct {
+	File   string `yaml:"file"`
+	Domain string `yaml:"domain"`
+}
+
+func (c HostsConfig) enabled() bool {
+	return c.File != ""
+}
+
+var invalidHostnameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+func hostnameLabel(name string) string {
+	return strings.Trim(invalidHostnameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func envHostname(envName string) string {
+	return hostnameLabel(envName) + "." + globalConfig().Hosts.Domain
+}
+
+func serviceHostname(service, envName string) string {
+	return hostnameLabel(service) + "." + envHostname(envName)
+}
+
+func hostnameEnv(envName string, allocations []Allocation) map[string]string {
+	env := make(map[string]string)
+	if !globalConfig().Hosts.enabled() {
+		return env
+	}
+	env["MONO_HOSTNAME"] = envHostname(envName)
+	for _, alloc := range allocations {
+		env[serviceEnvPrefix(alloc.Service)+"_HOSTNAME"] = serviceHostname(alloc.Service, envName)
+	}
+	return env
+}
+
+func hostsBlockMarkers(envName string) (string, string) {
+	return "# >>> mono " + envName + " >>>", "# <<< mono " + envName + " <<<"
+}
+
+func renderHostsBlock(envName string, allocations []Allocation) string {
+	names := map[string]bool{envHostname(envName): true}
+	for _, alloc := range allocations {
+		names[serviceHostname(alloc.Service, envName)] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	begin, end := hostsBlockMarkers(envName)
+	return begin + "\n127.0.0.1 " + strings.Join(sorted, " ") + "\n" + end + "\n"
+}
+
+func RegisterHosts(envName string, allocations []Allocation) (bool, error) {
+	cfg := globalConfig().Hosts
+	if !cfg.enabled() {
+		return false, nil
+	}
+	begin, end := hostsBlockMarkers(envName)
+	return updateHostsFile(cfg.File, func(content string) (string, error) {
+		return replaceBlock(content, begin, end, renderHostsBlock(envName, allocations))
+	})
+}
+
+func UnregisterHosts(envName string) (bool, error) {
+	cfg := globalConfig().Hosts
+	if !cfg.enabled() {
+		return false, nil
+	}
+	begin, end := hostsBlockMarkers(envName)
+	return updateHostsFile(cfg.File, func(content string) (string, error) {
+		if !strings.Contains(content, begin) {
+			return content, nil
+		}
+		updated, err := replaceBlock(content, begin, end, "")
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(updated, "\n") + "\n", nil
+	})
+}
+
+func updateHostsFile(path string, update func(string) (string, error)) (bool, error) {
+	home, err := GetMonoHome()
+	if err != nil {
+		return false, err
+	}
+	lockPath := filepath.Join(home, "locks", "hosts.lock")
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return false, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	lock, err := openLockFile(lockPath, syscall.LOCK_EX)
+	if err != nil {
+		return false, fmt.Errorf("failed to lock hosts file: %w", err)
+	}
+	defer lock.Close()
+	defer syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	updated, err := update(string(existing))
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	if updated == string(existing) {
+		return false, nil
+	}
+	if err := writeHostsFile(path, []byte(updated)); err != nil {
+		return false, fmt.Errorf("failed to write %s (make it writable by your user or point hosts.file at a resolver's hosts file): %w", path, err)
+	}
+	return true, nil
+}
+
+func writeHostsFile(path string, data []byte) error {
+	target, err := filepath.EvalSymlinks(path)
+	if os.IsNotExist(err) {
+		target = path
+	} else if err != nil {
+		return err
+	}
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(target); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	err = writeFileAtomic(target, data, perm)
+	if errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS) {
+		return os.WriteFile(target, data, perm)
+	}
+	return err
+}
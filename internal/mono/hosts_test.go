@@ -0,0 +1,110 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRegisterHosts(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	hostsFile := filepath.Join(t.TempDir(), "hosts")
+	original := "127.0.0.1 localhost\n"
+	if err := os.WriteFile(hostsFile, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultGlobalConfig()
+	cfg.Hosts.File = hostsFile
+	SetGlobalConfig(cfg)
+
+	allocations := []Allocation{
+		{Service: "web", ContainerPort: 3000, HostPort: 19100},
+		{Service: "api_v2", ContainerPort: 8080, HostPort: 19180},
+	}
+	for range 2 {
+		if _, err := RegisterHosts("shop-Feature_X", allocations); err != nil {
+			t.Fatalf("RegisterHosts failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(hostsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := original + "\n# >>> mono shop-Feature_X >>>\n127.0.0.1 api-v2.shop-feature-x.test shop-feature-x.test web.shop-feature-x.test\n# <<< mono shop-Feature_X <<<\n"
+	if string(data) != want {
+		t.Errorf("unexpected hosts file:\n%s\nwant:\n%s", data, want)
+	}
+
+	vars := buildScriptEnv("shop-Feature_X", 1, "/env", "/root", allocations, nil, nil)
+	for _, v := range []string{"MONO_HOSTNAME=shop-feature-x.test", "MONO_WEB_HOSTNAME=web.shop-feature-x.test"} {
+		if !slices.Contains(vars, v) {
+			t.Errorf("expected %s in %v", v, vars)
+		}
+	}
+
+	if removed, err := UnregisterHosts("shop-Feature_X"); err != nil || !removed {
+		t.Fatalf("UnregisterHosts = %v, %v", removed, err)
+	}
+	data, err = os.ReadFile(hostsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != original {
+		t.Errorf("expected only the original entries to remain, got %q", data)
+	}
+	if removed, err := UnregisterHosts("shop-Feature_X"); err != nil || removed {
+		t.Errorf("expected a second UnregisterHosts to be a no-op, got %v, %v", removed, err)
+	}
+}
+
+func TestHostsDisabled(t *testing.T) {
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+	SetGlobalConfig(DefaultGlobalConfig())
+
+	if registered, err := RegisterHosts("env", []Allocation{{Service: "web"}}); err != nil || registered {
+		t.Errorf("expected no registration without hosts.file, got %v, %v", registered, err)
+	}
+	vars := buildScriptEnv("env", 1, "/env", "/root", nil, nil, nil)
+	for _, v := range vars {
+		if v == "MONO_HOSTNAME=env.test" {
+			t.Error("expected no hostname variables without hosts.file")
+		}
+	}
+}
+
+func TestWriteHostsFileKeepsSymlinkAndMode(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "addn-hosts")
+	if err := os.WriteFile(target, []byte("old\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "hosts")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeHostsFile(link, []byte("new\n")); err != nil {
+		t.Fatalf("writeHostsFile failed: %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected %s to stay a symlink, got %v, %v", link, info, err)
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("expected mode 0640 to be kept, got %v", info.Mode().Perm())
+	}
+	if data, err := os.ReadFile(target); err != nil || string(data) != "new\n" {
+		t.Errorf("unexpected hosts content %q: %v", data, err)
+	}
+	if _, err := os.Stat(target + cacheTmpSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected no staging file to be left behind, got %v", err)
+	}
+}
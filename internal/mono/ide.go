@@ -0,0 +1,245 @@
+package mono
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	IDEFormatVSCode    = "vscode"
+	IDEFormatJetBrains = "jetbrains"
+
+	ideTaskPrefix = "mono: "
+)
+
+type IDETask struct {
+	Name   string
+	Script string
+	Env    map[string]string
+	Detail string
+}
+
+type IDEFile struct {
+	Path    string
+	Content []byte
+}
+
+func IDETasks(path string) ([]IDETask, error) {
+	env, cfg, vars, err := loadEnvironmentVars(path)
+	if err != nil {
+		return nil, err
+	}
+
+	envMap := make(map[string]string, len(vars))
+	var ports []string
+	for _, kv := range vars {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		envMap[key] = value
+		if strings.HasPrefix(key, "MONO_") && strings.HasSuffix(key, "_PORT") {
+			ports = append(ports, key+"="+value)
+		}
+	}
+	sort.Strings(ports)
+
+	detail := "mono environment " + EnvName(path)
+	if len(ports) > 0 {
+		detail += ", ports: " + strings.Join(ports, ", ")
+	}
+
+	var tasks []IDETask
+	if cfg.Scripts.Run != "" {
+		script, err := wrapRunScript(env, cfg, cfg.Scripts.Run, nil, true)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, IDETask{
+			Name:   ideTaskPrefix + "run",
+			Script: script,
+			Env:    envMap,
+			Detail: detail,
+		})
+		tasks = append(tasks, IDETask{
+			Name:   ideTaskPrefix + "run in tmux",
+			Script: "mono run " + shellQuote(path),
+			Detail: "run the run script in the " + SessionName(EnvName(path)) + " tmux session",
+		})
+	}
+	tasks = append(tasks, IDETask{
+		Name:   ideTaskPrefix + "sync",
+		Script: "mono sync " + shellQuote(path),
+		Detail: "save build artifacts to the mono cache",
+	})
+	tasks = append(tasks, IDETask{
+		Name:   ideTaskPrefix + "reconcile",
+		Script: "mono reconcile " + shellQuote(path),
+		Detail: "repair stopped services, sessions and missing artifacts",
+	})
+	return tasks, nil
+}
+
+func RenderIDEFiles(path, format string, tasks []IDETask) ([]IDEFile, error) {
+	switch format {
+	case IDEFormatVSCode:
+		file, err := renderVSCodeTasks(path, tasks)
+		if err != nil {
+			return nil, err
+		}
+		return []IDEFile{file}, nil
+	case IDEFormatJetBrains:
+		return renderJetBrainsConfigs(path, tasks)
+	default:
+		return nil, fmt.Errorf("unknown IDE format %q (use vscode or jetbrains)", format)
+	}
+}
+
+func WriteIDEFiles(files []IDEFile) error {
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(f.Path, f.Content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.Path, err)
+		}
+	}
+	return nil
+}
+
+func renderVSCodeTasks(path string, tasks []IDETask) (IDEFile, error) {
+	tasksPath := filepath.Join(path, ".vscode", "tasks.json")
+
+	doc := map[string]any{"version": "2.0.0"}
+	data, err := os.ReadFile(tasksPath)
+	if err != nil && !os.IsNotExist(err) {
+		return IDEFile{}, fmt.Errorf("failed to read tasks.json: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(stripJSONC(data), &doc); err != nil {
+			return IDEFile{}, fmt.Errorf("invalid %s: %w", tasksPath, err)
+		}
+	}
+
+	var kept []any
+	if existing, ok := doc["tasks"].([]any); ok {
+		for _, t := range existing {
+			task, ok := t.(map[string]any)
+			if !ok {
+				kept = append(kept, t)
+				continue
+			}
+			if label, _ := task["label"].(string); strings.HasPrefix(label, ideTaskPrefix) {
+				continue
+			}
+			kept = append(kept, t)
+		}
+	}
+
+	for _, t := range tasks {
+		task := map[string]any{
+			"label":          t.Name,
+			"type":           "shell",
+			"command":        t.Script,
+			"detail":         t.Detail,
+			"options":        vscodeTaskOptions(path, t.Env),
+			"problemMatcher": []any{},
+		}
+		kept = append(kept, task)
+	}
+	doc["tasks"] = kept
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return IDEFile{}, err
+	}
+	return IDEFile{Path: tasksPath, Content: append(out, '\n')}, nil
+}
+
+func vscodeTaskOptions(path string, env map[string]string) map[string]any {
+	options := map[string]any{"cwd": path}
+	if len(env) > 0 {
+		options["env"] = env
+	}
+	return options
+}
+
+type jetbrainsComponent struct {
+	XMLName       xml.Name               `xml:"component"`
+	Name          string                 `xml:"name,attr"`
+	Configuration jetbrainsConfiguration `xml:"configuration"`
+}
+
+type jetbrainsConfiguration struct {
+	Default string            `xml:"default,attr"`
+	Name    string            `xml:"name,attr"`
+	Type    string            `xml:"type,attr"`
+	Options []jetbrainsOption `xml:"option"`
+	Envs    []jetbrainsEnv    `xml:"envs>env"`
+	Method  jetbrainsMethod   `xml:"method"`
+}
+
+type jetbrainsOption struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type jetbrainsEnv struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type jetbrainsMethod struct {
+	V string `xml:"v,attr"`
+}
+
+func renderJetBrainsConfigs(path string, tasks []IDETask) ([]IDEFile, error) {
+	var files []IDEFile
+	for _, t := range tasks {
+		cfg := jetbrainsConfiguration{
+			Default: "false",
+			Name:    t.Name,
+			Type:    "ShConfigurationType",
+			Options: []jetbrainsOption{
+				{"SCRIPT_TEXT", t.Script},
+				{"INDEPENDENT_SCRIPT_PATH", "true"},
+				{"SCRIPT_PATH", ""},
+				{"SCRIPT_OPTIONS", ""},
+				{"INDEPENDENT_SCRIPT_WORKING_DIRECTORY", "true"},
+				{"SCRIPT_WORKING_DIRECTORY", path},
+				{"INDEPENDENT_INTERPRETER_PATH", "true"},
+				{"INTERPRETER_PATH", "/bin/sh"},
+				{"INTERPRETER_OPTIONS", ""},
+				{"EXECUTE_IN_TERMINAL", "true"},
+				{"EXECUTE_SCRIPT_FILE", "false"},
+			},
+			Method: jetbrainsMethod{V: "2"},
+		}
+
+		keys := make([]string, 0, len(t.Env))
+		for k := range t.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			cfg.Envs = append(cfg.Envs, jetbrainsEnv{Name: k, Value: t.Env[k]})
+		}
+
+		out, err := xml.MarshalIndent(jetbrainsComponent{Name: "ProjectRunConfigurationManager", Configuration: cfg}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		filename := strings.NewReplacer(":", "", " ", "_").Replace(t.Name) + ".run.xml"
+		files = append(files, IDEFile{
+			Path:    filepath.Join(path, ".run", filename),
+			Content: append(out, '\n'),
+		})
+	}
+	return files, nil
+}
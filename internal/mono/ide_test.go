@@ -0,0 +1,151 @@
+package mono
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupIDEEnvironment(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("MONO_HOME", "")
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mono.yml"), []byte("scripts:\n  run: npm run dev\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := OpenDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.InsertEnvironment(dir, "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRenderVSCodeTasksKeepsUserTasks(t *testing.T) {
+	dir := setupIDEEnvironment(t)
+
+	existing := `{
+		// user tasks
+		"version": "2.0.0",
+		"tasks": [
+			{"label": "lint", "type": "shell", "command": "npm run lint"},
+			{"label": "mono: stale", "type": "shell", "command": "old"},
+		]
+	}`
+	if err := os.MkdirAll(filepath.Join(dir, ".vscode"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".vscode", "tasks.json"), []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := IDETasks(dir)
+	if err != nil {
+		t.Fatalf("IDETasks failed: %v", err)
+	}
+	files, err := RenderIDEFiles(dir, IDEFormatVSCode, tasks)
+	if err != nil {
+		t.Fatalf("RenderIDEFiles failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != filepath.Join(dir, ".vscode", "tasks.json") {
+		t.Fatalf("unexpected files: %+v", files)
+	}
+
+	var doc struct {
+		Tasks []struct {
+			Label   string `json:"label"`
+			Command string `json:"command"`
+			Options struct {
+				Cwd string            `json:"cwd"`
+				Env map[string]string `json:"env"`
+			} `json:"options"`
+		} `json:"tasks"`
+	}
+	if err := json.Unmarshal(files[0].Content, &doc); err != nil {
+		t.Fatalf("generated tasks.json is invalid: %v", err)
+	}
+
+	labels := make(map[string]int)
+	for i, task := range doc.Tasks {
+		labels[task.Label] = i
+	}
+	if _, ok := labels["lint"]; !ok {
+		t.Error("user task was dropped")
+	}
+	if _, ok := labels["mono: stale"]; ok {
+		t.Error("stale mono task was kept")
+	}
+	run, ok := labels["mono: run"]
+	if !ok {
+		t.Fatalf("missing run task: %+v", doc.Tasks)
+	}
+	if doc.Tasks[run].Command != "npm run dev" || doc.Tasks[run].Options.Cwd != dir {
+		t.Errorf("unexpected run task: %+v", doc.Tasks[run])
+	}
+	if doc.Tasks[run].Options.Env["MONO_ENV_NAME"] != EnvName(dir) {
+		t.Errorf("run task missing mono environment: %v", doc.Tasks[run].Options.Env)
+	}
+}
+
+func TestRenderJetBrainsConfigs(t *testing.T) {
+	dir := setupIDEEnvironment(t)
+
+	tasks, err := IDETasks(dir)
+	if err != nil {
+		t.Fatalf("IDETasks failed: %v", err)
+	}
+	files, err := RenderIDEFiles(dir, IDEFormatJetBrains, tasks)
+	if err != nil {
+		t.Fatalf("RenderIDEFiles failed: %v", err)
+	}
+	if len(files) != len(tasks) {
+		t.Fatalf("expected one file per task, got %d", len(files))
+	}
+
+	runPath := filepath.Join(dir, ".run", "mono_run.run.xml")
+	var run *IDEFile
+	for i := range files {
+		if files[i].Path == runPath {
+			run = &files[i]
+		}
+	}
+	if run == nil {
+		t.Fatalf("missing %s in %+v", runPath, files)
+	}
+
+	var component jetbrainsComponent
+	if err := xml.Unmarshal(run.Content, &component); err != nil {
+		t.Fatalf("generated run configuration is invalid: %v", err)
+	}
+	script := ""
+	for _, o := range component.Configuration.Options {
+		if o.Name == "SCRIPT_TEXT" {
+			script = o.Value
+		}
+	}
+	if script != "npm run dev" {
+		t.Errorf("unexpected script text %q", script)
+	}
+	found := false
+	for _, e := range component.Configuration.Envs {
+		if e.Name == "MONO_ENV_PATH" && e.Value == dir {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("run configuration missing MONO_ENV_PATH: %+v", component.Configuration.Envs)
+	}
+
+	if _, err := RenderIDEFiles(dir, "emacs", tasks); err == nil || !strings.Contains(err.Error(), "unknown IDE format") {
+		t.Errorf("expected unknown format error, got %v", err)
+	}
+}
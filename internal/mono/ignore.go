@@ -0,0 +1,120 @@
+package mono
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const monoIgnoreFile = ".monoignore"
+
+type ignorePattern struct {
+	base     string
+	glob     string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+	filesToo bool
+}
+
+type ignoreRules struct {
+	patterns []ignorePattern
+}
+
+func parseIgnorePatterns(content, base string, filesToo bool) []ignorePattern {
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p := ignorePattern{base: base, filesToo: filesToo}
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			p.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		p.glob = line
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+func (r *ignoreRules) load(dir, rel string) error {
+	for _, f := range []struct {
+		name     string
+		filesToo bool
+	}{{".gitignore", false}, {monoIgnoreFile, true}} {
+		data, err := os.ReadFile(filepath.Join(dir, f.name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		r.patterns = append(r.patterns, parseIgnorePatterns(string(data), rel, f.filesToo)...)
+	}
+	return nil
+}
+
+func (r *ignoreRules) ignored(rel string, isDir bool) bool {
+	rel = filepath.ToSlash(rel)
+	ignored := false
+	for _, p := range r.patterns {
+		if (!isDir && (p.dirOnly || !p.filesToo)) || !p.matches(rel) {
+			continue
+		}
+		ignored = !p.negate
+	}
+	return ignored
+}
+
+func (p ignorePattern) matches(rel string) bool {
+	if p.base != "" {
+		if !strings.HasPrefix(rel, p.base+"/") {
+			return false
+		}
+		rel = strings.TrimPrefix(rel, p.base+"/")
+	}
+	if !p.anchored {
+		return globMatch(strings.Split(p.glob, "/"), []string{path.Base(rel)})
+	}
+	return globMatch(strings.Split(p.glob, "/"), strings.Split(rel, "/"))
+}
+
+func globMatch(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if globMatch(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], parts[0])
+	if err != nil || !ok {
+		return false
+	}
+	return globMatch(pattern[1:], parts[1:])
+}
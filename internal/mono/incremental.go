@@ -0,0 +1,231 @@
+package mono
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+type incrementalChange struct {
+	src string
+	dst string
+	rel string
+}
+
+func (cm *CacheManager) updateCacheEntry(artifact ArtifactConfig, envPath, cachePath string) (int, error) {
+	lock, err := cm.acquireCacheLock(cachePath)
+	if err != nil || lock == nil {
+		return 0, err
+	}
+	defer cm.releaseCacheLock(lock)
+
+	status, _, err := checkEntry(cachePath, false)
+	if err != nil {
+		return 0, err
+	}
+	if status != VerifyOK {
+		return 0, nil
+	}
+	m, _, err := readManifest(cachePath)
+	if err != nil {
+		return 0, err
+	}
+
+	known := maps.Clone(m.Files)
+	updated := 0
+	for _, p := range artifact.Paths {
+		localPath := p.resolve(envPath)
+		if !dirExists(localPath) {
+			continue
+		}
+		base := filepath.Base(localPath)
+		if _, ok := findArchive(cachePath, base); ok {
+			continue
+		}
+
+		strategy, err := cm.Strategy(localPath)
+		if err != nil {
+			return updated, err
+		}
+		opts := SeedOptions{
+			ArtifactName: artifact.Name,
+			PathType:     artifact.PathType(p),
+			Link:         strategy.Link,
+			Include:      artifact.Include,
+			Exclude:      artifact.Exclude,
+			SkipPatterns: artifact.SkipPatterns,
+		}
+		changes, err := incrementalChanges(localPath, filepath.Join(cachePath, base), base, opts, m.Files)
+		if err != nil {
+			return updated, fmt.Errorf("failed to diff %s against the cache: %w", localPath, err)
+		}
+		if err := applyIncrementalChanges(changes, opts, workerCount(workersSeed, localPath, cachePath)); err != nil {
+			return updated, err
+		}
+		for _, c := range changes {
+			delete(known, c.rel)
+		}
+		updated += len(changes)
+	}
+	if updated == 0 {
+		return 0, nil
+	}
+
+	if err := syncTree(cachePath); err != nil {
+		return updated, err
+	}
+	if err := rewriteManifest(cachePath, known); err != nil {
+		return updated, err
+	}
+	if err := cm.linkBlobs(cachePath); err != nil {
+		return updated, fmt.Errorf("failed to dedup %s into the blob store: %w", cachePath, err)
+	}
+	return updated, nil
+}
+
+func incrementalChanges(localPath, targetPath, base string, opts SeedOptions, files map[string]manifestFile) ([]incrementalChange, error) {
+	filter := opts.pathFilter()
+	var mu sync.Mutex
+	var dirs []copiedDir
+	var changes []incrementalChange
+
+	err := parallelWalk(localPath, workerCount(workersWalk, localPath), func(path, rel string, d fs.DirEntry) error {
+		if d.IsDir() {
+			if shouldSkipPath(rel+"/", opts.pathType()) || filter.skip(rel, true) {
+				return filepath.SkipDir
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			dirs = append(dirs, copiedDir{src: path, dst: filepath.Join(targetPath, rel), info: info})
+			mu.Unlock()
+			return nil
+		}
+		if !d.Type().IsRegular() && d.Type()&fs.ModeSymlink == 0 {
+			return nil
+		}
+		if shouldSkipPath(rel, opts.pathType()) || filter.skip(rel, false) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(targetPath, rel)
+		manifestRel := filepath.Join(base, rel)
+		changed, err := cachedFileChanged(path, info, dst, files[manifestRel])
+		if err != nil || !changed {
+			return err
+		}
+		mu.Lock()
+		changes = append(changes, incrementalChange{src: path, dst: dst, rel: manifestRel})
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].dst < dirs[j].dst })
+	for _, dir := range dirs {
+		info, err := os.Lstat(dir.dst)
+		if err == nil && !info.IsDir() {
+			if err := os.Remove(dir.dst); err != nil {
+				return nil, err
+			}
+		} else if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err := os.MkdirAll(dir.dst, dir.info.Mode().Perm()); err != nil {
+			return nil, fmt.Errorf("failed to create directory %s: %w", dir.dst, err)
+		}
+	}
+	return changes, nil
+}
+
+func cachedFileChanged(src string, info fs.FileInfo, dst string, known manifestFile) (bool, error) {
+	cached, err := os.Lstat(dst)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if os.SameFile(info, cached) {
+		return false, nil
+	}
+	if info.Mode().Type() != cached.Mode().Type() {
+		return true, nil
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		a, err := os.Readlink(src)
+		if err != nil {
+			return false, err
+		}
+		b, err := os.Readlink(dst)
+		if err != nil {
+			return false, err
+		}
+		return a != b, nil
+	}
+	if info.Size() != cached.Size() || info.Mode().Perm() != cached.Mode().Perm() {
+		return true, nil
+	}
+	if info.ModTime().Equal(cached.ModTime()) {
+		return false, nil
+	}
+
+	want := known.SHA256
+	if want == "" || known.Size != cached.Size() {
+		if want, err = fileSHA256(dst); err != nil {
+			return false, err
+		}
+	}
+	got, err := fileSHA256(src)
+	if err != nil {
+		return false, err
+	}
+	return got != want, nil
+}
+
+func applyIncrementalChanges(changes []incrementalChange, opts SeedOptions, workers int) error {
+	var g errgroup.Group
+	g.SetLimit(workers)
+	for _, c := range changes {
+		g.Go(func() error {
+			tmp := c.dst + cacheTmpSuffix
+			if err := os.RemoveAll(tmp); err != nil {
+				return err
+			}
+			info, err := os.Lstat(c.dst)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			if err == nil && info.IsDir() {
+				if err := os.RemoveAll(c.dst); err != nil {
+					return err
+				}
+			}
+			if err := linkFile(c.src, tmp, opts.linkFor(c.rel)); err != nil {
+				return fmt.Errorf("failed to link %s: %w", c.rel, err)
+			}
+			if err := os.Rename(tmp, c.dst); err != nil {
+				if rmErr := os.Remove(tmp); rmErr != nil && !os.IsNotExist(rmErr) {
+					return fmt.Errorf("failed to replace %s: %w (cleanup failed: %v)", c.rel, err, rmErr)
+				}
+				return fmt.Errorf("failed to replace %s: %w", c.rel, err)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
@@ -0,0 +1,122 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestSyncIncremental(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(cacheSaltEnv, "")
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+
+	testDir := t.TempDir()
+	rootPath := filepath.Join(testDir, "root")
+	envPath := filepath.Join(testDir, "env")
+	targetDir := filepath.Join(envPath, "target")
+
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatalf("failed to create target dir: %v", err)
+	}
+	files := map[string]string{
+		"Cargo.lock":         "lockfile",
+		"target/kept.txt":    "kept",
+		"target/rebuilt.txt": "old build",
+		"target/deleted.txt": "deleted",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(envPath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	artifacts := []ArtifactConfig{
+		{
+			Name:        "cargo",
+			KeyFiles:    []string{"Cargo.lock"},
+			KeyCommands: []string{"echo v1"},
+			Paths:       []ArtifactPath{{Path: "target"}},
+		},
+	}
+	if err := cm.Sync(artifacts, rootPath, envPath, SyncOptions{HardlinkBack: true}); err != nil {
+		t.Fatalf("first sync failed: %v", err)
+	}
+
+	key, err := cm.ComputeCacheKey(artifacts[0], envPath)
+	if err != nil {
+		t.Fatalf("ComputeCacheKey failed: %v", err)
+	}
+	cachePath := cm.GetArtifactCachePath(rootPath, "cargo", key)
+	cachedTarget := filepath.Join(cachePath, "target")
+
+	keptBefore, err := os.Stat(filepath.Join(cachedTarget, "kept.txt"))
+	if err != nil {
+		t.Fatalf("expected kept.txt in the cache: %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(targetDir, "rebuilt.txt")); err != nil {
+		t.Fatalf("failed to remove rebuilt.txt: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "rebuilt.txt"), []byte("new build"), 0644); err != nil {
+		t.Fatalf("failed to rewrite rebuilt.txt: %v", err)
+	}
+	if err := os.Remove(filepath.Join(targetDir, "deleted.txt")); err != nil {
+		t.Fatalf("failed to remove deleted.txt: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(targetDir, "deps"), 0755); err != nil {
+		t.Fatalf("failed to create deps dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "deps", "added.txt"), []byte("added"), 0644); err != nil {
+		t.Fatalf("failed to write added.txt: %v", err)
+	}
+
+	if err := cm.Sync(artifacts, rootPath, envPath, SyncOptions{HardlinkBack: true}); err != nil {
+		t.Fatalf("second sync failed: %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(cachedTarget, "rebuilt.txt")); err != nil || string(content) != "old build" {
+		t.Errorf("expected a plain sync to skip the cached entry, got %q %v", content, err)
+	}
+
+	if err := cm.Sync(artifacts, rootPath, envPath, SyncOptions{HardlinkBack: true, Incremental: true}); err != nil {
+		t.Fatalf("incremental sync failed: %v", err)
+	}
+
+	want := map[string]string{
+		"kept.txt":       "kept",
+		"rebuilt.txt":    "new build",
+		"deleted.txt":    "deleted",
+		"deps/added.txt": "added",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(cachedTarget, name))
+		if err != nil {
+			t.Errorf("expected %s in the cache: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("expected cached %s to be %q, got %q", name, content, got)
+		}
+	}
+
+	keptAfter, err := os.Stat(filepath.Join(cachedTarget, "kept.txt"))
+	if err != nil {
+		t.Fatalf("expected kept.txt in the cache: %v", err)
+	}
+	if keptBefore.Sys().(*syscall.Stat_t).Ino != keptAfter.Sys().(*syscall.Stat_t).Ino {
+		t.Error("expected an unchanged file to keep its cached inode")
+	}
+
+	status, problems, err := checkEntry(cachePath, true)
+	if err != nil {
+		t.Fatalf("checkEntry failed: %v", err)
+	}
+	if status != VerifyOK {
+		t.Errorf("expected the updated entry to verify, got %v: %v", status, problems)
+	}
+}
@@ -0,0 +1,90 @@
+package mono
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+const initResultFile = "init-result.json"
+
+type ScriptStatus struct {
+	Name       string `json:"name"`
+	ExitCode   int    `json:"exit_code"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+type PhaseStatus struct {
+	Name       string `json:"name"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+type phaseTimer struct {
+	status  *StatusServer
+	phases  []PhaseStatus
+	current string
+	start   time.Time
+}
+
+func newPhaseTimer(status *StatusServer) *phaseTimer {
+	return &phaseTimer{status: status}
+}
+
+func (t *phaseTimer) SetPhase(phase string) {
+	t.status.SetPhase(phase)
+	now := time.Now()
+	t.end(now)
+	t.current, t.start = phase, now
+}
+
+func (t *phaseTimer) end(now time.Time) {
+	if t.current == "" {
+		return
+	}
+	t.phases = append(t.phases, PhaseStatus{Name: t.current, DurationMS: now.Sub(t.start).Milliseconds()})
+	t.current = ""
+}
+
+func (t *phaseTimer) finish() []PhaseStatus {
+	t.end(time.Now())
+	return t.phases
+}
+
+func newScriptStatus(name string, start time.Time, err error) ScriptStatus {
+	s := ScriptStatus{Name: name, DurationMS: time.Since(start).Milliseconds()}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		s.ExitCode = exitErr.ExitCode()
+	default:
+		s.ExitCode = -1
+	}
+	return s
+}
+
+func InitResultPath(envName string) (string, error) {
+	dataDir, err := DataDir(envName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, initResultFile), nil
+}
+
+func writeInitResult(event LifecycleEvent) error {
+	path, err := InitResultPath(event.Name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(event, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package mono
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestWriteInitResult(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+	dataDir, err := DataDir("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	phases := newPhaseTimer(nil)
+	phases.SetPhase("running init script")
+	phases.SetPhase("running setup script")
+
+	start := time.Now()
+	scripts := []ScriptStatus{
+		newScriptStatus("init", start, nil),
+		newScriptStatus("setup", start, exec.Command("sh", "-c", "exit 3").Run()),
+	}
+
+	result := &InitResult{
+		Name:          "feature",
+		Path:          "/work/feature",
+		DockerProject: "mono-feature",
+		Allocations:   []Allocation{{Service: "db", ContainerPort: 5432, HostPort: 15432}},
+		Artifacts:     []ArtifactStatus{{Name: "cargo", Key: "abc", Hit: false}},
+		Scripts:       scripts,
+		Phases:        phases.finish(),
+	}
+	if err := writeInitResult(newInitEvent("/work/feature", result, nil, time.Second)); err != nil {
+		t.Fatalf("writeInitResult failed: %v", err)
+	}
+
+	path, err := InitResultPath("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected %s to be written: %v", path, err)
+	}
+	var got LifecycleEvent
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid init result: %v", err)
+	}
+
+	if got.Name != "feature" || got.DockerProject != "mono-feature" || got.DurationMS != 1000 {
+		t.Errorf("unexpected init result: %+v", got)
+	}
+	if len(got.Ports) != 1 || got.Ports[0].HostPort != 15432 {
+		t.Errorf("unexpected ports: %+v", got.Ports)
+	}
+	if len(got.Artifacts) != 1 || got.Artifacts[0].Hit {
+		t.Errorf("unexpected artifacts: %+v", got.Artifacts)
+	}
+	if len(got.Scripts) != 2 || got.Scripts[0].ExitCode != 0 || got.Scripts[1].ExitCode != 3 {
+		t.Errorf("unexpected scripts: %+v", got.Scripts)
+	}
+	if len(got.Phases) != 2 || got.Phases[0].Name != "running init script" || got.Phases[1].Name != "running setup script" {
+		t.Errorf("unexpected phases: %+v", got.Phases)
+	}
+}
@@ -0,0 +1,93 @@
+package mono
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ioLimiter struct {
+	rate int64
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newIOLimiter(rate int64) *ioLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &ioLimiter{rate: rate}
+}
+
+func (l *ioLimiter) wait(n int64) {
+	if l == nil || n <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(float64(n) / float64(l.rate) * float64(time.Second)))
+	l.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+func (l *ioLimiter) waitCopy(path string, mode LinkMode) error {
+	if l == nil || mode != LinkCopy {
+		return nil
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	l.wait(info.Size())
+	return nil
+}
+
+var byteUnits = map[string]int64{
+	"":   1,
+	"b":  1,
+	"k":  1 << 10,
+	"kb": 1 << 10,
+	"m":  1 << 20,
+	"mb": 1 << 20,
+	"g":  1 << 30,
+	"gb": 1 << 30,
+}
+
+func ParseByteSize(s string) (int64, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(value)
+	}
+
+	unit, ok := byteUnits[strings.TrimSpace(value[i:])]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q (use a size such as 50GB)", s)
+	}
+	n, err := strconv.ParseFloat(value[:i], 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q (use a size such as 50GB)", s)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+func parseByteRate(s string) (int64, error) {
+	n, err := ParseByteSize(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "/s"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q (use a size such as 50MB/s)", s)
+	}
+	return n, nil
+}
@@ -0,0 +1,96 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseByteRate(t *testing.T) {
+	for in, want := range map[string]int64{
+		"512":      512,
+		"64k":      64 << 10,
+		"50MB/s":   50 << 20,
+		"1.5 GB/s": 3 << 29,
+	} {
+		got, err := parseByteRate(in)
+		if err != nil {
+			t.Errorf("parseByteRate(%q) failed: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("parseByteRate(%q) = %d, want %d", in, got, want)
+		}
+	}
+
+	for _, in := range []string{"", "fast", "10TB", "-5MB", "0"} {
+		if _, err := parseByteRate(in); err == nil {
+			t.Errorf("expected parseByteRate(%q) to fail", in)
+		}
+	}
+}
+
+func TestIOLimiterPacesCopies(t *testing.T) {
+	src := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.WriteFile(filepath.Join(src, name), make([]byte, 50<<10), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Now()
+	err := SeedDirectory(src, filepath.Join(t.TempDir(), "dst"), SeedOptions{
+		ArtifactName: "plain",
+		NumWorkers:   3,
+		Link:         LinkCopy,
+		IOLimit:      500 << 10,
+	})
+	if err != nil {
+		t.Fatalf("SeedDirectory failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("expected 150KB at 500KB/s to be paced, took %v", elapsed)
+	}
+}
+
+func TestLoadConfigArtifactThrottling(t *testing.T) {
+	dir := t.TempDir()
+	monoYml := `build:
+  artifacts:
+    - name: npm
+      paths: [node_modules]
+      workers: 64
+      io_limit: 100MB/s
+`
+	if err := os.WriteFile(filepath.Join(dir, "mono.yml"), []byte(monoYml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	entries, err := (&CacheManager{}).PrepareArtifactCache(cfg.Build.Artifacts, dir, dir)
+	if err != nil {
+		t.Fatalf("PrepareArtifactCache failed: %v", err)
+	}
+	if entries[0].Workers != 64 || entries[0].IOLimit != 100<<20 {
+		t.Errorf("expected 64 workers at 100MB/s, got %d workers at %d B/s", entries[0].Workers, entries[0].IOLimit)
+	}
+
+	for name, artifact := range map[string]string{
+		"negative workers": "      workers: -1\n",
+		"bad io_limit":     "      io_limit: quickly\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			monoYml := "build:\n  artifacts:\n    - name: npm\n      paths: [node_modules]\n" + artifact
+			if err := os.WriteFile(filepath.Join(dir, "mono.yml"), []byte(monoYml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadConfig(dir); err == nil {
+				t.Error("expected LoadConfig to fail")
+			}
+		})
+	}
+}
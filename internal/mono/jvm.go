@@ -0,0 +1,140 @@
+package mono
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	jvmVersionCommand = "java -version 2>&1"
+	gradleCacheDir    = ".gradle"
+)
+
+func isJVMBaseType(baseType string) bool {
+	return baseType == "gradle" || baseType == "maven"
+}
+
+func gradleArtifact(name, keyFile, warmCommand string) ArtifactConfig {
+	dir := filepath.Dir(keyFile)
+	return ArtifactConfig{
+		Name:        name,
+		KeyFiles:    []string{keyFile},
+		KeyCommands: []string{jvmVersionCommand},
+		Paths: []ArtifactPath{
+			{Path: filepath.Join(dir, gradleCacheDir), Type: "gradle"},
+			{Path: filepath.Join(dir, "build"), Type: "gradle"},
+		},
+		WarmCommand: warmCommand,
+	}
+}
+
+func jvmModuleRoot(f foundLockFile, lockFiles []foundLockFile) (foundLockFile, bool) {
+	dir := filepath.Dir(f.relPath)
+	var root foundLockFile
+	found := false
+	for _, other := range lockFiles {
+		otherDir := filepath.Dir(other.relPath)
+		if other.spec.baseType != f.spec.baseType || otherDir == dir || !isParentDir(otherDir, dir) {
+			continue
+		}
+		if !found || len(otherDir) < len(filepath.Dir(root.relPath)) {
+			root, found = other, true
+		}
+	}
+	return root, found
+}
+
+func isParentDir(parent, child string) bool {
+	return parent == "." || strings.HasPrefix(child, parent+string(filepath.Separator))
+}
+
+func jvmModuleArtifact(cfg ArtifactConfig, root, module foundLockFile) ArtifactConfig {
+	cfg.KeyFiles = []string{root.relPath, module.relPath}
+	cfg.Paths = []ArtifactPath{{Path: filepath.Join(filepath.Dir(module.relPath), module.spec.artifactDir), Type: module.spec.baseType}}
+	cfg.WarmCommand = ""
+	return cfg
+}
+
+func shouldSkipGradlePath(relPath string) bool {
+	return strings.HasSuffix(relPath, ".lock")
+}
+
+func fixMavenTarget(targetDir string) error {
+	if err := rewriteMavenStatus(targetDir); err != nil {
+		return err
+	}
+
+	var files []string
+	err := filepath.WalkDir(targetDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return touchFilesParallel(files, time.Now(), workerCount(workersTouch, targetDir), "touching maven outputs")
+}
+
+func rewriteMavenStatus(targetDir string) error {
+	lists, err := filepath.Glob(filepath.Join(targetDir, "maven-status", "*", "*", "*", "inputFiles.lst"))
+	if err != nil {
+		return err
+	}
+
+	moduleDir := filepath.Dir(targetDir)
+	for _, list := range lists {
+		oldPath, err := mavenModuleOrigin(list, moduleDir)
+		if err != nil {
+			return err
+		}
+		oldRoot, newRoot, ok := cargoPathRemap(oldPath, moduleDir)
+		if !ok {
+			continue
+		}
+		pattern := regexp.MustCompile(`(?m)` + regexp.QuoteMeta(oldRoot) + `([^\w.-]|$)`)
+		replacement := strings.ReplaceAll(newRoot, "$", "$$") + "${1}"
+		if err := rewriteFile(list, pattern, replacement); err != nil {
+			return fmt.Errorf("failed to rewrite %s: %w", list, err)
+		}
+	}
+	return nil
+}
+
+func mavenModuleOrigin(list, moduleDir string) (string, error) {
+	f, err := os.Open(list)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		source := strings.TrimSpace(scanner.Text())
+		if !filepath.IsAbs(source) {
+			continue
+		}
+		for i := 1; i < len(source); i++ {
+			if source[i] != filepath.Separator {
+				continue
+			}
+			if fileExists(filepath.Join(moduleDir, source[i+1:])) {
+				return source[:i], nil
+			}
+		}
+	}
+	return "", scanner.Err()
+}
@@ -0,0 +1,127 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDetectJVMArtifacts(t *testing.T) {
+	testDir := t.TempDir()
+
+	for _, name := range []string{"pom.xml", "api/pom.xml", "api/core/pom.xml", "android/gradle.lockfile", "android/app/gradle.lockfile", "target/classes/META-INF/maven/pom.xml"} {
+		path := filepath.Join(testDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	artifacts := detectArtifacts(testDir)
+	if len(artifacts) != 5 {
+		t.Fatalf("expected an artifact per maven and gradle module, got %+v", artifacts)
+	}
+	byName := make(map[string]ArtifactConfig)
+	for _, a := range artifacts {
+		if err := validateArtifactPaths(a); err != nil {
+			t.Errorf("expected the detected %s artifact to be valid: %v", a.Name, err)
+		}
+		byName[a.Name] = a
+	}
+
+	maven, ok := byName["maven"]
+	if !ok {
+		t.Fatalf("expected a maven artifact, got %+v", artifacts)
+	}
+	if !slices.Equal(maven.Paths, []ArtifactPath{{Path: "target", Type: "maven"}}) || !slices.Equal(maven.KeyCommands, []string{jvmVersionCommand}) || maven.WarmCommand != "mvn -B package -DskipTests" {
+		t.Errorf("unexpected maven artifact %+v", maven)
+	}
+
+	core, ok := byName["maven-api-core"]
+	if !ok {
+		t.Fatalf("expected a maven-api-core artifact, got %+v", artifacts)
+	}
+	if !slices.Equal(core.KeyFiles, []string{"pom.xml", "api/core/pom.xml"}) || !slices.Equal(core.Paths, []ArtifactPath{{Path: "api/core/target", Type: "maven"}}) {
+		t.Errorf("expected a module keyed on the root pom and its own, got %+v", core)
+	}
+	if core.WarmCommand != "" {
+		t.Errorf("expected modules to be built by the root's warm command, got %q", core.WarmCommand)
+	}
+
+	gradle, ok := byName["gradle-android"]
+	if !ok {
+		t.Fatalf("expected a gradle-android artifact, got %+v", artifacts)
+	}
+	wantGradle := []ArtifactPath{{Path: "android/.gradle", Type: "gradle"}, {Path: "android/build", Type: "gradle"}}
+	if !slices.Equal(gradle.Paths, wantGradle) {
+		t.Errorf("expected paths %v, got %v", wantGradle, gradle.Paths)
+	}
+	app := byName["gradle-android-app"]
+	if !slices.Equal(app.Paths, []ArtifactPath{{Path: "android/app/build", Type: "gradle"}}) || !slices.Equal(app.KeyFiles, []string{"android/gradle.lockfile", "android/app/gradle.lockfile"}) {
+		t.Errorf("unexpected gradle module artifact %+v", app)
+	}
+}
+
+func TestMavenRestoreFixes(t *testing.T) {
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	if !shouldSkipPath("8.5/fileHashes/fileHashes.lock", "gradle") || shouldSkipPath("8.5/fileHashes/fileHashes.bin", "gradle") {
+		t.Error("expected only gradle lock files to be skipped")
+	}
+
+	oldModule := filepath.Join(t.TempDir(), "feature-a", "api")
+	module := filepath.Join(t.TempDir(), "feature-b", "api")
+	source := filepath.Join(module, "src", "main", "java", "App.java")
+	target := filepath.Join(module, "target")
+	status := filepath.Join(target, "maven-status", "maven-compiler-plugin", "compile", "default-compile")
+	class := filepath.Join(target, "classes", "App.class")
+	for _, dir := range []string{filepath.Dir(source), status, filepath.Dir(class)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(source, []byte("class App {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(class, []byte("cafebabe"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-24 * time.Hour)
+	if err := os.Chtimes(class, old, old); err != nil {
+		t.Fatal(err)
+	}
+	list := filepath.Join(status, "inputFiles.lst")
+	if err := os.WriteFile(list, []byte(filepath.Join(oldModule, "src", "main", "java", "App.java")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cm.ApplyPostRestoreFixes("maven", target); err != nil {
+		t.Fatalf("ApplyPostRestoreFixes failed: %v", err)
+	}
+
+	data, err := os.ReadFile(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != source+"\n" {
+		t.Errorf("expected inputFiles.lst to point at the new module, got %q", data)
+	}
+	info, err := os.Stat(class)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().After(old) {
+		t.Error("expected restored classes to be touched so sources do not look newer")
+	}
+
+	if err := cm.ApplyPostRestoreFixes("maven", filepath.Join(t.TempDir(), "target")); err != nil {
+		t.Errorf("expected a missing target dir to be ignored: %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+package mono
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	KeyStrategyContent = "content"
+	KeyStrategyStat    = "stat"
+)
+
+type keyFileMemo struct {
+	Size       int64     `json:"size"`
+	ModTime    int64     `json:"mtime_ns"`
+	Inode      uint64    `json:"inode"`
+	SHA256     string    `json:"sha256"`
+	VerifiedAt time.Time `json:"verified_at"`
+}
+
+type keyMemo struct {
+	mu       sync.Mutex
+	files    map[keyFileStamp][]byte
+	commands map[string]*keyCommandResult
+}
+
+type keyFileStamp struct {
+	path    string
+	size    int64
+	modTime int64
+	inode   uint64
+}
+
+type keyCommandResult struct {
+	once   sync.Once
+	output []byte
+	err    error
+}
+
+func (m *keyMemo) command(cmd string) *keyCommandResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.commands == nil {
+		m.commands = make(map[string]*keyCommandResult)
+	}
+	result, ok := m.commands[cmd]
+	if !ok {
+		result = &keyCommandResult{}
+		m.commands[cmd] = result
+	}
+	return result
+}
+
+func (m *keyMemo) forgetCommands() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commands = nil
+}
+
+func (m *keyMemo) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commands = nil
+	m.files = nil
+}
+
+func (m *keyMemo) file(stamp keyFileStamp) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	input, ok := m.files[stamp]
+	return input, ok
+}
+
+func (m *keyMemo) storeFile(stamp keyFileStamp, input []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.files == nil {
+		m.files = make(map[keyFileStamp][]byte)
+	}
+	m.files[stamp] = input
+}
+
+func validKeyStrategy(strategy string) bool {
+	return strategy == "" || strategy == KeyStrategyContent || strategy == KeyStrategyStat
+}
+
+func (cm *CacheManager) keyInputs(artifact ArtifactConfig, envPath string) ([][]byte, error) {
+	files := make(map[string][]byte)
+	var order []string
+	for _, keyFile := range artifact.KeyFiles {
+		if _, ok := files[keyFile]; !ok {
+			files[keyFile] = nil
+			order = append(order, keyFile)
+		}
+	}
+	fileInputs := make([][]byte, len(order))
+	outputs := make([][]byte, len(artifact.KeyCommands))
+
+	var g errgroup.Group
+	g.SetLimit(workerCount(workersWalk, envPath))
+	for i, keyFile := range order {
+		g.Go(func() error {
+			input, err := cm.keyFileInput(artifact.KeyStrategy, filepath.Join(envPath, keyFile))
+			if err != nil {
+				return fmt.Errorf("failed to hash key file %s: %w", keyFile, err)
+			}
+			fileInputs[i] = input
+			return nil
+		})
+	}
+	for i, cmd := range artifact.KeyCommands {
+		g.Go(func() error {
+			output, err := cm.keyCommandOutput(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to run key command %s: %w", cmd, err)
+			}
+			outputs[i] = output
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	for i, keyFile := range order {
+		files[keyFile] = fileInputs[i]
+	}
+	inputs := make([][]byte, 0, len(artifact.KeyFiles)+len(outputs))
+	for _, keyFile := range artifact.KeyFiles {
+		inputs = append(inputs, files[keyFile])
+	}
+	return append(inputs, outputs...), nil
+}
+
+func (cm *CacheManager) keyCommandOutput(cmd string) ([]byte, error) {
+	result := cm.keys.command(cmd)
+	result.once.Do(func() {
+		result.output, result.err = exec.Command("bash", "-c", cmd).Output()
+	})
+	return result.output, result.err
+}
+
+func (cm *CacheManager) keyFileInput(strategy, path string) ([]byte, error) {
+	if strategy == KeyStrategyStat {
+		sum, err := cm.statKeyFileHash(path)
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return []byte(sum), err
+	}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	stamp := keyFileStamp{path: path, size: info.Size(), modTime: info.ModTime().UnixNano()}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		stamp.inode = stat.Ino
+	}
+	if input, ok := cm.keys.file(stamp); ok {
+		return input, nil
+	}
+
+	input, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cm.keys.storeFile(stamp, input)
+	return input, nil
+}
+
+func (cm *CacheManager) keyMemoPath(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(cm.HomeDir, "key_hashes", hex.EncodeToString(sum[:16])+".json")
+}
+
+func (cm *CacheManager) statKeyFileHash(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	current := keyFileMemo{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		current.Inode = stat.Ino
+	}
+
+	memoPath := cm.keyMemoPath(path)
+	if memo, ok := readKeyFileMemo(memoPath); ok &&
+		memo.Size == current.Size &&
+		memo.ModTime == current.ModTime &&
+		memo.Inode == current.Inode &&
+		time.Since(memo.VerifiedAt) < globalConfig().Cache.KeyRevalidate {
+		return memo.SHA256, nil
+	}
+
+	current.SHA256, err = fileSHA256(path)
+	if err != nil {
+		return "", err
+	}
+	current.VerifiedAt = time.Now()
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(memoPath), 0755); err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(memoPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to record key file hash: %w", err)
+	}
+	return current.SHA256, nil
+}
+
+func readKeyFileMemo(path string) (keyFileMemo, bool) {
+	var memo keyFileMemo
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return memo, false
+	}
+	if err := json.Unmarshal(data, &memo); err != nil {
+		return memo, false
+	}
+	return memo, true
+}
@@ -0,0 +1,145 @@
+package mono
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+const envLockPoll = 100 * time.Millisecond
+
+type EnvLock struct {
+	file *os.File
+}
+
+type lockHolder struct {
+	PID       int       `json:"pid"`
+	Operation string    `json:"operation"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+func envLockPath(envName string) (string, error) {
+	home, err := GetMonoHome()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, "locks", envName+".lock"), nil
+}
+
+func AcquireEnvLock(ctx context.Context, envName, operation string, out io.Writer) (*EnvLock, error) {
+	path, err := envLockPath(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	f, err := openLockFile(path, syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		if holder, readErr := readLockHolder(path); readErr == nil {
+			fmt.Fprintf(out, "waiting for %s started by PID %d (%s)...\n", holder.Operation, holder.PID, holder.StartedAt.Format("15:04:05"))
+		} else {
+			fmt.Fprintf(out, "waiting for another mono operation on %s...\n", envName)
+		}
+	}
+	for errors.Is(err, syscall.EWOULDBLOCK) {
+		select {
+		case <-ctx.Done():
+			return nil, interruptErr(ctx)
+		case <-time.After(envLockPoll):
+		}
+		f, err = openLockFile(path, syscall.LOCK_EX|syscall.LOCK_NB)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire environment lock: %w", err)
+	}
+
+	if err := writeLockHolder(f, operation); err != nil {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+		return nil, err
+	}
+
+	return &EnvLock{file: f}, nil
+}
+
+func openLockFile(path string, how int) (*os.File, error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := syscall.Flock(int(f.Fd()), how); err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		held, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		current, err := os.Stat(path)
+		if err == nil && os.SameFile(held, current) {
+			return f, nil
+		}
+		f.Close()
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+}
+
+func (l *EnvLock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	if err := l.file.Truncate(0); err != nil {
+		l.file.Close()
+		return fmt.Errorf("failed to clear lock holder: %w", err)
+	}
+	if err := syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN); err != nil {
+		l.file.Close()
+		return fmt.Errorf("failed to release environment lock: %w", err)
+	}
+	return l.file.Close()
+}
+
+func readLockHolder(path string) (*lockHolder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var holder lockHolder
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return nil, err
+	}
+	return &holder, nil
+}
+
+func writeLockHolder(f *os.File, operation string) error {
+	data, err := json.Marshal(lockHolder{
+		PID:       os.Getpid(),
+		Operation: operation,
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode lock holder: %w", err)
+	}
+	if err := f.Truncate(0); err != nil {
+		return fmt.Errorf("failed to write lock holder: %w", err)
+	}
+	if _, err := f.WriteAt(data, 0); err != nil {
+		return fmt.Errorf("failed to write lock holder: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package mono
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnvLockWaitsForHolder(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	first, err := AcquireEnvLock(context.Background(), "lock-test", "init", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("failed to acquire first lock: %v", err)
+	}
+
+	var out bytes.Buffer
+	acquired := make(chan *EnvLock)
+	go func() {
+		second, err := AcquireEnvLock(context.Background(), "lock-test", "sync", &out)
+		if err != nil {
+			t.Errorf("failed to acquire second lock: %v", err)
+		}
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second lock acquired while first was held")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatalf("failed to release first lock: %v", err)
+	}
+
+	select {
+	case second := <-acquired:
+		if err := second.Release(); err != nil {
+			t.Fatalf("failed to release second lock: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second lock not acquired after release")
+	}
+
+	if !strings.Contains(out.String(), "waiting for init started by PID") {
+		t.Errorf("expected waiting message, got %q", out.String())
+	}
+}
+
+func TestEnvLockStopsWaitingWhenInterrupted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	first, err := AcquireEnvLock(context.Background(), "lock-test", "init", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("failed to acquire first lock: %v", err)
+	}
+	defer first.Release()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(200*time.Millisecond, func() { cancel(ErrInterrupted) })
+
+	second, err := AcquireEnvLock(ctx, "lock-test", "sync", &bytes.Buffer{})
+	if !errors.Is(err, ErrInterrupted) {
+		second.Release()
+		t.Fatalf("expected waiting to stop with %v, got %v", ErrInterrupted, err)
+	}
+}
@@ -0,0 +1,83 @@
+package mono
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type packageLock struct {
+	LockfileVersion int                         `json:"lockfileVersion"`
+	Packages        map[string]packageLockEntry `json:"packages"`
+}
+
+type packageLockEntry struct {
+	Optional    bool `json:"optional"`
+	DevOptional bool `json:"devOptional"`
+}
+
+func missingLockedPackages(nodeModulesDir string) ([]string, error) {
+	if filepath.Base(nodeModulesDir) != "node_modules" {
+		return nil, nil
+	}
+	lockDir := filepath.Dir(nodeModulesDir)
+	data, err := os.ReadFile(filepath.Join(lockDir, "package-lock.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lock packageLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse package-lock.json: %w", err)
+	}
+
+	var missing []string
+	for key, pkg := range lock.Packages {
+		if pkg.Optional || pkg.DevOptional {
+			continue
+		}
+		if !strings.HasPrefix(key, "node_modules/") && !strings.Contains(key, "/node_modules/") {
+			continue
+		}
+		if !fileExists(filepath.Join(lockDir, key, "package.json")) {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+func (cm *CacheManager) verifyRestoredLockfile(entry ArtifactCacheEntry, envPath string, logger *FileLogger) error {
+	missing, err := missingLockedPackages(envPath)
+	if err != nil {
+		return fmt.Errorf("failed to check %s against its lockfile: %w", envPath, err)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if err := os.RemoveAll(envPath); err != nil {
+		return fmt.Errorf("failed to remove incomplete %s: %w", envPath, err)
+	}
+
+	lock, err := cm.waitCacheLock(entry.CachePath)
+	if err != nil {
+		return fmt.Errorf("failed to lock cache entry: %w", err)
+	}
+	defer cm.releaseCacheLock(lock)
+
+	if dirExists(entry.CachePath) {
+		dest, err := cm.quarantineEntry(entry.CachePath)
+		if err != nil {
+			return err
+		}
+		logger.Log("quarantined incomplete cache entry for %s to %s: %d locked packages missing, first %s", entry.Name, dest, len(missing), missing[0])
+	}
+	return fmt.Errorf("%w: %s (key: %s) is missing %d packages from package-lock.json", ErrCacheCorrupt, entry.Name, entry.Key, len(missing))
+}
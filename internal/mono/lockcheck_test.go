@@ -0,0 +1,91 @@
+package mono
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestoreVerifiesPackageLock(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+	logger, err := NewFileLogger("env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	envPath := filepath.Join(t.TempDir(), "env")
+	lock := `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app"},
+    "node_modules/left-pad": {"version": "1.3.0"},
+    "node_modules/left-pad/node_modules/nested": {"version": "1.0.0"},
+    "node_modules/fsevents": {"version": "2.3.3", "optional": true},
+    "packages/lib": {"version": "0.1.0"}
+  }
+}`
+	for path, content := range map[string]string{
+		"package-lock.json":                                      lock,
+		"node_modules/left-pad/package.json":                     "{}",
+		"node_modules/left-pad/node_modules/nested/package.json": "{}",
+	} {
+		full := filepath.Join(envPath, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	artifact := ArtifactConfig{
+		Name:           "npm",
+		KeyFiles:       []string{"package-lock.json"},
+		Paths:          []ArtifactPath{{Path: "node_modules"}},
+		VerifyLockfile: true,
+	}
+	entries, err := cm.PrepareArtifactCache([]ArtifactConfig{artifact}, envPath, envPath)
+	if err != nil {
+		t.Fatalf("PrepareArtifactCache failed: %v", err)
+	}
+	entry := entries[0]
+	if err := cm.StoreToCache(entry); err != nil {
+		t.Fatalf("StoreToCache failed: %v", err)
+	}
+
+	nodeModules := filepath.Join(envPath, "node_modules")
+	if err := os.RemoveAll(nodeModules); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.RestoreFromCache(entry, logger); err != nil {
+		t.Fatalf("RestoreFromCache of a complete entry failed: %v", err)
+	}
+
+	if err := os.RemoveAll(filepath.Join(entry.CachePath, "node_modules", "left-pad", "node_modules")); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeManifest(entry.CachePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(nodeModules); err != nil {
+		t.Fatal(err)
+	}
+
+	err = cm.RestoreFromCache(entry, logger)
+	if !errors.Is(err, ErrCacheCorrupt) {
+		t.Fatalf("expected ErrCacheCorrupt for an incomplete entry, got %v", err)
+	}
+	if dirExists(nodeModules) {
+		t.Error("expected the incomplete node_modules to be removed")
+	}
+	if dirExists(entry.CachePath) {
+		t.Error("expected the incomplete cache entry to be quarantined")
+	}
+}
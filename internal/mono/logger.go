@@ -2,6 +2,7 @@ package mono
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,17 +15,17 @@ type FileLogger struct {
 	file    *os.File
 	start   time.Time
 	envName string
+	status  *StatusServer
+	mirror  io.Writer
 }
 
 func NewFileLogger(envName string) (*FileLogger, error) {
-	home, err := os.UserHomeDir()
+	monoDir, err := GetMonoHome()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
-
-	monoDir := filepath.Join(home, ".mono")
 	if err := os.MkdirAll(monoDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create ~/.mono directory: %w", err)
+		return nil, fmt.Errorf("failed to create mono home %s: %w", monoDir, err)
 	}
 
 	logPath := filepath.Join(monoDir, "mono.log")
@@ -53,6 +54,14 @@ func (l *FileLogger) Log(format string, args ...any) {
 		msg)
 }
 
+func (l *FileLogger) SetStatus(status *StatusServer) {
+	l.status = status
+}
+
+func (l *FileLogger) SetMirror(w io.Writer) {
+	l.mirror = w
+}
+
 func (l *FileLogger) Close() {
 	if l.file != nil {
 		l.file.Close()
@@ -92,17 +101,20 @@ type ProgressLogger struct {
 }
 
 func NewProgressLogger(logger *FileLogger, operation string, total int64) *ProgressLogger {
-	return &ProgressLogger{
+	p := &ProgressLogger{
 		logger:      logger,
 		operation:   operation,
 		total:       total,
 		lastLogTime: time.Now(),
 		interval:    5 * time.Second,
 	}
+	logger.status.track(p)
+	return p
 }
 
 func (p *ProgressLogger) Increment() {
-	p.completed.Add(1)
+	completed := p.completed.Add(1)
+	progressFiles(p.operation, completed, p.total, false)
 	p.maybeLog()
 }
 
@@ -132,4 +144,6 @@ func (p *ProgressLogger) Done() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.logProgress()
+	progressFiles(p.operation, p.completed.Load(), p.total, true)
+	p.logger.status.untrack(p)
 }
@@ -0,0 +1,59 @@
+package mono
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const flakeLockFile = "flake.lock"
+
+type NixConfig struct {
+	Develop bool   `yaml:"develop"`
+	Shell   string `yaml:"shell"`
+}
+
+func HasFlake(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "flake.nix"))
+	return err == nil
+}
+
+func (n NixConfig) active(dir string) bool {
+	return n.Develop && HasFlake(dir)
+}
+
+func (n NixConfig) developArgs(dir string) []string {
+	ref := dir
+	if n.Shell != "" {
+		ref += "#" + n.Shell
+	}
+	return []string{"--extra-experimental-features", "nix-command flakes", "develop", ref, "--command"}
+}
+
+func (n NixConfig) applyKeyFiles(artifacts []ArtifactConfig, dir string) {
+	if !n.active(dir) {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(dir, flakeLockFile)); err != nil {
+		return
+	}
+	for i := range artifacts {
+		if !slices.Contains(artifacts[i].KeyFiles, flakeLockFile) {
+			artifacts[i].KeyFiles = append(artifacts[i].KeyFiles, flakeLockFile)
+		}
+	}
+}
+
+func runNixScript(ctx context.Context, n NixConfig, dir, script string, envVars []string, logger *FileLogger) error {
+	args := append(n.developArgs(dir), "sh", "-c", script)
+	return runProcess(ctx, dir, append(os.Environ(), envVars...), logger, "nix", args...)
+}
+
+func nixRunScript(n NixConfig, dir, script string, scriptArgs []string) string {
+	args := append(n.developArgs(dir), "sh", "-c", script)
+	if len(scriptArgs) > 0 {
+		args = append(append(args, "sh"), scriptArgs...)
+	}
+	return "exec nix " + shellQuoteArgs(args) + "\n"
+}
@@ -0,0 +1,85 @@
+package mono
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNixAddsFlakeLockToCacheKeys(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"flake.nix", "flake.lock"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	artifacts := func() []ArtifactConfig {
+		return []ArtifactConfig{{Name: "cargo", KeyFiles: []string{"Cargo.lock"}, Paths: []ArtifactPath{{Path: "target"}}}}
+	}
+
+	cfg := &Config{Build: BuildConfig{Artifacts: artifacts()}}
+	cfg.ApplyDefaults(dir)
+	if slices.Contains(cfg.Build.Artifacts[0].KeyFiles, flakeLockFile) {
+		t.Error("flake.lock should only be keyed when nix.develop is enabled")
+	}
+
+	cfg = &Config{Build: BuildConfig{Artifacts: artifacts()}, Nix: NixConfig{Develop: true}}
+	cfg.ApplyDefaults(dir)
+	cfg.ApplyDefaults(dir)
+	if got := cfg.Build.Artifacts[0].KeyFiles; !slices.Equal(got, []string{"Cargo.lock", flakeLockFile}) {
+		t.Errorf("expected flake.lock appended once, got %v", got)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "flake.nix")); err != nil {
+		t.Fatal(err)
+	}
+	cfg = &Config{Build: BuildConfig{Artifacts: artifacts()}, Nix: NixConfig{Develop: true}}
+	cfg.ApplyDefaults(dir)
+	if slices.Contains(cfg.Build.Artifacts[0].KeyFiles, flakeLockFile) {
+		t.Error("flake.lock should not be keyed without a flake.nix")
+	}
+}
+
+func TestRunEnvScriptUsesNixDevelop(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	binDir := t.TempDir()
+	fakeNix := "#!/bin/sh\necho \"$@\" > \"$NIX_ARGS_FILE\"\nwhile [ \"$1\" != \"--command\" ]; do shift; done\nshift\nexec \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(binDir, "nix"), []byte(fakeNix), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "flake.nix"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	argsFile := filepath.Join(t.TempDir(), "args")
+	t.Setenv("NIX_ARGS_FILE", argsFile)
+
+	logger, err := NewFileLogger("nix-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	cfg := &Config{Nix: NixConfig{Develop: true, Shell: "ci"}}
+	if err := runEnvScript(context.Background(), cfg, nil, dir, "touch ran", nil, logger); err != nil {
+		t.Fatalf("runEnvScript failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "ran")); err != nil {
+		t.Errorf("script did not run inside nix develop: %v", err)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(args), "develop "+dir+"#ci --command sh -c touch ran") {
+		t.Errorf("unexpected nix invocation: %s", args)
+	}
+}
@@ -1,77 +1,161 @@
 package mono
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 )
 
-func Init(path string) error {
-	if _, err := os.Stat(path); err != nil {
-		return fmt.Errorf("path does not exist: %s", path)
+type InitOptions struct {
+	Force bool
+	Root  string
+}
+
+type InitResult struct {
+	Name          string
+	Path          string
+	DataDir       string
+	DockerProject string
+	Allocations   []Allocation
+	SessionName   string
+	Devcontainer  string
+	Reconciled    bool
+	CacheHit      bool
+	Artifacts     []ArtifactStatus
+	Scripts       []ScriptStatus
+	Phases        []PhaseStatus
+}
+
+type ArtifactStatus struct {
+	Name         string `json:"name"`
+	Key          string `json:"key"`
+	Hit          bool   `json:"hit"`
+	RestoredFrom string `json:"restored_from,omitempty"`
+}
+
+func Init(path string, opts InitOptions, out io.Writer) error {
+	result, err := runInit(path, opts)
+	if err != nil {
+		return err
 	}
 
-	project, workspace := DeriveNames(path)
-	envName := fmt.Sprintf("%s-%s", project, workspace)
-	if project == "" || workspace == "" {
-		envName = filepath.Base(path)
+	if result.Reconciled {
+		fmt.Fprintf(out, "Environment reconciled: %s\n", result.Name)
+	} else {
+		fmt.Fprintf(out, "Environment initialized: %s\n", result.Name)
+	}
+	fmt.Fprintf(out, "  Path: %s\n", result.Path)
+	fmt.Fprintf(out, "  Data: %s\n", result.DataDir)
+	if result.DockerProject != "" {
+		fmt.Fprintf(out, "  Docker: %s\n", result.DockerProject)
+		for _, alloc := range result.Allocations {
+			fmt.Fprintf(out, "  %s: %d -> %d\n", alloc.Service, alloc.ContainerPort, alloc.HostPort)
+		}
+	}
+	if result.Devcontainer != "" {
+		fmt.Fprintf(out, "  Devcontainer: %s\n", result.Devcontainer)
+		for _, alloc := range result.Allocations {
+			fmt.Fprintf(out, "  %d -> %d\n", alloc.ContainerPort, alloc.HostPort)
+		}
 	}
+	if result.DockerProject == "" && result.Devcontainer == "" {
+		for _, alloc := range result.Allocations {
+			fmt.Fprintf(out, "  %s: %d\n", alloc.Service, alloc.HostPort)
+		}
+	}
+	fmt.Fprintf(out, "  Tmux: %s\n", result.SessionName)
+
+	return nil
+}
+
+func initEnvironment(path string, opts InitOptions) (_ *InitResult, err error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("path does not exist: %s", path)
+	}
+
+	envName := EnvName(path)
 
 	logger, err := NewFileLogger(envName)
 	if err != nil {
-		return fmt.Errorf("failed to create logger: %w", err)
+		return nil, fmt.Errorf("failed to create logger: %w", err)
 	}
 	defer logger.Close()
 
 	logger.Log("mono init %s", path)
 
+	ctx, stopSignals := notifyInterrupt("init")
+	defer stopSignals()
+
+	lock, err := AcquireEnvLock(ctx, envName, "init", os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, lock.Release())
+	}()
+
 	db, err := OpenDB()
 	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 	defer db.Close()
 
 	exists, err := db.EnvironmentExists(path)
 	if err != nil {
-		return fmt.Errorf("failed to check environment: %w", err)
+		return nil, fmt.Errorf("failed to check environment: %w", err)
 	}
 	if exists {
-		return fmt.Errorf("environment already exists: %s", path)
+		if !opts.Force {
+			return nil, fmt.Errorf("environment already exists: %s (use --force to reconcile)", path)
+		}
+		return reconcileEnvironment(ctx, db, logger, path)
 	}
 
-	home, err := os.UserHomeDir()
+	tx := newRollback(logger)
+	defer tx.run()
+
+	dataDir, err := DataDir(envName)
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
-	dataDir := filepath.Join(home, ".mono", "data", envName)
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		return fmt.Errorf("failed to create data directory: %w", err)
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
+	tx.add("data directory", func() error {
+		return os.RemoveAll(dataDir)
+	})
 	logger.Log("created data directory")
 
-	cleanup := func() {
-		os.RemoveAll(dataDir)
+	status, err := StartStatusServer(envName, "init")
+	if err != nil {
+		logger.Log("warning: failed to start status server: %v", err)
 	}
+	defer status.Close()
+	logger.SetStatus(status)
+	phases := newPhaseTimer(status)
 
 	cfg, err := LoadConfig(path)
 	if err != nil {
-		cleanup()
-		return fmt.Errorf("failed to load config: %w", err)
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 	cfg.ApplyDefaults(path)
 
 	cm, err := NewCacheManager()
 	if err != nil {
-		cleanup()
-		return fmt.Errorf("failed to initialize cache: %w", err)
+		return nil, fmt.Errorf("failed to initialize cache: %w", err)
 	}
 
 	if err := cm.EnsureDirectories(); err != nil {
-		cleanup()
-		return fmt.Errorf("failed to create cache directories: %w", err)
+		return nil, fmt.Errorf("failed to create cache directories: %w", err)
 	}
 
 	if cm.SccacheAvailable {
@@ -81,10 +165,23 @@ func Init(path string) error {
 		logger.Log("hint: install sccache for faster builds: cargo install sccache")
 	}
 
-	rootPath := os.Getenv("CONDUCTOR_ROOT_PATH")
+	strategy, err := cm.Strategy(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect cache strategy: %w", err)
+	}
+	logger.Log("cache strategy: %s", strategy)
+
+	rootPath, err := ResolveRootPath(path, opts.Root)
+	if err != nil {
+		return nil, err
+	}
+	if rootPath != "" {
+		logger.Log("using root path %s", rootPath)
+	}
 
 	var cacheEntries []ArtifactCacheEntry
 	if len(cfg.Build.Artifacts) > 0 && rootPath != "" {
+		phases.SetPhase("preparing artifact cache")
 		entries, err := cm.PrepareArtifactCache(cfg.Build.Artifacts, rootPath, path)
 		if err != nil {
 			logger.Log("warning: failed to prepare artifact cache: %v", err)
@@ -97,6 +194,7 @@ func Init(path string) error {
 			initialHits[entry.Name] = entry.Hit
 		}
 
+		remoteHits := make(map[string]bool)
 		hasMiss := false
 		for _, entry := range cacheEntries {
 			if !entry.Hit {
@@ -106,6 +204,7 @@ func Init(path string) error {
 		}
 
 		if hasMiss {
+			phases.SetPhase("seeding cache from root")
 			if err := cm.SeedFromRoot(cfg.Build.Artifacts, rootPath, path, logger); err != nil {
 				logger.Log("warning: failed to seed cache from root: %v", err)
 			}
@@ -116,46 +215,120 @@ func Init(path string) error {
 			} else {
 				cacheEntries = entries
 			}
+
+			for i := range cacheEntries {
+				entry := &cacheEntries[i]
+				if entry.Hit || !remoteCacheEnabled() {
+					continue
+				}
+				phases.SetPhase("fetching " + entry.Name + " from remote cache")
+				fetched, err := cm.FetchFromRemote(ctx, *entry)
+				if err != nil {
+					if err := interruptErr(ctx); err != nil {
+						return nil, err
+					}
+					logger.Log("warning: failed to fetch %s from remote cache: %v", entry.Name, err)
+					continue
+				}
+				if fetched {
+					entry.Hit = true
+					entry.RestoreKey = ""
+					remoteHits[entry.Name] = true
+				}
+			}
 		}
 
-		projectID := ComputeProjectID(rootPath)
 		for i := range cacheEntries {
 			entry := &cacheEntries[i]
 			if entry.Hit {
 				wasSeeded := !initialHits[entry.Name]
-				if wasSeeded {
+				if remoteHits[entry.Name] {
+					logger.Log("fetched %s from remote cache (key: %s)", entry.Name, entry.Key)
+				} else if wasSeeded {
 					logger.Log("seeded %s from root (key: %s)", entry.Name, entry.Key)
 				} else {
 					logger.Log("cache hit for %s (key: %s)", entry.Name, entry.Key)
 				}
-				if err := cm.RestoreFromCache(*entry, logger); err != nil {
+				for _, envPath := range entry.EnvPaths {
+					if !dirExists(envPath) {
+						tx.add("restored "+envPath, func() error {
+							return os.RemoveAll(envPath)
+						})
+					}
+				}
+				phases.SetPhase("restoring " + entry.Name)
+				refetched, err := cm.restoreOrRefetch(ctx, *entry, !remoteHits[entry.Name], logger)
+				if refetched {
+					remoteHits[entry.Name] = true
+					logger.Log("fetched %s from remote cache after a corrupt local entry (key: %s)", entry.Name, entry.Key)
+				}
+				if err != nil {
+					if err := interruptErr(ctx); err != nil {
+						return nil, err
+					}
 					logger.Log("warning: failed to restore cache: %v", err)
 					entry.Hit = false
+					if errors.Is(err, ErrCacheCorrupt) {
+						if err := db.RecordCacheEvent("miss", entry.ProjectID, entry.Name, entry.Key); err != nil {
+							logger.Log("warning: failed to record cache miss: %v", err)
+						}
+					}
+				} else if err := cm.RunPostRestore(ctx, cfg, *entry, path, rootPath, logger); err != nil {
+					if err := interruptErr(ctx); err != nil {
+						logger.Log("%v during post_restore, rolling back", err)
+						return nil, err
+					}
+					logger.Log("warning: %v", err)
+					entry.Hit = false
 				} else {
-					if err := db.RecordCacheEvent("hit", projectID, entry.Name, entry.Key); err != nil {
+					if err := db.RecordCacheEvent("hit", entry.ProjectID, entry.Name, entry.Key); err != nil {
 						logger.Log("warning: failed to record cache hit: %v", err)
 					}
 				}
 			} else {
 				logger.Log("cache miss for %s (key: %s)", entry.Name, entry.Key)
-				if err := db.RecordCacheEvent("miss", projectID, entry.Name, entry.Key); err != nil {
+				if err := db.RecordCacheEvent("miss", entry.ProjectID, entry.Name, entry.Key); err != nil {
 					logger.Log("warning: failed to record cache miss: %v", err)
 				}
+				if entry.RestoreKey == "" {
+					continue
+				}
+				for _, envPath := range entry.EnvPaths {
+					if !dirExists(envPath) {
+						tx.add("restored "+envPath, func() error {
+							return os.RemoveAll(envPath)
+						})
+					}
+				}
+				phases.SetPhase("restoring " + entry.Name + " from " + entry.RestoreKey)
+				if err := cm.RestoreFromRestoreKey(*entry, logger); err != nil {
+					logger.Log("warning: failed to restore %s from fallback entry %s: %v", entry.Name, entry.RestoreKey, err)
+					entry.RestoreKey = ""
+					continue
+				}
+				logger.Log("restored stale %s from key %s as a starting point (key: %s)", entry.Name, entry.RestoreKey, entry.Key)
+				if err := db.RecordCacheEvent("hit", entry.ProjectID, entry.Name, entry.RestoreKey); err != nil {
+					logger.Log("warning: failed to record cache hit: %v", err)
+				}
 			}
 		}
 	}
 
 	allHit := true
+	var artifactStatuses []ArtifactStatus
 	for _, entry := range cacheEntries {
 		if !entry.Hit {
 			allHit = false
-			break
 		}
+		artifactStatuses = append(artifactStatuses, ArtifactStatus{Name: entry.Name, Key: entry.Key, Hit: entry.Hit, RestoredFrom: entry.RestoreKey})
 	}
 
-	cacheEnvVars := cm.EnvVars(cfg.Build)
+	cacheEnvVars := cm.EnvVars(cfg.Build, path, rootPath)
 	cacheEnvVars = append(cacheEnvVars, fmt.Sprintf("MONO_CACHE_HIT=%t", allHit))
 	cacheEnvVars = append(cacheEnvVars, "MONO_CACHE_DIR="+cm.LocalCacheDir)
+	if err := cm.EnsureSccache(cfg.Build, cmp.Or(rootPath, path)); err != nil {
+		logger.Log("warning: %v", err)
+	}
 
 	composeDir := cfg.ResolveComposeDir(path)
 	_, composeErr := DetectComposeFile(composeDir)
@@ -166,26 +339,99 @@ func Init(path string) error {
 		dockerProject = fmt.Sprintf("mono-%s", envName)
 	}
 
+	var devcontainer *DevcontainerConfig
+	if isSimpleMode {
+		devcontainer, err = LoadDevcontainer(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(cfg.Processes) > 0 && (!isSimpleMode || devcontainer != nil) {
+		return nil, fmt.Errorf("processes are only supported without docker compose or a devcontainer")
+	}
+
+	if err := interruptErr(ctx); err != nil {
+		logger.Log("%v, rolling back", err)
+		return nil, err
+	}
+
 	envID, err := db.InsertEnvironment(path, dockerProject, rootPath, cfg.ComposeDir)
 	if err != nil {
-		cleanup()
-		return fmt.Errorf("failed to save environment: %w", err)
+		return nil, fmt.Errorf("failed to save environment: %w", err)
 	}
+	tx.add("environment registration", func() error {
+		return db.DeleteEnvironment(path)
+	})
 	logger.Log("registered environment (id=%d)", envID)
 
-	cleanupWithDB := func() {
-		db.DeleteEnvironment(path)
-		cleanup()
+	var allocations []Allocation
+	var scripts []ScriptStatus
+	if len(cfg.Processes) > 0 {
+		allocations = processAllocations(envID, cfg.Processes)
 	}
 
-	var allocations []Allocation
+	if devcontainer != nil {
+		if err := CheckDockerAvailable(); err != nil {
+			return nil, err
+		}
+		allocations = devcontainerAllocations(envID, devcontainer)
+
+		phases.SetPhase("starting devcontainer")
+		logger.Log("starting devcontainer %s", DevcontainerName(envName))
+		stdout := NewLogWriter(logger, "out")
+		stderr := NewLogWriter(logger, "err")
+		tx.add("devcontainer", func() error {
+			return StopDevcontainer(context.Background(), envName, stdout, stderr)
+		})
+		if err := StartDevcontainer(ctx, devcontainer, envName, path, allocations, stdout, stderr); err != nil {
+			if err := interruptErr(ctx); err != nil {
+				logger.Log("%v while starting devcontainer, rolling back", err)
+				return nil, err
+			}
+			return nil, err
+		}
+		if err := db.SetEnvironmentDevcontainer(path, DevcontainerName(envName)); err != nil {
+			return nil, err
+		}
+		logger.Log("devcontainer started")
+	}
+
+	sessionName := SessionName(envName)
+	tm := NewTmuxManager(sessionName, path, cfg.Tmux)
+	scriptsSession := false
+	if cfg.Tmux.ScriptsWindow && (cfg.Scripts.Init != "" || cfg.Scripts.Setup != "") {
+		if tm.SessionExists() {
+			if err := tm.KillSession(); err != nil {
+				return nil, fmt.Errorf("failed to replace stale tmux session: %w", err)
+			}
+			logger.Log("killed stale tmux session %s", sessionName)
+		}
+		windowEnv := buildScriptEnv(envName, envID, path, rootPath, allocations, cfg.Env, cacheEnvVars)
+		mirror, created, err := startScriptsWindow(tm, envName, windowEnv, logger)
+		if err != nil {
+			logger.Log("warning: failed to open scripts window: %v", err)
+		} else {
+			defer mirror.Close()
+			scriptsSession = created
+			tx.add("tmux session", tm.KillSession)
+			logger.Log("mirroring script output to %s:%s", sessionName, scriptsWindowName)
+		}
+	}
 
 	if cfg.Scripts.Init != "" {
 		scriptEnv := buildScriptEnv(envName, envID, path, rootPath, allocations, cfg.Env, cacheEnvVars)
+		phases.SetPhase("running init script")
 		logger.Log("running init script: %s", cfg.Scripts.Init)
-		if err := runScript(path, cfg.Scripts.Init, scriptEnv, logger); err != nil {
-			cleanupWithDB()
-			return fmt.Errorf("init script failed: %w", err)
+		start := time.Now()
+		err := runEnvScript(ctx, cfg, devcontainer, path, cfg.Scripts.Init, scriptEnv, logger)
+		scripts = append(scripts, newScriptStatus("init", start, err))
+		if err != nil {
+			if err := interruptErr(ctx); err != nil {
+				logger.Log("%v during init script, rolling back", err)
+				return nil, err
+			}
+			return nil, fmt.Errorf("init script failed: %w", err)
 		}
 		logger.Log("init script completed")
 	}
@@ -193,93 +439,501 @@ func Init(path string) error {
 	for i := range cacheEntries {
 		entry := &cacheEntries[i]
 		if !entry.Hit {
+			phases.SetPhase("storing " + entry.Name + " to cache")
 			if err := cm.StoreToCache(*entry); err != nil {
 				logger.Log("warning: failed to store %s to cache: %v", entry.Name, err)
 			} else {
 				logger.Log("stored %s to cache (key: %s)", entry.Name, entry.Key)
 				entry.Hit = true
+				if err := cm.UploadToRemote(ctx, *entry); err != nil {
+					logger.Log("warning: failed to upload %s to remote cache: %v", entry.Name, err)
+				}
 			}
 		}
 	}
 
+	if err := RecordArtifactKeys(db, path, cacheEntries); err != nil {
+		logger.Log("warning: %v", err)
+	}
+	if err := cm.RecordCacheSizes(db, cacheEntries); err != nil {
+		logger.Log("warning: %v", err)
+	}
+	cm.enforceCacheLimit(db, cacheEntries, logger)
+
 	if !isSimpleMode {
 		if err := CheckDockerAvailable(); err != nil {
-			cleanupWithDB()
-			return err
+			return nil, err
 		}
 
-		composeConfig, err := ParseComposeConfig(composeDir)
+		composeProject, composeAllocations, err := buildComposeOverride(composeDir, envName, envID)
 		if err != nil {
-			cleanupWithDB()
-			return fmt.Errorf("failed to parse compose config: %w", err)
+			return nil, err
 		}
-
-		servicePorts := composeConfig.GetServicePorts()
-		allocations = Allocate(envID, servicePorts)
-
-		composeProject := composeConfig.Project()
-		ApplyOverrides(composeProject, envName, allocations)
+		allocations = composeAllocations
 
 		monoComposePath := filepath.Join(composeDir, "docker-compose.mono.yml")
+		if err := db.RecordComposeOverride(monoComposePath, path); err != nil {
+			return nil, fmt.Errorf("failed to record compose override: %w", err)
+		}
+		tx.add("docker-compose.mono.yml", func() error {
+			if err := os.Remove(monoComposePath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return db.DeleteComposeOverride(monoComposePath)
+		})
 		if err := WriteComposeOverride(monoComposePath, composeProject); err != nil {
-			cleanupWithDB()
-			return fmt.Errorf("failed to write compose override: %w", err)
+			return nil, fmt.Errorf("failed to write compose override: %w", err)
 		}
 		logger.Log("generated docker-compose.mono.yml")
 
+		if cfg.Docker.PreloadImages && rootPath != "" {
+			phases.SetPhase("preloading images")
+			preloadComposeImages(ctx, cm, rootPath, cfg.ResolveComposeDir(rootPath), composeProject, dockerProject, logger)
+		}
+
+		phases.SetPhase("starting containers")
 		logger.Log("running: docker compose -p %s up -d", dockerProject)
 		stdout := NewLogWriter(logger, "out")
 		stderr := NewLogWriter(logger, "err")
-		if err := StartContainers(dockerProject, composeDir, stdout, stderr); err != nil {
-			cleanupWithDB()
-			return fmt.Errorf("failed to start containers: %w", err)
+		tx.add("containers", func() error {
+			return StopContainers(context.Background(), dockerProject, composeDir, true, stdout, stderr)
+		})
+		if err := StartContainers(ctx, dockerProject, composeDir, stdout, stderr); err != nil {
+			if err := interruptErr(ctx); err != nil {
+				logger.Log("%v while starting containers, rolling back", err)
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to start containers: %w", err)
 		}
 		logger.Log("docker compose completed")
+
+		if err := resolvePublishedPorts(dockerProject, composeProject, allocations); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(cfg.Processes) > 0 {
+		phases.SetPhase("starting processes")
+		tx.add("processes", func() error {
+			_, err := StopSupervisor(envName, logger)
+			return err
+		})
+		processEnv := buildScriptEnv(envName, envID, path, rootPath, allocations, cfg.Env, cacheEnvVars)
+		if err := StartSupervisor(path, processEnv, logger); err != nil {
+			return nil, err
+		}
+		phases.SetPhase("waiting for processes")
+		if err := WaitForProcesses(ctx, envName, cfg, logger); err != nil {
+			if err := interruptErr(ctx); err != nil {
+				logger.Log("%v while waiting for processes, rolling back", err)
+				return nil, err
+			}
+			return nil, err
+		}
+	}
+
+	if registered, err := RegisterHosts(envName, allocations); err != nil {
+		logger.Log("warning: failed to register hostnames: %v", err)
+	} else if registered {
+		tx.add("hostnames", func() error {
+			_, err := UnregisterHosts(envName)
+			return err
+		})
+		logger.Log("registered %s in %s", envHostname(envName), globalConfig().Hosts.File)
+	}
+
+	if len(cfg.WaitFor) > 0 {
+		phases.SetPhase("waiting for conditions")
+		waitEnv := buildScriptEnv(envName, envID, path, rootPath, allocations, cfg.Env, cacheEnvVars)
+		if err := WaitForConditions(ctx, cfg.WaitFor, path, waitEnv, logger); err != nil {
+			if err := interruptErr(ctx); err != nil {
+				logger.Log("%v while waiting for conditions, rolling back", err)
+				return nil, err
+			}
+			return nil, err
+		}
 	}
 
 	if cfg.Scripts.Setup != "" {
 		scriptEnv := buildScriptEnv(envName, envID, path, rootPath, allocations, cfg.Env, cacheEnvVars)
+		phases.SetPhase("running setup script")
 		logger.Log("running setup script: %s", cfg.Scripts.Setup)
-		if err := runScript(path, cfg.Scripts.Setup, scriptEnv, logger); err != nil {
-			if !isSimpleMode {
-				StopContainers(dockerProject, composeDir, true, nil, nil)
+		start := time.Now()
+		err := runEnvScript(ctx, cfg, devcontainer, path, cfg.Scripts.Setup, scriptEnv, logger)
+		scripts = append(scripts, newScriptStatus("setup", start, err))
+		if err != nil {
+			if err := interruptErr(ctx); err != nil {
+				logger.Log("%v during setup script, rolling back", err)
+				return nil, err
 			}
-			cleanupWithDB()
-			return fmt.Errorf("setup script failed: %w", err)
+			return nil, fmt.Errorf("setup script failed: %w", err)
 		}
 		logger.Log("setup script completed")
 	}
 
-	sessionName := SessionName(envName)
+	if err := interruptErr(ctx); err != nil {
+		logger.Log("%v, rolling back", err)
+		return nil, err
+	}
+
+	phases.SetPhase("creating tmux session")
 	sessionEnv := buildScriptEnv(envName, envID, path, rootPath, allocations, cfg.Env, cacheEnvVars)
-	tm := NewTmuxManager(sessionName, path, cfg.Tmux)
-	if err := tm.CreateSession(sessionEnv); err != nil {
-		logger.Log("warning: failed to create tmux session: %v", err)
+	if devcontainer != nil {
+		if _, err := prepareDevcontainerEnv(envName, sessionEnv); err != nil {
+			return nil, err
+		}
+	}
+	if refreshed, err := refreshEnvrc(path, sessionEnv); err != nil {
+		logger.Log("warning: %v", err)
+	} else if refreshed {
+		logger.Log("refreshed .envrc")
+	}
+	if scriptsSession {
+		if err := tm.AddShellWindow(sessionEnv); err != nil {
+			return nil, fmt.Errorf("failed to create tmux session: %w", err)
+		}
 	} else {
-		logger.Log("created tmux session %s", sessionName)
+		if tm.SessionExists() {
+			if err := tm.KillSession(); err != nil {
+				return nil, fmt.Errorf("failed to replace stale tmux session: %w", err)
+			}
+			logger.Log("killed stale tmux session %s", sessionName)
+		}
+		if err := tm.CreateSession(sessionEnv); err != nil {
+			return nil, fmt.Errorf("failed to create tmux session: %w", err)
+		}
 	}
+	logger.Log("created tmux session %s", sessionName)
 
-	fmt.Printf("Environment initialized: %s\n", envName)
-	fmt.Printf("  Path: %s\n", path)
-	fmt.Printf("  Data: %s\n", dataDir)
-	if !isSimpleMode {
-		fmt.Printf("  Docker: %s\n", dockerProject)
-		for _, alloc := range allocations {
-			fmt.Printf("  %s: %d -> %d\n", alloc.Service, alloc.ContainerPort, alloc.HostPort)
+	tx.commit()
+
+	result := &InitResult{
+		Name:          envName,
+		Path:          path,
+		DataDir:       dataDir,
+		DockerProject: dockerProject,
+		Allocations:   allocations,
+		SessionName:   sessionName,
+		CacheHit:      allHit,
+		Artifacts:     artifactStatuses,
+		Scripts:       scripts,
+		Phases:        phases.finish(),
+	}
+	if devcontainer != nil {
+		result.Devcontainer = DevcontainerName(envName)
+	}
+	return result, nil
+}
+
+func reconcileEnvironment(ctx context.Context, db *DB, logger *FileLogger, path string) (*InitResult, error) {
+	envName := EnvName(path)
+
+	env, err := db.GetEnvironmentByPath(path)
+	if err != nil {
+		return nil, err
+	}
+	logger.Log("environment already registered (id=%d), reconciling", env.ID)
+
+	status, err := StartStatusServer(envName, "init")
+	if err != nil {
+		logger.Log("warning: failed to start status server: %v", err)
+	}
+	defer status.Close()
+	logger.SetStatus(status)
+	phases := newPhaseTimer(status)
+
+	dataDir, err := DataDir(envName)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.ApplyDefaults(path)
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cache: %w", err)
+	}
+
+	rootPath := ""
+	if env.RootPath.Valid {
+		rootPath = env.RootPath.String
+	}
+
+	allHit := true
+	var artifactStatuses []ArtifactStatus
+	if len(cfg.Build.Artifacts) > 0 && rootPath != "" {
+		entries, err := cm.PrepareArtifactCache(cfg.Build.Artifacts, rootPath, path)
+		if err != nil {
+			logger.Log("warning: failed to prepare artifact cache: %v", err)
+			allHit = false
+		}
+		for _, entry := range entries {
+			if !entry.Hit {
+				allHit = false
+			}
+			artifactStatuses = append(artifactStatuses, ArtifactStatus{Name: entry.Name, Key: entry.Key, Hit: entry.Hit})
 		}
 	}
-	fmt.Printf("  Tmux: %s\n", sessionName)
 
-	return nil
+	cacheEnvVars := cm.EnvVars(cfg.Build, path, rootPath)
+	cacheEnvVars = append(cacheEnvVars, fmt.Sprintf("MONO_CACHE_HIT=%t", allHit))
+	cacheEnvVars = append(cacheEnvVars, "MONO_CACHE_DIR="+cm.LocalCacheDir)
+	if err := cm.EnsureSccache(cfg.Build, cmp.Or(rootPath, path)); err != nil {
+		logger.Log("warning: %v", err)
+	}
+
+	composeDir := path
+	if env.ComposeDir.Valid && env.ComposeDir.String != "" {
+		composeDir = filepath.Join(path, env.ComposeDir.String)
+	}
+
+	dockerProject := ""
+	if env.DockerProject.Valid {
+		dockerProject = env.DockerProject.String
+	}
+
+	var allocations []Allocation
+	var scripts []ScriptStatus
+
+	if dockerProject != "" {
+		if err := CheckDockerAvailable(); err != nil {
+			return nil, err
+		}
+
+		composeProject, composeAllocations, err := buildComposeOverride(composeDir, envName, env.ID)
+		if err != nil {
+			return nil, err
+		}
+		allocations = composeAllocations
+
+		monoComposePath := filepath.Join(composeDir, "docker-compose.mono.yml")
+		if err := db.RecordComposeOverride(monoComposePath, path); err != nil {
+			return nil, fmt.Errorf("failed to record compose override: %w", err)
+		}
+		if err := WriteComposeOverride(monoComposePath, composeProject); err != nil {
+			return nil, fmt.Errorf("failed to write compose override: %w", err)
+		}
+		logger.Log("refreshed docker-compose.mono.yml")
+
+		phases.SetPhase("starting containers")
+		logger.Log("running: docker compose -p %s up -d", dockerProject)
+		stdout := NewLogWriter(logger, "out")
+		stderr := NewLogWriter(logger, "err")
+		if err := StartContainers(ctx, dockerProject, composeDir, stdout, stderr); err != nil {
+			if err := interruptErr(ctx); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to start containers: %w", err)
+		}
+		logger.Log("docker compose completed")
+
+		if err := resolvePublishedPorts(dockerProject, composeProject, allocations); err != nil {
+			return nil, err
+		}
+	}
+
+	devcontainer, err := loadEnvironmentDevcontainer(env)
+	if err != nil {
+		return nil, err
+	}
+	if devcontainer != nil {
+		if err := CheckDockerAvailable(); err != nil {
+			return nil, err
+		}
+		allocations = devcontainerAllocations(env.ID, devcontainer)
+
+		phases.SetPhase("starting devcontainer")
+		stdout := NewLogWriter(logger, "out")
+		stderr := NewLogWriter(logger, "err")
+		if err := StartDevcontainer(ctx, devcontainer, envName, path, allocations, stdout, stderr); err != nil {
+			if err := interruptErr(ctx); err != nil {
+				return nil, err
+			}
+			return nil, err
+		}
+		logger.Log("devcontainer running")
+	}
+
+	if len(cfg.Processes) > 0 && dockerProject == "" && devcontainer == nil {
+		allocations = processAllocations(env.ID, cfg.Processes)
+		phases.SetPhase("starting processes")
+		processEnv := buildScriptEnv(envName, env.ID, path, rootPath, allocations, cfg.Env, cacheEnvVars)
+		if err := StartSupervisor(path, processEnv, logger); err != nil {
+			return nil, err
+		}
+		phases.SetPhase("waiting for processes")
+		if err := WaitForProcesses(ctx, envName, cfg, logger); err != nil {
+			if err := interruptErr(ctx); err != nil {
+				return nil, err
+			}
+			return nil, err
+		}
+	}
+
+	if registered, err := RegisterHosts(envName, allocations); err != nil {
+		logger.Log("warning: failed to register hostnames: %v", err)
+	} else if registered {
+		logger.Log("registered %s in %s", envHostname(envName), globalConfig().Hosts.File)
+	}
+
+	if len(cfg.WaitFor) > 0 {
+		phases.SetPhase("waiting for conditions")
+		waitEnv := buildScriptEnv(envName, env.ID, path, rootPath, allocations, cfg.Env, cacheEnvVars)
+		if err := WaitForConditions(ctx, cfg.WaitFor, path, waitEnv, logger); err != nil {
+			if err := interruptErr(ctx); err != nil {
+				return nil, err
+			}
+			return nil, err
+		}
+	}
+
+	sessionName := SessionName(envName)
+	tm := NewTmuxManager(sessionName, path, cfg.Tmux)
+	scriptsSession := false
+	if cfg.Tmux.ScriptsWindow && cfg.Scripts.Setup != "" {
+		windowEnv := buildScriptEnv(envName, env.ID, path, rootPath, allocations, cfg.Env, cacheEnvVars)
+		mirror, created, err := startScriptsWindow(tm, envName, windowEnv, logger)
+		if err != nil {
+			logger.Log("warning: failed to open scripts window: %v", err)
+		} else {
+			defer mirror.Close()
+			scriptsSession = created
+			logger.Log("mirroring script output to %s:%s", sessionName, scriptsWindowName)
+		}
+	}
+
+	if cfg.Scripts.Setup != "" {
+		scriptEnv := buildScriptEnv(envName, env.ID, path, rootPath, allocations, cfg.Env, cacheEnvVars)
+		phases.SetPhase("running setup script")
+		logger.Log("running setup script: %s", cfg.Scripts.Setup)
+		start := time.Now()
+		err := runEnvScript(ctx, cfg, devcontainer, path, cfg.Scripts.Setup, scriptEnv, logger)
+		scripts = append(scripts, newScriptStatus("setup", start, err))
+		if err != nil {
+			if err := interruptErr(ctx); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("setup script failed: %w", err)
+		}
+		logger.Log("setup script completed")
+	}
+
+	sessionEnv := buildScriptEnv(envName, env.ID, path, rootPath, allocations, cfg.Env, cacheEnvVars)
+	if devcontainer != nil {
+		if _, err := prepareDevcontainerEnv(envName, sessionEnv); err != nil {
+			return nil, err
+		}
+	}
+	if refreshed, err := refreshEnvrc(path, sessionEnv); err != nil {
+		logger.Log("warning: %v", err)
+	} else if refreshed {
+		logger.Log("refreshed .envrc")
+	}
+	if scriptsSession {
+		phases.SetPhase("creating tmux session")
+		if err := tm.AddShellWindow(sessionEnv); err != nil {
+			return nil, fmt.Errorf("failed to create tmux session: %w", err)
+		}
+		logger.Log("recreated tmux session %s", sessionName)
+	} else if !tm.SessionExists() {
+		phases.SetPhase("creating tmux session")
+		if err := tm.CreateSession(sessionEnv); err != nil {
+			return nil, fmt.Errorf("failed to create tmux session: %w", err)
+		}
+		logger.Log("recreated tmux session %s", sessionName)
+	}
+
+	result := &InitResult{
+		Name:          envName,
+		Path:          path,
+		DataDir:       dataDir,
+		DockerProject: dockerProject,
+		Allocations:   allocations,
+		SessionName:   sessionName,
+		Reconciled:    true,
+		CacheHit:      allHit,
+		Artifacts:     artifactStatuses,
+		Scripts:       scripts,
+		Phases:        phases.finish(),
+	}
+	if devcontainer != nil {
+		result.Devcontainer = DevcontainerName(envName)
+	}
+	return result, nil
+}
+
+type DestroyOptions struct {
+	Force       bool
+	NoSync      bool
+	SyncTimeout time.Duration
 }
 
-func Destroy(path string) error {
-	project, workspace := DeriveNames(path)
-	envName := fmt.Sprintf("%s-%s", project, workspace)
-	if project == "" || workspace == "" {
-		envName = filepath.Base(path)
+func DescribeDestroy(path string, opts DestroyOptions) ([]string, error) {
+	envName := EnvName(path)
+
+	db, err := OpenDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	env, err := db.GetEnvironmentByPath(path)
+	if err != nil {
+		if !opts.Force {
+			return nil, fmt.Errorf("environment not found: %s (use --force to clean up leftovers)", path)
+		}
+		return []string{fmt.Sprintf("leftovers of unregistered environment %s (tmux session, containers, data)", path)}, nil
 	}
 
+	items := []string{fmt.Sprintf("environment: %s (%s)", envName, path)}
+
+	state, err := ReadSupervisorState(envName)
+	if err != nil {
+		return nil, err
+	}
+	if state.Running() {
+		var names []string
+		for _, proc := range state.Processes {
+			names = append(names, proc.Name)
+		}
+		items = append(items, "processes: "+strings.Join(names, ", "))
+	}
+	pid, err := detachedRunPID(db, path)
+	if err != nil {
+		return nil, err
+	}
+	if processAlive(pid) {
+		items = append(items, fmt.Sprintf("background run script (pid %d)", pid))
+	}
+	if sessionName := SessionName(envName); SessionExists(sessionName) {
+		items = append(items, "tmux session: "+sessionName)
+	}
+	if env.DockerProject.Valid && env.DockerProject.String != "" {
+		items = append(items, fmt.Sprintf("docker project: %s (containers and volumes)", env.DockerProject.String))
+	}
+	if env.UsesDevcontainer() {
+		items = append(items, "dev container: "+env.Devcontainer.String)
+	}
+	dataDir, err := DataDir(envName)
+	if err != nil {
+		return nil, err
+	}
+	if dirExists(dataDir) {
+		items = append(items, "data directory: "+dataDir)
+	}
+	return items, nil
+}
+
+func Destroy(path string, opts DestroyOptions) (err error) {
+	envName := EnvName(path)
+
 	logger, err := NewFileLogger(envName)
 	if err != nil {
 		return fmt.Errorf("failed to create logger: %w", err)
@@ -288,6 +942,24 @@ func Destroy(path string) error {
 
 	logger.Log("mono destroy %s", path)
 
+	ctx, stopSignals := notifyInterrupt("destroy")
+	defer stopSignals()
+
+	lock, err := AcquireEnvLock(ctx, envName, "destroy", os.Stderr)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, lock.Release())
+	}()
+
+	status, err := StartStatusServer(envName, "destroy")
+	if err != nil {
+		logger.Log("warning: failed to start status server: %v", err)
+	}
+	defer status.Close()
+	logger.SetStatus(status)
+
 	db, err := OpenDB()
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
@@ -296,7 +968,14 @@ func Destroy(path string) error {
 
 	env, err := db.GetEnvironmentByPath(path)
 	if err != nil {
-		return fmt.Errorf("environment not found: %s", path)
+		if opts.Force {
+			return forceDestroy(ctx, db, logger, path)
+		}
+		return fmt.Errorf("environment not found: %s (use --force to clean up leftovers)", path)
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) && opts.Force {
+		return forceDestroy(ctx, db, logger, path)
 	}
 
 	composeDir := path
@@ -304,7 +983,14 @@ func Destroy(path string) error {
 		composeDir = filepath.Join(path, env.ComposeDir.String)
 	}
 
-	cfg, _ := LoadConfig(path)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		if !opts.Force {
+			return fmt.Errorf("%w (use --force to destroy anyway)", err)
+		}
+		logger.Log("warning: ignoring config error: %v", err)
+		cfg = nil
+	}
 
 	rootPath := ""
 	if env.RootPath.Valid {
@@ -320,30 +1006,79 @@ func Destroy(path string) error {
 		cfg.ApplyDefaults(path)
 	}
 
-	if cfg != nil && rootPath != "" {
-		if err := cm.Sync(cfg.Build.Artifacts, rootPath, path, SyncOptions{HardlinkBack: false}); err != nil {
-			logger.Log("warning: failed to sync before destroy: %v", err)
+	if cfg != nil && rootPath != "" && len(cfg.Build.Artifacts) > 0 {
+		if opts.NoSync || !cfg.Destroy.syncEnabled() {
+			logger.Log("skipped sync before destroy")
 		} else {
-			logger.Log("synced artifacts to cache before destroy")
+			syncOpts := SyncOptions{HardlinkBack: false, Status: status}
+			timeout := cmp.Or(opts.SyncTimeout, cfg.Destroy.SyncTimeout)
+			if timeout > 0 {
+				syncOpts.Deadline = time.Now().Add(timeout)
+			}
+			start := time.Now()
+			err := cm.Sync(cfg.Build.Artifacts, rootPath, path, syncOpts)
+			elapsed := time.Since(start).Round(time.Millisecond)
+			if err != nil {
+				logger.Log("warning: failed to sync before destroy after %s: %v", elapsed, err)
+				fmt.Printf("Sync failed after %s: %v\n", elapsed, err)
+			} else {
+				logger.Log("synced artifacts to cache before destroy in %s", elapsed)
+				fmt.Printf("Synced artifacts to cache in %s\n", elapsed)
+				entries, err := cm.PrepareArtifactCache(cfg.Build.Artifacts, rootPath, path)
+				if err != nil {
+					logger.Log("warning: %v", err)
+				} else {
+					if err := cm.RecordCacheSizes(db, entries); err != nil {
+						logger.Log("warning: %v", err)
+					}
+					cm.enforceCacheLimit(db, entries, logger)
+				}
+			}
 		}
 	}
 
 	var cacheEnvVars []string
 	if cfg != nil {
-		cacheEnvVars = cm.EnvVars(cfg.Build)
+		cacheEnvVars = cm.EnvVars(cfg.Build, path, rootPath)
 	}
 	cacheEnvVars = append(cacheEnvVars, "MONO_CACHE_DIR="+cm.LocalCacheDir)
 
+	var devcontainer *DevcontainerConfig
+	if env.UsesDevcontainer() && DevcontainerRunning(envName) {
+		devcontainer, err = loadEnvironmentDevcontainer(env)
+		if err != nil {
+			logger.Log("warning: running destroy script on the host: %v", err)
+		}
+	}
+
 	if cfg != nil && cfg.Scripts.Destroy != "" {
 		scriptEnv := buildScriptEnv(envName, env.ID, path, rootPath, nil, cfg.Env, cacheEnvVars)
+		status.SetPhase("running destroy script")
 		logger.Log("running destroy script: %s", cfg.Scripts.Destroy)
-		if err := runScript(path, cfg.Scripts.Destroy, scriptEnv, logger); err != nil {
+		if err := runEnvScript(ctx, cfg, devcontainer, path, cfg.Scripts.Destroy, scriptEnv, logger); err != nil {
+			if err := interruptErr(ctx); err != nil {
+				return destroyInterrupted(logger, path, err)
+			}
 			logger.Log("warning: destroy script failed: %v", err)
 		} else {
 			logger.Log("destroy script completed")
 		}
 	}
 
+	if err := interruptErr(ctx); err != nil {
+		return destroyInterrupted(logger, path, err)
+	}
+
+	status.SetPhase("stopping processes")
+	if stopped, err := StopSupervisor(envName, logger); err != nil {
+		logger.Log("warning: failed to stop processes: %v", err)
+	} else if stopped {
+		logger.Log("stopped processes")
+	}
+	if _, _, err := stopDetachedRun(db, path, logger); err != nil {
+		logger.Log("warning: failed to stop background run script: %v", err)
+	}
+
 	sessionName := SessionName(envName)
 	var tmuxCfg TmuxConfig
 	if cfg != nil {
@@ -359,18 +1094,56 @@ func Destroy(path string) error {
 	}
 
 	if env.DockerProject.Valid && env.DockerProject.String != "" {
+		status.SetPhase("stopping containers")
 		logger.Log("stopping containers: %s", env.DockerProject.String)
 		stdout := NewLogWriter(logger, "out")
 		stderr := NewLogWriter(logger, "err")
-		if err := StopContainers(env.DockerProject.String, composeDir, true, stdout, stderr); err != nil {
+		if err := StopContainers(ctx, env.DockerProject.String, composeDir, true, stdout, stderr); err != nil {
+			if err := interruptErr(ctx); err != nil {
+				return destroyInterrupted(logger, path, err)
+			}
 			logger.Log("warning: failed to stop containers: %v", err)
 		} else {
 			logger.Log("stopped containers")
 		}
+
+		monoComposePath := filepath.Join(composeDir, "docker-compose.mono.yml")
+		if err := os.Remove(monoComposePath); err != nil && !os.IsNotExist(err) {
+			logger.Log("warning: failed to remove compose override: %v", err)
+		} else if err := db.DeleteComposeOverride(monoComposePath); err != nil {
+			logger.Log("warning: failed to unregister compose override: %v", err)
+		}
+	}
+
+	if env.UsesDevcontainer() {
+		status.SetPhase("removing devcontainer")
+		logger.Log("removing devcontainer: %s", env.Devcontainer.String)
+		stdout := NewLogWriter(logger, "out")
+		stderr := NewLogWriter(logger, "err")
+		if err := StopDevcontainer(ctx, envName, stdout, stderr); err != nil {
+			if err := interruptErr(ctx); err != nil {
+				return destroyInterrupted(logger, path, err)
+			}
+			logger.Log("warning: failed to remove devcontainer: %v", err)
+		} else {
+			logger.Log("removed devcontainer")
+		}
+	}
+
+	if err := interruptErr(ctx); err != nil {
+		return destroyInterrupted(logger, path, err)
+	}
+
+	if unregistered, err := UnregisterHosts(envName); err != nil {
+		logger.Log("warning: failed to unregister hostnames: %v", err)
+	} else if unregistered {
+		logger.Log("unregistered %s", envHostname(envName))
 	}
 
-	home, _ := os.UserHomeDir()
-	dataDir := filepath.Join(home, ".mono", "data", envName)
+	dataDir, err := DataDir(envName)
+	if err != nil {
+		return err
+	}
 	if err := os.RemoveAll(dataDir); err != nil {
 		logger.Log("warning: failed to remove data directory: %v", err)
 	} else {
@@ -382,17 +1155,120 @@ func Destroy(path string) error {
 	}
 	logger.Log("removed from database")
 
+	cm.stopProjectSccache(db, cmp.Or(rootPath, path), logger)
+
 	fmt.Printf("Environment destroyed: %s\n", envName)
 	return nil
 }
 
-func Run(path string) error {
-	project, workspace := DeriveNames(path)
-	envName := fmt.Sprintf("%s-%s", project, workspace)
-	if project == "" || workspace == "" {
-		envName = filepath.Base(path)
+func forceDestroy(ctx context.Context, db *DB, logger *FileLogger, path string) error {
+	envName := EnvName(path)
+	logger.Log("force destroying leftovers")
+
+	sessionName := SessionName(envName)
+	if SessionExists(sessionName) {
+		if err := KillSession(sessionName); err != nil {
+			logger.Log("warning: failed to kill tmux session: %v", err)
+		} else {
+			logger.Log("killed tmux session %s", sessionName)
+		}
 	}
 
+	if err := CheckDockerAvailable(); err != nil {
+		logger.Log("warning: skipping docker cleanup: %v", err)
+	} else {
+		projects, err := ListDockerProjects()
+		if err != nil {
+			logger.Log("warning: failed to list docker projects: %v", err)
+		}
+		for _, project := range projects {
+			if !strings.EqualFold(project.Name, fmt.Sprintf("mono-%s", envName)) {
+				continue
+			}
+			logger.Log("stopping containers: %s", project.Name)
+			stdout := NewLogWriter(logger, "out")
+			stderr := NewLogWriter(logger, "err")
+			if err := StopContainers(ctx, project.Name, os.TempDir(), true, stdout, stderr); err != nil {
+				if err := interruptErr(ctx); err != nil {
+					return err
+				}
+				logger.Log("warning: failed to stop containers: %v", err)
+			} else {
+				logger.Log("stopped containers")
+			}
+		}
+
+		stdout := NewLogWriter(logger, "out")
+		stderr := NewLogWriter(logger, "err")
+		if err := StopDevcontainer(ctx, envName, stdout, stderr); err != nil {
+			if err := interruptErr(ctx); err != nil {
+				return err
+			}
+			logger.Log("warning: failed to remove devcontainer: %v", err)
+		}
+	}
+
+	if stopped, err := StopSupervisor(envName, logger); err != nil {
+		logger.Log("warning: failed to stop processes: %v", err)
+	} else if stopped {
+		logger.Log("stopped processes")
+	}
+	if _, _, err := stopDetachedRun(db, path, logger); err != nil {
+		logger.Log("warning: failed to stop background run script: %v", err)
+	}
+
+	overrides, err := db.ListComposeOverrides(path)
+	if err != nil {
+		logger.Log("warning: failed to list compose overrides: %v", err)
+	}
+	for _, override := range overrides {
+		if err := os.Remove(override); err != nil && !os.IsNotExist(err) {
+			logger.Log("warning: failed to remove compose override: %v", err)
+		} else if err := db.DeleteComposeOverride(override); err != nil {
+			logger.Log("warning: failed to unregister compose override: %v", err)
+		}
+	}
+
+	if unregistered, err := UnregisterHosts(envName); err != nil {
+		logger.Log("warning: failed to unregister hostnames: %v", err)
+	} else if unregistered {
+		logger.Log("unregistered %s", envHostname(envName))
+	}
+
+	dataDir, err := DataDir(envName)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dataDir); err != nil {
+		logger.Log("warning: failed to remove data directory: %v", err)
+	} else {
+		logger.Log("removed data directory")
+	}
+
+	if err := db.DeleteEnvironment(path); err != nil {
+		logger.Log("no database row removed: %v", err)
+	} else {
+		logger.Log("removed from database")
+	}
+
+	fmt.Printf("Environment force destroyed: %s\n", envName)
+	return nil
+}
+
+func destroyInterrupted(logger *FileLogger, path string, err error) error {
+	logger.Log("%v, environment left registered for retry", err)
+	return fmt.Errorf("%w; run 'mono destroy %s' again to finish", err, path)
+}
+
+type RunOptions struct {
+	Args   []string
+	Direct bool
+	Detach bool
+}
+
+func Run(path string, opts RunOptions) error {
+	envName := EnvName(path)
+
 	logger, err := NewFileLogger(envName)
 	if err != nil {
 		return fmt.Errorf("failed to create logger: %w", err)
@@ -401,22 +1277,17 @@ func Run(path string) error {
 
 	logger.Log("mono run %s", path)
 
-	db, err := OpenDB()
-	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
+	if opts.Direct {
+		return runDirect(path, opts.Args, logger)
 	}
-	defer db.Close()
-
-	_, err = db.GetEnvironmentByPath(path)
-	if err != nil {
-		return fmt.Errorf("environment not found: %s", path)
+	if opts.Detach {
+		return runDetached(path, opts.Args, logger)
 	}
 
-	cfg, err := LoadConfig(path)
+	env, cfg, vars, err := loadEnvironmentVars(path)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
-	cfg.Tmux.ApplyDefaults()
 
 	if cfg.Scripts.Run == "" {
 		return fmt.Errorf("no run script defined in mono.yml")
@@ -428,14 +1299,23 @@ func Run(path string) error {
 		return fmt.Errorf("tmux session does not exist: %s", sessionName)
 	}
 
-	home, err := os.UserHomeDir()
+	dataDir, err := DataDir(envName)
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return err
 	}
-	dataDir := filepath.Join(home, ".mono", "data", envName)
 	scriptPath := filepath.Join(dataDir, "run.sh")
 
-	if err := os.WriteFile(scriptPath, []byte(cfg.Scripts.Run), 0755); err != nil {
+	if env.UsesDevcontainer() {
+		if _, err := prepareDevcontainerEnv(envName, vars); err != nil {
+			return err
+		}
+	}
+	script, err := renderRunScript(env, cfg, vars, opts.Args)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
 		return fmt.Errorf("failed to write run script: %w", err)
 	}
 
@@ -448,11 +1328,69 @@ func Run(path string) error {
 	return nil
 }
 
+func runDirect(path string, args []string, logger *FileLogger) error {
+	env, cfg, vars, err := loadEnvironmentVars(path)
+	if err != nil {
+		return err
+	}
+	if cfg.Scripts.Run == "" {
+		return fmt.Errorf("no run script defined in mono.yml")
+	}
+
+	script, err := wrapRunScript(env, cfg, cfg.Scripts.Run, args, true)
+	if err != nil {
+		return err
+	}
+
+	logger.Log("running script directly")
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Dir = path
+	cmd.Env = append(os.Environ(), vars...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func renderRunScript(env *Environment, cfg *Config, vars, args []string) (string, error) {
+	script, err := wrapRunScript(env, cfg, cfg.Scripts.Run, args, true)
+	if err != nil {
+		return "", err
+	}
+	return renderExports(vars) + script, nil
+}
+
+func wrapRunScript(env *Environment, cfg *Config, script string, args []string, tty bool) (string, error) {
+	devcontainer, err := loadEnvironmentDevcontainer(env)
+	if err != nil {
+		return "", err
+	}
+	if devcontainer != nil {
+		envName := EnvName(env.Path)
+		envFile, err := DevcontainerEnvFile(envName)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(envFile); err != nil {
+			return "", fmt.Errorf("devcontainer environment missing, run 'mono reconcile %s': %w", env.Path, err)
+		}
+		return devcontainerRunScript(envName, devcontainer.WorkspaceFolderFor(env.Path), envFile, script, args, tty), nil
+	}
+	if cfg.Nix.active(env.Path) {
+		return nixRunScript(cfg.Nix, env.Path, script, args), nil
+	}
+	if len(args) > 0 {
+		return "set -- " + shellQuoteArgs(args) + "\n" + script, nil
+	}
+	return script, nil
+}
+
 type EnvironmentStatus struct {
 	Name          string
 	Path          string
 	TmuxRunning   bool
 	DockerRunning bool
+	Cache         EnvironmentCacheStatus
 }
 
 func List() ([]EnvironmentStatus, error) {
@@ -467,13 +1405,19 @@ func List() ([]EnvironmentStatus, error) {
 		return nil, fmt.Errorf("failed to list environments: %w", err)
 	}
 
+	cm, err := NewCacheManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache manager: %w", err)
+	}
+
+	index, err := db.GetCacheEntryIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache index: %w", err)
+	}
+
 	var statuses []EnvironmentStatus
 	for _, env := range environments {
-		project, workspace := DeriveNames(env.Path)
-		envName := fmt.Sprintf("%s-%s", project, workspace)
-		if project == "" || workspace == "" {
-			envName = filepath.Base(env.Path)
-		}
+		envName := EnvName(env.Path)
 
 		sessionName := SessionName(envName)
 		tmuxRunning := SessionExists(sessionName)
@@ -481,6 +1425,13 @@ func List() ([]EnvironmentStatus, error) {
 		dockerRunning := false
 		if env.DockerProject.Valid && env.DockerProject.String != "" {
 			dockerRunning = ContainersRunning(env.DockerProject.String)
+		} else if env.UsesDevcontainer() {
+			dockerRunning = DevcontainerRunning(envName)
+		}
+
+		cache, err := cm.EnvironmentCache(db, index, env.Path, env.RootPath.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cache status for %s: %w", envName, err)
 		}
 
 		statuses = append(statuses, EnvironmentStatus{
@@ -488,6 +1439,7 @@ func List() ([]EnvironmentStatus, error) {
 			Path:          env.Path,
 			TmuxRunning:   tmuxRunning,
 			DockerRunning: dockerRunning,
+			Cache:         cache,
 		})
 	}
 
@@ -505,11 +1457,7 @@ func Attach(path string) error {
 
 	env, err := db.GetEnvironmentByPath(path)
 	if err == nil {
-		project, workspace := DeriveNames(env.Path)
-		envName := fmt.Sprintf("%s-%s", project, workspace)
-		if project == "" || workspace == "" {
-			envName = filepath.Base(env.Path)
-		}
+		envName := EnvName(env.Path)
 		sessionName = SessionName(envName)
 	} else {
 		sessions, err := ListMonoSessions()
@@ -577,9 +1525,12 @@ func selectSessionWithFzf(sessions []string) (string, error) {
 	return selected, nil
 }
 
+func portEnvVar(service string) string {
+	return serviceEnvPrefix(service) + "_PORT"
+}
+
 func buildScriptEnv(envName string, envID int64, envPath, rootPath string, allocations []Allocation, configEnv map[string]string, cacheEnvVars []string) []string {
-	home, _ := os.UserHomeDir()
-	dataDir := filepath.Join(home, ".mono", "data", envName)
+	dataDir, _ := DataDir(envName)
 
 	monoEnvMap := map[string]string{
 		"MONO_ENV_NAME":  envName,
@@ -590,9 +1541,10 @@ func buildScriptEnv(envName string, envID int64, envPath, rootPath string, alloc
 	}
 
 	for _, alloc := range allocations {
-		varName := "MONO_" + strings.ToUpper(strings.ReplaceAll(alloc.Service, "-", "_")) + "_PORT"
-		monoEnvMap[varName] = fmt.Sprintf("%d", alloc.HostPort)
+		monoEnvMap[portEnvVar(alloc.Service)] = fmt.Sprintf("%d", alloc.HostPort)
 	}
+	maps.Copy(monoEnvMap, connectionEnv(allocations))
+	maps.Copy(monoEnvMap, hostnameEnv(envName, allocations))
 
 	var result []string
 	for key, value := range monoEnvMap {
@@ -615,26 +1567,41 @@ func buildScriptEnv(envName string, envID int64, envPath, rootPath string, alloc
 	return result
 }
 
-func runScript(workDir, script string, envVars []string, logger *FileLogger) error {
-	stdout := NewLogWriter(logger, "out")
-	stderr := NewLogWriter(logger, "err")
+func runScript(ctx context.Context, workDir, script string, envVars []string, logger *FileLogger) error {
+	return runProcess(ctx, workDir, append(os.Environ(), envVars...), logger, "sh", "-c", script)
+}
 
-	cmd := exec.Command("sh", "-c", script)
+func runProcess(ctx context.Context, workDir string, env []string, logger *FileLogger, name string, args ...string) error {
+	var stdout, stderr io.Writer = NewLogWriter(logger, "out"), NewLogWriter(logger, "err")
+	if logger.mirror != nil {
+		stdout = io.MultiWriter(stdout, logger.mirror)
+		stderr = io.MultiWriter(stderr, logger.mirror)
+	}
+
+	timeout := timeouts().Script
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Dir = workDir
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
-	cmd.Env = append(os.Environ(), envVars...)
-
-	done := make(chan error, 1)
-	go func() {
-		done <- cmd.Run()
-	}()
+	cmd.Env = env
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = 10 * time.Second
 
-	select {
-	case err := <-done:
-		return err
-	case <-time.After(10 * time.Minute):
-		cmd.Process.Kill()
-		return fmt.Errorf("script timed out after 10 minutes")
+	err := cmd.Run()
+	if ctx.Err() != nil && cmd.Process != nil {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("script timed out after %v (raise timeouts.script in the global config)", timeout)
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("script %w", ErrInterrupted)
 	}
+	return err
 }
@@ -0,0 +1,338 @@
+package mono
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+const nodeModulesDir = "node_modules"
+
+type packageFile struct {
+	relPath string
+	size    int64
+}
+
+func (cm *CacheManager) PackageStoreDir() string {
+	return filepath.Join(cm.HomeDir, "cache_packages")
+}
+
+func (cm *CacheManager) dedupIfNodeModules(dir string) error {
+	if filepath.Base(dir) != nodeModulesDir || !dirExists(dir) {
+		return nil
+	}
+	strategy, err := cm.Strategy(dir)
+	if err != nil || strategy.Link == LinkHardlink {
+		return err
+	}
+	if _, err := cm.DedupNodeModules(dir); err != nil {
+		return fmt.Errorf("failed to dedup packages: %w", err)
+	}
+	return nil
+}
+
+func (cm *CacheManager) DedupNodeModules(nodeModules string) (int64, error) {
+	units, err := listPackageUnits(nodeModules)
+	if err != nil {
+		return 0, err
+	}
+
+	var saved int64
+	for _, unit := range units {
+		n, err := cm.dedupPackage(unit)
+		if err != nil {
+			return saved, fmt.Errorf("%s: %w", unit, err)
+		}
+		saved += n
+	}
+	return saved, nil
+}
+
+func listPackageUnits(nodeModules string) ([]string, error) {
+	entries, err := os.ReadDir(nodeModules)
+	if err != nil {
+		return nil, err
+	}
+
+	var units []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		path := filepath.Join(nodeModules, name)
+
+		switch {
+		case name == ".pnpm":
+			pnpmEntries, err := os.ReadDir(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, pe := range pnpmEntries {
+				if pe.IsDir() && pe.Name() != nodeModulesDir {
+					units = append(units, filepath.Join(path, pe.Name()))
+				}
+			}
+		case strings.HasPrefix(name, "."):
+			continue
+		case strings.HasPrefix(name, "@"):
+			scoped, err := os.ReadDir(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, se := range scoped {
+				if se.IsDir() {
+					units = append(units, filepath.Join(path, se.Name()))
+				}
+			}
+		default:
+			units = append(units, path)
+		}
+	}
+	return units, nil
+}
+
+func hashPackage(dir string) (string, []packageFile, error) {
+	type record struct {
+		relPath string
+		line    string
+	}
+
+	var records []record
+	var files []packageFile
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			records = append(records, record{relPath, fmt.Sprintf("d %s %o", relPath, info.Mode().Perm())})
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			records = append(records, record{relPath, fmt.Sprintf("l %s %s", relPath, target)})
+		case info.Mode().IsRegular():
+			sum, err := fileSHA256(path)
+			if err != nil {
+				return err
+			}
+			records = append(records, record{relPath, fmt.Sprintf("f %s %o %s", relPath, info.Mode().Perm(), sum)})
+			files = append(files, packageFile{relPath: relPath, size: info.Size()})
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].relPath < records[j].relPath })
+
+	h := sha256.New()
+	for _, r := range records {
+		fmt.Fprintln(h, r.line)
+	}
+	return hex.EncodeToString(h.Sum(nil)), files, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (cm *CacheManager) dedupPackage(dir string) (int64, error) {
+	hash, files, err := hashPackage(dir)
+	if err != nil {
+		return 0, err
+	}
+	if len(files) == 0 {
+		return 0, nil
+	}
+
+	storePath := filepath.Join(cm.PackageStoreDir(), hash[:2], hash)
+	if !dirExists(storePath) {
+		return 0, publishPackage(dir, storePath, files)
+	}
+
+	var saved int64
+	for _, f := range files {
+		src := filepath.Join(storePath, f.relPath)
+		dst := filepath.Join(dir, f.relPath)
+
+		same, err := sameInode(src, dst)
+		if err != nil {
+			return saved, err
+		}
+		if same {
+			continue
+		}
+
+		tmp := dst + cacheTmpSuffix
+		if err := os.Link(src, tmp); err != nil {
+			return saved, err
+		}
+		if err := os.Rename(tmp, dst); err != nil {
+			os.Remove(tmp)
+			return saved, err
+		}
+		saved += f.size
+	}
+	return saved, nil
+}
+
+func publishPackage(dir, storePath string, files []packageFile) error {
+	if err := os.MkdirAll(filepath.Dir(storePath), 0755); err != nil {
+		return err
+	}
+
+	tmpPath, err := os.MkdirTemp(filepath.Dir(storePath), filepath.Base(storePath)+"-*"+cacheTmpSuffix)
+	if err != nil {
+		return err
+	}
+	untrack, err := trackTempDir(tmpPath)
+	if err != nil {
+		os.RemoveAll(tmpPath)
+		return err
+	}
+	defer untrack()
+
+	for _, f := range files {
+		dst := filepath.Join(tmpPath, f.relPath)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			os.RemoveAll(tmpPath)
+			return err
+		}
+		if err := os.Link(filepath.Join(dir, f.relPath), dst); err != nil {
+			os.RemoveAll(tmpPath)
+			return err
+		}
+	}
+
+	if err := os.Rename(tmpPath, storePath); err != nil {
+		if removeErr := os.RemoveAll(tmpPath); removeErr != nil {
+			return removeErr
+		}
+		if dirExists(storePath) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func sameInode(a, b string) (bool, error) {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(ai, bi), nil
+}
+
+type PrunedPackage struct {
+	Path string
+	Size int64
+}
+
+func (cm *CacheManager) PrunePackageStore(dryRun bool) ([]PrunedPackage, error) {
+	shards, err := os.ReadDir(cm.PackageStoreDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []PrunedPackage
+	for _, shard := range shards {
+		shardPath := filepath.Join(cm.PackageStoreDir(), shard.Name())
+		packages, err := os.ReadDir(shardPath)
+		if err != nil {
+			return pruned, err
+		}
+
+		for _, pkg := range packages {
+			if strings.HasSuffix(pkg.Name(), cacheTmpSuffix) {
+				continue
+			}
+			pkgPath := filepath.Join(shardPath, pkg.Name())
+			referenced, err := packageReferenced(pkgPath)
+			if err != nil {
+				return pruned, err
+			}
+			if referenced {
+				continue
+			}
+			size, _, err := newUsageTracker().walk(pkgPath)
+			if err != nil {
+				return pruned, err
+			}
+			if !dryRun {
+				if err := os.RemoveAll(pkgPath); err != nil {
+					return pruned, err
+				}
+			}
+			pruned = append(pruned, PrunedPackage{Path: pkgPath, Size: size})
+		}
+		if !dryRun {
+			cm.cleanEmptyParentDirs(shardPath)
+		}
+	}
+	return pruned, nil
+}
+
+func packageReferenced(pkgPath string) (bool, error) {
+	referenced := false
+	err := filepath.WalkDir(pkgPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok && stat.Nlink > 1 {
+			referenced = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return referenced, err
+}
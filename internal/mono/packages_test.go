@@ -0,0 +1,137 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePackage(t *testing.T, nodeModules, name, version string) {
+	t.Helper()
+	dir := filepath.Join(nodeModules, name)
+	if err := os.MkdirAll(filepath.Join(dir, "lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"version":"`+version+`"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "lib", "index.js"), []byte("module.exports = '"+name+"'"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDedupNodeModulesSharesIdenticalPackages(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+
+	testDir := t.TempDir()
+	first := filepath.Join(testDir, "first", "node_modules")
+	second := filepath.Join(testDir, "second", "node_modules")
+
+	writePackage(t, first, "left-pad", "1.0.0")
+	writePackage(t, first, "@scope/util", "2.0.0")
+	writePackage(t, second, "left-pad", "1.0.0")
+	writePackage(t, second, "@scope/util", "2.1.0")
+
+	if _, err := cm.DedupNodeModules(first); err != nil {
+		t.Fatalf("dedup failed: %v", err)
+	}
+	saved, err := cm.DedupNodeModules(second)
+	if err != nil {
+		t.Fatalf("dedup failed: %v", err)
+	}
+	if saved == 0 {
+		t.Error("expected bytes saved for shared package")
+	}
+
+	same, err := sameInode(filepath.Join(first, "left-pad", "lib", "index.js"), filepath.Join(second, "left-pad", "lib", "index.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !same {
+		t.Error("identical packages should share inodes")
+	}
+
+	same, err = sameInode(filepath.Join(first, "@scope", "util", "package.json"), filepath.Join(second, "@scope", "util", "package.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same {
+		t.Error("different package versions should not share inodes")
+	}
+
+	if err := os.RemoveAll(filepath.Join(testDir, "first")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(testDir, "second")); err != nil {
+		t.Fatal(err)
+	}
+
+	planned, err := cm.PrunePackageStore(true)
+	if err != nil {
+		t.Fatalf("dry-run prune failed: %v", err)
+	}
+	if len(planned) != 3 {
+		t.Errorf("expected 3 unreferenced packages in the dry run, got %d", len(planned))
+	}
+	for _, pkg := range planned {
+		if !dirExists(pkg.Path) || pkg.Size == 0 {
+			t.Errorf("expected the dry run to keep %s and report its size, got %+v", pkg.Path, pkg)
+		}
+	}
+
+	removed, err := cm.PrunePackageStore(false)
+	if err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	if len(removed) != 3 {
+		t.Errorf("expected 3 unreferenced packages pruned, got %d", len(removed))
+	}
+	for _, pkg := range removed {
+		if dirExists(pkg.Path) {
+			t.Errorf("expected %s to be removed", pkg.Path)
+		}
+	}
+}
+
+func TestDedupIfNodeModulesSkipsHardlinkStrategy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := DefaultGlobalConfig()
+	cfg.Cache.Link = LinkHardlink
+	SetGlobalConfig(cfg)
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+	if !cm.FS.Hardlink {
+		t.Skip("filesystem does not support hardlinks")
+	}
+
+	first := filepath.Join(cm.LocalCacheDir, "proj", "npm", "key1", "node_modules")
+	second := filepath.Join(cm.LocalCacheDir, "proj", "npm", "key2", "node_modules")
+	writePackage(t, first, "left-pad", "1.0.0")
+	writePackage(t, second, "left-pad", "1.0.0")
+
+	for _, dir := range []string{first, second} {
+		if err := cm.dedupIfNodeModules(dir); err != nil {
+			t.Fatalf("dedup failed: %v", err)
+		}
+	}
+
+	same, err := sameInode(filepath.Join(first, "left-pad", "lib", "index.js"), filepath.Join(second, "left-pad", "lib", "index.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same {
+		t.Error("packages restored by hardlink should not share inodes across entries")
+	}
+	if dirExists(cm.PackageStoreDir()) {
+		t.Error("expected no packages to be stored under the hardlink strategy")
+	}
+}
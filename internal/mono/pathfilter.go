@@ -0,0 +1,99 @@
+package mono
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type pathFilter struct {
+	include []string
+	exclude []string
+	skips   []ignorePattern
+}
+
+func newPathFilter(include, exclude, skipPatterns []string) pathFilter {
+	return pathFilter{include: include, exclude: exclude, skips: parseIgnorePatterns(strings.Join(skipPatterns, "\n"), "", true)}
+}
+
+func (a ArtifactConfig) pathFilter() pathFilter {
+	return newPathFilter(a.Include, a.Exclude, a.SkipPatterns)
+}
+
+func (a ArtifactConfig) validateGlobs() error {
+	for _, globs := range []struct {
+		field    string
+		patterns []string
+	}{{"include", a.Include}, {"exclude", a.Exclude}, {"skip_patterns", a.SkipPatterns}} {
+		for _, pattern := range globs.patterns {
+			if strings.TrimSpace(pattern) == "" {
+				return fmt.Errorf("artifact %s has an empty %s glob", a.Name, globs.field)
+			}
+			for _, seg := range strings.Split(strings.TrimPrefix(pattern, "!"), "/") {
+				if _, err := path.Match(seg, ""); err != nil {
+					return fmt.Errorf("artifact %s %s glob %q: %w", a.Name, globs.field, pattern, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (f pathFilter) empty() bool {
+	return len(f.include) == 0 && len(f.exclude) == 0 && len(f.skips) == 0
+}
+
+func (f pathFilter) skip(relPath string, isDir bool) bool {
+	if f.empty() || relPath == "." || relPath == "" {
+		return false
+	}
+	if len(f.skips) > 0 && (&ignoreRules{patterns: f.skips}).ignored(relPath, isDir) {
+		return true
+	}
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	for _, pattern := range f.exclude {
+		if globCovers(strings.Split(strings.Trim(pattern, "/"), "/"), parts, false) {
+			return true
+		}
+	}
+	if len(f.include) == 0 {
+		return false
+	}
+	for _, pattern := range f.include {
+		if globCovers(strings.Split(strings.Trim(pattern, "/"), "/"), parts, isDir) {
+			return false
+		}
+	}
+	return true
+}
+
+func globCovers(pattern, parts []string, isDir bool) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if len(parts) == 0 {
+		return isDir || globMatch(pattern, nil)
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if globCovers(pattern[1:], parts[i:], isDir) {
+				return true
+			}
+		}
+		return false
+	}
+	ok, err := path.Match(pattern[0], parts[0])
+	if err != nil || !ok {
+		return false
+	}
+	return globCovers(pattern[1:], parts[1:], isDir)
+}
+
+func (e ArtifactCacheEntry) pathFilter() pathFilter {
+	return newPathFilter(e.Include, e.Exclude, e.SkipPatterns)
+}
+
+func (o SeedOptions) pathFilter() pathFilter {
+	return newPathFilter(o.Include, o.Exclude, o.SkipPatterns)
+}
@@ -0,0 +1,201 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPathFilterSkip(t *testing.T) {
+	filter := pathFilter{
+		include: []string{"debug", "**/*.rlib"},
+		exclude: []string{"debug/examples", "**/fixtures/"},
+	}
+	for rel, want := range map[string]bool{
+		".":                          false,
+		"debug":                      false,
+		"debug/deps/app":             false,
+		"debug/examples":             true,
+		"debug/examples/demo":        true,
+		"debug/web/fixtures/big":     true,
+		"release":                    false,
+		"release/deps":               false,
+		"release/deps/libserde.rlib": false,
+		"release/app":                true,
+	} {
+		isDir := rel == "." || rel == "debug" || rel == "debug/examples" || rel == "release" || rel == "release/deps"
+		if got := filter.skip(rel, isDir); got != want {
+			t.Errorf("skip(%q) = %v, want %v", rel, got, want)
+		}
+	}
+	if (pathFilter{}).skip("anything", false) {
+		t.Error("expected an empty filter to keep everything")
+	}
+}
+
+func TestArtifactGlobValidation(t *testing.T) {
+	if err := (ArtifactConfig{Name: "cargo", Exclude: []string{"debug/[examples"}}).validateGlobs(); err == nil {
+		t.Error("expected a malformed glob to be rejected")
+	}
+	if err := (ArtifactConfig{Name: "cargo", Include: []string{" "}}).validateGlobs(); err == nil {
+		t.Error("expected an empty glob to be rejected")
+	}
+	if err := (ArtifactConfig{Name: "cargo", Include: []string{"debug/**"}, Exclude: []string{"*/examples"}}).validateGlobs(); err != nil {
+		t.Errorf("expected valid globs to pass, got %v", err)
+	}
+}
+
+func TestStoreAndSyncHonorExclude(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	writeTarget := func(envPath string) {
+		for rel, content := range map[string]string{
+			"target/debug/deps/app":      "app",
+			"target/debug/examples/demo": "demo",
+			"target/debug/fixtures/big":  "big",
+		} {
+			path := filepath.Join(envPath, rel)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(envPath, "Cargo.lock"), []byte(envPath), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	artifact := ArtifactConfig{
+		Name:     "cargo",
+		KeyFiles: []string{"Cargo.lock"},
+		Paths:    []ArtifactPath{{Path: "target", Type: "plain"}},
+		Exclude:  []string{"debug/examples", "**/fixtures"},
+	}
+	rootPath := t.TempDir()
+
+	envPath := t.TempDir()
+	writeTarget(envPath)
+	entries, err := cm.PrepareArtifactCache([]ArtifactConfig{artifact}, rootPath, envPath)
+	if err != nil {
+		t.Fatalf("PrepareArtifactCache failed: %v", err)
+	}
+	if err := cm.StoreToCache(entries[0]); err != nil {
+		t.Fatalf("StoreToCache failed: %v", err)
+	}
+
+	syncEnv := t.TempDir()
+	writeTarget(syncEnv)
+	if err := cm.Sync([]ArtifactConfig{artifact}, rootPath, syncEnv, SyncOptions{HardlinkBack: true}); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	synced, err := cm.PrepareArtifactCache([]ArtifactConfig{artifact}, rootPath, syncEnv)
+	if err != nil {
+		t.Fatalf("PrepareArtifactCache failed: %v", err)
+	}
+
+	for _, env := range []struct {
+		path      string
+		cachePath string
+	}{{envPath, entries[0].CachePath}, {syncEnv, synced[0].CachePath}} {
+		if !fileExists(filepath.Join(env.cachePath, "target", "debug", "deps", "app")) {
+			t.Errorf("expected %s to contain included files", env.cachePath)
+		}
+		for _, rel := range []string{"debug/examples", "debug/fixtures"} {
+			if dirExists(filepath.Join(env.cachePath, "target", filepath.FromSlash(rel))) {
+				t.Errorf("expected %s to be excluded from %s", rel, env.cachePath)
+			}
+		}
+		for _, rel := range []string{"debug/deps/app", "debug/examples/demo", "debug/fixtures/big"} {
+			if !fileExists(filepath.Join(env.path, "target", filepath.FromSlash(rel))) {
+				t.Errorf("expected %s to stay in the environment %s", rel, env.path)
+			}
+		}
+	}
+}
+
+func TestSkipPatternsApplyOnStoreAndRestore(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	logger, err := NewFileLogger("skip-patterns-test")
+	if err != nil {
+		t.Fatalf("NewFileLogger failed: %v", err)
+	}
+	defer logger.Close()
+
+	filter := newPathFilter(nil, nil, []string{"*.lock", "tmp/", "!keep.lock"})
+	for rel, want := range map[string]bool{
+		"providers/registry/aws/.terraform.lock": true,
+		"providers/keep.lock":                    false,
+		"providers/tmp":                          true,
+		"providers/aws":                          false,
+	} {
+		if got := filter.skip(rel, rel == "providers/tmp"); got != want {
+			t.Errorf("skip(%q) = %v, want %v", rel, got, want)
+		}
+	}
+	if err := (ArtifactConfig{Name: "terraform", SkipPatterns: []string{"[lock"}}).validateGlobs(); err == nil {
+		t.Error("expected a malformed skip pattern to be rejected")
+	}
+
+	envPath := t.TempDir()
+	terraform := filepath.Join(envPath, ".terraform")
+	for _, rel := range []string{"providers/aws/provider", "providers/aws/plugin.lock", "tmp/plan"} {
+		path := filepath.Join(terraform, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(rel), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entry := ArtifactCacheEntry{
+		Name:         "terraform",
+		ProjectID:    "proj",
+		Key:          "key1",
+		CachePath:    filepath.Join(cm.LocalCacheDir, "proj", "terraform", "key1"),
+		EnvPaths:     []string{terraform},
+		SkipPatterns: []string{"*.lock", "tmp/"},
+	}
+	if err := cm.StoreToCache(entry); err != nil {
+		t.Fatalf("StoreToCache failed: %v", err)
+	}
+	for rel, want := range map[string]bool{"providers/aws/provider": true, "providers/aws/plugin.lock": false, "tmp/plan": false} {
+		if got := fileExists(filepath.Join(entry.CachePath, ".terraform", filepath.FromSlash(rel))); got != want {
+			t.Errorf("cached %s = %v, want %v", rel, got, want)
+		}
+	}
+
+	legacy := entry
+	legacy.Key = "key0"
+	legacy.CachePath = filepath.Join(cm.LocalCacheDir, "proj", "terraform", "key0")
+	legacy.SkipPatterns = nil
+	if err := cm.StoreToCache(legacy); err != nil {
+		t.Fatalf("StoreToCache failed: %v", err)
+	}
+	if !fileExists(filepath.Join(legacy.CachePath, ".terraform", "tmp", "plan")) {
+		t.Fatal("expected an entry stored without skip patterns to keep everything")
+	}
+
+	entry.Key, entry.CachePath = legacy.Key, legacy.CachePath
+	if err := cm.RestoreFromCache(entry, logger); err != nil {
+		t.Fatalf("RestoreFromCache failed: %v", err)
+	}
+	if !fileExists(filepath.Join(terraform, "providers", "aws", "provider")) {
+		t.Error("expected cached files to be restored")
+	}
+	if fileExists(filepath.Join(terraform, "providers", "aws", "plugin.lock")) || dirExists(filepath.Join(terraform, "tmp")) {
+		t.Error("expected skip patterns to apply to entries cached before they were added")
+	}
+}
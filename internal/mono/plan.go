@@ -0,0 +1,246 @@
+package mono
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type ArtifactPlan struct {
+	Name   string
+	Key    string
+	Action string
+}
+
+type InitPlan struct {
+	Name          string
+	Path          string
+	DataDir       string
+	Exists        bool
+	Reconcile     bool
+	RootPath      string
+	Artifacts     []ArtifactPlan
+	DockerProject string
+	Devcontainer  string
+	Allocations   []Allocation
+	InitScript    string
+	WaitFor       []string
+	SetupScript   string
+	SessionName   string
+	Warnings      []string
+}
+
+func PlanInit(path string, opts InitOptions) (*InitPlan, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("path does not exist: %s", path)
+	}
+
+	envName := EnvName(path)
+	dataDir, err := DataDir(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &InitPlan{
+		Name:        envName,
+		Path:        path,
+		DataDir:     dataDir,
+		SessionName: SessionName(envName),
+	}
+
+	db, err := OpenDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	plan.Exists, err = db.EnvironmentExists(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check environment: %w", err)
+	}
+	if plan.Exists && !opts.Force {
+		return nil, fmt.Errorf("environment already exists: %s (use --force to reconcile)", path)
+	}
+	plan.Reconcile = plan.Exists
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.ApplyDefaults(path)
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cache: %w", err)
+	}
+
+	var envID int64
+	composeDir := cfg.ResolveComposeDir(path)
+	plan.RootPath, err = ResolveRootPath(path, opts.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.Reconcile {
+		env, err := db.GetEnvironmentByPath(path)
+		if err != nil {
+			return nil, err
+		}
+		envID = env.ID
+		plan.RootPath = ""
+		if env.RootPath.Valid {
+			plan.RootPath = env.RootPath.String
+		}
+		if env.ComposeDir.Valid && env.ComposeDir.String != "" {
+			composeDir = filepath.Join(path, env.ComposeDir.String)
+		}
+	} else {
+		envID, err = db.NextEnvironmentID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to predict environment id: %w", err)
+		}
+		plan.InitScript = cfg.Scripts.Init
+	}
+	for _, w := range cfg.WaitFor {
+		plan.WaitFor = append(plan.WaitFor, w.String())
+	}
+	plan.SetupScript = cfg.Scripts.Setup
+
+	if len(cfg.Build.Artifacts) > 0 {
+		if plan.RootPath == "" {
+			plan.Warnings = append(plan.Warnings, "no root path found (set --root or CONDUCTOR_ROOT_PATH), artifact caching disabled")
+		} else {
+			for _, artifact := range cfg.Build.Artifacts {
+				artifactPlan, err := cm.planArtifact(artifact, plan.RootPath, path, plan.Reconcile)
+				if err != nil {
+					return nil, err
+				}
+				plan.Artifacts = append(plan.Artifacts, artifactPlan)
+			}
+		}
+	}
+
+	if _, err := DetectComposeFile(composeDir); err == nil {
+		plan.DockerProject = fmt.Sprintf("mono-%s", envName)
+
+		_, allocations, err := buildComposeOverride(composeDir, envName, envID)
+		if err != nil {
+			return nil, err
+		}
+		plan.Allocations = allocations
+
+		if err := CheckDockerAvailable(); err != nil {
+			plan.Warnings = append(plan.Warnings, err.Error())
+		}
+	} else {
+		devcontainer, err := LoadDevcontainer(path)
+		if err != nil {
+			return nil, err
+		}
+		if devcontainer != nil {
+			plan.Devcontainer = DevcontainerName(envName)
+			plan.Allocations = devcontainerAllocations(envID, devcontainer)
+			if err := CheckDockerAvailable(); err != nil {
+				plan.Warnings = append(plan.Warnings, err.Error())
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+func (cm *CacheManager) planArtifact(artifact ArtifactConfig, rootPath, envPath string, reconcile bool) (ArtifactPlan, error) {
+	key, err := cm.ComputeCacheKey(artifact, envPath)
+	if err != nil {
+		return ArtifactPlan{}, fmt.Errorf("failed to compute cache key for %s: %w", artifact.Name, err)
+	}
+
+	p := ArtifactPlan{Name: artifact.Name, Key: key}
+
+	switch {
+	case reconcile:
+		p.Action = "keep existing"
+	case dirExists(cm.artifactCachePath(artifact, rootPath, key)):
+		p.Action = "hit, restore from cache"
+	case cm.canSeedFromRoot(artifact, rootPath, envPath, key):
+		p.Action = "miss, seed from root then restore"
+	default:
+		p.Action = "miss, build and store to cache"
+	}
+
+	return p, nil
+}
+
+func (cm *CacheManager) canSeedFromRoot(artifact ArtifactConfig, rootPath, envPath, envKey string) bool {
+	if rootPath == envPath || cm.isBuildInProgress(rootPath, artifact) {
+		return false
+	}
+
+	rootKey, err := cm.ComputeCacheKey(artifact, rootPath)
+	if err != nil || rootKey != envKey {
+		return false
+	}
+
+	for _, p := range artifact.Paths {
+		if dirExists(p.resolve(rootPath)) {
+			return true
+		}
+	}
+	return false
+}
+
+func DryRunInit(path string, opts InitOptions) error {
+	plan, err := PlanInit(path, opts)
+	if err != nil {
+		return err
+	}
+
+	if plan.Reconcile {
+		fmt.Printf("Would reconcile environment: %s\n", plan.Name)
+	} else {
+		fmt.Printf("Would initialize environment: %s\n", plan.Name)
+	}
+	fmt.Printf("  Path: %s\n", plan.Path)
+	fmt.Printf("  Data: %s\n", plan.DataDir)
+	if plan.RootPath != "" {
+		fmt.Printf("  Root: %s\n", plan.RootPath)
+	}
+
+	for _, a := range plan.Artifacts {
+		fmt.Printf("  Artifact %s (key: %s): %s\n", a.Name, a.Key, a.Action)
+	}
+
+	if plan.Devcontainer != "" {
+		fmt.Printf("  Devcontainer: %s\n", plan.Devcontainer)
+		for _, alloc := range plan.Allocations {
+			fmt.Printf("  %d -> %d\n", alloc.ContainerPort, alloc.HostPort)
+		}
+	}
+
+	if plan.InitScript != "" {
+		fmt.Printf("  Init script: %s\n", plan.InitScript)
+	}
+
+	if plan.DockerProject != "" {
+		fmt.Printf("  Docker: %s\n", plan.DockerProject)
+		for _, alloc := range plan.Allocations {
+			fmt.Printf("  %s: %d -> %d\n", alloc.Service, alloc.ContainerPort, alloc.HostPort)
+		}
+	}
+
+	for _, w := range plan.WaitFor {
+		fmt.Printf("  Wait for: %s\n", w)
+	}
+
+	if plan.SetupScript != "" {
+		fmt.Printf("  Setup script: %s\n", plan.SetupScript)
+	}
+
+	fmt.Printf("  Tmux: %s\n", plan.SessionName)
+
+	for _, w := range plan.Warnings {
+		fmt.Printf("  warning: %s\n", w)
+	}
+
+	return nil
+}
@@ -1,6 +1,9 @@
 package mono
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 const (
 	BasePort             = 19000
@@ -11,6 +14,7 @@ type Allocation struct {
 	Service       string
 	ContainerPort int
 	HostPort      int
+	URL           string
 }
 
 func Allocate(envID int64, servicePorts map[string][]int) []Allocation {
@@ -20,8 +24,14 @@ func Allocate(envID int64, servicePorts map[string][]int) []Allocation {
 	usedPorts := make(map[int]bool)
 	portIndex := 0
 
-	for service, ports := range servicePorts {
-		for _, containerPort := range ports {
+	services := make([]string, 0, len(servicePorts))
+	for service := range servicePorts {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	for _, service := range services {
+		for _, containerPort := range servicePorts[service] {
 			hostPort := basePort + (containerPort % 100)
 			for usedPorts[hostPort] {
 				hostPort = basePort + portIndex
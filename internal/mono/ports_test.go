@@ -0,0 +1,30 @@
+package mono
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAllocateIsDeterministic(t *testing.T) {
+	servicePorts := map[string][]int{
+		"web":   {3000, 8080},
+		"db":    {5432},
+		"cache": {6379},
+		"api":   {8080},
+	}
+
+	first := Allocate(1, servicePorts)
+	for range 20 {
+		if next := Allocate(1, servicePorts); !slices.Equal(first, next) {
+			t.Fatalf("allocations differ between runs: %v vs %v", first, next)
+		}
+	}
+
+	seen := make(map[int]bool)
+	for _, a := range first {
+		if seen[a.HostPort] {
+			t.Errorf("host port %d allocated twice", a.HostPort)
+		}
+		seen[a.HostPort] = true
+	}
+}
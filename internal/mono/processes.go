@@ -0,0 +1,140 @@
+package mono
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var processName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+const (
+	RestartAlways    = "always"
+	RestartOnFailure = "on-failure"
+	RestartNever     = "no"
+)
+
+type ReadinessConfig struct {
+	TCP     string        `yaml:"tcp"`
+	HTTP    string        `yaml:"http"`
+	Log     string        `yaml:"log"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+func (r ReadinessConfig) enabled() bool {
+	return r.TCP != "" || r.HTTP != "" || r.Log != ""
+}
+
+type ProcessConfig struct {
+	Command    string          `yaml:"command"`
+	Port       int             `yaml:"port"`
+	Restart    string          `yaml:"restart"`
+	MaxRetries int             `yaml:"max_retries"`
+	Ready      ReadinessConfig `yaml:"ready"`
+}
+
+func (p *ProcessConfig) applyDefaults() {
+	if p.Restart == "" {
+		p.Restart = RestartAlways
+	}
+	if p.Ready.Timeout <= 0 {
+		p.Ready.Timeout = time.Minute
+	}
+}
+
+func (p *ProcessConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		p.Command = value.Value
+		return nil
+	}
+	type plain ProcessConfig
+	return value.Decode((*plain)(p))
+}
+
+func (c *Config) ProcessNames() []string {
+	names := make([]string, 0, len(c.Processes))
+	for name := range c.Processes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func validateProcesses(processes map[string]ProcessConfig) error {
+	for name, proc := range processes {
+		if !processName.MatchString(name) {
+			return fmt.Errorf("process name %q may only contain letters, digits, - and _", name)
+		}
+		if strings.TrimSpace(proc.Command) == "" {
+			return fmt.Errorf("process %s has no command", name)
+		}
+		switch proc.Restart {
+		case "", RestartAlways, RestartOnFailure, RestartNever:
+		default:
+			return fmt.Errorf("process %s has unknown restart policy %q (use always, on-failure or no)", name, proc.Restart)
+		}
+		if proc.Port < 0 || proc.Port > 65535 {
+			return fmt.Errorf("process %s has invalid port %d", name, proc.Port)
+		}
+		if proc.MaxRetries < 0 {
+			return fmt.Errorf("process %s has negative max_retries", name)
+		}
+		if proc.Ready.Log != "" {
+			if _, err := regexp.Compile(proc.Ready.Log); err != nil {
+				return fmt.Errorf("process %s has invalid ready.log pattern: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func processAllocations(envID int64, processes map[string]ProcessConfig) []Allocation {
+	servicePorts := make(map[string][]int, len(processes))
+	for name, proc := range processes {
+		servicePorts[name] = []int{proc.Port}
+	}
+	return Allocate(envID, servicePorts)
+}
+
+func LoadProcfile(dir string) (map[string]ProcessConfig, error) {
+	f, err := os.Open(filepath.Join(dir, "Procfile"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Procfile: %w", err)
+	}
+	defer f.Close()
+
+	processes := make(map[string]ProcessConfig)
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, command, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid Procfile line %d: expected <name>: <command>", lineNo)
+		}
+		name = strings.TrimSpace(name)
+		if _, exists := processes[name]; exists {
+			return nil, fmt.Errorf("invalid Procfile line %d: duplicate process %s", lineNo, name)
+		}
+		processes[name] = ProcessConfig{Command: strings.TrimSpace(command)}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Procfile: %w", err)
+	}
+	return processes, nil
+}
@@ -0,0 +1,377 @@
+package mono
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigProcesses(t *testing.T) {
+	dir := t.TempDir()
+	monoYml := `processes:
+  web: npm run dev
+  api:
+    command: cargo run
+`
+	if err := os.WriteFile(filepath.Join(dir, "mono.yml"), []byte(monoYml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Procfile"), []byte("worker: ignored\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Processes["web"].Command != "npm run dev" || cfg.Processes["api"].Command != "cargo run" {
+		t.Errorf("unexpected processes: %+v", cfg.Processes)
+	}
+	if _, ok := cfg.Processes["worker"]; ok {
+		t.Error("Procfile should be ignored when mono.yml declares processes")
+	}
+	if names := cfg.ProcessNames(); strings.Join(names, ",") != "api,web" {
+		t.Errorf("expected sorted names, got %v", names)
+	}
+}
+
+func TestLoadConfigProcfile(t *testing.T) {
+	dir := t.TempDir()
+	procfile := "# services\nweb: npm run dev -- --port $PORT\n\nworker:   bin/worker\n"
+	if err := os.WriteFile(filepath.Join(dir, "Procfile"), []byte(procfile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if len(cfg.Processes) != 2 || cfg.Processes["web"].Command != "npm run dev -- --port $PORT" || cfg.Processes["worker"].Command != "bin/worker" {
+		t.Errorf("unexpected processes: %+v", cfg.Processes)
+	}
+
+	for name, content := range map[string]string{
+		"missing colon": "web npm run dev\n",
+		"duplicate":     "web: a\nweb: b\n",
+		"bad name":      "web app: a\n",
+		"empty command": "web:\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, "Procfile"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfig(dir); err == nil {
+			t.Errorf("%s: expected Procfile to be rejected", name)
+		}
+	}
+}
+
+func TestRunSupervisorRestartsProcesses(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+
+	monoYml := `processes:
+  web: echo "started $SUPERVISOR_TEST"; exec sleep 30
+  crash: exit 3
+`
+	if err := os.WriteFile(filepath.Join(dir, "mono.yml"), []byte(monoYml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SUPERVISOR_TEST", "ok")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- RunSupervisor(ctx, dir)
+	}()
+
+	envName := EnvName(dir)
+	var state *SupervisorState
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s, err := ReadSupervisorState(envName)
+		if err != nil {
+			t.Fatalf("ReadSupervisorState failed: %v", err)
+		}
+		if s != nil && len(s.Processes) == 2 && s.Processes[0].Restarts > 0 && s.Processes[1].Status == ProcessRunning {
+			state = s
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if state == nil {
+		cancel()
+		t.Fatal("supervisor did not start both processes")
+	}
+	if !state.Running() {
+		t.Error("supervisor state should report the running test process")
+	}
+	if state.Processes[0].Name != "crash" || state.Processes[0].ExitCode != 3 {
+		t.Errorf("unexpected crash state: %+v", state.Processes[0])
+	}
+	webPID := state.Processes[1].PID
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("RunSupervisor failed: %v", err)
+	}
+
+	state, err := ReadSupervisorState(envName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range state.Processes {
+		if p.Status != ProcessStopped || p.PID != 0 {
+			t.Errorf("expected %s to be stopped: %+v", p.Name, p)
+		}
+	}
+	if processAlive(webPID) {
+		t.Errorf("web process %d should have been stopped", webPID)
+	}
+
+	log, err := os.ReadFile(state.Processes[1].Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(log), "started ok") {
+		t.Errorf("expected process output in log, got %q", log)
+	}
+}
+
+func TestLoadConfigRejectsInvalidProcessPolicies(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"restart":     "processes:\n  web:\n    command: a\n    restart: sometimes\n",
+		"max_retries": "processes:\n  web:\n    command: a\n    max_retries: -1\n",
+		"log pattern": "processes:\n  web:\n    command: a\n    ready:\n      log: \"(\"\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, "mono.yml"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfig(dir); err == nil {
+			t.Errorf("%s: expected config to be rejected", name)
+		}
+	}
+}
+
+func startTestSupervisor(t *testing.T, monoYml string) (string, *Config) {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mono.yml"), []byte(monoYml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	cfg.ApplyDefaults(dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- RunSupervisor(ctx, dir)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("RunSupervisor failed: %v", err)
+		}
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		state, err := ReadSupervisorState(EnvName(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state != nil {
+			return dir, cfg
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("supervisor did not write its state")
+	return "", nil
+}
+
+func TestSupervisorRestartPoliciesAndReadiness(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	t.Setenv("READY_TEST_ADDR", listener.Addr().String())
+
+	dir, cfg := startTestSupervisor(t, `processes:
+  once:
+    command: exit 0
+    restart: on-failure
+  flaky:
+    command: exit 2
+    max_retries: 1
+  server:
+    command: echo booting; sleep 0.2; echo "listening on 4000"; exec sleep 30
+    ready:
+      log: listening on \d+
+  tcp:
+    command: exec sleep 30
+    ready:
+      tcp: $READY_TEST_ADDR
+`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	logger, err := NewFileLogger("processes-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+	if err := WaitForProcesses(ctx, EnvName(dir), cfg, logger); err != nil {
+		t.Fatalf("WaitForProcesses failed: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	var byName map[string]ProcessState
+	for time.Now().Before(deadline) {
+		state, err := ReadSupervisorState(EnvName(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		byName = make(map[string]ProcessState)
+		for _, p := range state.Processes {
+			byName[p.Name] = p
+		}
+		if byName["flaky"].Status == ProcessFailed {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if p := byName["once"]; p.Status != ProcessExited || p.Restarts != 0 {
+		t.Errorf("on-failure process exiting cleanly should not restart: %+v", p)
+	}
+	if p := byName["flaky"]; p.Status != ProcessFailed || p.Restarts != 1 || p.ExitCode != 2 {
+		t.Errorf("flaky process should give up after one retry: %+v", p)
+	}
+	if p := byName["server"]; p.Status != ProcessRunning || !p.Ready {
+		t.Errorf("server should be ready after logging: %+v", p)
+	}
+	if p := byName["tcp"]; p.Status != ProcessRunning || !p.Ready {
+		t.Errorf("tcp process should be ready once the port accepts: %+v", p)
+	}
+}
+
+func TestWaitForProcessesFailsWhenProcessExits(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	dir, cfg := startTestSupervisor(t, `processes:
+  api:
+    command: echo crashed; exit 1
+    restart: "no"
+    ready:
+      log: never printed
+`)
+
+	logger, err := NewFileLogger("processes-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	err = WaitForProcesses(context.Background(), EnvName(dir), cfg, logger)
+	if err == nil || !strings.Contains(err.Error(), "before becoming ready") {
+		t.Errorf("expected exit before readiness error, got %v", err)
+	}
+}
+
+func TestCheckReadinessHTTP(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	if err := checkReadiness(context.Background(), "", server.URL, nil, "", 0); err == nil {
+		t.Error("expected 503 to be not ready")
+	}
+	status = http.StatusOK
+	if err := checkReadiness(context.Background(), "", server.URL, nil, "", 0); err != nil {
+		t.Errorf("expected 200 to be ready, got %v", err)
+	}
+}
+
+func TestProcessAllocations(t *testing.T) {
+	allocations := processAllocations(2, map[string]ProcessConfig{
+		"web":    {Command: "npm run dev", Port: 3000},
+		"api":    {Command: "cargo run", Port: 8080},
+		"worker": {Command: "bin/worker"},
+	})
+
+	ports := AllocationsToMap(allocations)
+	if len(ports) != 3 {
+		t.Fatalf("expected a port per process, got %v", allocations)
+	}
+	if ports["web"] == ports["worker"] || ports["api"] == ports["worker"] || ports["web"] == ports["api"] {
+		t.Errorf("expected distinct ports, got %v", ports)
+	}
+	base := BasePort + 2*PortRangePerWorktree
+	if ports["api"] != base+80 {
+		t.Errorf("expected api to keep its port suffix, got %d", ports["api"])
+	}
+	for name, port := range ports {
+		if port < base || port >= base+PortRangePerWorktree {
+			t.Errorf("%s port %d outside the environment's range", name, port)
+		}
+	}
+
+	env := buildScriptEnv("proj", 2, "/ws", "", allocations, nil, nil)
+	if !slices.Contains(env, fmt.Sprintf("MONO_WORKER_PORT=%d", ports["worker"])) {
+		t.Errorf("expected MONO_WORKER_PORT in %v", env)
+	}
+}
+
+func TestSupervisorPassesPort(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("MONO_DEV_SERVER_PORT", "19123")
+
+	dir, cfg := startTestSupervisor(t, `processes:
+  dev-server:
+    command: echo "port=$PORT"; exec sleep 30
+    ready:
+      log: port=\d+
+`)
+
+	logger, err := NewFileLogger("processes-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+	if err := WaitForProcesses(context.Background(), EnvName(dir), cfg, logger); err != nil {
+		t.Fatalf("WaitForProcesses failed: %v", err)
+	}
+
+	state, err := ReadSupervisorState(EnvName(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proc := state.Processes[0]
+	if proc.Port != 19123 {
+		t.Errorf("expected port 19123 in state, got %d", proc.Port)
+	}
+	log, err := os.ReadFile(proc.Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(log), "port=19123") {
+		t.Errorf("expected PORT in process env, got %q", log)
+	}
+}
@@ -0,0 +1,207 @@
+package mono
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const processLogPoll = 200 * time.Millisecond
+
+type ProcessLogLine struct {
+	Time    time.Time
+	Process string
+	Stream  string
+	Text    string
+}
+
+type ProcessLogOptions struct {
+	Processes []string
+	Tail      int
+	Follow    bool
+}
+
+func processOutputPath(envName string) (string, error) {
+	dir, err := ProcessesDir(envName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "output.log"), nil
+}
+
+func (l ProcessLogLine) encode() string {
+	return fmt.Sprintf("%s\t%s\t%s\t%s\n", l.Time.Format(time.RFC3339Nano), l.Process, l.Stream, l.Text)
+}
+
+func parseProcessLogLine(raw string) (ProcessLogLine, bool) {
+	parts := strings.SplitN(raw, "\t", 4)
+	if len(parts) != 4 {
+		return ProcessLogLine{}, false
+	}
+	ts, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return ProcessLogLine{}, false
+	}
+	return ProcessLogLine{Time: ts, Process: parts[1], Stream: parts[2], Text: parts[3]}, true
+}
+
+type processOutput struct {
+	mu     sync.Mutex
+	file   *os.File
+	logger *FileLogger
+}
+
+func (o *processOutput) write(line ProcessLogLine) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, err := io.WriteString(o.file, line.encode()); err != nil {
+		o.logger.Log("warning: failed to write process output: %v", err)
+	}
+	o.logger.Log("[%s] [%s] %s", line.Process, line.Stream, line.Text)
+}
+
+type processStream struct {
+	output  *processOutput
+	log     io.Writer
+	process string
+	stream  string
+	buf     []byte
+}
+
+func (w *processStream) Write(p []byte) (int, error) {
+	if _, err := w.log.Write(p); err != nil {
+		return 0, err
+	}
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *processStream) Flush() {
+	if len(w.buf) > 0 {
+		w.emit(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *processStream) emit(line []byte) {
+	w.output.write(ProcessLogLine{
+		Time:    time.Now().UTC(),
+		Process: w.process,
+		Stream:  w.stream,
+		Text:    strings.TrimRight(string(line), "\r"),
+	})
+}
+
+func StreamProcessLogs(ctx context.Context, envName string, opts ProcessLogOptions, fn func(ProcessLogLine) error) error {
+	path, err := processOutputPath(envName)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) && opts.Follow {
+		f, err = waitForFile(ctx, path)
+	}
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open process output: %w", err)
+	}
+	if f == nil {
+		return nil
+	}
+	defer f.Close()
+
+	match := func(line ProcessLogLine) bool {
+		return len(opts.Processes) == 0 || slices.Contains(opts.Processes, line.Process)
+	}
+
+	reader := bufio.NewReader(f)
+	var backlog []ProcessLogLine
+	var partial string
+	for {
+		raw, err := reader.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			partial = raw
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read process output: %w", err)
+		}
+		line, ok := parseProcessLogLine(strings.TrimSuffix(raw, "\n"))
+		if !ok || !match(line) {
+			continue
+		}
+		backlog = append(backlog, line)
+		if opts.Tail > 0 && len(backlog) > opts.Tail {
+			backlog = backlog[1:]
+		}
+	}
+	for _, line := range backlog {
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+
+	if !opts.Follow {
+		return nil
+	}
+
+	for {
+		raw, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read process output: %w", err)
+		}
+		partial += raw
+		if errors.Is(err, io.EOF) {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(processLogPoll):
+			}
+			continue
+		}
+		line, ok := parseProcessLogLine(strings.TrimSuffix(partial, "\n"))
+		partial = ""
+		if !ok || !match(line) {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+}
+
+func waitForFile(ctx context.Context, path string) (*os.File, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(processLogPoll):
+		}
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		return f, err
+	}
+}
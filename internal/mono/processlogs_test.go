@@ -0,0 +1,155 @@
+package mono
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSupervisorMultiplexesProcessOutput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+
+	monoYml := `processes:
+  api:
+    command: echo one; echo two >&2
+    restart: "no"
+  web:
+    command: printf partial
+    restart: "no"
+`
+	if err := os.WriteFile(filepath.Join(dir, "mono.yml"), []byte(monoYml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- RunSupervisor(ctx, dir)
+	}()
+
+	envName := EnvName(dir)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s, err := ReadSupervisorState(envName)
+		if err != nil {
+			t.Fatalf("ReadSupervisorState failed: %v", err)
+		}
+		if s != nil && len(s.Processes) == 2 && s.Processes[0].Status == ProcessExited && s.Processes[1].Status == ProcessExited {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("RunSupervisor failed: %v", err)
+	}
+
+	var lines []ProcessLogLine
+	collect := func(line ProcessLogLine) error {
+		lines = append(lines, line)
+		return nil
+	}
+	if err := StreamProcessLogs(context.Background(), envName, ProcessLogOptions{}, collect); err != nil {
+		t.Fatalf("StreamProcessLogs failed: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, line := range lines {
+		got[line.Process+"/"+line.Stream+"/"+line.Text] = line.Process
+	}
+	for _, want := range []string{"api/out/one", "api/err/two", "web/out/partial"} {
+		if _, ok := got[want]; !ok {
+			t.Errorf("expected %s in interleaved output, got %+v", want, lines)
+		}
+	}
+
+	lines = nil
+	if err := StreamProcessLogs(context.Background(), envName, ProcessLogOptions{Processes: []string{"api"}, Tail: 1}, collect); err != nil {
+		t.Fatalf("StreamProcessLogs failed: %v", err)
+	}
+	if len(lines) != 1 || lines[0].Process != "api" {
+		t.Errorf("expected the last api line only, got %+v", lines)
+	}
+
+	processesDir, err := ProcessesDir(envName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiLog, err := os.ReadFile(filepath.Join(processesDir, "api.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(apiLog) != "one\ntwo\n" && string(apiLog) != "two\none\n" {
+		t.Errorf("unexpected api log %q", apiLog)
+	}
+
+	monoHome, err := GetMonoHome()
+	if err != nil {
+		t.Fatal(err)
+	}
+	monoLog, err := os.ReadFile(filepath.Join(monoHome, "mono.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(monoLog), "[api] [out] one") || !strings.Contains(string(monoLog), "[web] [out] partial") {
+		t.Errorf("expected prefixed process output in environment log, got %q", monoLog)
+	}
+}
+
+func TestStreamProcessLogsFollow(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	envName := "follow-test"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lines := make(chan ProcessLogLine, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- StreamProcessLogs(ctx, envName, ProcessLogOptions{Follow: true}, func(line ProcessLogLine) error {
+			lines <- line
+			return nil
+		})
+	}()
+
+	path, err := processOutputPath(envName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	line := ProcessLogLine{Time: time.Now().UTC(), Process: "web", Stream: "out", Text: "hello\tworld"}
+	encoded := line.encode()
+	if _, err := f.WriteString(encoded[:10]); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(3 * processLogPoll)
+	if _, err := f.WriteString(encoded[10:]); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-lines:
+		if got.Process != "web" || got.Text != "hello\tworld" {
+			t.Errorf("unexpected line %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("followed line was not streamed")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("StreamProcessLogs failed: %v", err)
+	}
+}
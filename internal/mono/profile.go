@@ -0,0 +1,210 @@
+package mono
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var activeProfiler atomic.Pointer[Profiler]
+
+type ProfilePhase struct {
+	Name     string        `json:"name"`
+	Start    time.Duration `json:"start_ns"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+type ProfileWorker struct {
+	Operation string        `json:"operation"`
+	Worker    int           `json:"worker"`
+	Items     int64         `json:"items"`
+	Busy      time.Duration `json:"busy_ns"`
+	Wall      time.Duration `json:"wall_ns"`
+}
+
+type ProfileReport struct {
+	Command   string          `json:"command"`
+	Env       string          `json:"env"`
+	StartedAt time.Time       `json:"started_at"`
+	Duration  time.Duration   `json:"duration_ns"`
+	Error     string          `json:"error,omitempty"`
+	Phases    []ProfilePhase  `json:"phases"`
+	Workers   []ProfileWorker `json:"workers"`
+}
+
+type Profiler struct {
+	Dir string
+
+	mu        sync.Mutex
+	report    ProfileReport
+	start     time.Time
+	phase     string
+	phaseAt   time.Time
+	workerIDs map[string]int
+	cpuFile   *os.File
+}
+
+type workerSpan struct {
+	profiler *Profiler
+	record   ProfileWorker
+	start    time.Time
+}
+
+func ProfilesDir() (string, error) {
+	home, err := GetMonoHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "profiles"), nil
+}
+
+func StartProfiler(envName, command string, cpu bool) (*Profiler, error) {
+	root, err := ProfilesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	dir := filepath.Join(root, envName, command+"-"+now.Format("20060102-150405"))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	p := &Profiler{
+		Dir:       dir,
+		report:    ProfileReport{Command: command, Env: envName, StartedAt: now},
+		start:     now,
+		workerIDs: make(map[string]int),
+	}
+
+	if cpu {
+		f, err := os.Create(filepath.Join(dir, "cpu.pprof"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cpu profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to start cpu profile: %w", err)
+		}
+		p.cpuFile = f
+	}
+
+	if !activeProfiler.CompareAndSwap(nil, p) {
+		if p.cpuFile != nil {
+			pprof.StopCPUProfile()
+			p.cpuFile.Close()
+		}
+		return nil, fmt.Errorf("a profile is already being recorded")
+	}
+	return p, nil
+}
+
+func (p *Profiler) Finish(runErr error) error {
+	if p == nil {
+		return nil
+	}
+	activeProfiler.CompareAndSwap(p, nil)
+
+	var cpuErr error
+	if p.cpuFile != nil {
+		pprof.StopCPUProfile()
+		cpuErr = p.cpuFile.Close()
+	}
+
+	p.mu.Lock()
+	p.endPhase(time.Now())
+	p.report.Duration = time.Since(p.start)
+	if runErr != nil {
+		p.report.Error = runErr.Error()
+	}
+	data, err := json.MarshalIndent(p.report, "", "  ")
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(p.Dir, "profile.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	if cpuErr != nil {
+		return fmt.Errorf("failed to write cpu profile: %w", cpuErr)
+	}
+	return nil
+}
+
+func (p *Profiler) Report() ProfileReport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.report
+}
+
+func (p *Profiler) markPhase(name string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	p.endPhase(now)
+	p.phase = name
+	p.phaseAt = now
+}
+
+func (p *Profiler) endPhase(now time.Time) {
+	if p.phase == "" {
+		return
+	}
+	p.report.Phases = append(p.report.Phases, ProfilePhase{
+		Name:     p.phase,
+		Start:    p.phaseAt.Sub(p.start),
+		Duration: now.Sub(p.phaseAt),
+	})
+	p.phase = ""
+}
+
+func profilePhase(name string) {
+	activeProfiler.Load().markPhase(name)
+}
+
+func profileWorker(operation string) *workerSpan {
+	p := activeProfiler.Load()
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	id := p.workerIDs[operation]
+	p.workerIDs[operation] = id + 1
+	p.mu.Unlock()
+
+	return &workerSpan{
+		profiler: p,
+		record:   ProfileWorker{Operation: operation, Worker: id},
+		start:    time.Now(),
+	}
+}
+
+func (w *workerSpan) item(start time.Time) {
+	if w == nil {
+		return
+	}
+	w.record.Items++
+	w.record.Busy += time.Since(start)
+}
+
+func (w *workerSpan) finish() {
+	if w == nil {
+		return
+	}
+	w.record.Wall = time.Since(w.start)
+
+	w.profiler.mu.Lock()
+	defer w.profiler.mu.Unlock()
+	w.profiler.report.Workers = append(w.profiler.report.Workers, w.record)
+}
@@ -0,0 +1,76 @@
+package mono
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfilerRecordsPhasesAndWorkers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	profiler, err := StartProfiler("env", "init", false)
+	if err != nil {
+		t.Fatalf("StartProfiler failed: %v", err)
+	}
+	if _, err := StartProfiler("env", "init", false); err == nil {
+		t.Error("expected a second profiler to be rejected")
+	}
+
+	var status *StatusServer
+	status.SetPhase("seeding")
+
+	src := filepath.Join(t.TempDir(), "src")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := os.WriteFile(filepath.Join(src, fmt.Sprintf("f%d", i)), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dst := filepath.Join(t.TempDir(), "dst")
+	if err := SeedDirectory(src, dst, SeedOptions{ArtifactName: "npm", NumWorkers: 2}); err != nil {
+		t.Fatalf("SeedDirectory failed: %v", err)
+	}
+
+	status.SetPhase("done")
+
+	if err := profiler.Finish(nil); err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(profiler.Dir, "profile.json"))
+	if err != nil {
+		t.Fatalf("failed to read profile: %v", err)
+	}
+	var report ProfileReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("failed to parse profile: %v", err)
+	}
+
+	if len(report.Phases) != 2 || report.Phases[0].Name != "seeding" || report.Phases[1].Name != "done" {
+		t.Errorf("unexpected phases: %+v", report.Phases)
+	}
+
+	var items int64
+	for _, w := range report.Workers {
+		if w.Operation != "seeding npm" {
+			t.Errorf("unexpected worker operation %q", w.Operation)
+		}
+		items += w.Items
+	}
+	if len(report.Workers) != 2 || items != 10 {
+		t.Errorf("expected 2 workers handling 10 files, got %d workers and %d files", len(report.Workers), items)
+	}
+
+	next, err := StartProfiler("env", "sync", false)
+	if err != nil {
+		t.Fatalf("expected a new profiler after Finish: %v", err)
+	}
+	if err := next.Finish(nil); err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package mono
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	ProgressStarted        = "started"
+	ProgressPhaseStarted   = "phase_started"
+	ProgressPhaseCompleted = "phase_completed"
+	ProgressFiles          = "progress"
+	ProgressCompleted      = "completed"
+	ProgressFailed         = "failed"
+
+	progressInterval = 100 * time.Millisecond
+)
+
+var activeProgress atomic.Pointer[ProgressEmitter]
+
+type ProgressEvent struct {
+	Time       time.Time `json:"time"`
+	Event      string    `json:"event"`
+	Operation  string    `json:"operation"`
+	Env        string    `json:"env"`
+	Phase      string    `json:"phase,omitempty"`
+	Task       string    `json:"task,omitempty"`
+	Completed  int64     `json:"completed,omitempty"`
+	Total      int64     `json:"total,omitempty"`
+	Percent    *float64  `json:"percent,omitempty"`
+	DurationMs int64     `json:"duration_ms,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type ProgressEmitter struct {
+	mu        sync.Mutex
+	enc       *json.Encoder
+	env       string
+	operation string
+	start     time.Time
+	phase     string
+	phaseAt   time.Time
+	lastFiles map[string]time.Time
+	err       error
+}
+
+func StartProgress(w io.Writer, envName, operation string) (*ProgressEmitter, error) {
+	e := &ProgressEmitter{
+		enc:       json.NewEncoder(w),
+		env:       envName,
+		operation: operation,
+		start:     time.Now(),
+		lastFiles: make(map[string]time.Time),
+	}
+	if !activeProgress.CompareAndSwap(nil, e) {
+		return nil, fmt.Errorf("progress is already being reported")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.emit(ProgressEvent{Event: ProgressStarted})
+	return e, e.err
+}
+
+func (e *ProgressEmitter) Finish(runErr error) error {
+	if e == nil {
+		return nil
+	}
+	activeProgress.CompareAndSwap(e, nil)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	e.endPhase(now)
+	event := ProgressEvent{Event: ProgressCompleted, DurationMs: now.Sub(e.start).Milliseconds()}
+	if runErr != nil {
+		event.Event = ProgressFailed
+		event.Error = runErr.Error()
+	}
+	e.emit(event)
+	return e.err
+}
+
+func (e *ProgressEmitter) emit(event ProgressEvent) {
+	if e.err != nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	event.Operation = e.operation
+	event.Env = e.env
+	if err := e.enc.Encode(event); err != nil {
+		e.err = fmt.Errorf("failed to write progress event: %w", err)
+	}
+}
+
+func (e *ProgressEmitter) endPhase(now time.Time) {
+	if e.phase == "" {
+		return
+	}
+	e.emit(ProgressEvent{
+		Event:      ProgressPhaseCompleted,
+		Phase:      e.phase,
+		DurationMs: now.Sub(e.phaseAt).Milliseconds(),
+	})
+	e.phase = ""
+}
+
+func (e *ProgressEmitter) markPhase(name string) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	e.endPhase(now)
+	e.phase = name
+	e.phaseAt = now
+	e.emit(ProgressEvent{Event: ProgressPhaseStarted, Phase: name})
+}
+
+func (e *ProgressEmitter) files(task string, completed, total int64, done bool) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	if !done && now.Sub(e.lastFiles[task]) < progressInterval {
+		return
+	}
+	e.lastFiles[task] = now
+
+	event := ProgressEvent{
+		Event:     ProgressFiles,
+		Phase:     e.phase,
+		Task:      task,
+		Completed: completed,
+		Total:     total,
+	}
+	if total > 0 {
+		pct := float64(completed) / float64(total) * 100
+		event.Percent = &pct
+	}
+	e.emit(event)
+}
+
+func progressPhase(name string) {
+	activeProgress.Load().markPhase(name)
+}
+
+func progressFiles(task string, completed, total int64, done bool) {
+	activeProgress.Load().files(task, completed, total, done)
+}
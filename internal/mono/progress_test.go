@@ -0,0 +1,101 @@
+package mono
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProgressEmitsNDJSONEvents(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var out bytes.Buffer
+	progress, err := StartProgress(&out, "env", "init")
+	if err != nil {
+		t.Fatalf("StartProgress failed: %v", err)
+	}
+	if _, err := StartProgress(&out, "env", "init"); err == nil {
+		t.Error("expected a second progress stream to be rejected")
+	}
+
+	logger, err := NewFileLogger("env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	var status *StatusServer
+	status.SetPhase("restoring npm")
+
+	src := filepath.Join(t.TempDir(), "src")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := os.WriteFile(filepath.Join(src, fmt.Sprintf("f%d", i)), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dst := filepath.Join(t.TempDir(), "dst")
+	if err := SeedDirectory(src, dst, SeedOptions{ArtifactName: "npm", Logger: logger, OperationName: "restoring"}); err != nil {
+		t.Fatalf("SeedDirectory failed: %v", err)
+	}
+
+	status.SetPhase("creating tmux session")
+
+	if err := progress.Finish(errors.New("boom")); err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+
+	var events []ProgressEvent
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var event ProgressEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", scanner.Text(), err)
+		}
+		if event.Operation != "init" || event.Env != "env" {
+			t.Errorf("unexpected event identity: %+v", event)
+		}
+		events = append(events, event)
+	}
+
+	var kinds []string
+	var last ProgressEvent
+	for _, e := range events {
+		if e.Event == ProgressFiles {
+			last = e
+			continue
+		}
+		kinds = append(kinds, e.Event+":"+e.Phase)
+	}
+	want := []string{
+		"started:",
+		"phase_started:restoring npm",
+		"phase_completed:restoring npm",
+		"phase_started:creating tmux session",
+		"phase_completed:creating tmux session",
+		"failed:",
+	}
+	if fmt.Sprint(kinds) != fmt.Sprint(want) {
+		t.Errorf("unexpected event sequence:\n got %v\nwant %v", kinds, want)
+	}
+
+	if last.Task != "restoring npm" || last.Phase != "restoring npm" || last.Completed != 10 || last.Total != 10 || last.Percent == nil || *last.Percent != 100 {
+		t.Errorf("expected a final progress event for all files, got %+v", last)
+	}
+	if events[len(events)-1].Error != "boom" {
+		t.Errorf("expected failure error in final event, got %+v", events[len(events)-1])
+	}
+
+	if _, err := StartProgress(&out, "env", "sync"); err != nil {
+		t.Errorf("expected progress to be reusable after Finish: %v", err)
+	} else {
+		activeProgress.Store(nil)
+	}
+}
@@ -0,0 +1,132 @@
+package mono
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type Orphans struct {
+	Environments   []string
+	Sessions       []string
+	DockerProjects []string
+	Warnings       []string
+}
+
+func (o *Orphans) Empty() bool {
+	return len(o.Environments) == 0 && len(o.Sessions) == 0 && len(o.DockerProjects) == 0
+}
+
+func FindOrphans() (*Orphans, error) {
+	db, err := OpenDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	environments, err := db.ListEnvironments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environments: %w", err)
+	}
+
+	orphans := &Orphans{}
+	knownSessions := make(map[string]bool)
+	knownProjects := make(map[string]bool)
+
+	for _, env := range environments {
+		if _, err := os.Stat(env.Path); os.IsNotExist(err) {
+			orphans.Environments = append(orphans.Environments, env.Path)
+		}
+
+		envName := EnvName(env.Path)
+		knownSessions[SessionName(envName)] = true
+		knownProjects[strings.ToLower(fmt.Sprintf("mono-%s", envName))] = true
+		if env.DockerProject.Valid && env.DockerProject.String != "" {
+			knownProjects[strings.ToLower(env.DockerProject.String)] = true
+		}
+	}
+
+	sessions, err := ListMonoSessions()
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		if !knownSessions[session] {
+			orphans.Sessions = append(orphans.Sessions, session)
+		}
+	}
+
+	if err := CheckDockerAvailable(); err != nil {
+		orphans.Warnings = append(orphans.Warnings, fmt.Sprintf("skipping docker projects: %v", err))
+		return orphans, nil
+	}
+
+	projects, err := ListDockerProjects()
+	if err != nil {
+		return nil, err
+	}
+	for _, project := range projects {
+		name := strings.ToLower(project.Name)
+		if strings.HasPrefix(name, "mono-") && !knownProjects[name] {
+			orphans.DockerProjects = append(orphans.DockerProjects, project.Name)
+		}
+	}
+
+	return orphans, nil
+}
+
+func Prune(orphans *Orphans) error {
+	logger, err := NewFileLogger("prune")
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer logger.Close()
+
+	ctx, stopSignals := notifyInterrupt("prune")
+	defer stopSignals()
+
+	var failed []string
+
+	for _, path := range orphans.Environments {
+		if err := interruptErr(ctx); err != nil {
+			return err
+		}
+		if err := Destroy(path, DestroyOptions{Force: true}); err != nil {
+			logger.Log("warning: failed to prune environment %s: %v", path, err)
+			failed = append(failed, path)
+		}
+	}
+
+	for _, session := range orphans.Sessions {
+		if err := KillSession(session); err != nil {
+			logger.Log("warning: failed to kill tmux session %s: %v", session, err)
+			failed = append(failed, session)
+			continue
+		}
+		logger.Log("killed orphaned tmux session %s", session)
+		fmt.Printf("Killed tmux session: %s\n", session)
+	}
+
+	for _, project := range orphans.DockerProjects {
+		if err := interruptErr(ctx); err != nil {
+			return err
+		}
+		stdout := NewLogWriter(logger, "out")
+		stderr := NewLogWriter(logger, "err")
+		if err := StopContainers(ctx, project, os.TempDir(), true, stdout, stderr); err != nil {
+			if err := interruptErr(ctx); err != nil {
+				return err
+			}
+			logger.Log("warning: failed to stop docker project %s: %v", project, err)
+			failed = append(failed, project)
+			continue
+		}
+		logger.Log("stopped orphaned docker project %s", project)
+		fmt.Printf("Stopped docker project: %s\n", project)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to prune: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
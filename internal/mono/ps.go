@@ -0,0 +1,222 @@
+package mono
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	KindProcess      = "process"
+	KindContainer    = "container"
+	KindDevcontainer = "devcontainer"
+)
+
+type ProcessInfo struct {
+	Kind     string
+	Name     string
+	Status   string
+	PID      int
+	Uptime   string
+	Restarts int
+	Ports    []string
+}
+
+type EnvironmentProcesses struct {
+	Name     string
+	Path     string
+	Entries  []ProcessInfo
+	Warnings []string
+}
+
+func Ps(path string) (*EnvironmentProcesses, error) {
+	db, err := OpenDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	env, err := db.GetEnvironmentByPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("environment not found: %s", path)
+	}
+	return environmentProcesses(env)
+}
+
+func PsAll() ([]*EnvironmentProcesses, error) {
+	db, err := OpenDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	environments, err := db.ListEnvironments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environments: %w", err)
+	}
+
+	var result []*EnvironmentProcesses
+	for _, env := range environments {
+		procs, err := environmentProcesses(env)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, procs)
+	}
+	return result, nil
+}
+
+func environmentProcesses(env *Environment) (*EnvironmentProcesses, error) {
+	envName := EnvName(env.Path)
+	result := &EnvironmentProcesses{Name: envName, Path: env.Path}
+
+	state, err := ReadSupervisorState(envName)
+	if err != nil {
+		return nil, err
+	}
+	if state.Running() {
+		for _, p := range state.Processes {
+			result.Entries = append(result.Entries, supervisedProcessInfo(p))
+		}
+	}
+
+	switch {
+	case env.DockerProject.Valid && env.DockerProject.String != "":
+		if err := CheckDockerAvailable(); err != nil {
+			result.Warnings = append(result.Warnings, err.Error())
+			return result, nil
+		}
+		statuses, err := ListServiceStatuses(env.DockerProject.String)
+		if err != nil {
+			result.Warnings = append(result.Warnings, err.Error())
+			return result, nil
+		}
+		for _, s := range statuses {
+			result.Entries = append(result.Entries, containerInfo(s))
+		}
+	case env.UsesDevcontainer():
+		if err := CheckDockerAvailable(); err != nil {
+			result.Warnings = append(result.Warnings, err.Error())
+			return result, nil
+		}
+		info, err := devcontainerInfo(env, envName)
+		if err != nil {
+			result.Warnings = append(result.Warnings, err.Error())
+			return result, nil
+		}
+		result.Entries = append(result.Entries, info)
+	}
+
+	return result, nil
+}
+
+func supervisedProcessInfo(p ProcessState) ProcessInfo {
+	info := ProcessInfo{
+		Kind:     KindProcess,
+		Name:     p.Name,
+		Status:   p.Status,
+		Restarts: p.Restarts,
+	}
+	switch p.Status {
+	case ProcessRunning:
+		info.PID = p.PID
+		info.Uptime = formatUptime(time.Since(p.StartedAt))
+		if !p.Ready {
+			info.Status = "running (not ready)"
+		}
+	case ProcessBackoff, ProcessExited, ProcessFailed:
+		info.Status = fmt.Sprintf("%s (exit %d)", p.Status, p.ExitCode)
+	}
+	if p.Port > 0 {
+		info.Ports = []string{fmt.Sprintf("%d", p.Port)}
+	}
+	return info
+}
+
+func containerInfo(s ServiceStatus) ProcessInfo {
+	info := ProcessInfo{
+		Kind:     KindContainer,
+		Name:     s.Service,
+		Status:   s.State,
+		Restarts: -1,
+	}
+	if s.Health != "" {
+		info.Status = fmt.Sprintf("%s (%s)", s.State, s.Health)
+	}
+	if s.State == "running" {
+		info.Uptime = dockerUptime(s.Status)
+	}
+
+	seen := make(map[string]bool)
+	for _, pub := range s.Publishers {
+		if pub.PublishedPort == 0 {
+			continue
+		}
+		binding := fmt.Sprintf("%d->%d/%s", pub.PublishedPort, pub.TargetPort, pub.Protocol)
+		if !seen[binding] {
+			seen[binding] = true
+			info.Ports = append(info.Ports, binding)
+		}
+	}
+	sort.Strings(info.Ports)
+	return info
+}
+
+func devcontainerInfo(env *Environment, envName string) (ProcessInfo, error) {
+	name := DevcontainerName(envName)
+	info := ProcessInfo{Kind: KindDevcontainer, Name: name, Restarts: -1}
+
+	result, err := Command("docker", "ps", "-a", "--filter", "name=^"+name+"$", "--format", "{{.State}}\t{{.Status}}").RunCapture()
+	if err != nil {
+		return info, fmt.Errorf("failed to inspect devcontainer: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return info, fmt.Errorf("failed to inspect devcontainer: %s", strings.TrimSpace(string(result.Stderr)))
+	}
+
+	state, status, _ := strings.Cut(strings.TrimSpace(string(result.Stdout)), "\t")
+	if state == "" {
+		info.Status = "missing"
+		return info, nil
+	}
+	info.Status = state
+	if state == "running" {
+		info.Uptime = dockerUptime(status)
+	}
+
+	dc, err := loadEnvironmentDevcontainer(env)
+	if err != nil {
+		return info, err
+	}
+	if dc != nil {
+		for _, alloc := range devcontainerAllocations(env.ID, dc) {
+			info.Ports = append(info.Ports, fmt.Sprintf("%d->%d/tcp", alloc.HostPort, alloc.ContainerPort))
+		}
+	}
+	return info, nil
+}
+
+func dockerUptime(status string) string {
+	uptime, ok := strings.CutPrefix(status, "Up ")
+	if !ok {
+		return ""
+	}
+	if i := strings.Index(uptime, " ("); i >= 0 {
+		uptime = uptime[:i]
+	}
+	return uptime
+}
+
+func formatUptime(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
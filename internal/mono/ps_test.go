@@ -0,0 +1,75 @@
+package mono
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestContainerInfo(t *testing.T) {
+	info := containerInfo(ServiceStatus{
+		Service: "db",
+		State:   "running",
+		Health:  "healthy",
+		Status:  "Up 12 minutes (healthy)",
+		Publishers: []ServicePublisher{
+			{URL: "0.0.0.0", TargetPort: 5432, PublishedPort: 19132, Protocol: "tcp"},
+			{URL: "::", TargetPort: 5432, PublishedPort: 19132, Protocol: "tcp"},
+			{TargetPort: 8080, Protocol: "tcp"},
+		},
+	})
+
+	if info.Kind != KindContainer || info.Status != "running (healthy)" || info.Uptime != "12 minutes" {
+		t.Errorf("unexpected container info: %+v", info)
+	}
+	if !slices.Equal(info.Ports, []string{"19132->5432/tcp"}) {
+		t.Errorf("expected deduplicated published ports, got %v", info.Ports)
+	}
+	if info.Restarts != -1 {
+		t.Errorf("containers should not report restarts, got %d", info.Restarts)
+	}
+
+	exited := containerInfo(ServiceStatus{Service: "worker", State: "exited", Status: "Exited (1) 3 minutes ago"})
+	if exited.Uptime != "" || exited.Status != "exited" {
+		t.Errorf("unexpected exited container info: %+v", exited)
+	}
+}
+
+func TestSupervisedProcessInfo(t *testing.T) {
+	running := supervisedProcessInfo(ProcessState{
+		Name:      "web",
+		PID:       42,
+		Port:      19100,
+		Status:    ProcessRunning,
+		Ready:     true,
+		Restarts:  2,
+		StartedAt: time.Now().Add(-90 * time.Second),
+	})
+	if running.PID != 42 || running.Uptime != "1m" || running.Restarts != 2 || !slices.Equal(running.Ports, []string{"19100"}) {
+		t.Errorf("unexpected running process info: %+v", running)
+	}
+
+	failed := supervisedProcessInfo(ProcessState{Name: "api", PID: 7, Status: ProcessFailed, ExitCode: 2})
+	if failed.PID != 0 || failed.Status != "failed (exit 2)" || failed.Uptime != "" {
+		t.Errorf("unexpected failed process info: %+v", failed)
+	}
+
+	starting := supervisedProcessInfo(ProcessState{Name: "api", PID: 7, Status: ProcessRunning, StartedAt: time.Now()})
+	if starting.Status != "running (not ready)" {
+		t.Errorf("expected not ready status, got %q", starting.Status)
+	}
+}
+
+func TestFormatUptime(t *testing.T) {
+	cases := map[time.Duration]string{
+		42 * time.Second:               "42s",
+		5*time.Minute + 30*time.Second: "5m",
+		3*time.Hour + 12*time.Minute:   "3h12m",
+		50 * time.Hour:                 "2d2h",
+	}
+	for d, want := range cases {
+		if got := formatUptime(d); got != want {
+			t.Errorf("formatUptime(%s) = %s, want %s", d, got, want)
+		}
+	}
+}
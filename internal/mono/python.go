@@ -0,0 +1,157 @@
+package mono
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	venvDir              = ".venv"
+	pythonVersionCommand = "python3 --version || python --version"
+)
+
+var virtualEnvPattern = regexp.MustCompile(`VIRTUAL_ENV=['"]?(/[^'"\s]+)`)
+
+func pythonEnv(artifacts []ArtifactConfig) []string {
+	for _, artifact := range artifacts {
+		for _, p := range artifact.Paths {
+			if artifact.PathType(p) == "poetry" {
+				return []string{"POETRY_VIRTUALENVS_IN_PROJECT=true"}
+			}
+		}
+	}
+	return nil
+}
+
+func shouldSkipVenvPath(relPath string) bool {
+	return strings.HasSuffix(relPath, ".pyc") || strings.Contains("/"+relPath, "/__pycache__/")
+}
+
+func relocateVenv(venvPath string) error {
+	origin, err := venvOrigin(venvPath)
+	if err != nil || origin == "" {
+		return err
+	}
+	oldRoot, newRoot, ok := cargoPathRemap(origin, venvPath)
+	if !ok {
+		return nil
+	}
+
+	files, err := venvTextFiles(venvPath)
+	if err != nil {
+		return err
+	}
+	pattern := regexp.MustCompile(regexp.QuoteMeta(oldRoot) + `([^\w.-]|$)`)
+	replacement := strings.ReplaceAll(newRoot, "$", "$$") + "${1}"
+	for _, path := range files {
+		if err := rewriteFile(path, pattern, replacement); err != nil {
+			return fmt.Errorf("failed to rewrite %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func venvOrigin(venvPath string) (string, error) {
+	entries, err := os.ReadDir(filepath.Join(venvPath, "bin"))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(venvPath, "bin", "activate"))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if m := virtualEnvPattern.FindSubmatch(data); m != nil {
+		return filepath.Clean(string(m[1])), nil
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		interpreter, err := scriptInterpreter(filepath.Join(venvPath, "bin", entry.Name()))
+		if err != nil {
+			return "", err
+		}
+		if filepath.Base(filepath.Dir(interpreter)) == "bin" && strings.HasPrefix(filepath.Base(interpreter), "python") {
+			return filepath.Dir(filepath.Dir(interpreter)), nil
+		}
+	}
+	return "", nil
+}
+
+func scriptInterpreter(path string) (string, error) {
+	head, err := readHead(path, 512)
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := bytes.Cut(head, []byte("\n"))
+	interpreter, ok := strings.CutPrefix(strings.TrimSpace(string(line)), "#!")
+	if !ok {
+		return "", nil
+	}
+	fields := strings.Fields(interpreter)
+	if len(fields) == 0 || !filepath.IsAbs(fields[0]) {
+		return "", nil
+	}
+	return fields[0], nil
+}
+
+func venvTextFiles(venvPath string) ([]string, error) {
+	files := []string{filepath.Join(venvPath, "pyvenv.cfg")}
+
+	entries, err := os.ReadDir(filepath.Join(venvPath, "bin"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		path := filepath.Join(venvPath, "bin", entry.Name())
+		script, err := isScript(path)
+		if err != nil {
+			return nil, err
+		}
+		if script || strings.HasPrefix(entry.Name(), "activate") {
+			files = append(files, path)
+		}
+	}
+
+	pths, err := filepath.Glob(filepath.Join(venvPath, "lib", "python*", "site-packages", "*.pth"))
+	if err != nil {
+		return nil, err
+	}
+	return append(files, pths...), nil
+}
+
+func isScript(path string) (bool, error) {
+	head, err := readHead(path, 2)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(head, []byte("#!")), nil
+}
+
+func readHead(path string, size int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	head := make([]byte, size)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return head[:n], nil
+}
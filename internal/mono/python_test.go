@@ -0,0 +1,132 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDetectPythonArtifacts(t *testing.T) {
+	testDir := t.TempDir()
+
+	for _, name := range []string{"poetry.lock", "requirements.txt", "tools/uv.lock", "scripts/requirements.txt", ".venv/lib/python3.12/site-packages/pkg/requirements.txt"} {
+		path := filepath.Join(testDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	artifacts := detectArtifacts(testDir)
+	byName := make(map[string]ArtifactConfig)
+	for _, a := range artifacts {
+		byName[a.Name] = a
+	}
+	if len(artifacts) != 3 {
+		t.Fatalf("expected poetry, pip-scripts and uv-tools artifacts, got %+v", artifacts)
+	}
+
+	poetry, ok := byName["poetry"]
+	if !ok {
+		t.Fatalf("expected a poetry artifact, got %+v", artifacts)
+	}
+	if !slices.Equal(poetry.Paths, []ArtifactPath{{Path: ".venv", Type: "poetry"}}) {
+		t.Errorf("expected the poetry artifact to cache .venv, got %v", poetry.Paths)
+	}
+	if !slices.Equal(poetry.KeyFiles, []string{"poetry.lock"}) || !slices.Equal(poetry.KeyCommands, []string{pythonVersionCommand}) {
+		t.Errorf("expected the poetry artifact to be keyed on its lockfile and python version, got %v %v", poetry.KeyFiles, poetry.KeyCommands)
+	}
+
+	uv, ok := byName["uv-tools"]
+	if !ok {
+		t.Fatalf("expected a uv-tools artifact, got %+v", artifacts)
+	}
+	if !slices.Equal(uv.Paths, []ArtifactPath{{Path: "tools/.venv", Type: "uv"}}) || uv.WarmCommand != `cd "tools" && uv sync --frozen` {
+		t.Errorf("unexpected uv artifact %+v", uv)
+	}
+
+	if pip, ok := byName["pip-scripts"]; !ok || !slices.Equal(pip.Paths, []ArtifactPath{{Path: "scripts/.venv", Type: "pip"}}) {
+		t.Errorf("expected a pip-scripts artifact caching scripts/.venv, got %+v", artifacts)
+	}
+	if _, ok := byName["pip"]; ok {
+		t.Error("expected requirements.txt next to poetry.lock not to claim the same venv")
+	}
+}
+
+func TestPythonEnvVars(t *testing.T) {
+	if env := pythonEnv([]ArtifactConfig{{Name: "poetry", Paths: []ArtifactPath{{Path: ".venv"}}}}); !slices.Equal(env, []string{"POETRY_VIRTUALENVS_IN_PROJECT=true"}) {
+		t.Errorf("expected poetry to be told to keep its venv in the project, got %v", env)
+	}
+	if env := pythonEnv([]ArtifactConfig{{Name: "uv", Paths: []ArtifactPath{{Path: ".venv"}}}}); len(env) != 0 {
+		t.Errorf("expected no variables for uv, got %v", env)
+	}
+}
+
+func TestVenvRestoreFixes(t *testing.T) {
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	if !shouldSkipPath("lib/python3.12/site-packages/pkg/__pycache__/mod.cpython-312.pyc", "uv") || shouldSkipPath("lib/python3.12/site-packages/pkg/mod.py", "uv") {
+		t.Error("expected only bytecode to be skipped from a venv")
+	}
+
+	oldEnv := filepath.Join(t.TempDir(), "feature-a")
+	newEnv := filepath.Join(t.TempDir(), "feature-b")
+	oldVenv := filepath.Join(oldEnv, ".venv")
+	venv := filepath.Join(newEnv, ".venv")
+	sitePackages := filepath.Join(venv, "lib", "python3.12", "site-packages")
+	if err := os.MkdirAll(filepath.Join(venv, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(sitePackages, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"bin/activate":                           "VIRTUAL_ENV='" + oldVenv + "'\nexport VIRTUAL_ENV\n",
+		"bin/pip":                                "#!" + oldVenv + "/bin/python\nimport pip\n",
+		"bin/black":                              "#!/bin/sh\n'''exec' '" + oldVenv + "/bin/python3' \"$0\" \"$@\"\n' '''\n",
+		"pyvenv.cfg":                             "home = /usr/bin\ncommand = /usr/bin/python3 -m venv " + oldVenv + "\n",
+		"lib/python3.12/site-packages/_app.pth":  oldEnv + "/src\n",
+		"lib/python3.12/site-packages/other.pth": oldEnv + "-other/src\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(venv, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("/usr/bin/python3", filepath.Join(venv, "bin", "python")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cm.ApplyPostRestoreFixes("uv", venv); err != nil {
+		t.Fatalf("ApplyPostRestoreFixes failed: %v", err)
+	}
+
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(venv, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.ReplaceAll(content, oldVenv, venv)
+		if name == "lib/python3.12/site-packages/_app.pth" {
+			want = newEnv + "/src\n"
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+	if target, err := os.Readlink(filepath.Join(venv, "bin", "python")); err != nil || target != "/usr/bin/python3" {
+		t.Errorf("expected the interpreter symlink to be left alone, got %q %v", target, err)
+	}
+
+	if err := cm.ApplyPostRestoreFixes("uv", venv); err != nil {
+		t.Fatalf("ApplyPostRestoreFixes on a relocated venv failed: %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package mono
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+func expandEnv(value string, env []string) string {
+	vars := make(map[string]string, len(env))
+	for _, kv := range env {
+		if key, val, ok := strings.Cut(kv, "="); ok {
+			vars[key] = val
+		}
+	}
+	return os.Expand(value, func(key string) string {
+		return vars[key]
+	})
+}
+
+func (s *supervisor) probeReadiness(ctx context.Context, i int, proc ProcessState, env []string, ready ReadinessConfig, logOffset int64) {
+	var pattern *regexp.Regexp
+	if ready.Log != "" {
+		pattern = regexp.MustCompile(ready.Log)
+	}
+	tcp := expandEnv(ready.TCP, env)
+	url := expandEnv(ready.HTTP, env)
+
+	ticker := time.NewTicker(readinessInterval)
+	defer ticker.Stop()
+
+	for {
+		err := checkReadiness(ctx, tcp, url, pattern, proc.Log, logOffset)
+		if err == nil {
+			s.logger.Log("process %s is ready", proc.Name)
+			s.update(i, func(p *ProcessState) {
+				if p.Status == ProcessRunning {
+					p.Ready = true
+				}
+			})
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func checkReadiness(ctx context.Context, tcp, url string, pattern *regexp.Regexp, logPath string, logOffset int64) error {
+	if tcp != "" {
+		if err := checkTCP(ctx, tcp); err != nil {
+			return err
+		}
+	}
+	if url != "" {
+		if err := checkHTTP(ctx, url, 0); err != nil {
+			return err
+		}
+	}
+	if pattern != nil {
+		if err := checkLog(pattern, logPath, logOffset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkTCP(ctx context.Context, address string) error {
+	if !strings.Contains(address, ":") {
+		address = net.JoinHostPort("127.0.0.1", address)
+	}
+	dialer := net.Dialer{Timeout: time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func checkHTTP(ctx context.Context, url string, status int) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if status != 0 && resp.StatusCode != status {
+		return fmt.Errorf("%s returned %s, want %d", url, resp.Status, status)
+	}
+	if status == 0 && resp.StatusCode >= 400 {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return nil
+}
+
+func checkLog(pattern *regexp.Regexp, path string, offset int64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	if !pattern.Match(data) {
+		return fmt.Errorf("log does not match %s yet", pattern)
+	}
+	return nil
+}
+
+func WaitForProcesses(ctx context.Context, envName string, cfg *Config, logger *FileLogger) error {
+	deadlines := make(map[string]time.Time)
+	for _, name := range cfg.ProcessNames() {
+		if proc := cfg.Processes[name]; proc.Ready.enabled() {
+			deadlines[name] = time.Now().Add(proc.Ready.Timeout)
+		}
+	}
+	if len(deadlines) == 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(readinessInterval)
+	defer ticker.Stop()
+
+	for {
+		state, err := ReadSupervisorState(envName)
+		if err != nil {
+			return err
+		}
+		if !state.Running() {
+			return fmt.Errorf("process supervisor is not running")
+		}
+
+		pending := 0
+		for _, p := range state.Processes {
+			deadline, ok := deadlines[p.Name]
+			if !ok || p.Ready {
+				continue
+			}
+			if p.Status == ProcessExited || p.Status == ProcessFailed {
+				return fmt.Errorf("process %s %s with code %d before becoming ready (see %s)", p.Name, p.Status, p.ExitCode, p.Log)
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("process %s not ready after %s (see %s)", p.Name, cfg.Processes[p.Name].Ready.Timeout, p.Log)
+			}
+			pending++
+		}
+		if pending == 0 {
+			logger.Log("processes ready")
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for processes %w", ErrInterrupted)
+		case <-ticker.C:
+		}
+	}
+}
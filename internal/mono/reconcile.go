@@ -0,0 +1,262 @@
+package mono
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type ReconcileResult struct {
+	Repaired   []string
+	Unresolved []HealthCheck
+}
+
+func Reconcile(path string) (_ *ReconcileResult, err error) {
+	envName := EnvName(path)
+
+	logger, err := NewFileLogger(envName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer logger.Close()
+
+	logger.Log("mono reconcile %s", path)
+
+	ctx, stopSignals := notifyInterrupt("reconcile")
+	defer stopSignals()
+
+	lock, err := AcquireEnvLock(ctx, envName, "reconcile", os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, lock.Release())
+	}()
+
+	status, err := StartStatusServer(envName, "reconcile")
+	if err != nil {
+		logger.Log("warning: failed to start status server: %v", err)
+	}
+	defer status.Close()
+	logger.SetStatus(status)
+
+	status.SetPhase("checking health")
+	report, err := Health(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReconcileResult{}
+	if report.Healthy() {
+		logger.Log("no drift detected")
+		return result, nil
+	}
+
+	db, err := OpenDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	env, err := db.GetEnvironmentByPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("environment not found: %s", path)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.ApplyDefaults(path)
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cache: %w", err)
+	}
+
+	rootPath := ""
+	if env.RootPath.Valid {
+		rootPath = env.RootPath.String
+	}
+
+	composeDir := path
+	if env.ComposeDir.Valid && env.ComposeDir.String != "" {
+		composeDir = filepath.Join(path, env.ComposeDir.String)
+	}
+
+	var failedArtifacts []HealthCheck
+	servicesDown := false
+	var sessionCheck *HealthCheck
+
+	for _, check := range report.Checks {
+		if check.OK {
+			continue
+		}
+		switch check.Kind {
+		case checkDataDir:
+			dataDir, err := DataDir(envName)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.MkdirAll(dataDir, 0755); err != nil {
+				result.Unresolved = append(result.Unresolved, check)
+				logger.Log("warning: failed to recreate data directory: %v", err)
+				continue
+			}
+			result.Repaired = append(result.Repaired, "recreated data directory")
+		case checkArtifact:
+			failedArtifacts = append(failedArtifacts, check)
+		case checkService:
+			servicesDown = true
+		case checkTmux:
+			sessionCheck = &check
+		default:
+			result.Unresolved = append(result.Unresolved, check)
+		}
+	}
+
+	if len(failedArtifacts) > 0 {
+		status.SetPhase("restoring artifacts")
+		repaired, unresolved := reconcileArtifacts(ctx, cm, db, cfg, rootPath, path, failedArtifacts, logger)
+		result.Repaired = append(result.Repaired, repaired...)
+		result.Unresolved = append(result.Unresolved, unresolved...)
+	}
+
+	var allocations []Allocation
+	if env.DockerProject.Valid && env.DockerProject.String != "" {
+		composeProject, composeAllocations, err := buildComposeOverride(composeDir, envName, env.ID)
+		if err != nil {
+			return nil, err
+		}
+		allocations = composeAllocations
+
+		if servicesDown {
+			status.SetPhase("restarting containers")
+			monoComposePath := filepath.Join(composeDir, "docker-compose.mono.yml")
+			if err := db.RecordComposeOverride(monoComposePath, path); err != nil {
+				return nil, fmt.Errorf("failed to record compose override: %w", err)
+			}
+			if err := WriteComposeOverride(monoComposePath, composeProject); err != nil {
+				return nil, fmt.Errorf("failed to write compose override: %w", err)
+			}
+
+			logger.Log("running: docker compose -p %s up -d", env.DockerProject.String)
+			stdout := NewLogWriter(logger, "out")
+			stderr := NewLogWriter(logger, "err")
+			if err := StartContainers(ctx, env.DockerProject.String, composeDir, stdout, stderr); err != nil {
+				if err := interruptErr(ctx); err != nil {
+					return nil, err
+				}
+				logger.Log("warning: failed to restart containers: %v", err)
+				for _, check := range report.Checks {
+					if check.Kind == checkService && !check.OK {
+						result.Unresolved = append(result.Unresolved, check)
+					}
+				}
+			} else {
+				result.Repaired = append(result.Repaired, "restarted containers")
+			}
+		}
+	}
+
+	devcontainer, err := loadEnvironmentDevcontainer(env)
+	if err != nil {
+		return nil, err
+	}
+	if devcontainer != nil {
+		allocations = devcontainerAllocations(env.ID, devcontainer)
+
+		if servicesDown {
+			status.SetPhase("restarting devcontainer")
+			stdout := NewLogWriter(logger, "out")
+			stderr := NewLogWriter(logger, "err")
+			if err := StartDevcontainer(ctx, devcontainer, envName, path, allocations, stdout, stderr); err != nil {
+				if err := interruptErr(ctx); err != nil {
+					return nil, err
+				}
+				logger.Log("warning: failed to restart devcontainer: %v", err)
+				for _, check := range report.Checks {
+					if check.Kind == checkService && !check.OK {
+						result.Unresolved = append(result.Unresolved, check)
+					}
+				}
+			} else {
+				result.Repaired = append(result.Repaired, "restarted devcontainer")
+			}
+		}
+	}
+
+	if sessionCheck != nil {
+		status.SetPhase("creating tmux session")
+		cacheEnvVars := cm.EnvVars(cfg.Build, path, rootPath)
+		cacheEnvVars = append(cacheEnvVars, "MONO_CACHE_DIR="+cm.LocalCacheDir)
+		sessionEnv := buildScriptEnv(envName, env.ID, path, rootPath, allocations, cfg.Env, cacheEnvVars)
+		if devcontainer != nil {
+			if _, err := prepareDevcontainerEnv(envName, sessionEnv); err != nil {
+				return nil, err
+			}
+		}
+		tm := NewTmuxManager(SessionName(envName), path, cfg.Tmux)
+		if err := tm.CreateSession(sessionEnv); err != nil {
+			logger.Log("warning: failed to recreate tmux session: %v", err)
+			result.Unresolved = append(result.Unresolved, *sessionCheck)
+		} else {
+			result.Repaired = append(result.Repaired, "recreated tmux session")
+		}
+	}
+
+	for _, r := range result.Repaired {
+		logger.Log("reconcile: %s", r)
+	}
+
+	return result, nil
+}
+
+func reconcileArtifacts(ctx context.Context, cm *CacheManager, db *DB, cfg *Config, rootPath, path string, checks []HealthCheck, logger *FileLogger) ([]string, []HealthCheck) {
+	if rootPath == "" {
+		return nil, checks
+	}
+
+	byName := make(map[string]ArtifactConfig)
+	for _, artifact := range cfg.Build.Artifacts {
+		byName[artifact.Name] = artifact
+	}
+
+	var repaired []string
+	var unresolved []HealthCheck
+	for _, check := range checks {
+		artifact, ok := byName[check.Target]
+		if !ok {
+			unresolved = append(unresolved, check)
+			continue
+		}
+
+		entries, err := cm.PrepareArtifactCache([]ArtifactConfig{artifact}, rootPath, path)
+		if err != nil || len(entries) == 0 || !entries[0].Hit {
+			if err != nil {
+				logger.Log("warning: failed to prepare %s cache: %v", artifact.Name, err)
+			}
+			unresolved = append(unresolved, check)
+			continue
+		}
+
+		if err := cm.RestoreFromCache(entries[0], logger); err != nil {
+			logger.Log("warning: failed to restore %s: %v", artifact.Name, err)
+			unresolved = append(unresolved, check)
+			continue
+		}
+		if err := cm.RunPostRestore(ctx, cfg, entries[0], path, rootPath, logger); err != nil {
+			logger.Log("warning: %v", err)
+			unresolved = append(unresolved, check)
+			continue
+		}
+		if err := RecordArtifactKeys(db, path, entries); err != nil {
+			logger.Log("warning: %v", err)
+		}
+		repaired = append(repaired, fmt.Sprintf("restored %s from cache (key: %s)", artifact.Name, entries[0].Key))
+	}
+
+	return repaired, unresolved
+}
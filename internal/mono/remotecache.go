@@ -0,0 +1,534 @@
+package mono
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	remoteBlobName      = "mono-entry.tar.gz"
+	remoteEntryDir      = "entry"
+	remoteManifestName  = "manifest.json"
+	remoteSignatureName = "manifest.sig"
+)
+
+type RemoteCacheConfig struct {
+	URL     string        `yaml:"url"`
+	Upload  *bool         `yaml:"upload"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+func (c RemoteCacheConfig) enabled() bool {
+	return c.URL != ""
+}
+
+func (c RemoteCacheConfig) uploadEnabled() bool {
+	return c.enabled() && (c.Upload == nil || *c.Upload)
+}
+
+func (c RemoteCacheConfig) validate() error {
+	if !c.enabled() {
+		return nil
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("cache.remote.url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("cache.remote.url must be an http or https url, got %q", c.URL)
+	}
+	return nil
+}
+
+type remoteStore interface {
+	name() string
+	has(ctx context.Context, entry ArtifactCacheEntry) (bool, error)
+	put(ctx context.Context, entry ArtifactCacheEntry, bundle *remoteBundle) error
+	get(ctx context.Context, entry ArtifactCacheEntry) (*remoteBlob, error)
+}
+
+type remoteBundle struct {
+	path   string
+	digest string
+	size   int64
+}
+
+type remoteBlob struct {
+	body   io.ReadCloser
+	digest string
+	size   int64
+}
+
+func remoteStores(upload bool) []remoteStore {
+	cfg := globalConfig().Cache
+	var stores []remoteStore
+	if (upload && cfg.Remote.uploadEnabled()) || (!upload && cfg.Remote.enabled()) {
+		stores = append(stores, bazelStore{cfg.Remote})
+	}
+	if gha, ok := githubActionsStoreFromEnv(cfg); ok {
+		stores = append(stores, gha)
+	}
+	return stores
+}
+
+func remoteCacheEnabled() bool {
+	return len(remoteStores(false)) > 0
+}
+
+func remoteActionKey(entry ArtifactCacheEntry) string {
+	sum := sha256.Sum256([]byte("mono\x00" + entry.ProjectID + "\x00" + entry.Name + "\x00" + entry.Key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (cm *CacheManager) UploadToRemote(ctx context.Context, entry ArtifactCacheEntry) error {
+	stores := remoteStores(true)
+	if len(stores) == 0 || !dirExists(entry.CachePath) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, globalConfig().Cache.Remote.Timeout)
+	defer cancel()
+
+	var bundle *remoteBundle
+	var errs []error
+	for _, store := range stores {
+		exists, err := store.has(ctx, entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", store.name(), err))
+			continue
+		}
+		if exists {
+			continue
+		}
+		if bundle == nil {
+			if bundle, err = writeRemoteBundle(entry); err != nil {
+				return errors.Join(append(errs, err)...)
+			}
+			defer os.Remove(bundle.path)
+		}
+		if err := store.put(ctx, entry, bundle); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", store.name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func writeRemoteBundle(entry ArtifactCacheEntry) (*remoteBundle, error) {
+	items, err := remoteBundleItems(entry.CachePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", entry.CachePath, err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(entry.CachePath), filepath.Base(entry.CachePath)+cacheTmpSuffix+"-*."+ArtifactFormatTarGz)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create remote cache bundle: %w", err)
+	}
+
+	hash := sha256.New()
+	counter := &countingWriter{}
+	err = streamArchive(io.MultiWriter(f, hash, counter), items, ArtifactFormatTarGz)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to bundle %s for the remote cache: %w", entry.Name, err)
+	}
+	return &remoteBundle{path: f.Name(), digest: hex.EncodeToString(hash.Sum(nil)), size: counter.n}, nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+func remoteBundleItems(cachePath string) ([]*archiveItem, error) {
+	info, err := os.Lstat(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	items := []*archiveItem{{path: cachePath, rel: remoteEntryDir, info: info, done: make(chan struct{})}}
+
+	entryItems, err := collectArchiveItems(cachePath, "", pathFilter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range entryItems {
+		item.rel = remoteEntryDir + "/" + item.rel
+		items = append(items, item)
+	}
+
+	for name, path := range map[string]string{remoteManifestName: manifestPath(cachePath), remoteSignatureName: signaturePath(cachePath)} {
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, &archiveItem{path: path, rel: name, info: info, done: make(chan struct{})})
+	}
+	return items, nil
+}
+
+func (cm *CacheManager) FetchFromRemote(ctx context.Context, entry ArtifactCacheEntry) (bool, error) {
+	stores := remoteStores(false)
+	if len(stores) == 0 {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, globalConfig().Cache.Remote.Timeout)
+	defer cancel()
+
+	lock, err := cm.waitCacheLock(entry.CachePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to lock cache entry: %w", err)
+	}
+	defer cm.releaseCacheLock(lock)
+
+	if dirExists(entry.CachePath) {
+		return true, nil
+	}
+
+	var errs []error
+	for _, store := range stores {
+		fetched, err := fetchRemoteEntry(ctx, store, entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", store.name(), err))
+			continue
+		}
+		if fetched {
+			return true, nil
+		}
+	}
+	return false, errors.Join(errs...)
+}
+
+func (cm *CacheManager) restoreOrRefetch(ctx context.Context, entry ArtifactCacheEntry, refetch bool, logger *FileLogger) (bool, error) {
+	err := cm.RestoreFromCache(entry, logger)
+	if !refetch || !errors.Is(err, ErrCacheCorrupt) || !remoteCacheEnabled() {
+		return false, err
+	}
+	logger.Log("warning: %v, fetching %s from remote cache", err, entry.Name)
+	fetched, fetchErr := cm.FetchFromRemote(ctx, entry)
+	if fetchErr != nil || !fetched {
+		return false, errors.Join(err, fetchErr)
+	}
+	return true, cm.RestoreFromCache(entry, logger)
+}
+
+func fetchRemoteEntry(ctx context.Context, store remoteStore, entry ArtifactCacheEntry) (bool, error) {
+	blob, err := store.get(ctx, entry)
+	if err != nil || blob == nil {
+		return false, err
+	}
+	defer blob.body.Close()
+
+	f, err := os.CreateTemp(filepath.Dir(entry.CachePath), filepath.Base(entry.CachePath)+cacheTmpSuffix+"-*."+ArtifactFormatTarGz)
+	if err != nil {
+		return false, fmt.Errorf("failed to create remote cache download: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, hash), blob.body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to download %s: %w", entry.Name, err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); blob.digest != "" && (got != blob.digest || n != blob.size) {
+		return false, fmt.Errorf("blob for %s does not match its digest %s/%d, got %s/%d", entry.Name, blob.digest, blob.size, got, n)
+	}
+
+	return true, publishRemoteBundle(f.Name(), entry)
+}
+
+func publishRemoteBundle(bundle string, entry ArtifactCacheEntry) error {
+	tmpPath := entry.CachePath + cacheTmpSuffix
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return fmt.Errorf("failed to clear staging dir: %w", err)
+	}
+	defer os.RemoveAll(tmpPath)
+
+	if err := extractArchive(bundle, tmpPath, entry.Workers, newIOLimiter(entry.IOLimit)); err != nil {
+		return err
+	}
+	staged := filepath.Join(tmpPath, remoteEntryDir)
+	if !dirExists(staged) {
+		return fmt.Errorf("blob for %s has no %s directory", entry.Name, remoteEntryDir)
+	}
+
+	for name, dst := range map[string]string{remoteManifestName: manifestPath(staged), remoteSignatureName: signaturePath(staged)} {
+		src := filepath.Join(tmpPath, name)
+		if !fileExists(src) {
+			continue
+		}
+		if err := os.Rename(src, dst); err != nil {
+			return fmt.Errorf("failed to stage remote %s: %w", name, err)
+		}
+	}
+
+	status, problems, err := checkRemoteEntry(staged)
+	if err != nil {
+		return fmt.Errorf("failed to verify blob for %s: %w", entry.Name, err)
+	}
+	if status != VerifyOK {
+		return fmt.Errorf("%w: blob for %s failed verification (%s): %s", ErrCacheCorrupt, entry.Name, status, strings.Join(problems, "; "))
+	}
+
+	for _, path := range []string{manifestPath(staged), signaturePath(staged)} {
+		if !fileExists(path) {
+			continue
+		}
+		dst := entry.CachePath + strings.TrimPrefix(path, staged)
+		if err := os.Rename(path, dst); err != nil {
+			return fmt.Errorf("failed to publish remote %s: %w", filepath.Base(dst), err)
+		}
+	}
+	if err := syncTree(staged); err != nil {
+		return err
+	}
+	if err := os.Rename(staged, entry.CachePath); err != nil {
+		return fmt.Errorf("failed to publish cache entry: %w", err)
+	}
+	return syncParent(entry.CachePath)
+}
+
+type bazelStore struct {
+	cfg RemoteCacheConfig
+}
+
+func (s bazelStore) name() string {
+	return "remote cache"
+}
+
+func (s bazelStore) endpoint(kind, hash string) string {
+	return strings.TrimRight(s.cfg.URL, "/") + "/" + kind + "/" + hash
+}
+
+func (s bazelStore) has(ctx context.Context, entry ArtifactCacheEntry) (bool, error) {
+	return remoteHas(ctx, s.endpoint("ac", remoteActionKey(entry)))
+}
+
+func (s bazelStore) put(ctx context.Context, entry ArtifactCacheEntry, bundle *remoteBundle) error {
+	f, err := os.Open(bundle.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := remotePut(ctx, s.endpoint("cas", bundle.digest), f, bundle.size); err != nil {
+		return err
+	}
+	result := encodeActionResult(remoteBlobName, bundle.digest, bundle.size)
+	return remotePut(ctx, s.endpoint("ac", remoteActionKey(entry)), strings.NewReader(string(result)), int64(len(result)))
+}
+
+func (s bazelStore) get(ctx context.Context, entry ArtifactCacheEntry) (*remoteBlob, error) {
+	result, err := remoteGet(ctx, s.endpoint("ac", remoteActionKey(entry)))
+	if err != nil || result == nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(result)
+	if closeErr := result.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read action result: %w", err)
+	}
+	digest, size, err := decodeActionResult(data, remoteBlobName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid action result for %s: %w", entry.Name, err)
+	}
+
+	body, err := remoteGet(ctx, s.endpoint("cas", digest))
+	if err != nil || body == nil {
+		return nil, err
+	}
+	return &remoteBlob{body: body, digest: digest, size: size}, nil
+}
+
+func remoteHas(ctx context.Context, endpoint string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+	if err != nil {
+		return false, fmt.Errorf("invalid remote cache url: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("remote cache request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, nil
+	}
+	return false, fmt.Errorf("remote cache HEAD %s returned %s", endpoint, resp.Status)
+}
+
+func remoteGet(ctx context.Context, endpoint string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote cache url: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("remote cache request failed: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, nil
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return resp.Body, nil
+	}
+	resp.Body.Close()
+	return nil, fmt.Errorf("remote cache GET %s returned %s", endpoint, resp.Status)
+}
+
+func remotePut(ctx context.Context, endpoint string, body io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("invalid remote cache url: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote cache request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("remote cache PUT %s returned %s", endpoint, resp.Status)
+	}
+	return nil
+}
+
+func appendProtoBytes(b []byte, field int, value []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+func appendProtoVarint(b []byte, field int, value uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3)
+	return binary.AppendUvarint(b, value)
+}
+
+func encodeActionResult(path, hash string, size int64) []byte {
+	digest := appendProtoBytes(nil, 1, []byte(hash))
+	digest = appendProtoVarint(digest, 2, uint64(size))
+	file := appendProtoBytes(nil, 1, []byte(path))
+	file = appendProtoBytes(file, 2, digest)
+	return appendProtoBytes(nil, 2, file)
+}
+
+func decodeActionResult(data []byte, path string) (string, int64, error) {
+	var hash string
+	var size int64
+	found := false
+	err := walkProto(data, func(field int, _ uint64, file []byte) error {
+		if field != 2 || found {
+			return nil
+		}
+		var name string
+		var digest []byte
+		if err := walkProto(file, func(field int, _ uint64, value []byte) error {
+			switch field {
+			case 1:
+				name = string(value)
+			case 2:
+				digest = value
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if name != path {
+			return nil
+		}
+		found = true
+		return walkProto(digest, func(field int, v uint64, value []byte) error {
+			switch field {
+			case 1:
+				hash = string(value)
+			case 2:
+				size = int64(v)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	if !found || hash == "" {
+		return "", 0, fmt.Errorf("no output file %s", path)
+	}
+	return hash, size, nil
+}
+
+func walkProto(data []byte, fn func(field int, v uint64, value []byte) error) error {
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errors.New("malformed protobuf tag")
+		}
+		data = data[n:]
+
+		field := int(tag >> 3)
+		var v uint64
+		var value []byte
+		switch tag & 7 {
+		case 0:
+			v, n = binary.Uvarint(data)
+			if n <= 0 {
+				return errors.New("malformed protobuf varint")
+			}
+			data = data[n:]
+		case 1, 5:
+			width := 8
+			if tag&7 == 5 {
+				width = 4
+			}
+			if len(data) < width {
+				return errors.New("truncated protobuf field")
+			}
+			data = data[width:]
+		case 2:
+			l, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < l {
+				return errors.New("truncated protobuf field")
+			}
+			value = data[n : n+int(l)]
+			data = data[n+int(l):]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", tag&7)
+		}
+		if err := fn(field, v, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package mono
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type fakeBazelCache struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+	puts  []string
+}
+
+func (f *fakeBazelCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.URL.Path, "/ac/") && !strings.HasPrefix(r.URL.Path, "/cas/") {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.blobs[r.URL.Path] = data
+		f.puts = append(f.puts, r.URL.Path)
+	case http.MethodGet, http.MethodHead:
+		data, ok := f.blobs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestRemoteCacheRoundTrip(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+	t.Setenv(githubActionsCacheURLEnv, "")
+
+	server := &fakeBazelCache{blobs: make(map[string][]byte)}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	cfg := DefaultGlobalConfig()
+	cfg.Cache.Remote.URL = ts.URL + "/"
+	SetGlobalConfig(cfg)
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	logger, err := NewFileLogger("remote-test")
+	if err != nil {
+		t.Fatalf("NewFileLogger failed: %v", err)
+	}
+	defer logger.Close()
+
+	envDir := t.TempDir()
+	modules := filepath.Join(envDir, "node_modules")
+	if err := os.MkdirAll(filepath.Join(modules, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(modules, "pkg", "index.js"), []byte("module.exports = 1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("pkg/index.js", filepath.Join(modules, "link.js")); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := ArtifactCacheEntry{
+		Name:      "node_modules",
+		ProjectID: "proj",
+		Key:       "key123",
+		CachePath: filepath.Join(cm.LocalCacheDir, "proj", "node_modules", "key123"),
+		EnvPaths:  []string{modules},
+	}
+	if err := cm.StoreToCache(entry); err != nil {
+		t.Fatalf("StoreToCache failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := cm.UploadToRemote(ctx, entry); err != nil {
+		t.Fatalf("UploadToRemote failed: %v", err)
+	}
+	if len(server.puts) != 2 || !strings.HasPrefix(server.puts[0], "/cas/") || server.puts[1] != "/ac/"+remoteActionKey(entry) {
+		t.Fatalf("expected a cas then an ac upload, got %v", server.puts)
+	}
+	if err := cm.UploadToRemote(ctx, entry); err != nil {
+		t.Fatalf("second UploadToRemote failed: %v", err)
+	}
+	if len(server.puts) != 2 {
+		t.Errorf("expected an entry already on the remote not to be uploaded again, got %v", server.puts)
+	}
+
+	for _, p := range []string{entry.CachePath, manifestPath(entry.CachePath), signaturePath(entry.CachePath), modules} {
+		if err := os.RemoveAll(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fetched, err := cm.FetchFromRemote(ctx, entry)
+	if err != nil || !fetched {
+		t.Fatalf("FetchFromRemote = %v, %v", fetched, err)
+	}
+	if status, problems, err := checkEntry(entry.CachePath, false); err != nil || status != VerifyOK {
+		t.Fatalf("expected the fetched entry to verify, got %s %v %v", status, problems, err)
+	}
+	if err := cm.RestoreFromCache(entry, logger); err != nil {
+		t.Fatalf("RestoreFromCache failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(modules, "link.js"))
+	if err != nil || string(data) != "module.exports = 1" {
+		t.Errorf("unexpected restored content %q: %v", data, err)
+	}
+
+	cached := filepath.Join(entry.CachePath, "node_modules", "pkg", "index.js")
+	if err := os.Remove(cached); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cached, []byte("module.exports = 22"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if refetched, err := cm.restoreOrRefetch(ctx, entry, false, logger); refetched || !errors.Is(err, ErrCacheCorrupt) {
+		t.Fatalf("expected a corrupt entry not to be refetched when disabled, got %v, %v", refetched, err)
+	}
+	if refetched, err := cm.restoreOrRefetch(ctx, entry, true, logger); !refetched || err != nil {
+		t.Fatalf("expected a corrupt entry to be refetched from the remote, got %v, %v", refetched, err)
+	}
+	data, err = os.ReadFile(filepath.Join(modules, "pkg", "index.js"))
+	if err != nil || string(data) != "module.exports = 1" {
+		t.Errorf("unexpected refetched content %q: %v", data, err)
+	}
+
+	missing := entry
+	missing.Key = "other"
+	missing.CachePath = filepath.Join(cm.LocalCacheDir, "proj", "node_modules", "other")
+	if fetched, err := cm.FetchFromRemote(ctx, missing); err != nil || fetched {
+		t.Errorf("expected a remote miss, got %v, %v", fetched, err)
+	}
+
+	for path := range server.blobs {
+		if strings.HasPrefix(path, "/cas/") {
+			server.blobs[path] = []byte("tampered")
+		}
+	}
+	if err := os.RemoveAll(entry.CachePath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cm.FetchFromRemote(ctx, entry); err == nil || !strings.Contains(err.Error(), "does not match its digest") {
+		t.Errorf("expected a tampered blob to be rejected, got %v", err)
+	}
+	if dirExists(entry.CachePath) {
+		t.Error("expected a rejected blob not to be published")
+	}
+}
+
+func TestPublishRemoteBundleRejectsUnverifiedEntries(t *testing.T) {
+	for name, tamper := range map[string]func(t *testing.T, entry ArtifactCacheEntry){
+		"swapped content": func(t *testing.T, entry ArtifactCacheEntry) {
+			cached := filepath.Join(entry.CachePath, "node_modules", "index.js")
+			if err := os.Remove(cached); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(cached, []byte("module.exports = 22"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		},
+		"unsigned": func(t *testing.T, entry ArtifactCacheEntry) {
+			if err := os.Remove(signaturePath(entry.CachePath)); err != nil {
+				t.Fatal(err)
+			}
+		},
+		"no manifest": func(t *testing.T, entry ArtifactCacheEntry) {
+			if err := removeManifest(entry.CachePath); err != nil {
+				t.Fatal(err)
+			}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("MONO_HOME", t.TempDir())
+
+			cm, err := NewCacheManager()
+			if err != nil {
+				t.Fatalf("NewCacheManager failed: %v", err)
+			}
+
+			modules := filepath.Join(t.TempDir(), "node_modules")
+			if err := os.MkdirAll(modules, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(modules, "index.js"), []byte("module.exports = 1"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			entry := ArtifactCacheEntry{
+				Name:      "node_modules",
+				ProjectID: "proj",
+				Key:       "key123",
+				CachePath: filepath.Join(cm.LocalCacheDir, "proj", "node_modules", "key123"),
+				EnvPaths:  []string{modules},
+			}
+			if err := cm.StoreToCache(entry); err != nil {
+				t.Fatalf("StoreToCache failed: %v", err)
+			}
+			tamper(t, entry)
+
+			bundle, err := writeRemoteBundle(entry)
+			if err != nil {
+				t.Fatalf("writeRemoteBundle failed: %v", err)
+			}
+			defer os.Remove(bundle.path)
+
+			for _, p := range []string{entry.CachePath, manifestPath(entry.CachePath), signaturePath(entry.CachePath)} {
+				if err := os.RemoveAll(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := publishRemoteBundle(bundle.path, entry); !errors.Is(err, ErrCacheCorrupt) {
+				t.Fatalf("expected the entry to be rejected, got %v", err)
+			}
+			for _, p := range []string{entry.CachePath, manifestPath(entry.CachePath), signaturePath(entry.CachePath)} {
+				if fileExists(p) || dirExists(p) {
+					t.Errorf("expected %s not to be published", p)
+				}
+			}
+		})
+	}
+}
+
+func TestActionResultEncoding(t *testing.T) {
+	data := encodeActionResult(remoteBlobName, "abc123", 42)
+	hash, size, err := decodeActionResult(data, remoteBlobName)
+	if err != nil || hash != "abc123" || size != 42 {
+		t.Errorf("decodeActionResult = %q, %d, %v", hash, size, err)
+	}
+	if _, _, err := decodeActionResult(data, "other"); err == nil {
+		t.Error("expected a missing output file to fail")
+	}
+	if _, _, err := decodeActionResult([]byte{0x12, 0x05, 0x01}, remoteBlobName); err == nil {
+		t.Error("expected a truncated action result to fail")
+	}
+}
+
+func TestRemoteCacheConfigValidation(t *testing.T) {
+	for url, ok := range map[string]bool{
+		"":                            true,
+		"http://cache.internal:8080":  true,
+		"https://user:pw@cache.local": true,
+		"grpc://cache.internal:9092":  false,
+		"cache.internal":              false,
+	} {
+		err := RemoteCacheConfig{URL: url}.validate()
+		if (err == nil) != ok {
+			t.Errorf("validate(%q) = %v", url, err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package mono
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (cm *CacheManager) findRestoreKey(artifact ArtifactConfig, rootPath, key string) (string, error) {
+	if !artifact.RestoreFallback {
+		return "", nil
+	}
+
+	artifactDir := filepath.Dir(cm.artifactCachePath(artifact, rootPath, key))
+	dirEntries, err := os.ReadDir(artifactDir)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to list cache entries for %s: %w", artifact.Name, err)
+	}
+
+	type candidate struct {
+		key      string
+		storedAt time.Time
+	}
+	var candidates []candidate
+	for _, d := range dirEntries {
+		if !d.IsDir() || d.Name() == key || strings.Contains(d.Name(), cacheTmpSuffix) {
+			continue
+		}
+		info, err := os.Stat(manifestPath(filepath.Join(artifactDir, d.Name())))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		candidates = append(candidates, candidate{key: d.Name(), storedAt: info.ModTime()})
+	}
+	if len(candidates) == 0 {
+		return "", nil
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].storedAt.After(candidates[j].storedAt) })
+	return candidates[0].key, nil
+}
+
+func (cm *CacheManager) RestoreFromRestoreKey(entry ArtifactCacheEntry, logger *FileLogger) error {
+	if entry.RestoreKey == "" {
+		return fmt.Errorf("no fallback entry for %s", entry.Name)
+	}
+	stale := entry
+	stale.Key = entry.RestoreKey
+	stale.CachePath = filepath.Join(filepath.Dir(entry.CachePath), entry.RestoreKey)
+	stale.VerifyLockfile = false
+	return cm.RestoreFromCache(stale, logger)
+}
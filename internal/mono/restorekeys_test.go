@@ -0,0 +1,96 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRestoreFallback(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	logger, err := NewFileLogger("restore-keys-test")
+	if err != nil {
+		t.Fatalf("NewFileLogger failed: %v", err)
+	}
+	defer logger.Close()
+
+	rootPath := t.TempDir()
+	envPath := t.TempDir()
+	artifact := ArtifactConfig{
+		Name:            "cargo",
+		KeyFiles:        []string{"Cargo.lock"},
+		Paths:           []ArtifactPath{{Path: "target"}},
+		RestoreFallback: true,
+	}
+
+	store := func(lockfile, output string, storedAt time.Time) ArtifactCacheEntry {
+		if err := os.WriteFile(filepath.Join(envPath, "Cargo.lock"), []byte(lockfile), 0644); err != nil {
+			t.Fatal(err)
+		}
+		target := filepath.Join(envPath, "target")
+		if err := os.RemoveAll(target); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(target, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(target, "out.txt"), []byte(output), 0644); err != nil {
+			t.Fatal(err)
+		}
+		entries, err := cm.PrepareArtifactCache([]ArtifactConfig{artifact}, rootPath, envPath)
+		if err != nil {
+			t.Fatalf("PrepareArtifactCache failed: %v", err)
+		}
+		if err := cm.StoreToCache(entries[0]); err != nil {
+			t.Fatalf("StoreToCache failed: %v", err)
+		}
+		if err := os.Chtimes(manifestPath(entries[0].CachePath), storedAt, storedAt); err != nil {
+			t.Fatal(err)
+		}
+		return entries[0]
+	}
+	older := store("lock v1", "v1", time.Now().Add(-2*time.Hour))
+	newer := store("lock v2", "v2", time.Now().Add(-time.Hour))
+
+	if err := os.WriteFile(filepath.Join(envPath, "Cargo.lock"), []byte("lock v3"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(envPath, "target")); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := cm.PrepareArtifactCache([]ArtifactConfig{artifact}, rootPath, envPath)
+	if err != nil {
+		t.Fatalf("PrepareArtifactCache failed: %v", err)
+	}
+	entry := entries[0]
+	if entry.Hit || entry.RestoreKey != newer.Key {
+		t.Fatalf("expected a miss falling back to the newest entry %s, got hit=%v restore key %q", newer.Key, entry.Hit, entry.RestoreKey)
+	}
+
+	if err := cm.RestoreFromRestoreKey(entry, logger); err != nil {
+		t.Fatalf("RestoreFromRestoreKey failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(envPath, "target", "out.txt"))
+	if err != nil || string(data) != "v2" {
+		t.Errorf("expected the stale entry's output, got %q: %v", data, err)
+	}
+
+	if entry.RestoreKey == older.Key {
+		t.Errorf("expected the older entry %s to be passed over", older.Key)
+	}
+
+	artifact.RestoreFallback = false
+	entries, err = cm.PrepareArtifactCache([]ArtifactConfig{artifact}, rootPath, envPath)
+	if err != nil {
+		t.Fatalf("PrepareArtifactCache failed: %v", err)
+	}
+	if entries[0].RestoreKey != "" {
+		t.Errorf("expected no fallback without restore_fallback, got %q", entries[0].RestoreKey)
+	}
+}
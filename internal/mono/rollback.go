@@ -0,0 +1,39 @@
+package mono
+
+type rollbackStep struct {
+	name string
+	undo func() error
+}
+
+type rollback struct {
+	logger    *FileLogger
+	steps     []rollbackStep
+	committed bool
+}
+
+func newRollback(logger *FileLogger) *rollback {
+	return &rollback{logger: logger}
+}
+
+func (r *rollback) add(name string, undo func() error) {
+	r.steps = append(r.steps, rollbackStep{name: name, undo: undo})
+}
+
+func (r *rollback) commit() {
+	r.committed = true
+}
+
+func (r *rollback) run() {
+	if r.committed || len(r.steps) == 0 {
+		return
+	}
+	for i := len(r.steps) - 1; i >= 0; i-- {
+		step := r.steps[i]
+		if err := step.undo(); err != nil {
+			r.logger.Log("warning: failed to undo %s: %v", step.name, err)
+		} else {
+			r.logger.Log("rolled back %s", step.name)
+		}
+	}
+	r.steps = nil
+}
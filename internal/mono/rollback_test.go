@@ -0,0 +1,65 @@
+package mono
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestRollbackUndoesInReverseOrder(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	logger, err := NewFileLogger("rollback-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	var order []string
+	tx := newRollback(logger)
+	tx.add("first", func() error {
+		order = append(order, "first")
+		return nil
+	})
+	tx.add("second", func() error {
+		order = append(order, "second")
+		return errors.New("boom")
+	})
+	tx.add("third", func() error {
+		order = append(order, "third")
+		return nil
+	})
+	tx.run()
+
+	if want := []string{"third", "second", "first"}; !slices.Equal(order, want) {
+		t.Errorf("expected %v, got %v", want, order)
+	}
+
+	tx.run()
+	if len(order) != 3 {
+		t.Errorf("expected steps to run once, got %v", order)
+	}
+}
+
+func TestRollbackSkipsAfterCommit(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	logger, err := NewFileLogger("rollback-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	ran := false
+	tx := newRollback(logger)
+	tx.add("step", func() error {
+		ran = true
+		return nil
+	})
+	tx.commit()
+	tx.run()
+
+	if ran {
+		t.Error("expected committed rollback to skip undo steps")
+	}
+}
@@ -0,0 +1,87 @@
+package mono
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func ResolveRootPath(envPath, explicit string) (string, error) {
+	if explicit != "" {
+		abs, err := filepath.Abs(explicit)
+		if err != nil {
+			return "", fmt.Errorf("invalid root path: %w", err)
+		}
+		if !dirExists(abs) {
+			return "", fmt.Errorf("root path does not exist: %s", abs)
+		}
+		return abs, nil
+	}
+
+	if rootPath := os.Getenv("CONDUCTOR_ROOT_PATH"); rootPath != "" {
+		return rootPath, nil
+	}
+
+	return DiscoverGitRoot(envPath)
+}
+
+func DiscoverGitRoot(envPath string) (string, error) {
+	if !dirExists(envPath) {
+		return "", nil
+	}
+
+	result, err := Command("git", "-C", envPath, "worktree", "list", "--porcelain").RunCapture()
+	if errors.Is(err, exec.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to list git worktrees: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return "", nil
+	}
+
+	mainWorktree := ""
+	scanner := bufio.NewScanner(bytes.NewReader(result.Stdout))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if worktree, ok := strings.CutPrefix(line, "worktree "); ok {
+			mainWorktree = worktree
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to parse git worktree list: %w", err)
+	}
+
+	if mainWorktree == "" || !dirExists(mainWorktree) {
+		return "", nil
+	}
+
+	same, err := samePath(mainWorktree, envPath)
+	if err != nil {
+		return "", err
+	}
+	if same {
+		return "", nil
+	}
+
+	return mainWorktree, nil
+}
+
+func samePath(a, b string) (bool, error) {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(ai, bi), nil
+}
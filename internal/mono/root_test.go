@@ -0,0 +1,72 @@
+package mono
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscoverGitRootFromWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	repo := filepath.Join(dir, "repo")
+	worktree := filepath.Join(dir, "wt")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, output)
+		}
+	}
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "init")
+	git("worktree", "add", "-q", worktree)
+
+	root, err := DiscoverGitRoot(worktree)
+	if err != nil {
+		t.Fatalf("discovery failed: %v", err)
+	}
+	same, err := samePath(root, repo)
+	if err != nil || !same {
+		t.Errorf("expected root %s, got %s", repo, root)
+	}
+
+	root, err = DiscoverGitRoot(repo)
+	if err != nil {
+		t.Fatalf("discovery failed: %v", err)
+	}
+	if root != "" {
+		t.Errorf("expected no root for main worktree, got %s", root)
+	}
+
+	root, err = DiscoverGitRoot(t.TempDir())
+	if err != nil {
+		t.Fatalf("discovery failed: %v", err)
+	}
+	if root != "" {
+		t.Errorf("expected no root outside git, got %s", root)
+	}
+}
+
+func TestResolveRootPathPrefersExplicit(t *testing.T) {
+	explicit := t.TempDir()
+	t.Setenv("CONDUCTOR_ROOT_PATH", t.TempDir())
+
+	root, err := ResolveRootPath(t.TempDir(), explicit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != explicit {
+		t.Errorf("expected %s, got %s", explicit, root)
+	}
+}
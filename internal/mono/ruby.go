@@ -0,0 +1,35 @@
+package mono
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const bundlePath = "vendor/bundle"
+
+func bundlerEnv(artifacts []ArtifactConfig) []string {
+	for _, artifact := range artifacts {
+		for _, p := range artifact.Paths {
+			path := filepath.ToSlash(p.Path)
+			if artifact.PathType(p) == "bundler" && (path == bundlePath || strings.HasSuffix(path, "/"+bundlePath)) {
+				return []string{"BUNDLE_PATH=" + bundlePath}
+			}
+		}
+	}
+	return nil
+}
+
+func isNativeExtension(relPath string) bool {
+	switch filepath.Ext(relPath) {
+	case ".so", ".bundle", ".dylib", ".dll":
+		return true
+	}
+	return false
+}
+
+func (o SeedOptions) linkFor(relPath string) LinkMode {
+	if o.pathType() == "bundler" && (o.Link == "" || o.Link == LinkHardlink) && isNativeExtension(relPath) {
+		return LinkCopy
+	}
+	return o.Link
+}
@@ -0,0 +1,90 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDetectBundlerArtifacts(t *testing.T) {
+	testDir := t.TempDir()
+
+	for _, name := range []string{"Gemfile.lock", "docs/Gemfile.lock", "vendor/bundle/ruby/3.3.0/gems/rails-7.1.0/Gemfile.lock"} {
+		path := filepath.Join(testDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	artifacts := detectArtifacts(testDir)
+	if len(artifacts) != 2 {
+		t.Fatalf("expected bundler and bundler-docs artifacts, got %+v", artifacts)
+	}
+	a := artifacts[0]
+	if a.Name != "bundler" || !slices.Equal(a.Paths, []ArtifactPath{{Path: "vendor/bundle", Type: "bundler"}}) {
+		t.Errorf("unexpected bundler artifact %+v", a)
+	}
+	if !slices.Equal(a.KeyFiles, []string{"Gemfile.lock"}) || !slices.Equal(a.KeyCommands, []string{"ruby --version"}) || a.WarmCommand != "bundle install" {
+		t.Errorf("expected the bundler artifact to be keyed on its lockfile and ruby version, got %+v", a)
+	}
+	if docs := artifacts[1]; docs.Name != "bundler-docs" || docs.Paths[0].Path != "docs/vendor/bundle" {
+		t.Errorf("unexpected nested bundler artifact %+v", docs)
+	}
+
+	if env := bundlerEnv(artifacts); !slices.Equal(env, []string{"BUNDLE_PATH=vendor/bundle"}) {
+		t.Errorf("expected bundler to install into vendor/bundle, got %v", env)
+	}
+	if env := bundlerEnv([]ArtifactConfig{{Name: "bundler", Paths: []ArtifactPath{{Path: ".gems"}}}}); len(env) != 0 {
+		t.Errorf("expected a custom bundle path to be left to the project, got %v", env)
+	}
+}
+
+func TestBundlerRestoreCopiesNativeExtensions(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "bundle")
+	ext := filepath.Join("ruby", "3.3.0", "extensions", "x86_64-linux", "3.3.0", "nokogiri-1.16.0")
+	lib := filepath.Join("ruby", "3.3.0", "gems", "nokogiri-1.16.0", "lib")
+	for _, dir := range []string{ext, lib} {
+		if err := os.MkdirAll(filepath.Join(src, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]os.FileMode{
+		filepath.Join(ext, "nokogiri", "nokogiri.so"): 0755,
+		filepath.Join(ext, "gem.build_complete"):      0644,
+		filepath.Join(lib, "nokogiri.rb"):             0644,
+	}
+	for name, mode := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(src, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(src, name), []byte(name), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := filepath.Join(t.TempDir(), "bundle")
+	if err := SeedDirectory(src, dst, SeedOptions{ArtifactName: "bundler", Link: LinkHardlink}); err != nil {
+		t.Fatalf("SeedDirectory failed: %v", err)
+	}
+
+	for name, mode := range files {
+		info, err := os.Stat(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatalf("expected %s to be restored: %v", name, err)
+		}
+		if info.Mode().Perm() != mode {
+			t.Errorf("expected %s to keep mode %o, got %o", name, mode, info.Mode().Perm())
+		}
+		same, err := sameInode(filepath.Join(src, name), filepath.Join(dst, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if same == isNativeExtension(name) {
+			t.Errorf("%s: shared inode = %v, want native extensions copied and everything else linked", name, same)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package mono
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSccachePort = 4226
+	sccachePortRange   = 1000
+)
+
+type SccacheConfig struct {
+	Dir       string `yaml:"dir"`
+	CacheSize string `yaml:"cache_size"`
+}
+
+type SccacheStats struct {
+	Running       bool
+	CompileCount  int64
+	Hits          int64
+	Misses        int64
+	CacheSize     int64
+	MaxCacheSize  int64
+	CacheLocation string
+}
+
+func (s SccacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total) * 100
+}
+
+type sccacheCounts struct {
+	Counts map[string]int64 `json:"counts"`
+}
+
+func (c sccacheCounts) total() int64 {
+	var n int64
+	for _, v := range c.Counts {
+		n += v
+	}
+	return n
+}
+
+type sccacheStatsJSON struct {
+	Stats struct {
+		CompileRequests int64         `json:"compile_requests"`
+		CacheHits       sccacheCounts `json:"cache_hits"`
+		CacheMisses     sccacheCounts `json:"cache_misses"`
+	} `json:"stats"`
+	CacheLocation string `json:"cache_location"`
+	CacheSize     *int64 `json:"cache_size"`
+	MaxCacheSize  *int64 `json:"max_cache_size"`
+}
+
+type SccacheServer struct {
+	RootPath string
+	Dir      string
+	Port     int
+	Shared   bool
+}
+
+type ProjectSccacheStats struct {
+	Server SccacheServer
+	Stats  SccacheStats
+}
+
+func SccacheEnv() []string {
+	cfg := globalConfig().Sccache
+	var vars []string
+	if cfg.Dir != "" {
+		vars = append(vars, "SCCACHE_DIR="+cfg.Dir)
+	}
+	if cfg.CacheSize != "" {
+		vars = append(vars, "SCCACHE_CACHE_SIZE="+cfg.CacheSize)
+	}
+	return vars
+}
+
+func sccachePort() int {
+	if v := os.Getenv("SCCACHE_SERVER_PORT"); v != "" {
+		if port, err := strconv.Atoi(v); err == nil {
+			return port
+		}
+	}
+	return defaultSccachePort
+}
+
+func projectSccachePort(projectID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(projectID))
+	return defaultSccachePort + 1 + int(h.Sum32()%sccachePortRange)
+}
+
+func (cm *CacheManager) SccacheServer(rootPath string) SccacheServer {
+	if globalConfig().Sccache.Dir != "" {
+		return SccacheServer{RootPath: rootPath, Dir: globalConfig().Sccache.Dir, Port: sccachePort(), Shared: true}
+	}
+	projectID := ComputeProjectID(rootPath)
+	return SccacheServer{
+		RootPath: rootPath,
+		Dir:      filepath.Join(cm.HomeDir, "sccache", projectID),
+		Port:     projectSccachePort(projectID),
+	}
+}
+
+func (s SccacheServer) Env() []string {
+	if s.Shared {
+		return SccacheEnv()
+	}
+	vars := []string{"SCCACHE_DIR=" + s.Dir, "SCCACHE_SERVER_PORT=" + strconv.Itoa(s.Port)}
+	if size := globalConfig().Sccache.CacheSize; size != "" {
+		vars = append(vars, "SCCACHE_CACHE_SIZE="+size)
+	}
+	return vars
+}
+
+func (s SccacheServer) Running() bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(s.Port)), 200*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func (s SccacheServer) command(args ...string) *Cmd {
+	return Command("sccache", args...).Env(append(os.Environ(), s.Env()...))
+}
+
+func (cm *CacheManager) StartSccache(server SccacheServer) (bool, error) {
+	if !cm.SccacheAvailable {
+		return false, fmt.Errorf("sccache not found in PATH")
+	}
+	if server.Running() {
+		return false, cm.checkSccacheOwner(server)
+	}
+	output, err := server.command("--start-server").CombinedOutput()
+	if err != nil {
+		return false, fmt.Errorf("failed to start sccache server: %w: %s", err, output)
+	}
+	return true, nil
+}
+
+func (cm *CacheManager) checkSccacheOwner(server SccacheServer) error {
+	if server.Shared {
+		return nil
+	}
+	stats, err := cm.SccacheStatus(server)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(stats.CacheLocation, server.Dir) {
+		return fmt.Errorf("port %d is used by another sccache server (%s)", server.Port, stats.CacheLocation)
+	}
+	return nil
+}
+
+func (cm *CacheManager) StopSccache(server SccacheServer) (bool, error) {
+	if !cm.SccacheAvailable {
+		return false, fmt.Errorf("sccache not found in PATH")
+	}
+	if !server.Running() {
+		return false, nil
+	}
+	if err := cm.checkSccacheOwner(server); err != nil {
+		return false, err
+	}
+	output, err := server.command("--stop-server").CombinedOutput()
+	if err != nil {
+		return false, fmt.Errorf("failed to stop sccache server: %w: %s", err, output)
+	}
+	return true, nil
+}
+
+func (cm *CacheManager) EnsureSccache(cfg BuildConfig, rootPath string) error {
+	if !cm.shouldEnableSccache(cfg) {
+		return nil
+	}
+	_, err := cm.StartSccache(cm.SccacheServer(rootPath))
+	return err
+}
+
+func (cm *CacheManager) stopProjectSccache(db *DB, rootPath string, logger *FileLogger) {
+	server := cm.SccacheServer(rootPath)
+	if server.Shared || !cm.SccacheAvailable {
+		return
+	}
+	envs, err := db.ListEnvironments()
+	if err != nil {
+		logger.Log("warning: %v", err)
+		return
+	}
+	for _, env := range envs {
+		if env.RootPath.Valid && env.RootPath.String == rootPath {
+			return
+		}
+	}
+	stopped, err := cm.StopSccache(server)
+	if err != nil {
+		logger.Log("warning: %v", err)
+	} else if stopped {
+		logger.Log("stopped sccache server for %s", rootPath)
+	}
+}
+
+func (cm *CacheManager) SccacheStatus(server SccacheServer) (SccacheStats, error) {
+	if !cm.SccacheAvailable {
+		return SccacheStats{}, fmt.Errorf("sccache not found in PATH")
+	}
+	if !server.Running() {
+		return SccacheStats{}, nil
+	}
+
+	output, err := server.command("--show-stats", "--stats-format", "json").Output()
+	if err != nil {
+		return SccacheStats{}, fmt.Errorf("failed to read sccache stats: %w", err)
+	}
+	return parseSccacheStats(output)
+}
+
+func (cm *CacheManager) ProjectSccacheStats(db *DB) ([]ProjectSccacheStats, error) {
+	if !cm.SccacheAvailable {
+		return nil, fmt.Errorf("sccache not found in PATH")
+	}
+	rootPaths, err := db.GetAllRootPaths()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(rootPaths)
+
+	var results []ProjectSccacheStats
+	seen := make(map[int]bool)
+	for _, rootPath := range rootPaths {
+		server := cm.SccacheServer(rootPath)
+		if seen[server.Port] {
+			continue
+		}
+		seen[server.Port] = true
+		stats, err := cm.SccacheStatus(server)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sccache stats for %s: %w", rootPath, err)
+		}
+		results = append(results, ProjectSccacheStats{Server: server, Stats: stats})
+	}
+	return results, nil
+}
+
+func parseSccacheStats(data []byte) (SccacheStats, error) {
+	var raw sccacheStatsJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return SccacheStats{}, fmt.Errorf("failed to parse sccache stats: %w", err)
+	}
+
+	stats := SccacheStats{
+		Running:       true,
+		CompileCount:  raw.Stats.CompileRequests,
+		Hits:          raw.Stats.CacheHits.total(),
+		Misses:        raw.Stats.CacheMisses.total(),
+		CacheLocation: raw.CacheLocation,
+	}
+	if raw.CacheSize != nil {
+		stats.CacheSize = *raw.CacheSize
+	}
+	if raw.MaxCacheSize != nil {
+		stats.MaxCacheSize = *raw.MaxCacheSize
+	}
+	return stats, nil
+}
@@ -0,0 +1,107 @@
+package mono
+
+import (
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestParseSccacheStats(t *testing.T) {
+	data := []byte(`{
+		"stats": {
+			"compile_requests": 12,
+			"cache_hits": {"counts": {"Rust": 7, "C/C++": 1}, "adv_counts": {"rust": 7}},
+			"cache_misses": {"counts": {"Rust": 2}}
+		},
+		"cache_location": "Local disk: \"/tmp/sccache\"",
+		"cache_size": 2048,
+		"max_cache_size": 10737418240
+	}`)
+
+	stats, err := parseSccacheStats(data)
+	if err != nil {
+		t.Fatalf("parseSccacheStats failed: %v", err)
+	}
+
+	if !stats.Running || stats.CompileCount != 12 || stats.Hits != 8 || stats.Misses != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats.CacheSize != 2048 || stats.MaxCacheSize != 10737418240 {
+		t.Errorf("unexpected sizes: %+v", stats)
+	}
+	if stats.HitRate() != 80 {
+		t.Errorf("expected 80%% hit rate, got %v", stats.HitRate())
+	}
+
+	if _, err := parseSccacheStats([]byte("not json")); err == nil {
+		t.Error("expected invalid stats to fail")
+	}
+}
+
+func TestSccacheEnvFromGlobalConfig(t *testing.T) {
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	if vars := SccacheEnv(); len(vars) != 0 {
+		t.Errorf("expected no sccache env by default, got %v", vars)
+	}
+
+	cfg := DefaultGlobalConfig()
+	cfg.Sccache = SccacheConfig{Dir: "/tmp/sccache", CacheSize: "20G"}
+	SetGlobalConfig(cfg)
+
+	vars := SccacheEnv()
+	if !slices.Contains(vars, "SCCACHE_DIR=/tmp/sccache") || !slices.Contains(vars, "SCCACHE_CACHE_SIZE=20G") {
+		t.Errorf("unexpected sccache env: %v", vars)
+	}
+
+	enabled := true
+	cm := &CacheManager{SccacheAvailable: true}
+	env := cm.EnvVars(BuildConfig{Sccache: &enabled}, t.TempDir(), "")
+	if !slices.Contains(env, "RUSTC_WRAPPER=sccache") || !slices.Contains(env, "SCCACHE_DIR=/tmp/sccache") {
+		t.Errorf("expected build env to carry sccache config, got %v", env)
+	}
+}
+
+func TestSccacheServerPerProject(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+	SetGlobalConfig(DefaultGlobalConfig())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	a := cm.SccacheServer("/src/project-a")
+	b := cm.SccacheServer("/src/project-b")
+	if a.Shared || a.Dir != filepath.Join(cm.HomeDir, "sccache", ComputeProjectID("/src/project-a")) {
+		t.Errorf("expected a per-project server under the mono home, got %+v", a)
+	}
+	if a.Port == b.Port || a.Dir == b.Dir {
+		t.Errorf("expected projects to get separate servers, got %+v and %+v", a, b)
+	}
+	for _, s := range []SccacheServer{a, b} {
+		if s.Port <= defaultSccachePort || s.Port > defaultSccachePort+sccachePortRange {
+			t.Errorf("port %d outside the per-project range", s.Port)
+		}
+	}
+	if again := cm.SccacheServer("/src/project-a"); again != a {
+		t.Errorf("expected a stable server for a project, got %+v and %+v", a, again)
+	}
+
+	enabled := true
+	cm.SccacheAvailable = true
+	env := cm.EnvVars(BuildConfig{Sccache: &enabled}, "/src/worktrees/feature", "/src/project-a")
+	want := []string{"RUSTC_WRAPPER=sccache", "SCCACHE_DIR=" + a.Dir, "SCCACHE_SERVER_PORT=" + strconv.Itoa(a.Port)}
+	if !slices.Equal(env, want) {
+		t.Errorf("expected %v, got %v", want, env)
+	}
+
+	cfg := DefaultGlobalConfig()
+	cfg.Sccache.Dir = "/tmp/sccache"
+	SetGlobalConfig(cfg)
+	if shared := cm.SccacheServer("/src/project-a"); !shared.Shared || shared.Port != defaultSccachePort || shared.Dir != "/tmp/sccache" {
+		t.Errorf("expected sccache.dir to select the shared server, got %+v", shared)
+	}
+}
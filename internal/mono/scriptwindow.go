@@ -0,0 +1,76 @@
+package mono
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const scriptsWindowName = "scripts"
+
+func ScriptsLogPath(envName string) (string, error) {
+	dataDir, err := DataDir(envName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "scripts.log"), nil
+}
+
+type scriptMirror struct {
+	mu     sync.Mutex
+	file   *os.File
+	logger *FileLogger
+}
+
+func (m *scriptMirror) Write(p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.file == nil {
+		return len(p), nil
+	}
+	if _, err := m.file.Write(p); err != nil {
+		m.logger.Log("warning: stopped mirroring script output: %v", err)
+		if err := m.file.Close(); err != nil {
+			m.logger.Log("warning: failed to close scripts log: %v", err)
+		}
+		m.file = nil
+	}
+	return len(p), nil
+}
+
+func (m *scriptMirror) Close() error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logger.SetMirror(nil)
+	if m.file == nil {
+		return nil
+	}
+	err := m.file.Close()
+	m.file = nil
+	return err
+}
+
+func startScriptsWindow(tm *TmuxManager, envName string, envVars []string, logger *FileLogger) (*scriptMirror, bool, error) {
+	logPath, err := ScriptsLogPath(envName)
+	if err != nil {
+		return nil, false, err
+	}
+	f, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open scripts log: %w", err)
+	}
+
+	created, err := tm.OpenScriptsWindow(logPath, envVars)
+	if err != nil {
+		f.Close()
+		return nil, false, err
+	}
+
+	mirror := &scriptMirror{file: f, logger: logger}
+	logger.SetMirror(mirror)
+	return mirror, created, nil
+}
@@ -0,0 +1,64 @@
+package mono
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScriptMirror(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	logger, err := NewFileLogger("env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logPath, err := ScriptsLogPath("env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mirror := &scriptMirror{file: f, logger: logger}
+	logger.SetMirror(mirror)
+
+	if err := runScript(context.Background(), t.TempDir(), "echo building; echo failed >&2", nil, logger); err != nil {
+		t.Fatalf("runScript failed: %v", err)
+	}
+	if err := mirror.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := runScript(context.Background(), t.TempDir(), "echo after", nil, logger); err != nil {
+		t.Fatalf("runScript failed: %v", err)
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"building\n", "failed\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in mirrored output %q", want, data)
+		}
+	}
+	if strings.Contains(string(data), "after") {
+		t.Errorf("expected no output after the mirror was closed, got %q", data)
+	}
+
+	monoLog, err := os.ReadFile(filepath.Join(os.Getenv("MONO_HOME"), "mono.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(monoLog), "[out] building") {
+		t.Errorf("expected script output to still be logged, got %q", monoLog)
+	}
+}
@@ -0,0 +1,181 @@
+package mono
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+const serviceHost = "127.0.0.1"
+
+type connectionSpec struct {
+	images []string
+	port   int
+	url    func(env map[string]string, host string) *url.URL
+}
+
+var connectionSpecs = []connectionSpec{
+	{
+		images: []string{"postgres", "postgis", "timescaledb", "pgvector"},
+		port:   5432,
+		url: func(env map[string]string, host string) *url.URL {
+			user := envOr(env, "POSTGRES_USER", "postgres")
+			u := &url.URL{Scheme: "postgres", Host: host, Path: "/" + envOr(env, "POSTGRES_DB", user), RawQuery: "sslmode=disable"}
+			u.User = userInfo(user, env["POSTGRES_PASSWORD"])
+			return u
+		},
+	},
+	{
+		images: []string{"mysql", "mariadb"},
+		port:   3306,
+		url: func(env map[string]string, host string) *url.URL {
+			user, password := "root", envOr(env, "MYSQL_ROOT_PASSWORD", env["MARIADB_ROOT_PASSWORD"])
+			if u := envOr(env, "MYSQL_USER", env["MARIADB_USER"]); u != "" {
+				user, password = u, envOr(env, "MYSQL_PASSWORD", env["MARIADB_PASSWORD"])
+			}
+			u := &url.URL{Scheme: "mysql", Host: host, Path: "/" + envOr(env, "MYSQL_DATABASE", env["MARIADB_DATABASE"])}
+			u.User = userInfo(user, password)
+			return u
+		},
+	},
+	{
+		images: []string{"redis", "valkey", "keydb"},
+		port:   6379,
+		url: func(env map[string]string, host string) *url.URL {
+			return &url.URL{Scheme: "redis", Host: host}
+		},
+	},
+	{
+		images: []string{"mongo"},
+		port:   27017,
+		url: func(env map[string]string, host string) *url.URL {
+			u := &url.URL{Scheme: "mongodb", Host: host, Path: "/"}
+			if user := env["MONGO_INITDB_ROOT_USERNAME"]; user != "" {
+				u.User = userInfo(user, env["MONGO_INITDB_ROOT_PASSWORD"])
+			}
+			return u
+		},
+	},
+	{
+		images: []string{"rabbitmq"},
+		port:   5672,
+		url: func(env map[string]string, host string) *url.URL {
+			u := &url.URL{Scheme: "amqp", Host: host, Path: "/"}
+			u.User = userInfo(envOr(env, "RABBITMQ_DEFAULT_USER", "guest"), envOr(env, "RABBITMQ_DEFAULT_PASS", "guest"))
+			return u
+		},
+	},
+}
+
+func envOr(env map[string]string, key, fallback string) string {
+	if v := env[key]; v != "" {
+		return v
+	}
+	return fallback
+}
+
+func userInfo(user, password string) *url.Userinfo {
+	if password == "" {
+		return url.User(user)
+	}
+	return url.UserPassword(user, password)
+}
+
+func imageName(image string) string {
+	name := image
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.IndexAny(name, ":@"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+func findConnectionSpec(image string) (connectionSpec, bool) {
+	name := imageName(image)
+	for _, spec := range connectionSpecs {
+		for _, candidate := range spec.images {
+			if name == candidate {
+				return spec, true
+			}
+		}
+	}
+	return connectionSpec{}, false
+}
+
+func describeConnections(project *types.Project, allocations []Allocation) {
+	for i := range allocations {
+		alloc := &allocations[i]
+		alloc.URL = ""
+
+		svc, ok := project.Services[alloc.Service]
+		if !ok {
+			continue
+		}
+		spec, ok := findConnectionSpec(svc.Image)
+		if !ok || spec.port != alloc.ContainerPort {
+			continue
+		}
+
+		env := make(map[string]string, len(svc.Environment))
+		for key, value := range svc.Environment {
+			if value != nil {
+				env[key] = *value
+			}
+		}
+		host := net.JoinHostPort(serviceHost, strconv.Itoa(alloc.HostPort))
+		alloc.URL = spec.url(env, host).String()
+	}
+}
+
+func resolvePublishedPorts(dockerProject string, project *types.Project, allocations []Allocation) error {
+	statuses, err := ListServiceStatuses(dockerProject)
+	if err != nil {
+		return fmt.Errorf("failed to resolve published ports: %w", err)
+	}
+	applyPublishers(allocations, statuses)
+	describeConnections(project, allocations)
+	return nil
+}
+
+func applyPublishers(allocations []Allocation, statuses []ServiceStatus) {
+	published := make(map[string]map[int]int)
+	for _, s := range statuses {
+		for _, p := range s.Publishers {
+			if p.PublishedPort == 0 {
+				continue
+			}
+			if published[s.Service] == nil {
+				published[s.Service] = make(map[int]int)
+			}
+			published[s.Service][p.TargetPort] = p.PublishedPort
+		}
+	}
+
+	for i := range allocations {
+		if port, ok := published[allocations[i].Service][allocations[i].ContainerPort]; ok {
+			allocations[i].HostPort = port
+		}
+	}
+}
+
+func serviceEnvPrefix(service string) string {
+	return "MONO_" + strings.ToUpper(strings.ReplaceAll(service, "-", "_"))
+}
+
+func connectionEnv(allocations []Allocation) map[string]string {
+	env := make(map[string]string)
+	for _, alloc := range allocations {
+		prefix := serviceEnvPrefix(alloc.Service)
+		env[fmt.Sprintf("%s_PORT_%d", prefix, alloc.ContainerPort)] = strconv.Itoa(alloc.HostPort)
+		if alloc.URL != "" {
+			env[prefix+"_URL"] = alloc.URL
+		}
+	}
+	return env
+}
@@ -0,0 +1,43 @@
+package mono
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+var ErrInterrupted = errors.New("interrupted")
+
+func notifyInterrupt(operation string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-sigs:
+			signal.Stop(sigs)
+			fmt.Fprintf(os.Stderr, "\nreceived %s, stopping %s and cleaning up (repeat to force quit)\n", sig, operation)
+			cancel(fmt.Errorf("%s %w by %s", operation, ErrInterrupted, sig))
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		close(done)
+		signal.Stop(sigs)
+		cancel(nil)
+	}
+}
+
+func interruptErr(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return context.Cause(ctx)
+}
@@ -0,0 +1,176 @@
+package mono
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const signatureSuffix = ".manifest.sig"
+
+type manifestSignature struct {
+	Key       string `json:"key"`
+	Signature string `json:"signature"`
+}
+
+func signaturePath(cachePath string) string {
+	return cachePath + signatureSuffix
+}
+
+func signingKeyPath() (string, error) {
+	home, err := GetMonoHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "keys", "cache.key"), nil
+}
+
+func loadSigningKey() (ed25519.PrivateKey, error) {
+	path, err := signingKeyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid signing key %s", path)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+func loadOrCreateSigningKey() (ed25519.PrivateKey, error) {
+	key, err := loadSigningKey()
+	if err != nil || key != nil {
+		return key, err
+	}
+
+	path, err := signingKeyPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create keys directory: %w", err)
+	}
+
+	_, key, err = ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(key.Seed()) + "\n"
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return loadSigningKey()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write signing key: %w", err)
+	}
+	if _, err := f.WriteString(encoded); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write signing key: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write signing key: %w", err)
+	}
+	return key, nil
+}
+
+func encodePublicKey(key ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func CachePublicKey() (string, error) {
+	key, err := loadOrCreateSigningKey()
+	if err != nil {
+		return "", err
+	}
+	return encodePublicKey(key.Public().(ed25519.PublicKey)), nil
+}
+
+func signManifest(cachePath string, data []byte) error {
+	key, err := loadOrCreateSigningKey()
+	if err != nil {
+		return err
+	}
+
+	sig, err := json.Marshal(manifestSignature{
+		Key:       encodePublicKey(key.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(signaturePath(cachePath), sig, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest signature: %w", err)
+	}
+	return nil
+}
+
+func trustedCacheKeys() (map[string]bool, error) {
+	trusted := make(map[string]bool)
+	for _, k := range globalConfig().Cache.TrustedKeys {
+		trusted[strings.TrimSpace(k)] = true
+	}
+
+	key, err := loadSigningKey()
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		trusted[encodePublicKey(key.Public().(ed25519.PublicKey))] = true
+	}
+	return trusted, nil
+}
+
+func checkManifestSignature(cachePath string, data []byte, required bool) (string, error) {
+	raw, err := os.ReadFile(signaturePath(cachePath))
+	if os.IsNotExist(err) {
+		if required {
+			return "manifest is unsigned", nil
+		}
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var sig manifestSignature
+	if err := json.Unmarshal(raw, &sig); err != nil {
+		return "manifest signature is malformed", nil
+	}
+
+	trusted, err := trustedCacheKeys()
+	if err != nil {
+		return "", err
+	}
+	if !trusted[sig.Key] {
+		return "manifest signed by untrusted key " + sig.Key, nil
+	}
+
+	pub, err := base64.StdEncoding.DecodeString(sig.Key)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return "manifest signature has an invalid key", nil
+	}
+	signature, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return "manifest signature is malformed", nil
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pub), data, signature) {
+		return "manifest signature does not match", nil
+	}
+	return "", nil
+}
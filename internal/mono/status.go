@@ -0,0 +1,227 @@
+package mono
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	statusSocketName   = "mono.sock"
+	maxSocketPathLen   = 100
+	statusQueryTimeout = 2 * time.Second
+)
+
+type OperationStatus struct {
+	Env       string    `json:"env"`
+	Operation string    `json:"operation"`
+	Phase     string    `json:"phase"`
+	Completed int64     `json:"completed"`
+	Total     int64     `json:"total"`
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+func (s OperationStatus) Progress() string {
+	switch {
+	case s.Total > 0:
+		pct := float64(s.Completed) / float64(s.Total) * 100
+		return fmt.Sprintf("%s: %.0f%% (%d/%d files)", s.Phase, pct, s.Completed, s.Total)
+	case s.Completed > 0:
+		return fmt.Sprintf("%s: %d files", s.Phase, s.Completed)
+	default:
+		return s.Phase
+	}
+}
+
+type StatusServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	status   OperationStatus
+	progress *ProgressLogger
+}
+
+func StatusSocketPath(envName string) (string, error) {
+	dataDir, err := DataDir(envName)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dataDir, statusSocketName)
+	if len(path) > maxSocketPathLen {
+		h := sha256.Sum256([]byte(path))
+		path = filepath.Join(os.TempDir(), "mono-"+hex.EncodeToString(h[:])[:12]+".sock")
+	}
+	return path, nil
+}
+
+func StartStatusServer(envName, operation string) (*StatusServer, error) {
+	path, err := StatusSocketPath(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	if current, err := QueryStatus(envName); err == nil {
+		return nil, fmt.Errorf("%s already in progress (pid %d)", current.Operation, current.PID)
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+
+	s := &StatusServer{
+		listener: listener,
+		status: OperationStatus{
+			Env:       envName,
+			Operation: operation,
+			PID:       os.Getpid(),
+			StartedAt: time.Now(),
+		},
+	}
+	go s.serve()
+
+	return s, nil
+}
+
+func (s *StatusServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *StatusServer) handle(conn net.Conn) {
+	defer conn.Close()
+	if err := conn.SetWriteDeadline(time.Now().Add(statusQueryTimeout)); err != nil {
+		return
+	}
+	json.NewEncoder(conn).Encode(s.Snapshot())
+}
+
+func (s *StatusServer) Snapshot() OperationStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.status
+	if s.progress != nil {
+		status.Phase = s.progress.operation
+		status.Completed = s.progress.completed.Load()
+		status.Total = s.progress.total
+	}
+	return status
+}
+
+func (s *StatusServer) SetPhase(phase string) {
+	profilePhase(phase)
+	progressPhase(phase)
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Phase = phase
+}
+
+func (s *StatusServer) track(p *ProgressLogger) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.progress = p
+}
+
+func (s *StatusServer) untrack(p *ProgressLogger) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.progress == p {
+		s.progress = nil
+	}
+}
+
+func (s *StatusServer) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+func QueryStatus(envName string) (*OperationStatus, error) {
+	path, err := StatusSocketPath(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout("unix", path, statusQueryTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetReadDeadline(time.Now().Add(statusQueryTimeout)); err != nil {
+		return nil, err
+	}
+
+	var status OperationStatus
+	if err := json.NewDecoder(conn).Decode(&status); err != nil {
+		return nil, fmt.Errorf("invalid status response: %w", err)
+	}
+	return &status, nil
+}
+
+type EnvironmentState struct {
+	Name       string
+	Path       string
+	Registered bool
+	Operation  *OperationStatus
+}
+
+func Status(path string) (*EnvironmentState, error) {
+	db, err := OpenDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	registered, err := db.EnvironmentExists(path)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &EnvironmentState{
+		Name:       EnvName(path),
+		Path:       path,
+		Registered: registered,
+	}
+
+	if op, err := QueryStatus(state.Name); err == nil {
+		state.Operation = op
+	}
+
+	if !state.Registered && state.Operation == nil {
+		return nil, fmt.Errorf("environment not found: %s", path)
+	}
+
+	return state, nil
+}
@@ -0,0 +1,58 @@
+package mono
+
+import (
+	"testing"
+)
+
+func TestStatusServerReportsProgress(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	status, err := StartStatusServer("status-test", "init")
+	if err != nil {
+		t.Fatalf("failed to start status server: %v", err)
+	}
+	defer status.Close()
+
+	if _, err := StartStatusServer("status-test", "sync"); err == nil {
+		t.Error("expected second status server for same environment to fail")
+	}
+
+	status.SetPhase("running init script")
+	got, err := QueryStatus("status-test")
+	if err != nil {
+		t.Fatalf("failed to query status: %v", err)
+	}
+	if got.Operation != "init" || got.Phase != "running init script" {
+		t.Errorf("unexpected status: %+v", got)
+	}
+
+	logger := &FileLogger{status: status}
+	progress := NewProgressLogger(logger, "restoring cargo", 200)
+	for i := 0; i < 126; i++ {
+		progress.completed.Add(1)
+	}
+
+	got, err = QueryStatus("status-test")
+	if err != nil {
+		t.Fatalf("failed to query status: %v", err)
+	}
+	if want := "restoring cargo: 63% (126/200 files)"; got.Progress() != want {
+		t.Errorf("progress = %q, want %q", got.Progress(), want)
+	}
+
+	progress.Done()
+	got, err = QueryStatus("status-test")
+	if err != nil {
+		t.Fatalf("failed to query status: %v", err)
+	}
+	if got.Total != 0 {
+		t.Errorf("expected progress to be cleared after Done, got %+v", got)
+	}
+
+	if err := status.Close(); err != nil {
+		t.Fatalf("failed to close status server: %v", err)
+	}
+	if _, err := QueryStatus("status-test"); err == nil {
+		t.Error("expected query to fail after server closed")
+	}
+}
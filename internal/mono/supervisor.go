@@ -0,0 +1,450 @@
+package mono
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	ProcessStarting = "starting"
+	ProcessRunning  = "running"
+	ProcessBackoff  = "backoff"
+	ProcessExited   = "exited"
+	ProcessFailed   = "failed"
+	ProcessStopped  = "stopped"
+
+	processMinBackoff  = time.Second
+	processMaxBackoff  = 30 * time.Second
+	processStableAfter = 10 * time.Second
+	processStopTimeout = 10 * time.Second
+	readinessInterval  = 250 * time.Millisecond
+)
+
+type ProcessState struct {
+	Name      string    `json:"name"`
+	Command   string    `json:"command"`
+	PID       int       `json:"pid,omitempty"`
+	Port      int       `json:"port,omitempty"`
+	Status    string    `json:"status"`
+	Ready     bool      `json:"ready"`
+	Restarts  int       `json:"restarts"`
+	ExitCode  int       `json:"exit_code"`
+	StartedAt time.Time `json:"started_at"`
+	Log       string    `json:"log"`
+}
+
+type SupervisorState struct {
+	PID       int            `json:"pid"`
+	StartedAt time.Time      `json:"started_at"`
+	Processes []ProcessState `json:"processes"`
+}
+
+func (s *SupervisorState) Running() bool {
+	return s != nil && processAlive(s.PID)
+}
+
+func ProcessesDir(envName string) (string, error) {
+	dataDir, err := DataDir(envName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "processes"), nil
+}
+
+func supervisorStatePath(envName string) (string, error) {
+	dir, err := ProcessesDir(envName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "state.json"), nil
+}
+
+func ReadSupervisorState(envName string) (*SupervisorState, error) {
+	path, err := supervisorStatePath(envName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read supervisor state: %w", err)
+	}
+	var state SupervisorState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid supervisor state: %w", err)
+	}
+	return &state, nil
+}
+
+type supervisor struct {
+	mu        sync.Mutex
+	state     SupervisorState
+	statePath string
+	path      string
+	configs   []ProcessConfig
+	env       []string
+	nix       NixConfig
+	output    *processOutput
+	logger    *FileLogger
+}
+
+func (s *supervisor) update(i int, fn func(*ProcessState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn(&s.state.Processes[i])
+	data, err := json.Marshal(s.state)
+	if err != nil {
+		s.logger.Log("warning: failed to encode supervisor state: %v", err)
+		return
+	}
+	if err := writeFileAtomic(s.statePath, data, 0644); err != nil {
+		s.logger.Log("warning: failed to write supervisor state: %v", err)
+	}
+}
+
+func RunSupervisor(ctx context.Context, path string) error {
+	envName := EnvName(path)
+
+	logger, err := NewFileLogger(envName)
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer logger.Close()
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.ApplyDefaults(path)
+	if len(cfg.Processes) == 0 {
+		return fmt.Errorf("no processes configured for %s", path)
+	}
+
+	dir, err := ProcessesDir(envName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create processes directory: %w", err)
+	}
+	statePath, err := supervisorStatePath(envName)
+	if err != nil {
+		return err
+	}
+	outputPath, err := processOutputPath(envName)
+	if err != nil {
+		return err
+	}
+	output, err := os.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open process output: %w", err)
+	}
+	defer output.Close()
+
+	s := &supervisor{
+		state:     SupervisorState{PID: os.Getpid(), StartedAt: time.Now().UTC()},
+		statePath: statePath,
+		path:      path,
+		env:       os.Environ(),
+		nix:       cfg.Nix,
+		output:    &processOutput{file: output, logger: logger},
+		logger:    logger,
+	}
+	for _, name := range cfg.ProcessNames() {
+		s.configs = append(s.configs, cfg.Processes[name])
+		s.state.Processes = append(s.state.Processes, ProcessState{
+			Name:    name,
+			Command: cfg.Processes[name].Command,
+			Port:    s.port(name),
+			Status:  ProcessStarting,
+			Log:     filepath.Join(dir, name+".log"),
+		})
+	}
+
+	logger.Log("supervisor started (pid %d) for %d processes", os.Getpid(), len(s.state.Processes))
+
+	var wg sync.WaitGroup
+	for i := range s.state.Processes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.supervise(ctx, i)
+		}()
+	}
+	<-ctx.Done()
+	wg.Wait()
+
+	logger.Log("supervisor stopped")
+	return nil
+}
+
+func (s *supervisor) supervise(ctx context.Context, i int) {
+	s.mu.Lock()
+	proc := s.state.Processes[i]
+	s.mu.Unlock()
+	cfg := s.configs[i]
+
+	backoff := processMinBackoff
+	restarts := 0
+	for {
+		start := time.Now()
+		exitCode, err := s.runOnce(ctx, i, proc, cfg.Ready)
+		if ctx.Err() != nil {
+			s.update(i, func(p *ProcessState) {
+				p.Status = ProcessStopped
+				p.PID = 0
+				p.Ready = false
+			})
+			return
+		}
+
+		if err != nil {
+			s.logger.Log("process %s failed to start: %v", proc.Name, err)
+		}
+
+		giveUp := cfg.Restart == RestartNever ||
+			(cfg.Restart == RestartOnFailure && exitCode == 0) ||
+			(cfg.MaxRetries > 0 && restarts >= cfg.MaxRetries)
+		if giveUp {
+			final := ProcessExited
+			if exitCode != 0 {
+				final = ProcessFailed
+			}
+			s.logger.Log("process %s exited with code %d after %d restarts, not restarting (restart: %s)", proc.Name, exitCode, restarts, cfg.Restart)
+			s.update(i, func(p *ProcessState) {
+				p.Status = final
+				p.PID = 0
+				p.Ready = false
+				p.ExitCode = exitCode
+			})
+			return
+		}
+
+		s.logger.Log("process %s exited with code %d, restarting in %s", proc.Name, exitCode, backoff)
+		if time.Since(start) > processStableAfter {
+			backoff = processMinBackoff
+		}
+		restarts++
+		s.update(i, func(p *ProcessState) {
+			p.Status = ProcessBackoff
+			p.PID = 0
+			p.Ready = false
+			p.ExitCode = exitCode
+			p.Restarts = restarts
+		})
+
+		select {
+		case <-ctx.Done():
+			s.update(i, func(p *ProcessState) {
+				p.Status = ProcessStopped
+			})
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, processMaxBackoff)
+	}
+}
+
+func (s *supervisor) port(name string) int {
+	prefix := portEnvVar(name) + "="
+	for _, kv := range slices.Backward(s.env) {
+		if value, ok := strings.CutPrefix(kv, prefix); ok {
+			port, err := strconv.Atoi(value)
+			if err != nil {
+				return 0
+			}
+			return port
+		}
+	}
+	return 0
+}
+
+func (s *supervisor) processEnv(proc ProcessState) []string {
+	if proc.Port == 0 {
+		return s.env
+	}
+	return append(slices.Clip(s.env), fmt.Sprintf("PORT=%d", proc.Port))
+}
+
+func (s *supervisor) command(ctx context.Context, command string) *exec.Cmd {
+	if s.nix.active(s.path) {
+		args := append(s.nix.developArgs(s.path), "sh", "-c", command)
+		return exec.CommandContext(ctx, "nix", args...)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+func (s *supervisor) runOnce(ctx context.Context, i int, proc ProcessState, ready ReadinessConfig) (int, error) {
+	logFile, err := os.OpenFile(proc.Log, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return -1, fmt.Errorf("failed to open log: %w", err)
+	}
+	defer logFile.Close()
+
+	info, err := logFile.Stat()
+	if err != nil {
+		return -1, fmt.Errorf("failed to stat log: %w", err)
+	}
+	logOffset := info.Size()
+
+	env := s.processEnv(proc)
+
+	cmd := s.command(ctx, proc.Command)
+	cmd.Dir = s.path
+	cmd.Env = env
+	stdout := &processStream{output: s.output, log: logFile, process: proc.Name, stream: "out"}
+	stderr := &processStream{output: s.output, log: logFile, process: proc.Name, stream: "err"}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = processStopTimeout
+
+	if err := cmd.Start(); err != nil {
+		return -1, err
+	}
+	s.logger.Log("process %s started (pid %d)", proc.Name, cmd.Process.Pid)
+	s.update(i, func(p *ProcessState) {
+		p.Status = ProcessRunning
+		p.PID = cmd.Process.Pid
+		p.StartedAt = time.Now().UTC()
+		p.Ready = !ready.enabled()
+	})
+
+	runCtx, cancelProbe := context.WithCancel(ctx)
+	defer cancelProbe()
+	if ready.enabled() {
+		go s.probeReadiness(runCtx, i, proc, env, ready, logOffset)
+	}
+
+	err = cmd.Wait()
+	stdout.Flush()
+	stderr.Flush()
+	cancelProbe()
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) && ctx.Err() == nil {
+		return -1, err
+	}
+	return cmd.ProcessState.ExitCode(), nil
+}
+
+func StartSupervisor(path string, env []string, logger *FileLogger) error {
+	envName := EnvName(path)
+
+	state, err := ReadSupervisorState(envName)
+	if err != nil {
+		return err
+	}
+	if state.Running() {
+		logger.Log("supervisor already running (pid %d)", state.PID)
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate mono executable: %w", err)
+	}
+
+	dir, err := ProcessesDir(envName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create processes directory: %w", err)
+	}
+	out, err := os.OpenFile(filepath.Join(dir, "supervisor.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open supervisor log: %w", err)
+	}
+	defer out.Close()
+
+	cmd := exec.Command(executable, "supervise", path)
+	cmd.Dir = path
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start supervisor: %w", err)
+	}
+	pid := cmd.Process.Pid
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case err := <-exited:
+			return fmt.Errorf("supervisor exited during startup: %v (see %s)", err, out.Name())
+		case <-deadline:
+			return fmt.Errorf("supervisor did not report its state within 5s (see %s)", out.Name())
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		state, err := ReadSupervisorState(envName)
+		if err != nil {
+			return err
+		}
+		if state != nil && state.PID == pid {
+			logger.Log("started supervisor (pid %d)", pid)
+			return nil
+		}
+	}
+}
+
+func StopSupervisor(envName string, logger *FileLogger) (bool, error) {
+	state, err := ReadSupervisorState(envName)
+	if err != nil || state == nil {
+		return false, err
+	}
+
+	stopped := false
+	if state.Running() {
+		if err := syscall.Kill(state.PID, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return false, fmt.Errorf("failed to stop supervisor: %w", err)
+		}
+		deadline := time.Now().Add(processStopTimeout + 5*time.Second)
+		for processAlive(state.PID) && time.Now().Before(deadline) {
+			time.Sleep(100 * time.Millisecond)
+		}
+		if processAlive(state.PID) {
+			logger.Log("warning: supervisor (pid %d) did not stop, killing it", state.PID)
+			if err := syscall.Kill(state.PID, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+				return false, fmt.Errorf("failed to kill supervisor: %w", err)
+			}
+		}
+		stopped = true
+	}
+
+	for _, proc := range state.Processes {
+		if proc.PID > 0 && processAlive(proc.PID) {
+			logger.Log("killing leftover process %s (pid %d)", proc.Name, proc.PID)
+			if err := syscall.Kill(-proc.PID, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+				return stopped, fmt.Errorf("failed to kill process %s: %w", proc.Name, err)
+			}
+			stopped = true
+		}
+	}
+	return stopped, nil
+}
@@ -0,0 +1,32 @@
+package mono
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+func isJSLockType(baseType string) bool {
+	switch baseType {
+	case "npm", "yarn", "pnpm", "bun":
+		return true
+	}
+	return false
+}
+
+func isTaskCacheType(baseType string) bool {
+	return baseType == "turbo" || baseType == "nx"
+}
+
+func addTaskCacheLockFiles(cfg ArtifactConfig, f foundLockFile, lockFiles []foundLockFile) ArtifactConfig {
+	dir := filepath.Dir(f.relPath)
+	for _, lf := range lockFiles {
+		if isJSLockType(lf.spec.baseType) && filepath.Dir(lf.relPath) == dir {
+			cfg.KeyFiles = append(cfg.KeyFiles, lf.relPath)
+		}
+	}
+	return cfg
+}
+
+func shouldSkipTurboPath(relPath string) bool {
+	return strings.HasPrefix(relPath, "cookies/") || strings.HasPrefix(relPath, "daemon/") || strings.HasSuffix(relPath, ".log")
+}
@@ -0,0 +1,59 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDetectTaskCacheArtifacts(t *testing.T) {
+	testDir := t.TempDir()
+
+	for _, name := range []string{"turbo.json", "pnpm-lock.yaml", "apps/admin/nx.json", "apps/admin/package-lock.json", ".turbo/cache/turbo.json"} {
+		path := filepath.Join(testDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	byName := make(map[string]ArtifactConfig)
+	for _, a := range detectArtifacts(testDir) {
+		if err := validateArtifactPaths(a); err != nil {
+			t.Errorf("expected the detected %s artifact to be valid: %v", a.Name, err)
+		}
+		byName[a.Name] = a
+	}
+	if len(byName) != 4 {
+		t.Fatalf("expected pnpm, turbo, npm-apps-admin and nx-apps-admin artifacts, got %+v", byName)
+	}
+
+	turbo := byName["turbo"]
+	if !slices.Equal(turbo.Paths, []ArtifactPath{{Path: ".turbo", Type: "turbo"}}) {
+		t.Errorf("expected the turbo artifact to cache .turbo, got %v", turbo.Paths)
+	}
+	if !slices.Equal(turbo.KeyFiles, []string{"turbo.json", "pnpm-lock.yaml"}) || !slices.Equal(turbo.KeyCommands, []string{"node --version"}) {
+		t.Errorf("expected turbo to be keyed on turbo.json, the lockfile and node, got %v %v", turbo.KeyFiles, turbo.KeyCommands)
+	}
+	if turbo.WarmCommand != "" {
+		t.Errorf("expected no warm command for a task cache, got %q", turbo.WarmCommand)
+	}
+	if _, ok := byName["pnpm"]; !ok {
+		t.Error("expected node_modules to keep its own artifact")
+	}
+
+	nx := byName["nx-apps-admin"]
+	if !slices.Equal(nx.Paths, []ArtifactPath{{Path: "apps/admin/.nx/cache", Type: "nx"}}) || nx.WarmCommand != "" {
+		t.Errorf("unexpected nx artifact %+v", nx)
+	}
+	if !slices.Equal(nx.KeyFiles, []string{"apps/admin/nx.json", "apps/admin/package-lock.json"}) {
+		t.Errorf("expected nx to be keyed on nx.json and its lockfile, got %v", nx.KeyFiles)
+	}
+
+	if !shouldSkipPath("cookies/1.cookie", "turbo") || !shouldSkipPath("daemon/log", "turbo") || shouldSkipPath("cache/abc123.tar.zst", "turbo") {
+		t.Error("expected turbo's daemon state to be skipped and its cache kept")
+	}
+}
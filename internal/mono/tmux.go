@@ -4,22 +4,32 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"time"
 )
 
-const tmuxTimeout = 5 * time.Second
-
 func SessionName(envName string) string {
 	return fmt.Sprintf("mono-%s", envName)
 }
 
 func SessionExists(sessionName string) bool {
 	err := Command("tmux", "has-session", "-t", sessionName).
-		Timeout(tmuxTimeout).
+		Timeout(timeouts().Tmux).
 		Run()
 	return err == nil
 }
 
+func ProbeSession(sessionName string) error {
+	output, err := Command("tmux", "display-message", "-p", "-t", sessionName, "#{session_name}").
+		Timeout(timeouts().Tmux).
+		CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w", strings.TrimSpace(string(output)), err)
+	}
+	if got := strings.TrimSpace(string(output)); got != sessionName {
+		return fmt.Errorf("answered as %q", got)
+	}
+	return nil
+}
+
 func CreateSession(sessionName, workDir string, envVars []string) error {
 	args := []string{"new-session", "-d", "-s", sessionName, "-c", workDir}
 	for _, envVar := range envVars {
@@ -27,7 +37,7 @@ func CreateSession(sessionName, workDir string, envVars []string) error {
 	}
 
 	output, err := Command("tmux", args...).
-		Timeout(tmuxTimeout).
+		Timeout(timeouts().Tmux).
 		CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to create session: %s: %w", string(output), err)
@@ -38,10 +48,10 @@ func CreateSession(sessionName, workDir string, envVars []string) error {
 
 func SendKeys(sessionName, keys string) error {
 	Command("tmux", "send-keys", "-t", sessionName, "C-u").
-		Timeout(tmuxTimeout).
+		Timeout(timeouts().Tmux).
 		Run()
 	return Command("tmux", "send-keys", "-t", sessionName, keys, "Enter").
-		Timeout(tmuxTimeout).
+		Timeout(timeouts().Tmux).
 		Run()
 }
 
@@ -50,7 +60,7 @@ func KillSession(sessionName string) error {
 		return nil
 	}
 	return Command("tmux", "kill-session", "-t", sessionName).
-		Timeout(tmuxTimeout).
+		Timeout(timeouts().Tmux).
 		Run()
 }
 
@@ -60,7 +70,7 @@ func IsInsideTmux() bool {
 
 func ListMonoSessions() ([]string, error) {
 	output, err := Command("tmux", "list-sessions", "-F", "#{session_name}").
-		Timeout(tmuxTimeout).
+		Timeout(timeouts().Tmux).
 		Output()
 	if err != nil {
 		return nil, nil
@@ -112,17 +122,74 @@ func (tm *TmuxManager) Run(scriptPath string) error {
 
 func (tm *TmuxManager) interrupt() error {
 	return Command("tmux", "send-keys", "-t", tm.sessionName, "C-c").
-		Timeout(tmuxTimeout).
+		Timeout(timeouts().Tmux).
 		Run()
 }
 
 func (tm *TmuxManager) respawn(cmd string) error {
 	fullCmd := fmt.Sprintf("cd %q && %s", tm.workDir, cmd)
 	return Command("tmux", "respawn-pane", "-k", "-t", tm.sessionName, fullCmd).
-		Timeout(tmuxTimeout).
+		Timeout(timeouts().Tmux).
 		Run()
 }
 
 func (tm *TmuxManager) sendKeys(keys string) error {
 	return SendKeys(tm.sessionName, keys)
 }
+
+func (tm *TmuxManager) OpenScriptsWindow(logPath string, envVars []string) (bool, error) {
+	tail := "tail -n +1 -F " + shellQuote(logPath)
+	if !tm.SessionExists() {
+		args := []string{"new-session", "-d", "-s", tm.sessionName, "-n", scriptsWindowName, "-c", tm.workDir}
+		for _, envVar := range envVars {
+			args = append(args, "-e", envVar)
+		}
+		output, err := Command("tmux", append(args, tail)...).
+			Timeout(timeouts().Tmux).
+			CombinedOutput()
+		if err != nil {
+			return false, fmt.Errorf("failed to create session: %s: %w", string(output), err)
+		}
+		return true, nil
+	}
+
+	target := tm.sessionName + ":" + scriptsWindowName
+	if Command("tmux", "list-panes", "-t", target).Timeout(timeouts().Tmux).Run() == nil {
+		output, err := Command("tmux", "respawn-pane", "-k", "-t", target, tail).
+			Timeout(timeouts().Tmux).
+			CombinedOutput()
+		if err != nil {
+			return false, fmt.Errorf("failed to respawn scripts window: %s: %w", string(output), err)
+		}
+		return false, nil
+	}
+	output, err := Command("tmux", "new-window", "-d", "-t", tm.sessionName+":", "-n", scriptsWindowName, "-c", tm.workDir, tail).
+		Timeout(timeouts().Tmux).
+		CombinedOutput()
+	if err != nil {
+		return false, fmt.Errorf("failed to create scripts window: %s: %w", string(output), err)
+	}
+	return false, nil
+}
+
+func (tm *TmuxManager) AddShellWindow(envVars []string) error {
+	args := []string{"new-window", "-t", tm.sessionName + ":", "-c", tm.workDir}
+	for _, envVar := range envVars {
+		key, value, _ := strings.Cut(envVar, "=")
+		output, err := Command("tmux", "set-environment", "-t", tm.sessionName, key, value).
+			Timeout(timeouts().Tmux).
+			CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to set %s: %s: %w", key, string(output), err)
+		}
+		args = append(args, "-e", envVar)
+	}
+
+	output, err := Command("tmux", args...).
+		Timeout(timeouts().Tmux).
+		CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to create shell window: %s: %w", string(output), err)
+	}
+	return nil
+}
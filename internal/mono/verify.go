@@ -0,0 +1,328 @@
+package mono
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const manifestSuffix = ".manifest.json"
+
+const (
+	VerifyOK          = "ok"
+	VerifyUnverified  = "no manifest"
+	VerifyCorrupt     = "corrupt"
+	VerifyQuarantined = "quarantined"
+)
+
+var ErrCacheCorrupt = errors.New("cache entry is corrupt")
+
+type manifestFile struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256,omitempty"`
+	Link   string `json:"link,omitempty"`
+}
+
+type cacheManifest struct {
+	Size      int64                   `json:"size"`
+	DiskUsage int64                   `json:"disk_usage"`
+	Files     map[string]manifestFile `json:"files"`
+}
+
+type VerifyResult struct {
+	Entry      CacheSizeEntry
+	Status     string
+	Problems   []string
+	Quarantine string
+}
+
+func manifestPath(cachePath string) string {
+	return cachePath + manifestSuffix
+}
+
+func buildManifest(cachePath string, known map[string]manifestFile) (*cacheManifest, error) {
+	files, _, err := listDiffFiles(cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &cacheManifest{Files: make(map[string]manifestFile, len(files))}
+	tracker := newUsageTracker()
+	for _, f := range files {
+		m.Size += f.info.Size()
+		m.DiskUsage += tracker.add(f.info)
+	}
+
+	var mu sync.Mutex
+	var g errgroup.Group
+	g.SetLimit(runtime.NumCPU())
+
+	for rel, f := range files {
+		if f.info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(f.path)
+			if err != nil {
+				return nil, err
+			}
+			mu.Lock()
+			m.Files[rel] = manifestFile{Link: target}
+			mu.Unlock()
+			continue
+		}
+		if k, ok := known[rel]; ok && k.SHA256 != "" && k.Size == f.info.Size() {
+			mu.Lock()
+			m.Files[rel] = k
+			mu.Unlock()
+			continue
+		}
+		g.Go(func() error {
+			sum, err := fileSHA256(f.path)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			m.Files[rel] = manifestFile{Size: f.info.Size(), SHA256: sum}
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("failed to hash %s: %w", cachePath, err)
+	}
+	return m, nil
+}
+
+func writeManifest(cachePath string) error {
+	return rewriteManifest(cachePath, nil)
+}
+
+func rewriteManifest(cachePath string, known map[string]manifestFile) error {
+	m, err := buildManifest(cachePath, known)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	if err := signManifest(cachePath, data); err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(manifestPath(cachePath), data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache manifest: %w", err)
+	}
+	return nil
+}
+
+func readManifest(cachePath string) (*cacheManifest, []byte, error) {
+	data, err := os.ReadFile(manifestPath(cachePath))
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var m cacheManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, nil, fmt.Errorf("invalid cache manifest %s: %w", manifestPath(cachePath), err)
+	}
+	return &m, data, nil
+}
+
+func checkEntry(cachePath string, full bool) (string, []string, error) {
+	return verifyEntry(cachePath, full, globalConfig().Cache.RequireSignatures)
+}
+
+func checkRemoteEntry(cachePath string) (string, []string, error) {
+	return verifyEntry(cachePath, true, true)
+}
+
+func verifyEntry(cachePath string, full, requireSignature bool) (string, []string, error) {
+	m, data, err := readManifest(cachePath)
+	if err != nil {
+		return "", nil, err
+	}
+	if m == nil {
+		if requireSignature {
+			return VerifyCorrupt, []string{"manifest is missing"}, nil
+		}
+		return VerifyUnverified, nil, nil
+	}
+
+	var problems []string
+	problem, err := checkManifestSignature(cachePath, data, requireSignature)
+	if err != nil {
+		return "", nil, err
+	}
+	if problem != "" {
+		problems = append(problems, problem)
+	}
+
+	files, _, err := listDiffFiles(cachePath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for rel, want := range m.Files {
+		f, ok := files[rel]
+		if !ok {
+			problems = append(problems, "missing "+rel)
+			continue
+		}
+		isLink := f.info.Mode()&os.ModeSymlink != 0
+		if want.Link != "" || isLink {
+			if !isLink {
+				problems = append(problems, rel+": expected symlink")
+				continue
+			}
+			target, err := os.Readlink(f.path)
+			if err != nil {
+				return "", nil, err
+			}
+			if target != want.Link {
+				problems = append(problems, fmt.Sprintf("%s: symlink points to %s, expected %s", rel, target, want.Link))
+			}
+			continue
+		}
+		if f.info.Size() != want.Size {
+			problems = append(problems, fmt.Sprintf("%s: size %d, expected %d", rel, f.info.Size(), want.Size))
+			continue
+		}
+		if full {
+			sum, err := fileSHA256(f.path)
+			if err != nil {
+				return "", nil, err
+			}
+			if sum != want.SHA256 {
+				problems = append(problems, rel+": content changed")
+			}
+		}
+	}
+	for rel := range files {
+		if _, ok := m.Files[rel]; !ok {
+			problems = append(problems, "unexpected "+rel)
+		}
+	}
+
+	if len(problems) > 0 {
+		return VerifyCorrupt, problems, nil
+	}
+	return VerifyOK, nil, nil
+}
+
+func (cm *CacheManager) QuarantineDir() string {
+	return filepath.Join(cm.HomeDir, "cache_quarantine")
+}
+
+func (cm *CacheManager) quarantineEntry(cachePath string) (string, error) {
+	rel, err := filepath.Rel(cm.LocalCacheDir, cachePath)
+	if err != nil {
+		return "", err
+	}
+
+	dest := filepath.Join(cm.QuarantineDir(), fmt.Sprintf("%s-%d", strings.ReplaceAll(rel, string(os.PathSeparator), "-"), time.Now().Unix()))
+	if err := os.MkdirAll(cm.QuarantineDir(), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(cachePath, dest); err != nil {
+		return "", fmt.Errorf("failed to quarantine %s: %w", cachePath, err)
+	}
+	if err := removeManifest(cachePath); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+func (cm *CacheManager) verifyBeforeRestore(entry ArtifactCacheEntry, logger *FileLogger) error {
+	status, problems, err := checkEntry(entry.CachePath, false)
+	if err != nil {
+		return fmt.Errorf("failed to verify cache for %s: %w", entry.Name, err)
+	}
+	if status != VerifyCorrupt {
+		return nil
+	}
+
+	lock, err := cm.waitCacheLock(entry.CachePath)
+	if err != nil {
+		return fmt.Errorf("failed to lock cache entry: %w", err)
+	}
+	defer cm.releaseCacheLock(lock)
+
+	if !dirExists(entry.CachePath) {
+		return fmt.Errorf("%w: %s (key: %s) was removed", ErrCacheCorrupt, entry.Name, entry.Key)
+	}
+
+	dest, err := cm.quarantineEntry(entry.CachePath)
+	if err != nil {
+		return err
+	}
+	logger.Log("quarantined corrupt cache entry for %s to %s: %s", entry.Name, dest, strings.Join(problems, "; "))
+	return fmt.Errorf("%w: %s (key: %s), %d problems", ErrCacheCorrupt, entry.Name, entry.Key, len(problems))
+}
+
+func (cm *CacheManager) VerifyCache(repair bool) ([]VerifyResult, error) {
+	entries, err := cm.ListCacheEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []VerifyResult
+	for _, entry := range entries {
+		result, err := cm.verifyCacheEntry(entry, repair)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify %s/%s/%s: %w", entry.ProjectID, entry.Artifact, entry.CacheKey, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (cm *CacheManager) verifyCacheEntry(entry CacheSizeEntry, repair bool) (VerifyResult, error) {
+	result := VerifyResult{Entry: entry}
+	cachePath := cm.cacheEntryPath(entry)
+
+	lock, err := cm.waitCacheLock(cachePath)
+	if err != nil {
+		return result, err
+	}
+	defer cm.releaseCacheLock(lock)
+
+	result.Status, result.Problems, err = checkEntry(cachePath, true)
+	if err != nil {
+		return result, err
+	}
+
+	if result.Status != VerifyCorrupt || !repair {
+		return result, nil
+	}
+
+	result.Quarantine, err = cm.quarantineEntry(cachePath)
+	if err != nil {
+		return result, err
+	}
+	result.Status = VerifyQuarantined
+	cm.cleanEmptyParentDirs(filepath.Join(cm.LocalCacheDir, entry.ProjectID, entry.Artifact))
+	cm.cleanEmptyParentDirs(filepath.Join(cm.LocalCacheDir, entry.ProjectID))
+	return result, nil
+}
+
+func removeManifest(cachePath string) error {
+	for _, path := range []string{manifestPath(cachePath), signaturePath(cachePath)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package mono
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCorruptCacheEntryIsQuarantinedOnRestore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	logger, err := NewFileLogger("verify-test")
+	if err != nil {
+		t.Fatalf("NewFileLogger failed: %v", err)
+	}
+	defer logger.Close()
+
+	targetDir := filepath.Join(t.TempDir(), "env", "target")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatalf("failed to create target dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "lib.rlib"), []byte("original"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	entry := ArtifactCacheEntry{
+		Name:      "cargo",
+		Key:       "key123",
+		CachePath: filepath.Join(cm.LocalCacheDir, "proj", "cargo", "key123"),
+		EnvPaths:  []string{targetDir},
+	}
+	if err := cm.StoreToCache(entry); err != nil {
+		t.Fatalf("StoreToCache failed: %v", err)
+	}
+
+	results, err := cm.VerifyCache(false)
+	if err != nil {
+		t.Fatalf("VerifyCache failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != VerifyOK {
+		t.Fatalf("expected one ok entry, got %+v", results)
+	}
+
+	if err := os.WriteFile(filepath.Join(targetDir, "lib.rlib"), []byte("tampered"), 0644); err != nil {
+		t.Fatalf("failed to tamper file: %v", err)
+	}
+
+	results, err = cm.VerifyCache(false)
+	if err != nil {
+		t.Fatalf("VerifyCache failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != VerifyCorrupt {
+		t.Fatalf("expected corrupt entry after same-size write, got %+v", results)
+	}
+
+	if err := os.WriteFile(filepath.Join(targetDir, "lib.rlib"), []byte("truncated"), 0644); err != nil {
+		t.Fatalf("failed to tamper file: %v", err)
+	}
+	if err := os.RemoveAll(targetDir); err != nil {
+		t.Fatalf("failed to remove target dir: %v", err)
+	}
+
+	err = cm.RestoreFromCache(entry, logger)
+	if !errors.Is(err, ErrCacheCorrupt) {
+		t.Fatalf("expected ErrCacheCorrupt, got %v", err)
+	}
+	if dirExists(entry.CachePath) {
+		t.Error("corrupt entry should be moved out of the cache")
+	}
+	if dirExists(targetDir) {
+		t.Error("corrupt entry should not be restored")
+	}
+
+	quarantined, err := os.ReadDir(cm.QuarantineDir())
+	if err != nil {
+		t.Fatalf("failed to read quarantine dir: %v", err)
+	}
+	if len(quarantined) != 1 {
+		t.Errorf("expected 1 quarantined entry, got %d", len(quarantined))
+	}
+}
+
+func TestVerifyCacheRepair(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	cachePath := filepath.Join(cm.LocalCacheDir, "proj", "node_modules", "key1")
+	pkgDir := filepath.Join(cachePath, "node_modules", "pkg")
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(pkgDir, "index.js"), []byte("module.exports = 1"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := writeManifest(cachePath); err != nil {
+		t.Fatalf("writeManifest failed: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(pkgDir, "extra.js"), []byte("x"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	results, err := cm.VerifyCache(true)
+	if err != nil {
+		t.Fatalf("VerifyCache failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != VerifyQuarantined {
+		t.Fatalf("expected quarantined entry, got %+v", results)
+	}
+	if len(results[0].Problems) != 1 {
+		t.Errorf("expected 1 problem, got %v", results[0].Problems)
+	}
+	if dirExists(cachePath) {
+		t.Error("quarantined entry should be removed from the cache")
+	}
+	if _, err := os.Stat(manifestPath(cachePath)); !os.IsNotExist(err) {
+		t.Errorf("manifest should be removed, got %v", err)
+	}
+
+	db, err := OpenDB()
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+	sizes, err := cm.GetCacheSizes(db)
+	if err != nil {
+		t.Fatalf("GetCacheSizes failed: %v", err)
+	}
+	if len(sizes) != 0 {
+		t.Errorf("expected empty cache after repair, got %d entries", len(sizes))
+	}
+}
+
+func TestManifestSignature(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+
+	cachePath := filepath.Join(cm.LocalCacheDir, "proj", "cargo", "key1")
+	if err := os.MkdirAll(filepath.Join(cachePath, "target"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cachePath, "target", "out"), []byte("bin"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := writeManifest(cachePath); err != nil {
+		t.Fatalf("writeManifest failed: %v", err)
+	}
+
+	status, problems, err := checkEntry(cachePath, true)
+	if err != nil {
+		t.Fatalf("checkEntry failed: %v", err)
+	}
+	if status != VerifyOK {
+		t.Fatalf("expected signed entry to verify, got %s %v", status, problems)
+	}
+
+	data, err := os.ReadFile(manifestPath(cachePath))
+	if err != nil {
+		t.Fatalf("failed to read manifest: %v", err)
+	}
+	tampered := []byte(strings.Replace(string(data), `"size":3`, `"size":3 `, 1))
+	if err := os.WriteFile(manifestPath(cachePath), tampered, 0644); err != nil {
+		t.Fatalf("failed to tamper manifest: %v", err)
+	}
+
+	status, problems, err = checkEntry(cachePath, false)
+	if err != nil {
+		t.Fatalf("checkEntry failed: %v", err)
+	}
+	if status != VerifyCorrupt || len(problems) != 1 || problems[0] != "manifest signature does not match" {
+		t.Errorf("expected signature mismatch, got %s %v", status, problems)
+	}
+
+	if err := os.WriteFile(manifestPath(cachePath), data, 0644); err != nil {
+		t.Fatalf("failed to restore manifest: %v", err)
+	}
+	keyPath, err := signingKeyPath()
+	if err != nil {
+		t.Fatalf("signingKeyPath failed: %v", err)
+	}
+	if err := os.Remove(keyPath); err != nil {
+		t.Fatalf("failed to remove signing key: %v", err)
+	}
+
+	status, problems, err = checkEntry(cachePath, false)
+	if err != nil {
+		t.Fatalf("checkEntry failed: %v", err)
+	}
+	if status != VerifyCorrupt || len(problems) != 1 || !strings.HasPrefix(problems[0], "manifest signed by untrusted key") {
+		t.Errorf("expected untrusted key, got %s %v", status, problems)
+	}
+
+	if err := removeManifest(cachePath); err != nil {
+		t.Fatalf("removeManifest failed: %v", err)
+	}
+	status, _, err = checkEntry(cachePath, false)
+	if err != nil {
+		t.Fatalf("checkEntry failed: %v", err)
+	}
+	if status != VerifyUnverified {
+		t.Errorf("expected unsigned entry to be unverified by default, got %s", status)
+	}
+
+	cfg := DefaultGlobalConfig()
+	cfg.Cache.RequireSignatures = true
+	SetGlobalConfig(cfg)
+
+	status, _, err = checkEntry(cachePath, false)
+	if err != nil {
+		t.Fatalf("checkEntry failed: %v", err)
+	}
+	if status != VerifyCorrupt {
+		t.Errorf("expected unsigned entry to be refused when signatures are required, got %s", status)
+	}
+}
@@ -0,0 +1,116 @@
+package mono
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+type WaitForConfig struct {
+	TCP     string        `yaml:"tcp"`
+	HTTP    string        `yaml:"http"`
+	Status  int           `yaml:"status"`
+	Command string        `yaml:"command"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+func (w WaitForConfig) String() string {
+	switch {
+	case w.TCP != "":
+		return "tcp " + w.TCP
+	case w.HTTP != "":
+		return "http " + w.HTTP
+	default:
+		return "command " + w.Command
+	}
+}
+
+func (w *WaitForConfig) applyDefaults() {
+	if w.Timeout <= 0 {
+		w.Timeout = time.Minute
+	}
+}
+
+func validateWaitFor(conditions []WaitForConfig) error {
+	for i, w := range conditions {
+		kinds := 0
+		for _, v := range []string{w.TCP, w.HTTP, w.Command} {
+			if v != "" {
+				kinds++
+			}
+		}
+		if kinds != 1 {
+			return fmt.Errorf("wait_for entry %d must set exactly one of tcp, http or command", i+1)
+		}
+		if w.Status != 0 && w.HTTP == "" {
+			return fmt.Errorf("wait_for entry %d sets status without http", i+1)
+		}
+		if w.Status != 0 && (w.Status < 100 || w.Status > 599) {
+			return fmt.Errorf("wait_for entry %d has invalid status %d", i+1, w.Status)
+		}
+		if w.Timeout < 0 {
+			return fmt.Errorf("wait_for entry %d has negative timeout", i+1)
+		}
+	}
+	return nil
+}
+
+func WaitForConditions(ctx context.Context, conditions []WaitForConfig, workDir string, env []string, logger *FileLogger) error {
+	for _, w := range conditions {
+		if err := waitForCondition(ctx, w, workDir, env); err != nil {
+			return err
+		}
+		logger.Log("wait_for %s ready", w)
+	}
+	return nil
+}
+
+func waitForCondition(ctx context.Context, w WaitForConfig, workDir string, env []string) error {
+	deadline := time.Now().Add(w.Timeout)
+	ticker := time.NewTicker(readinessInterval)
+	defer ticker.Stop()
+
+	for {
+		err := checkCondition(ctx, w, workDir, env, deadline)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("wait_for %s not ready after %s: %w", w, w.Timeout, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %s %w", w, ErrInterrupted)
+		case <-ticker.C:
+		}
+	}
+}
+
+func checkCondition(ctx context.Context, w WaitForConfig, workDir string, env []string, deadline time.Time) error {
+	switch {
+	case w.TCP != "":
+		return checkTCP(ctx, expandEnv(w.TCP, env))
+	case w.HTTP != "":
+		return checkHTTP(ctx, expandEnv(w.HTTP, env), w.Status)
+	default:
+		return checkCommand(ctx, w.Command, workDir, env, deadline)
+	}
+}
+
+func checkCommand(ctx context.Context, command, workDir string, env []string, deadline time.Time) error {
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = workDir
+	cmd.Env = append(os.Environ(), env...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
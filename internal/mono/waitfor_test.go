@@ -0,0 +1,116 @@
+package mono
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigWaitFor(t *testing.T) {
+	dir := t.TempDir()
+	monoYml := `wait_for:
+  - tcp: 127.0.0.1:$MONO_DB_PORT
+  - http: http://127.0.0.1:$MONO_API_PORT/health
+    status: 204
+    timeout: 30s
+  - command: pg_isready
+`
+	if err := os.WriteFile(filepath.Join(dir, "mono.yml"), []byte(monoYml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	cfg.ApplyDefaults(dir)
+
+	if len(cfg.WaitFor) != 3 {
+		t.Fatalf("expected 3 conditions, got %+v", cfg.WaitFor)
+	}
+	if cfg.WaitFor[0].Timeout != time.Minute || cfg.WaitFor[1].Timeout != 30*time.Second {
+		t.Errorf("unexpected timeouts: %s, %s", cfg.WaitFor[0].Timeout, cfg.WaitFor[1].Timeout)
+	}
+	if got := cfg.WaitFor[2].String(); got != "command pg_isready" {
+		t.Errorf("unexpected description %q", got)
+	}
+
+	for name, entry := range map[string]string{
+		"no kind":            "  - timeout: 5s\n",
+		"two kinds":          "  - tcp: \"5432\"\n    command: pg_isready\n",
+		"status without url": "  - tcp: \"5432\"\n    status: 200\n",
+		"invalid status":     "  - http: http://localhost\n    status: 42\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := os.WriteFile(filepath.Join(dir, "mono.yml"), []byte("wait_for:\n"+entry), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadConfig(dir); err == nil {
+				t.Error("expected LoadConfig to fail")
+			}
+		})
+	}
+}
+
+func TestWaitForConditions(t *testing.T) {
+	t.Setenv("MONO_HOME", t.TempDir())
+
+	logger, err := NewFileLogger("env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	workDir := t.TempDir()
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		if err := os.WriteFile(filepath.Join(workDir, "ready"), nil, 0644); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	env := []string{"MONO_DB_PORT=" + port, "MONO_API_URL=" + server.URL}
+	conditions := []WaitForConfig{
+		{TCP: "$MONO_DB_PORT", Timeout: 5 * time.Second},
+		{HTTP: "${MONO_API_URL}/health", Status: http.StatusNoContent, Timeout: 5 * time.Second},
+		{Command: `test -f ready && test -n "$MONO_DB_PORT"`, Timeout: 5 * time.Second},
+	}
+	if err := WaitForConditions(context.Background(), conditions, workDir, env, logger); err != nil {
+		t.Fatalf("WaitForConditions failed: %v", err)
+	}
+
+	err = WaitForConditions(context.Background(), []WaitForConfig{
+		{HTTP: server.URL, Status: http.StatusOK, Timeout: 300 * time.Millisecond},
+	}, workDir, env, logger)
+	if err == nil || !strings.Contains(err.Error(), "not ready after") {
+		t.Errorf("expected an unexpected status to time out, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = WaitForConditions(ctx, []WaitForConfig{{Command: "false", Timeout: time.Minute}}, workDir, env, logger)
+	if err == nil || !strings.Contains(err.Error(), ErrInterrupted.Error()) {
+		t.Errorf("expected an interrupted wait, got %v", err)
+	}
+}
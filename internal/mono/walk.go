@@ -0,0 +1,103 @@
+package mono
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+type walkFunc func(path, rel string, d fs.DirEntry) error
+
+type parallelWalker struct {
+	fn      walkFunc
+	sem     chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+	err     error
+	stopped atomic.Bool
+}
+
+func parallelWalk(root string, workers int, fn walkFunc) error {
+	info, err := os.Lstat(root)
+	if err != nil {
+		return err
+	}
+
+	if workers <= 0 {
+		workers = workerCount(workersWalk, root)
+	}
+
+	w := &parallelWalker{fn: fn, sem: make(chan struct{}, workers-1)}
+
+	d := fs.FileInfoToDirEntry(info)
+	if err := fn(root, ".", d); err != nil {
+		if errors.Is(err, filepath.SkipDir) {
+			return nil
+		}
+		return err
+	}
+	if !d.IsDir() {
+		return nil
+	}
+
+	w.walkDir(root, "")
+	w.wg.Wait()
+	return w.err
+}
+
+func (w *parallelWalker) fail(err error) {
+	w.once.Do(func() {
+		w.err = err
+		w.stopped.Store(true)
+	})
+}
+
+func (w *parallelWalker) walkDir(dir, rel string) {
+	if w.stopped.Load() {
+		return
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		w.fail(err)
+		return
+	}
+
+	for _, e := range entries {
+		if w.stopped.Load() {
+			return
+		}
+
+		path := filepath.Join(dir, e.Name())
+		entryRel := filepath.Join(rel, e.Name())
+
+		if err := w.fn(path, entryRel, e); err != nil {
+			if e.IsDir() && errors.Is(err, filepath.SkipDir) {
+				continue
+			}
+			w.fail(err)
+			return
+		}
+
+		if !e.IsDir() {
+			continue
+		}
+
+		select {
+		case w.sem <- struct{}{}:
+			w.wg.Add(1)
+			go func() {
+				defer func() {
+					<-w.sem
+					w.wg.Done()
+				}()
+				w.walkDir(path, entryRel)
+			}()
+		default:
+			w.walkDir(path, entryRel)
+		}
+	}
+}
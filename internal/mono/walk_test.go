@@ -0,0 +1,98 @@
+package mono
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestParallelWalkMatchesWalkDir(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 20; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("pkg%d", i), "lib", "nested")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		for j := 0; j < 5; j++ {
+			if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%d.js", j)), []byte("x"), 0644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, "skip", "deep"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "skip", "deep", "ignored"), []byte("x"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	var want []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && rel == "skip" {
+			return filepath.SkipDir
+		}
+		want = append(want, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkDir failed: %v", err)
+	}
+
+	for _, workers := range []int{1, 4, 32} {
+		var mu sync.Mutex
+		var got []string
+		err := parallelWalk(root, workers, func(path, rel string, d fs.DirEntry) error {
+			if d.IsDir() && rel == "skip" {
+				return filepath.SkipDir
+			}
+			mu.Lock()
+			got = append(got, rel)
+			mu.Unlock()
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("parallelWalk(%d) failed: %v", workers, err)
+		}
+
+		sort.Strings(want)
+		sort.Strings(got)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("parallelWalk(%d) visited %d paths, want %d", workers, len(got), len(want))
+		}
+	}
+}
+
+func TestParallelWalkStopsOnError(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 10; i++ {
+		if err := os.WriteFile(filepath.Join(root, fmt.Sprintf("f%d", i)), []byte("x"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	boom := fmt.Errorf("boom")
+	err := parallelWalk(root, 4, func(path, rel string, d fs.DirEntry) error {
+		if rel == "f3" {
+			return boom
+		}
+		return nil
+	})
+	if err != boom {
+		t.Errorf("expected walk error to propagate, got %v", err)
+	}
+
+	if err := parallelWalk(filepath.Join(root, "missing"), 4, func(string, string, fs.DirEntry) error { return nil }); !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error for missing root, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package mono
+
+import (
+	"fmt"
+	"time"
+)
+
+type WarmResult struct {
+	Name     string
+	Key      string
+	Status   string
+	Err      error
+	Duration time.Duration
+}
+
+func WarmCache(rootPath string) ([]WarmResult, error) {
+	if !dirExists(rootPath) {
+		return nil, fmt.Errorf("path does not exist: %s", rootPath)
+	}
+
+	logger, err := NewFileLogger(EnvName(rootPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer logger.Close()
+
+	logger.Log("mono cache warm %s", rootPath)
+
+	ctx, stopSignals := notifyInterrupt("cache warm")
+	defer stopSignals()
+
+	cfg, err := LoadConfig(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.ApplyDefaults(rootPath)
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cache: %w", err)
+	}
+
+	db, err := OpenDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	envVars := append(cm.EnvVars(cfg.Build, rootPath, rootPath), "MONO_ROOT_PATH="+rootPath, "MONO_CACHE_DIR="+cm.LocalCacheDir)
+
+	var results []WarmResult
+	var warmed []ArtifactCacheEntry
+	for _, artifact := range cfg.Build.Artifacts {
+		start := time.Now()
+		result := WarmResult{Name: artifact.Name}
+
+		key, err := cm.ComputeCacheKey(artifact, rootPath)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to compute cache key: %w", err)
+			results = append(results, result)
+			continue
+		}
+		result.Key = key
+
+		switch {
+		case dirExists(cm.artifactCachePath(artifact, rootPath, key)):
+			result.Status = "hit"
+		case artifact.WarmCommand == "":
+			result.Status = "miss, no warm_command configured"
+		case cm.isBuildInProgress(rootPath, artifact):
+			result.Status = "skipped, build in progress"
+		default:
+			logger.Log("cache miss for %s (key: %s), running: %s", artifact.Name, key, artifact.WarmCommand)
+			if err := cm.EnsureSccache(cfg.Build, rootPath); err != nil {
+				logger.Log("warning: %v", err)
+			}
+			if err := runEnvScript(ctx, cfg, nil, rootPath, artifact.WarmCommand, envVars, logger); err != nil {
+				if err := interruptErr(ctx); err != nil {
+					return results, err
+				}
+				result.Err = fmt.Errorf("warm command failed: %w", err)
+				break
+			}
+			cm.keys.forgetCommands()
+
+			entry, err := cm.storeWarmedArtifact(artifact, rootPath)
+			result.Key, result.Err = entry.Key, err
+			if result.Err != nil {
+				break
+			}
+			if err := cm.RecordCacheSizes(db, []ArtifactCacheEntry{entry}); err != nil {
+				logger.Log("warning: %v", err)
+			}
+			if err := cm.UploadToRemote(ctx, entry); err != nil {
+				logger.Log("warning: failed to upload %s to remote cache: %v", artifact.Name, err)
+			}
+			if err := db.RecordCacheEvent("miss", ArtifactProjectID(artifact, rootPath), artifact.Name, result.Key); err != nil {
+				logger.Log("warning: failed to record cache miss: %v", err)
+			}
+			warmed = append(warmed, entry)
+			logger.Log("stored %s to cache (key: %s)", artifact.Name, result.Key)
+			result.Status = "warmed"
+		}
+
+		result.Duration = time.Since(start)
+		results = append(results, result)
+	}
+
+	if len(warmed) > 0 {
+		cm.enforceCacheLimit(db, warmed, logger)
+	}
+	return results, nil
+}
+
+func (cm *CacheManager) storeWarmedArtifact(artifact ArtifactConfig, rootPath string) (ArtifactCacheEntry, error) {
+	key, err := cm.ComputeCacheKey(artifact, rootPath)
+	if err != nil {
+		return ArtifactCacheEntry{}, fmt.Errorf("failed to compute cache key: %w", err)
+	}
+
+	var envPaths, pathTypes []string
+	for _, p := range artifact.Paths {
+		envPaths = append(envPaths, p.resolve(rootPath))
+		pathTypes = append(pathTypes, artifact.PathType(p))
+	}
+
+	entry := ArtifactCacheEntry{
+		Name:         artifact.Name,
+		Key:          key,
+		ProjectID:    ArtifactProjectID(artifact, rootPath),
+		CachePath:    cm.artifactCachePath(artifact, rootPath, key),
+		EnvPaths:     envPaths,
+		PathTypes:    pathTypes,
+		Format:       artifact.Format,
+		Include:      artifact.Include,
+		Exclude:      artifact.Exclude,
+		SkipPatterns: artifact.SkipPatterns,
+	}
+	if err := cm.StoreToCache(entry); err != nil {
+		return entry, fmt.Errorf("failed to store to cache: %w", err)
+	}
+	return entry, nil
+}
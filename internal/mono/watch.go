@@ -0,0 +1,219 @@
+package mono
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+type watchTarget struct {
+	artifact int
+	names    map[string]bool
+}
+
+type artifactWatcher struct {
+	watcher *fsnotify.Watcher
+	targets map[string][]watchTarget
+	roots   map[string][]int
+}
+
+func newArtifactWatcher(artifacts []ArtifactConfig, envPath string) (*artifactWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watcher: %w", err)
+	}
+	w := &artifactWatcher{
+		watcher: watcher,
+		targets: make(map[string][]watchTarget),
+		roots:   make(map[string][]int),
+	}
+
+	for i, artifact := range artifacts {
+		for _, keyFile := range artifact.KeyFiles {
+			path := filepath.Join(envPath, keyFile)
+			w.register(filepath.Dir(path), i, filepath.Base(path))
+		}
+		for _, p := range artifact.Paths {
+			root := p.resolve(envPath)
+			w.register(filepath.Dir(root), i, filepath.Base(root))
+			w.register(root, i)
+			w.roots[root] = append(w.roots[root], i)
+			if artifact.BuildLockFile != "" {
+				lock := filepath.Join(root, artifact.BuildLockFile)
+				w.register(filepath.Dir(lock), i, filepath.Base(lock))
+			}
+		}
+	}
+
+	for dir := range w.targets {
+		if err := w.watch(dir); err != nil {
+			return nil, errors.Join(err, watcher.Close())
+		}
+	}
+	for root, artifacts := range w.roots {
+		if err := w.watchChildren(root, artifacts); err != nil {
+			return nil, errors.Join(err, watcher.Close())
+		}
+	}
+	return w, nil
+}
+
+func (w *artifactWatcher) register(dir string, artifact int, names ...string) {
+	target := watchTarget{artifact: artifact}
+	if len(names) > 0 {
+		target.names = make(map[string]bool, len(names))
+		for _, name := range names {
+			target.names[name] = true
+		}
+	}
+	w.targets[dir] = append(w.targets[dir], target)
+}
+
+func (w *artifactWatcher) watch(dir string) error {
+	if !dirExists(dir) {
+		return nil
+	}
+	if err := w.watcher.Add(dir); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+	return nil
+}
+
+func (w *artifactWatcher) watchChildren(root string, artifacts []int) error {
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		child := filepath.Join(root, entry.Name())
+		if _, ok := w.targets[child]; !ok {
+			for _, artifact := range artifacts {
+				w.register(child, artifact)
+			}
+		}
+		if err := w.watch(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *artifactWatcher) handle(event fsnotify.Event, pending map[int]bool) error {
+	for _, target := range w.targets[filepath.Dir(event.Name)] {
+		if target.names == nil || target.names[filepath.Base(event.Name)] {
+			pending[target.artifact] = true
+		}
+	}
+	if !event.Has(fsnotify.Create) || !dirExists(event.Name) {
+		return nil
+	}
+
+	if artifacts, ok := w.roots[event.Name]; ok {
+		if err := w.watch(event.Name); err != nil {
+			return err
+		}
+		return w.watchChildren(event.Name, artifacts)
+	}
+	if artifacts, ok := w.roots[filepath.Dir(event.Name)]; ok {
+		if _, ok := w.targets[event.Name]; !ok {
+			for _, artifact := range artifacts {
+				w.register(event.Name, artifact)
+			}
+		}
+	}
+	if _, ok := w.targets[event.Name]; ok {
+		return w.watch(event.Name)
+	}
+	return nil
+}
+
+func (w *artifactWatcher) Close() error {
+	return w.watcher.Close()
+}
+
+func (cm *CacheManager) Watch(ctx context.Context, artifacts []ArtifactConfig, envPath string, debounce time.Duration, out io.Writer, sync func([]ArtifactConfig) error) error {
+	w, err := newArtifactWatcher(artifacts, envPath)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	pending := make(map[int]bool, len(artifacts))
+	for i := range artifacts {
+		pending[i] = true
+	}
+	waiting := make(map[int]bool)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
+			}
+			if err := w.handle(event, pending); err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				timer.Reset(debounce)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			if !errors.Is(err, fsnotify.ErrEventOverflow) {
+				return fmt.Errorf("failed to watch artifacts: %w", err)
+			}
+			for i := range artifacts {
+				pending[i] = true
+			}
+			timer.Reset(debounce)
+		case <-timer.C:
+			if !dirExists(envPath) {
+				fmt.Fprintf(out, "%s was removed, stopping\n", envPath)
+				return nil
+			}
+
+			var ready []ArtifactConfig
+			for _, i := range slices.Sorted(maps.Keys(pending)) {
+				if cm.isBuildInProgress(envPath, artifacts[i]) {
+					if !waiting[i] {
+						fmt.Fprintf(out, "waiting for the %s build to finish\n", artifacts[i].Name)
+						waiting[i] = true
+					}
+					continue
+				}
+				delete(pending, i)
+				delete(waiting, i)
+				ready = append(ready, artifacts[i])
+			}
+			if len(ready) > 0 {
+				cm.keys.reset()
+				if err := sync(ready); err != nil {
+					return err
+				}
+			}
+			if len(pending) > 0 {
+				timer.Reset(debounce)
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package mono
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchSyncsAfterBuild(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cm, err := NewCacheManager()
+	if err != nil {
+		t.Fatalf("failed to create cache manager: %v", err)
+	}
+
+	envPath := t.TempDir()
+	targetDir := filepath.Join(envPath, "target")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatalf("failed to create target dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(envPath, "Cargo.lock"), []byte("v1"), 0644); err != nil {
+		t.Fatalf("failed to write Cargo.lock: %v", err)
+	}
+
+	artifacts := []ArtifactConfig{
+		{
+			Name:          "build",
+			KeyFiles:      []string{"Cargo.lock"},
+			Paths:         []ArtifactPath{{Path: "target"}},
+			BuildLockFile: "build.lock",
+		},
+		{
+			Name:     "npm",
+			KeyFiles: []string{"package-lock.json"},
+			Paths:    []ArtifactPath{{Path: "node_modules"}},
+		},
+	}
+
+	cm.keys.command("git rev-parse HEAD")
+	cm.keys.storeFile(keyFileStamp{path: filepath.Join(envPath, "Cargo.lock")}, []byte("v1"))
+
+	synced := make(chan []string, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- cm.Watch(ctx, artifacts, envPath, 50*time.Millisecond, io.Discard, func(batch []ArtifactConfig) error {
+			if cm.keys.commands != nil || cm.keys.files != nil {
+				t.Error("expected key memos to be reset before each sync")
+			}
+			var names []string
+			for _, artifact := range batch {
+				names = append(names, artifact.Name)
+			}
+			synced <- names
+			return nil
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Watch failed: %v", err)
+		}
+	})
+
+	expectSync := func(want ...string) {
+		t.Helper()
+		select {
+		case got := <-synced:
+			if len(got) != len(want) {
+				t.Fatalf("expected sync of %v, got %v", want, got)
+			}
+			for i := range got {
+				if got[i] != want[i] {
+					t.Fatalf("expected sync of %v, got %v", want, got)
+				}
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected sync of %v, got none", want)
+		}
+	}
+	expectNoSync := func() {
+		t.Helper()
+		select {
+		case got := <-synced:
+			t.Fatalf("expected no sync, got %v", got)
+		case <-time.After(300 * time.Millisecond):
+		}
+	}
+
+	expectSync("build", "npm")
+
+	lock := filepath.Join(targetDir, "build.lock")
+	if err := os.WriteFile(lock, nil, 0644); err != nil {
+		t.Fatalf("failed to write build lock: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(targetDir, "debug"), 0755); err != nil {
+		t.Fatalf("failed to create debug dir: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(targetDir, "debug", "app"), []byte("binary"), 0644); err != nil {
+		t.Fatalf("failed to write build output: %v", err)
+	}
+	expectNoSync()
+
+	if err := os.Remove(lock); err != nil {
+		t.Fatalf("failed to remove build lock: %v", err)
+	}
+	expectSync("build")
+
+	if err := os.WriteFile(filepath.Join(targetDir, "debug", "app"), []byte("rebuilt"), 0644); err != nil {
+		t.Fatalf("failed to rewrite build output: %v", err)
+	}
+	expectSync("build")
+
+	if err := os.WriteFile(filepath.Join(envPath, "package-lock.json"), []byte("{}"), 0644); err != nil {
+		t.Fatalf("failed to write package-lock.json: %v", err)
+	}
+	expectSync("npm")
+
+	if err := os.WriteFile(filepath.Join(envPath, "README.md"), []byte("docs"), 0644); err != nil {
+		t.Fatalf("failed to write README.md: %v", err)
+	}
+	expectNoSync()
+}
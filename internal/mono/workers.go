@@ -0,0 +1,65 @@
+package mono
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	workersSeed  = "seed"
+	workersTouch = "touch"
+	workersWalk  = "walk"
+)
+
+func workerCount(kind string, paths ...string) int {
+	cfg := globalConfig().Workers
+	override := map[string]int{
+		workersSeed:  cfg.Seed,
+		workersTouch: cfg.Touch,
+		workersWalk:  cfg.Walk,
+	}[kind]
+	if override > 0 {
+		return limitJobs(override)
+	}
+
+	return limitJobs(autoWorkerCount(runtime.NumCPU(), onNetworkFilesystem(paths...)))
+}
+
+func limitJobs(workers int) int {
+	if jobs := globalConfig().Workers.Jobs; jobs > 0 {
+		return min(workers, jobs)
+	}
+	return workers
+}
+
+func autoWorkerCount(cpus int, network bool) int {
+	workers, limit := cpus*2, 32
+	if network {
+		workers, limit = cpus*4, 64
+	}
+	return max(4, min(workers, limit))
+}
+
+func onNetworkFilesystem(paths ...string) bool {
+	for _, p := range paths {
+		network, err := isNetworkFilesystem(existingAncestor(p))
+		if err == nil && network {
+			return true
+		}
+	}
+	return false
+}
+
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Lstat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
@@ -0,0 +1,65 @@
+package mono
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAutoWorkerCount(t *testing.T) {
+	tests := []struct {
+		cpus    int
+		network bool
+		want    int
+	}{
+		{1, false, 4},
+		{8, false, 16},
+		{32, false, 32},
+		{1, true, 4},
+		{8, true, 32},
+		{32, true, 64},
+	}
+
+	for _, tt := range tests {
+		if got := autoWorkerCount(tt.cpus, tt.network); got != tt.want {
+			t.Errorf("autoWorkerCount(%d, %t) = %d, want %d", tt.cpus, tt.network, got, tt.want)
+		}
+	}
+}
+
+func TestWorkerCountOverride(t *testing.T) {
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	dir := filepath.Join(t.TempDir(), "not", "created", "yet")
+
+	cfg := DefaultGlobalConfig()
+	cfg.Workers.Seed = 3
+	SetGlobalConfig(cfg)
+
+	if got := workerCount(workersSeed, dir); got != 3 {
+		t.Errorf("expected seed override 3, got %d", got)
+	}
+	if got := workerCount(workersTouch, dir); got < 4 {
+		t.Errorf("expected auto-tuned touch workers, got %d", got)
+	}
+}
+
+func TestWorkerCountJobsCap(t *testing.T) {
+	t.Cleanup(func() { SetGlobalConfig(nil) })
+
+	dir := t.TempDir()
+
+	cfg := DefaultGlobalConfig()
+	cfg.Workers.Seed = 8
+	cfg.Workers.Jobs = 2
+	SetGlobalConfig(cfg)
+
+	if got := workerCount(workersSeed, dir); got != 2 {
+		t.Errorf("expected seed workers capped at 2, got %d", got)
+	}
+	if got := workerCount(workersWalk, dir); got != 2 {
+		t.Errorf("expected walk workers capped at 2, got %d", got)
+	}
+	if got := limitJobs(1); got != 1 {
+		t.Errorf("expected per-artifact workers below the cap to stay 1, got %d", got)
+	}
+}
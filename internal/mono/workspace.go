@@ -0,0 +1,192 @@
+package mono
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type WorkspaceOptions struct {
+	Dir  string
+	Base string
+	Init InitOptions
+}
+
+type RemoveWorkspaceOptions struct {
+	Force        bool
+	DeleteBranch bool
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	result, err := Command("git", append([]string{"-C", dir}, args...)...).Timeout(timeouts().Script).RunCapture()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), bytes.TrimSpace(result.Stderr))
+	}
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
+
+func gitBranchExists(rootPath, branch string) (bool, error) {
+	result, err := Command("git", "-C", rootPath, "show-ref", "--verify", "--quiet", "refs/heads/"+branch).RunCapture()
+	if err != nil {
+		return false, fmt.Errorf("failed to look up branch %s: %w", branch, err)
+	}
+	return result.ExitCode == 0, nil
+}
+
+func mainWorktree(path string) (string, error) {
+	top, err := runGit(path, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("%s is not inside a git repository: %w", path, err)
+	}
+
+	root, err := DiscoverGitRoot(top)
+	if err != nil {
+		return "", err
+	}
+	if root == "" {
+		return top, nil
+	}
+	return root, nil
+}
+
+func DefaultWorkspacePath(rootPath, branch string) (string, error) {
+	home, err := GetMonoHome()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, "workspaces", filepath.Base(rootPath), strings.ReplaceAll(branch, "/", "-")), nil
+}
+
+func NewWorkspace(rootPath, branch string, opts WorkspaceOptions) (string, error) {
+	root, err := mainWorktree(rootPath)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := runGit(root, "check-ref-format", "--branch", branch); err != nil {
+		return "", fmt.Errorf("invalid branch name %q: %w", branch, err)
+	}
+
+	path := opts.Dir
+	if path == "" {
+		path, err = DefaultWorkspacePath(root, branch)
+		if err != nil {
+			return "", err
+		}
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("workspace path already exists: %s", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create workspace directory: %w", err)
+	}
+
+	exists, err := gitBranchExists(root, branch)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"worktree", "add", path, branch}
+	if !exists {
+		args = []string{"worktree", "add", "-b", branch, path}
+		if opts.Base != "" {
+			args = append(args, opts.Base)
+		}
+	} else if opts.Base != "" {
+		return "", fmt.Errorf("branch %s already exists, --base only applies to new branches", branch)
+	}
+	if _, err := runGit(root, args...); err != nil {
+		return "", err
+	}
+
+	initOpts := opts.Init
+	if initOpts.Root == "" {
+		initOpts.Root = root
+	}
+	if err := Init(path, initOpts, os.Stdout); err != nil {
+		if _, rmErr := runGit(root, "worktree", "remove", "--force", path); rmErr != nil {
+			return "", fmt.Errorf("%w (failed to remove worktree %s: %v)", err, path, rmErr)
+		}
+		return "", err
+	}
+
+	return path, nil
+}
+
+func DescribeRemoveWorkspace(path string, opts RemoveWorkspaceOptions) ([]string, error) {
+	root, err := DiscoverGitRoot(path)
+	if err != nil {
+		return nil, err
+	}
+	if root == "" {
+		return nil, fmt.Errorf("%s is not a linked git worktree", path)
+	}
+
+	items, err := DescribeDestroy(path, DestroyOptions{Force: opts.Force})
+	if err != nil {
+		return nil, err
+	}
+	items = append(items, "worktree: "+path)
+
+	if opts.DeleteBranch {
+		branch, err := runGit(path, "rev-parse", "--abbrev-ref", "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		if branch != "HEAD" {
+			items = append(items, "branch: "+branch)
+		}
+	}
+	return items, nil
+}
+
+func RemoveWorkspace(path string, opts RemoveWorkspaceOptions) error {
+	root, err := DiscoverGitRoot(path)
+	if err != nil {
+		return err
+	}
+	if root == "" {
+		return fmt.Errorf("%s is not a linked git worktree", path)
+	}
+
+	branch, err := runGit(path, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return err
+	}
+
+	if err := Destroy(path, DestroyOptions{Force: opts.Force}); err != nil {
+		return err
+	}
+
+	args := []string{"worktree", "remove", path}
+	if opts.Force {
+		args = []string{"worktree", "remove", "--force", path}
+	}
+	if _, err := runGit(root, args...); err != nil {
+		return err
+	}
+	fmt.Printf("Removed worktree: %s\n", path)
+
+	if !opts.DeleteBranch || branch == "HEAD" {
+		return nil
+	}
+
+	deleteFlag := "-d"
+	if opts.Force {
+		deleteFlag = "-D"
+	}
+	if _, err := runGit(root, "branch", deleteFlag, branch); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted branch: %s\n", branch)
+	return nil
+}
@@ -0,0 +1,99 @@
+package mono
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	return root
+}
+
+func TestDefaultWorkspacePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path, err := DefaultWorkspacePath("/src/bibliotek", "feature/login")
+	if err != nil {
+		t.Fatalf("DefaultWorkspacePath failed: %v", err)
+	}
+
+	want := filepath.Join(home, ".mono", "workspaces", "bibliotek", "feature-login")
+	if path != want {
+		t.Errorf("expected %s, got %s", want, path)
+	}
+	if EnvName(path) != "bibliotek-feature-login" {
+		t.Errorf("unexpected env name: %s", EnvName(path))
+	}
+}
+
+func TestMainWorktreeFromLinkedWorktree(t *testing.T) {
+	root := initTestRepo(t)
+	linked := filepath.Join(t.TempDir(), "linked")
+	if _, err := runGit(root, "worktree", "add", "-q", "-b", "linked", linked); err != nil {
+		t.Fatalf("failed to add worktree: %v", err)
+	}
+
+	for _, path := range []string{root, linked} {
+		got, err := mainWorktree(path)
+		if err != nil {
+			t.Fatalf("mainWorktree(%s) failed: %v", path, err)
+		}
+		if got != root {
+			t.Errorf("mainWorktree(%s) = %s, want %s", path, got, root)
+		}
+	}
+
+	if _, err := mainWorktree(t.TempDir()); err == nil {
+		t.Error("expected a directory outside git to fail")
+	}
+}
+
+func TestNewWorkspaceRejectsInvalidInput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := initTestRepo(t)
+
+	if _, err := NewWorkspace(root, "bad..name", WorkspaceOptions{}); err == nil || !strings.Contains(err.Error(), "invalid branch name") {
+		t.Errorf("expected invalid branch name error, got %v", err)
+	}
+
+	existing := t.TempDir()
+	if _, err := NewWorkspace(root, "feature", WorkspaceOptions{Dir: existing}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected existing path error, got %v", err)
+	}
+
+	if _, err := NewWorkspace(root, "main", WorkspaceOptions{Dir: filepath.Join(t.TempDir(), "ws"), Base: "HEAD"}); err == nil || !strings.Contains(err.Error(), "--base") {
+		t.Errorf("expected --base error for an existing branch, got %v", err)
+	}
+}
+
+func TestRemoveWorkspaceRequiresLinkedWorktree(t *testing.T) {
+	root := initTestRepo(t)
+
+	if err := RemoveWorkspace(root, RemoveWorkspaceOptions{}); err == nil || !strings.Contains(err.Error(), "not a linked git worktree") {
+		t.Errorf("expected main worktree to be rejected, got %v", err)
+	}
+
+	if _, err := os.Stat(root); err != nil {
+		t.Errorf("main worktree should be untouched: %v", err)
+	}
+}